@@ -0,0 +1,109 @@
+package player
+
+import (
+	"context"
+	"log/slog"
+)
+
+// afterCurrent - отложенная остановка в конце текущей песни.
+type afterCurrent struct {
+	// pause - приостановить, а не остановить воспроизведение
+	pause bool
+}
+
+// StoppedAfterCurrent - событие остановки StopAfterCurrent или PauseAfterCurrent.
+type StoppedAfterCurrent struct {
+	// Song - доигравшая песня
+	Song Song
+	// Next - песня, с которой продолжится воспроизведение, nil для пустого плейлиста
+	Next *Song
+	// Paused - воспроизведение приостановлено PauseAfterCurrent
+	Paused bool
+	// State - состояние плеера после остановки
+	State State
+}
+
+// StopAfterCurrent - останавливает воспроизведение, когда текущая песня доиграет
+// до конца: наложение и переход на следующую не начинаются, следующая
+// песня становится текущей с начала. Если песню пропустить, остановка
+// переносится на конец следующей. Заменяет ранее заданный PauseAfterCurrent.
+func (p *playerImpl) StopAfterCurrent(ctx context.Context) error {
+	return p.setAfterCurrent(ctx, "stop after current", false)
+}
+
+// PauseAfterCurrent - как StopAfterCurrent, но плеер остаётся в состоянии
+// StatePaused на следующей песне.
+func (p *playerImpl) PauseAfterCurrent(ctx context.Context) error {
+	return p.setAfterCurrent(ctx, "pause after current", true)
+}
+
+// CancelAfterCurrent - отменяет StopAfterCurrent и PauseAfterCurrent.
+func (p *playerImpl) CancelAfterCurrent(ctx context.Context) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if p.afterCurrent == nil {
+		return nil
+	}
+
+	p.afterCurrent = nil
+	// наложение со следующей песней снова возможно
+	p.rescheduleLocked()
+	p.logger.DebugContext(ctx, "stop after current cancelled")
+	return nil
+}
+
+// setAfterCurrent - задаёт отложенную остановку.
+func (p *playerImpl) setAfterCurrent(ctx context.Context, op string, pause bool) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if err := p.requireStateLocked(op, StatePlaying, StateTransitioning, StatePaused); err != nil {
+		return err
+	}
+
+	p.afterCurrent = &afterCurrent{pause: pause}
+	p.rescheduleLocked()
+	p.logger.DebugContext(ctx, "stop after current set", songAttr(*p.current.song), slog.Bool("pause", pause))
+	return nil
+}
+
+// stopAfterCurrentLocked - выполняет отложенную остановку после доигравшей песни prev:
+// текущей становится upcoming, песня очереди Enqueue или первая песня плейлиста.
+// Вызывается под блокировкой.
+func (p *playerImpl) stopAfterCurrentLocked(ctx context.Context, prev, upcoming *playerNode) {
+	ac := p.afterCurrent
+	p.afterCurrent = nil
+
+	next := p.dequeueLocked(upcoming)
+	if next == nil {
+		next = upcoming
+	}
+	if next == nil {
+		next = p.first()
+	}
+
+	p.haltLocked(ctx)
+	p.moveToLocked(next)
+	p.paused = ac.pause && next != nil
+
+	event := StoppedAfterCurrent{Song: *prev.song, Paused: p.paused, State: p.stateLocked()}
+	if next != nil {
+		song := *next.song
+		event.Next = &song
+	}
+	p.publishLocked(EventStoppedAfterCurrent, event)
+	p.logger.InfoContext(ctx, "playback stopped after current song", songAttr(*prev.song), slog.Bool("paused", p.paused))
+}
@@ -0,0 +1,91 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_StopAfterCurrent(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stop", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSongs("a", "b", "c")...))
+		s, err := pl.Subscribe(ctx, SubscribeKinds(EventStoppedAfterCurrent))
+		td.CmpNoError(t, err)
+		defer s.Close()
+
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpNoError(t, pl.StopAfterCurrent(ctx))
+
+		played, err := pl.SimulatePlayback(ctx, 5*time.Minute)
+		td.CmpNoError(t, err)
+		td.Cmp(t, titles(played), []string{"a"}, "a доиграла, b не началась")
+
+		st := pl.Status(ctx)
+		td.Cmp(t, pl.State(ctx), StateStopped)
+		td.Cmp(t, st.Song.Name, "b")
+		td.Cmp(t, st.Position, time.Duration(0))
+
+		e := <-s.C
+		td.Cmp(t, e.Kind, EventStoppedAfterCurrent)
+		td.Cmp(t, e.Data, StoppedAfterCurrent{Song: minuteSong("a"), Next: &Song{Name: "b", Duration: time.Minute}, State: StateStopped})
+
+		td.CmpNoError(t, pl.Play(ctx))
+		played, err = pl.SimulatePlayback(ctx, 90*time.Second)
+		td.CmpNoError(t, err)
+		td.Cmp(t, titles(played), []string{"b", "c"}, "остановка срабатывает один раз")
+	})
+
+	t.Run("pause", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSongs("a", "b", "c")...))
+		td.CmpNoError(t, pl.PlayAt(ctx, 2))
+		td.CmpNoError(t, pl.PauseAfterCurrent(ctx))
+
+		_, err := pl.SimulatePlayback(ctx, 5*time.Minute)
+		td.CmpNoError(t, err)
+		td.Cmp(t, pl.State(ctx), StatePaused)
+		td.Cmp(t, pl.Status(ctx).Song.Name, "a", "после последней песни - первая")
+	})
+
+	t.Run("crossfade", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSongs("a", "b", "c")...), WithCrossfade(10*time.Second))
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpNoError(t, pl.StopAfterCurrent(ctx))
+
+		_, err := pl.SimulatePlayback(ctx, 55*time.Second)
+		td.CmpNoError(t, err)
+		td.Cmp(t, pl.Status(ctx).Song.Name, "a", "наложение не начинается")
+		td.Cmp(t, pl.State(ctx), StatePlaying)
+
+		td.CmpNoError(t, pl.CancelAfterCurrent(ctx))
+		played, err := pl.SimulatePlayback(ctx, 10*time.Second)
+		td.CmpNoError(t, err)
+		td.Cmp(t, titles(played), []string{"a", "b"}, "после отмены переход идёт как обычно")
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSongs("a", "b", "c")...))
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpNoError(t, pl.StopAfterCurrent(ctx))
+		td.CmpNoError(t, pl.Next(ctx))
+
+		played, err := pl.SimulatePlayback(ctx, 5*time.Minute)
+		td.CmpNoError(t, err)
+		td.Cmp(t, titles(played), []string{"b"}, "остановка переносится на следующую песню")
+		td.Cmp(t, pl.Status(ctx).Song.Name, "c")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSongs("a", "b", "c")...))
+		td.CmpTrue(t, errors.Is(pl.StopAfterCurrent(ctx), ErrInvalidState), "воспроизведение не начато")
+		td.CmpNoError(t, pl.CancelAfterCurrent(ctx), "отменять нечего")
+
+		td.CmpNoError(t, pl.Close(ctx))
+		td.CmpTrue(t, errors.Is(pl.PauseAfterCurrent(ctx), ErrClosed))
+		td.CmpTrue(t, errors.Is(pl.CancelAfterCurrent(ctx), ErrClosed))
+	})
+}
@@ -0,0 +1,67 @@
+package player
+
+import (
+	_ "embed"
+)
+
+// StatusResponse - ответ GET /status.
+type StatusResponse struct {
+	Status
+	// State - состояние воспроизведения, например "playing"
+	State State `json:"state"`
+}
+
+// AddSongResponse - ответ POST /queue.
+type AddSongResponse struct {
+	// ID - ID добавленной песни
+	ID SongID `json:"id"`
+}
+
+// VoteResponse - ответ POST /vote.
+type VoteResponse struct {
+	// Skipped - голосов хватило, и песня пропущена
+	Skipped bool `json:"skipped"`
+}
+
+// VolumeRequest - тело PUT /volume.
+type VolumeRequest struct {
+	// Volume - громкость от 0 до 100, обязательна
+	Volume *int `json:"volume"`
+}
+
+// MoveRequest - тело POST /queue/{id}/move.
+type MoveRequest struct {
+	// Index - новая позиция песни, считая с нуля, обязательна
+	Index *int `json:"index"`
+}
+
+// EQResponse - ответ GET /eq.
+type EQResponse struct {
+	// Bands - усиление полос в децибелах, пусто если эквалайзер не задавался
+	Bands []float64 `json:"bands"`
+	// Presets - названия предустановок для PUT /eq
+	Presets []string `json:"presets"`
+}
+
+// EQRequest - тело PUT /eq, задаётся ровно одно из полей.
+type EQRequest struct {
+	// Bands - усиление EQBands полос в децибелах от -12 до 12
+	Bands []float64 `json:"bands,omitempty"`
+	// Preset - название предустановки из EQPresets
+	Preset string `json:"preset,omitempty"`
+}
+
+// ErrorResponse - тело ответа с ошибкой.
+type ErrorResponse struct {
+	// Error - текст ошибки
+	Error string `json:"error"`
+}
+
+//go:embed openapi.json
+var openAPI []byte
+
+// OpenAPI - возвращает описание HTTP API в формате OpenAPI 3.
+// Тот же документ HTTPHandler отдаёт по GET /openapi.json без аутентификации.
+func OpenAPI() []byte {
+	return append([]byte(nil), openAPI...)
+}
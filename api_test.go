@@ -0,0 +1,110 @@
+package player
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// jsonFields - имена полей структуры t в JSON, включая встроенные структуры.
+func jsonFields(t reflect.Type) []string {
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch {
+		case name == "-":
+		case f.Anonymous && name == "":
+			fields = append(fields, jsonFields(f.Type)...)
+		case name == "":
+			fields = append(fields, f.Name)
+		default:
+			fields = append(fields, name)
+		}
+	}
+
+	return fields
+}
+
+func TestOpenAPI(t *testing.T) {
+	var spec struct {
+		OpenAPI    string `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]json.RawMessage
+			}
+		}
+	}
+	td.CmpNoError(t, json.Unmarshal(OpenAPI(), &spec))
+	td.Cmp(t, spec.OpenAPI, td.HasPrefix("3."))
+
+	t.Run("schemas", func(t *testing.T) {
+		for name, v := range map[string]any{
+			"Song":            Song{},
+			"Chapter":         Chapter{},
+			"Status":          StatusResponse{},
+			"QueuedSong":      QueuedSong{},
+			"QueuePage":       QueuePage{},
+			"AddSongResponse": AddSongResponse{},
+			"VoteResponse":    VoteResponse{},
+			"VolumeRequest":   VolumeRequest{},
+			"MoveRequest":     MoveRequest{},
+			"EQResponse":      EQResponse{},
+			"EQRequest":       EQRequest{},
+			"Error":           ErrorResponse{},
+			"HealthReport":    HealthReport{},
+			"HealthCheck":     HealthCheck{},
+		} {
+			var props []string
+			for p := range spec.Components.Schemas[name].Properties {
+				props = append(props, p)
+			}
+
+			td.Cmp(t, props, td.Bag(td.Flatten(jsonFields(reflect.TypeOf(v)))), "схема %s совпадает с типом", name)
+		}
+	})
+
+	t.Run("paths", func(t *testing.T) {
+		pl, _ := NewPlayer(Song{Name: "a", Duration: 30 * time.Second})
+		h, _ := pl.HTTPHandler(WithAuth(TokenAuth(map[string]Principal{"a": {UserID: "root", Role: RoleAdmin}})))
+
+		// каждая описанная операция существует: обработчик не отвечает 404 "not found" и 405
+		for path, ops := range spec.Paths {
+			for method := range ops {
+				if method == "parameters" {
+					continue
+				}
+
+				w := serve(h, strings.ToUpper(method), strings.ReplaceAll(path, "{id}", "1"), "a", "{}")
+				td.Cmp(t, w.Code, td.None(http.StatusMethodNotAllowed, http.StatusUnauthorized), "%s %s", method, path)
+				if w.Code == http.StatusNotFound {
+					td.CmpJSON(t, decode(t, w), `{"error": "song not found"}`, nil, "%s %s", method, path)
+				}
+			}
+		}
+	})
+
+	t.Run("served", func(t *testing.T) {
+		pl, _ := NewPlayer()
+		h, _ := pl.HTTPHandler(WithAuth(TokenAuth(nil)))
+
+		w := serve(h, http.MethodGet, "/openapi.json", "", "")
+		td.Cmp(t, w.Code, http.StatusOK, "без аутентификации")
+		td.Cmp(t, w.Header().Get("Content-Type"), "application/json")
+		td.Cmp(t, w.Body.Bytes(), OpenAPI())
+
+		td.Cmp(t, serve(h, http.MethodPost, "/openapi.json", "", "").Code, http.StatusUnauthorized, "только GET")
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		spec := OpenAPI()
+		spec[0] = 'x'
+		td.Cmp(t, OpenAPI()[0], byte('{'))
+	})
+}
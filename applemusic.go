@@ -0,0 +1,205 @@
+package player
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AppleMusicImporter - Importer для медиатеки Apple Music или iTunes,
+// экспортированной в XML (Файл - Медиатека - Экспортировать медиатеку).
+// Локальные файлы получают путь в качестве адреса, интернет-радио становится потоком.
+type AppleMusicImporter struct {
+	// Playlist - название плейлиста медиатеки, пусто - все треки по порядку медиатеки
+	Playlist string
+}
+
+// plistDict - словарь plist с сохранением порядка ключей.
+type plistDict struct {
+	keys   []string
+	values map[string]any
+}
+
+// decodeLibrary - читает корневой словарь медиатеки.
+func decodeLibrary(r io.Reader) (*plistDict, error) {
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local == "plist" {
+			continue
+		}
+
+		v, err := decodePlist(dec, start)
+		if err != nil {
+			return nil, err
+		}
+
+		lib, ok := v.(*plistDict)
+		if !ok {
+			return nil, errors.New("library is not a dict")
+		}
+		return lib, nil
+	}
+}
+
+func (imp AppleMusicImporter) Import(_ context.Context, r io.Reader) ([]Song, error) {
+	lib, err := decodeLibrary(r)
+	if err != nil {
+		return nil, fmt.Errorf("decode apple music library: %v", err)
+	}
+
+	tracks, _ := lib.values["Tracks"].(*plistDict)
+	if tracks == nil {
+		return nil, errors.New("apple music library has no tracks")
+	}
+
+	if imp.Playlist == "" {
+		songs := make([]Song, 0, len(tracks.keys))
+		for _, id := range tracks.keys {
+			if t, ok := tracks.values[id].(*plistDict); ok {
+				songs = append(songs, appleMusicSong(t))
+			}
+		}
+		return songs, nil
+	}
+
+	playlists, _ := lib.values["Playlists"].([]any)
+	for _, v := range playlists {
+		pl, ok := v.(*plistDict)
+		if !ok || pl.values["Name"] != imp.Playlist {
+			continue
+		}
+
+		items, _ := pl.values["Playlist Items"].([]any)
+		songs := make([]Song, 0, len(items))
+		for _, item := range items {
+			ref, _ := item.(*plistDict)
+			if ref == nil {
+				continue
+			}
+			id, _ := ref.values["Track ID"].(int64)
+			if t, ok := tracks.values[strconv.FormatInt(id, 10)].(*plistDict); ok {
+				songs = append(songs, appleMusicSong(t))
+			}
+		}
+		return songs, nil
+	}
+
+	return nil, fmt.Errorf("apple music playlist %q: %w", imp.Playlist, ErrPlaylistNotFound)
+}
+
+// appleMusicSong - переводит трек медиатеки в песню.
+func appleMusicSong(t *plistDict) Song {
+	str := func(key string) string {
+		s, _ := t.values[key].(string)
+		return s
+	}
+
+	song := Song{Name: str("Name"), Artist: str("Artist"), Album: str("Album")}
+	if ms, ok := t.values["Total Time"].(int64); ok {
+		song.Duration = time.Duration(ms) * time.Millisecond
+	}
+
+	loc := str("Location")
+	if u, err := url.Parse(loc); err == nil && u.Scheme == "file" {
+		loc = u.Path
+	}
+	song.URL = loc
+
+	return song
+}
+
+// decodePlist - читает значение plist, начатое элементом start.
+func decodePlist(dec *xml.Decoder, start xml.StartElement) (any, error) {
+	switch start.Name.Local {
+	case "dict":
+		d := &plistDict{values: make(map[string]any)}
+		var key string
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			switch tok := tok.(type) {
+			case xml.EndElement:
+				return d, nil
+			case xml.StartElement:
+				if tok.Name.Local == "key" {
+					if err := dec.DecodeElement(&key, &tok); err != nil {
+						return nil, err
+					}
+					continue
+				}
+
+				v, err := decodePlist(dec, tok)
+				if err != nil {
+					return nil, err
+				}
+				if _, ok := d.values[key]; !ok {
+					d.keys = append(d.keys, key)
+				}
+				d.values[key] = v
+			}
+		}
+	case "array":
+		var arr []any
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			switch tok := tok.(type) {
+			case xml.EndElement:
+				return arr, nil
+			case xml.StartElement:
+				v, err := decodePlist(dec, tok)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, v)
+			}
+		}
+	case "true", "false":
+		if err := dec.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local == "true", nil
+	}
+
+	var text string
+	if err := dec.DecodeElement(&text, &start); err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+
+	switch start.Name.Local {
+	case "integer":
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse integer %q: %v", text, err)
+		}
+		return n, nil
+	case "real":
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse real %q: %v", text, err)
+		}
+		return f, nil
+	default:
+		// string, date, data
+		return text, nil
+	}
+}
@@ -0,0 +1,79 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+const appleMusicLibrary = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple Computer//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Major Version</key><integer>1</integer>
+	<key>Tracks</key>
+	<dict>
+		<key>101</key>
+		<dict>
+			<key>Track ID</key><integer>101</integer>
+			<key>Name</key><string>Karma Police</string>
+			<key>Artist</key><string>Radiohead</string>
+			<key>Album</key><string>OK Computer</string>
+			<key>Total Time</key><integer>264066</integer>
+			<key>Compilation</key><true/>
+			<key>Location</key><string>file:///Users/me/Music/Karma%20Police.m4a</string>
+		</dict>
+		<key>102</key>
+		<dict>
+			<key>Track ID</key><integer>102</integer>
+			<key>Name</key><string>Jazz Radio</string>
+			<key>Track Type</key><string>URL</string>
+			<key>Location</key><string>http://radio.example/jazz</string>
+		</dict>
+		<key>103</key>
+		<dict>
+			<key>Track ID</key><integer>103</integer>
+			<key>Name</key><string>Airbag</string>
+			<key>Artist</key><string>Radiohead</string>
+			<key>Total Time</key><integer>284000</integer>
+			<key>Rating</key><real>0.8</real>
+		</dict>
+	</dict>
+	<key>Playlists</key>
+	<array>
+		<dict>
+			<key>Name</key><string>Favourites</string>
+			<key>Playlist Items</key>
+			<array>
+				<dict><key>Track ID</key><integer>103</integer></dict>
+				<dict><key>Track ID</key><integer>101</integer></dict>
+			</array>
+		</dict>
+	</array>
+</dict>
+</plist>`
+
+func TestAppleMusicImporter(t *testing.T) {
+	ctx := context.Background()
+	karma := Song{Name: "Karma Police", Artist: "Radiohead", Album: "OK Computer", Duration: 264066 * time.Millisecond, URL: "/Users/me/Music/Karma Police.m4a"}
+	radio := Song{Name: "Jazz Radio", URL: "http://radio.example/jazz"}
+	airbag := Song{Name: "Airbag", Artist: "Radiohead", Duration: 284 * time.Second}
+
+	songs, err := AppleMusicImporter{}.Import(ctx, strings.NewReader(appleMusicLibrary))
+	td.CmpNoError(t, err)
+	td.Cmp(t, songs, []Song{karma, radio, airbag}, "вся медиатека по порядку")
+
+	songs, err = AppleMusicImporter{Playlist: "Favourites"}.Import(ctx, strings.NewReader(appleMusicLibrary))
+	td.CmpNoError(t, err)
+	td.Cmp(t, songs, []Song{airbag, karma}, "порядок плейлиста")
+
+	_, err = AppleMusicImporter{Playlist: "nope"}.Import(ctx, strings.NewReader(appleMusicLibrary))
+	td.CmpTrue(t, errors.Is(err, ErrPlaylistNotFound))
+
+	_, err = AppleMusicImporter{}.Import(ctx, strings.NewReader("<plist><dict>"))
+	td.CmpError(t, err)
+}
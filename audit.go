@@ -0,0 +1,202 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// defaultAuditSize - количество записей журнала аудита по умолчанию.
+const defaultAuditSize = 1000
+
+// AuditOp - вид операции управления в журнале аудита.
+type AuditOp int
+
+const (
+	// AuditPlay - Play
+	AuditPlay AuditOp = iota
+	// AuditPause - Pause
+	AuditPause
+	// AuditNext - Next, пропуск песни
+	AuditNext
+	// AuditPrev - Prev
+	AuditPrev
+	// AuditPlayAt - PlayAt, Detail - индекс песни
+	AuditPlayAt
+	// AuditAdd - добавление песни, Detail - плейлист
+	AuditAdd
+	// AuditRemove - удаление песни
+	AuditRemove
+	// AuditVoteSkip - голос за пропуск, Detail - "skipped", если песня пропущена
+	AuditVoteSkip
+	// AuditVolume - громкость, Detail - новая громкость, "muted" или "unmuted"
+	AuditVolume
+	// AuditConfig - Apply
+	AuditConfig
+	// AuditClear - ClearPlaylist, Detail - плейлист
+	AuditClear
+	// AuditMerge - Merge, изменившее плейлист, Detail - плейлист
+	AuditMerge
+	// AuditTransaction - WithTransaction, изменившая плейлист, Detail - плейлист
+	AuditTransaction
+	// AuditEnqueue - Enqueue, Song - поставленная в очередь песня
+	AuditEnqueue
+	// AuditClearQueue - ClearQueue
+	AuditClearQueue
+	// AuditPlayByID - PlayByID, Detail - ID песни
+	AuditPlayByID
+	// AuditPlayFrom - PlayFrom, Detail - индекс песни и позиция, например "2 1m30s"
+	AuditPlayFrom
+	// AuditMove - MoveSong, Detail - новая позиция
+	AuditMove
+	// AuditBookmark - PlayBookmark, Detail - закладка
+	AuditBookmark
+	// AuditUndo - Undo, Detail - отменённое изменение, например "add" или "sort"
+	AuditUndo
+	// AuditRedo - Redo, Detail - повторённое изменение
+	AuditRedo
+	// AuditSort - SortPlaylist, Detail - плейлист
+	AuditSort
+	// AuditSwitchPlaylist - SwitchPlaylist, Detail - новый активный плейлист
+	AuditSwitchPlaylist
+)
+
+func (op AuditOp) String() string {
+	switch op {
+	case AuditPlay:
+		return "play"
+	case AuditPause:
+		return "pause"
+	case AuditNext:
+		return "next"
+	case AuditPrev:
+		return "prev"
+	case AuditPlayAt:
+		return "play_at"
+	case AuditAdd:
+		return "add"
+	case AuditRemove:
+		return "remove"
+	case AuditVoteSkip:
+		return "vote_skip"
+	case AuditVolume:
+		return "volume"
+	case AuditConfig:
+		return "config"
+	case AuditClear:
+		return "clear"
+	case AuditMerge:
+		return "merge"
+	case AuditTransaction:
+		return "transaction"
+	case AuditEnqueue:
+		return "enqueue"
+	case AuditClearQueue:
+		return "clear_queue"
+	case AuditPlayByID:
+		return "play_by_id"
+	case AuditPlayFrom:
+		return "play_from"
+	case AuditMove:
+		return "move"
+	case AuditBookmark:
+		return "bookmark"
+	case AuditUndo:
+		return "undo"
+	case AuditRedo:
+		return "redo"
+	case AuditSort:
+		return "sort"
+	case AuditSwitchPlaylist:
+		return "switch_playlist"
+	default:
+		return "AuditOp(" + strconv.Itoa(int(op)) + ")"
+	}
+}
+
+// AuditEntry - запись журнала аудита: кто, когда и что сделал.
+type AuditEntry struct {
+	// At - время вызова
+	At time.Time
+	// UserID - кто вызвал: пользователь из PrincipalFromContext,
+	// для AddSongAs и VoteSkip без него - переданный пользователь, иначе пусто
+	UserID string
+	// Op - операция
+	Op AuditOp
+	// Song - песня, к которой относится операция: добавленная, удалённая,
+	// пропущенная или текущая, nil для пустого плейлиста
+	Song *Song
+	// Detail - подробности операции, см. AuditOp
+	Detail string
+}
+
+// ContextWithPrincipal - возвращает контекст с пользователем pr,
+// которого журнал аудита запишет как вызвавшего. HTTP API делает это сам.
+func ContextWithPrincipal(ctx context.Context, pr Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, pr)
+}
+
+// WithAuditLogSize - сколько последних вызовов управления хранит журнал аудита,
+// по умолчанию 1000. 0 отключает журнал.
+func WithAuditLogSize(size int) Option {
+	return func(p *playerImpl) error {
+		if size < 0 {
+			return errors.New("audit log size is negative")
+		}
+
+		p.auditSize = size
+		p.audit = nil
+		p.auditStart = 0
+		return nil
+	}
+}
+
+// AuditLog - возвращает записи журнала аудита не раньше since, от старых к новым.
+// Нулевой since - весь журнал.
+func (p *playerImpl) AuditLog(_ context.Context, since time.Time) []AuditEntry {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var entries []AuditEntry
+	for i := range p.audit {
+		entry := p.audit[(p.auditStart+i)%len(p.audit)]
+		if !entry.At.Before(since) {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// auditLocked - записывает вызов в журнал аудита, вытесняя самую старую запись.
+// Вызывается под блокировкой.
+func (p *playerImpl) auditLocked(ctx context.Context, entry AuditEntry) {
+	if p.auditSize == 0 {
+		return
+	}
+
+	entry.At = p.now()
+	if pr, ok := PrincipalFromContext(ctx); ok && pr.UserID != "" {
+		entry.UserID = pr.UserID
+	}
+
+	if len(p.audit) < p.auditSize {
+		p.audit = append(p.audit, entry)
+		return
+	}
+
+	p.audit[p.auditStart] = entry
+	p.auditStart = (p.auditStart + 1) % len(p.audit)
+}
+
+// currentSongLocked - копия текущей песни для журнала или nil.
+// Вызывается под блокировкой.
+func (p *playerImpl) currentSongLocked() *Song {
+	if p.current == nil {
+		return nil
+	}
+
+	song := *p.current.song
+	return &song
+}
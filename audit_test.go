@@ -0,0 +1,144 @@
+package player
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_AuditLog(t *testing.T) {
+	ctx := context.Background()
+
+	// ops - операции записей журнала.
+	ops := func(entries []AuditEntry) []string {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Op.String())
+		}
+		return names
+	}
+
+	t.Run("record", func(t *testing.T) {
+		clock := NewFakeClock(testStart)
+		pl, err := New(WithClock(clock), WithSongs(minuteSongs("a", "b")...))
+		td.CmpNoError(t, err)
+
+		admin := ContextWithPrincipal(ctx, Principal{UserID: "root", Role: RoleAdmin})
+		td.CmpNoError(t, pl.Play(admin))
+		clock.Advance(time.Second)
+
+		id, err := pl.AddSong(ctx, minuteSong("c"))
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, pl.Next(admin))
+		td.CmpNoError(t, pl.RemoveSong(admin, id))
+		td.CmpNoError(t, pl.SetVolume(ctx, 40))
+		td.CmpNoError(t, pl.Pause(ctx))
+
+		entries := pl.AuditLog(ctx, time.Time{})
+		td.Cmp(t, ops(entries), []string{"play", "add", "next", "remove", "volume", "pause"})
+		td.Cmp(t, entries[0], AuditEntry{At: testStart, UserID: "root", Op: AuditPlay, Song: &Song{Name: "a", Duration: time.Minute}})
+		td.Cmp(t, entries[1], AuditEntry{At: testStart.Add(time.Second), Op: AuditAdd, Song: &Song{Name: "c", Duration: time.Minute}, Detail: DefaultPlaylist},
+			"без пользователя в контексте")
+		td.Cmp(t, entries[2].Song.Name, "a", "пропущенная песня")
+		td.Cmp(t, entries[3].Song.Name, "c")
+		td.Cmp(t, entries[4].Detail, "40")
+
+		td.Cmp(t, ops(pl.AuditLog(ctx, testStart.Add(time.Second))), []string{"add", "next", "remove", "volume", "pause"}, "с момента since")
+		td.CmpEmpty(t, pl.AuditLog(ctx, testStart.Add(time.Hour)))
+
+		td.Cmp(t, pl.PlayAt(ctx, 10), ErrIndexOutOfRange, "неудачный вызов не записывается")
+		td.Cmp(t, pl.AuditLog(ctx, time.Time{}), td.Len(6))
+	})
+
+	t.Run("playlist edits", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSongs("b", "a")...))
+		td.CmpNoError(t, pl.CreatePlaylist(ctx, "other"))
+		_, err := pl.AddSongTo(ctx, "other", minuteSong("x"))
+		td.CmpNoError(t, err)
+
+		td.CmpNoError(t, pl.SortPlaylist(ctx, ByName))
+		td.CmpNoError(t, pl.Undo(ctx))
+		td.CmpNoError(t, pl.Redo(ctx))
+		songs := pl.Queue(ctx)
+		td.CmpNoError(t, pl.MoveSong(ctx, songs[1].ID, 0))
+		td.CmpNoError(t, pl.PlayByID(ctx, songs[0].ID))
+		td.CmpNoError(t, pl.PlayFrom(ctx, 1, 30*time.Second))
+		td.CmpNoError(t, pl.Bookmark(ctx, "mark"))
+		td.CmpNoError(t, pl.Enqueue(ctx, minuteSong("q")))
+		td.CmpNoError(t, pl.ClearQueue(ctx))
+		_, err = pl.Merge(ctx, minuteSongs("c"), MergeAppendMissing)
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, pl.WithTransaction(ctx, func(tx PlaylistTx) error {
+			_, err := tx.Add(minuteSong("d"))
+			return err
+		}))
+		id, err := pl.Library().Add(minuteSong("e"))
+		td.CmpNoError(t, err)
+		_, err = pl.AddTrack(ctx, id)
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, pl.SwitchPlaylist(ctx, "other"))
+		td.CmpNoError(t, pl.PlayBookmark(ctx, "mark"))
+		td.CmpNoError(t, pl.ClearPlaylist(ctx))
+
+		entries := pl.AuditLog(ctx, time.Time{})
+		td.Cmp(t, ops(entries), []string{
+			"add", "sort", "undo", "redo", "move", "play_by_id", "play_from", "enqueue", "clear_queue",
+			"merge", "transaction", "add", "switch_playlist", "bookmark", "clear",
+		})
+		td.Cmp(t, entries[2].Detail, "sort")
+		td.Cmp(t, entries[4].Detail, "0")
+		td.Cmp(t, entries[5].Detail, strconv.FormatUint(uint64(songs[0].ID), 10))
+		td.Cmp(t, entries[6].Detail, "1 30s")
+		td.Cmp(t, entries[7].Song.Name, "q")
+		td.Cmp(t, entries[12].Detail, "other")
+		td.Cmp(t, entries[13].Detail, "mark")
+		td.Cmp(t, entries[14].Detail, DefaultPlaylist)
+
+		td.Cmp(t, pl.SwitchPlaylist(ctx, "нет такого"), ErrPlaylistNotFound)
+		td.Cmp(t, pl.Redo(ctx), ErrNothingToRedo)
+		td.Cmp(t, pl.AuditLog(ctx, time.Time{}), td.Len(len(entries)), "неудачные вызовы не записываются")
+	})
+
+	t.Run("users", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSongs("a", "b")...), WithSkipVotes(2))
+
+		_, err := pl.AddSongAs(ctx, "alice", minuteSong("c"))
+		td.CmpNoError(t, err)
+		_, err = pl.VoteSkip(ctx, "bob")
+		td.CmpNoError(t, err)
+		_, err = pl.VoteSkip(ctx, "carol")
+		td.CmpNoError(t, err)
+
+		entries := pl.AuditLog(ctx, time.Time{})
+		td.Cmp(t, ops(entries), []string{"add", "vote_skip", "vote_skip"})
+		td.Cmp(t, entries[0].UserID, "alice")
+		td.Cmp(t, entries[1].UserID, "bob")
+		td.Cmp(t, entries[1].Detail, "")
+		td.Cmp(t, entries[2].Detail, "skipped")
+	})
+
+	t.Run("ring", func(t *testing.T) {
+		pl := newFakePlayer(t, WithAuditLogSize(2))
+
+		td.CmpNoError(t, pl.SetVolume(ctx, 10))
+		td.CmpNoError(t, pl.Mute(ctx))
+		td.CmpNoError(t, pl.Unmute(ctx))
+
+		var details []string
+		for _, e := range pl.AuditLog(ctx, time.Time{}) {
+			details = append(details, e.Detail)
+		}
+		td.Cmp(t, details, []string{"muted", "unmuted"}, "старые записи вытесняются")
+
+		pl, err := New(WithAuditLogSize(0))
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, pl.SetVolume(ctx, 10))
+		td.CmpEmpty(t, pl.AuditLog(ctx, time.Time{}), "журнал отключён")
+
+		_, err = New(WithAuditLogSize(-1))
+		td.CmpString(t, err, "audit log size is negative")
+	})
+}
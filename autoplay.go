@@ -0,0 +1,42 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WithAutoplay - начинает воспроизведение сразу после создания плеера,
+// если в плейлисте есть песни.
+func WithAutoplay(enabled bool) Option {
+	return func(p *playerImpl) error {
+		p.autoplay = enabled
+		return nil
+	}
+}
+
+// PlayFrom - начинает играть песню активного плейлиста с индексом index
+// с позиции offset.
+func (p *playerImpl) PlayFrom(ctx context.Context, index int, offset time.Duration) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	node := p.nodeAt(index)
+	if node == nil {
+		return ErrIndexOutOfRange
+	}
+
+	if offset < 0 || offset > node.song.Duration {
+		return fmt.Errorf("offset %v out of song duration %v", offset, node.song.Duration)
+	}
+
+	song := *node.song
+	p.auditLocked(ctx, AuditEntry{Op: AuditPlayFrom, Song: &song, Detail: fmt.Sprintf("%d %v", index, offset)})
+	return p.playNodeLocked(ctx, node, offset)
+}
@@ -0,0 +1,53 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// unpluggedOutput - бэкенд, который не может начать воспроизведение.
+type unpluggedOutput struct{ nopOutput }
+
+func (unpluggedOutput) Start(context.Context, Song, time.Duration) error {
+	return errors.New("device unplugged")
+}
+
+func TestWithAutoplay(t *testing.T) {
+	ctx := context.Background()
+
+	pl, err := New(WithAutoplay(true), WithSongs(Song{Name: "a", Duration: 30 * time.Second}))
+	td.CmpNoError(t, err)
+	td.CmpTrue(t, pl.Status(ctx).Playing, "воспроизведение началось сразу")
+	_ = pl.Pause(ctx)
+
+	empty, err := New(WithAutoplay(true))
+	td.CmpNoError(t, err)
+	td.CmpFalse(t, empty.Status(ctx).Playing, "пустой плейлист нечего играть")
+
+	_, err = New(WithAutoplay(true), WithOutput(unpluggedOutput{}), WithSongs(Song{Name: "a", Duration: time.Minute}))
+	td.CmpError(t, err, "ошибка бэкенда возвращается из конструктора")
+}
+
+func TestPlayerImpl_PlayFrom(t *testing.T) {
+	ctx := context.Background()
+	pl, _ := NewPlayer(
+		Song{Name: "a", Duration: 30 * time.Second},
+		Song{Name: "b", Duration: 30 * time.Second},
+	)
+
+	td.CmpNoError(t, pl.PlayFrom(ctx, 1, 10*time.Second))
+	st := pl.Status(ctx)
+	td.Cmp(t, st.Song.Name, "b")
+	td.Cmp(t, st.Position, td.Between(10*time.Second, 11*time.Second))
+	td.CmpTrue(t, st.Playing)
+
+	td.Cmp(t, pl.PlayFrom(ctx, 2, 0), ErrIndexOutOfRange)
+	td.CmpError(t, pl.PlayFrom(ctx, 0, -time.Second))
+	td.CmpError(t, pl.PlayFrom(ctx, 0, time.Minute))
+	td.Cmp(t, pl.Status(ctx).Song.Name, "b", "при ошибке курсор не сдвигается")
+	_ = pl.Pause(ctx)
+}
@@ -0,0 +1,71 @@
+package player
+
+import (
+	"context"
+	"log/slog"
+)
+
+// AddSongs - добавляет песни в конец активного плейлиста за один захват блокировки.
+// Песни, не прошедшие проверку или не поместившиеся в плейлист
+// с ErrPlaylistFull, пропускаются, остальные добавляются.
+// Возвращает nil, если добавлены все песни, иначе срез ошибок
+// той же длины, что и songs, с nil для добавленных песен.
+func (p *playerImpl) AddSongs(ctx context.Context, songs ...Song) []error {
+	var errs []error
+	for i, song := range songs {
+		if err := p.validator.Validate(song); err != nil {
+			if errs == nil {
+				errs = make([]error, len(songs))
+			}
+			errs[i] = err
+		}
+	}
+
+	// index - позиции прошедших проверку песен в songs
+	valid := make([]Song, 0, len(songs))
+	index := make([]int, 0, len(songs))
+	for i, song := range songs {
+		if errs == nil || errs[i] == nil {
+			valid = append(valid, song)
+			index = append(index, i)
+		}
+	}
+
+	err := p.lock(ctx)
+	if err == nil && p.closed {
+		p.mu.Unlock()
+		err = ErrClosed
+	}
+	if err != nil {
+		errs = make([]error, len(songs))
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+	added := make([]*playerNode, 0, len(valid))
+	for i, song := range valid {
+		if err := p.makeRoomLocked(ctx, p.active, song); err != nil {
+			if errs == nil {
+				errs = make([]error, len(songs))
+			}
+			errs[index[i]] = err
+			continue
+		}
+
+		// в библиотеку попадают только принятые песни
+		node := p.newNode(song, nil)
+		p.appendNode(node)
+		p.auditLocked(ctx, AuditEntry{Op: AuditAdd, Song: &songs[index[i]], Detail: p.active})
+		added = append(added, node)
+	}
+	if len(added) > 0 {
+		p.recordEditLocked(p.addEdit(added...))
+		p.rescheduleLocked()
+	}
+	active := p.active
+	p.mu.Unlock()
+
+	p.logger.DebugContext(ctx, "songs added", slog.Int("count", len(added)), slog.String("playlist", active))
+	return errs
+}
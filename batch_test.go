@@ -0,0 +1,50 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_AddSongs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("all valid", func(t *testing.T) {
+		pl, _ := NewPlayer()
+		songs := make([]Song, 1000)
+		for i := range songs {
+			songs[i] = Song{Name: fmt.Sprintf("%d", i), Duration: time.Minute}
+		}
+
+		td.CmpNil(t, pl.AddSongs(ctx, songs...))
+		td.Cmp(t, pl.Metrics(ctx).PlaylistLength, 1000)
+		td.Cmp(t, pl.last().song.Name, "999", "порядок сохранён")
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		pl, _ := NewPlayer()
+		errs := pl.AddSongs(ctx,
+			Song{Name: "a", Duration: time.Minute},
+			Song{Name: "", Duration: time.Minute},
+			Song{Name: "b", Duration: time.Millisecond},
+			Song{Name: "c", Duration: time.Minute},
+		)
+
+		td.Cmp(t, errs, td.Len(4))
+		td.CmpNoError(t, errs[0])
+		td.CmpTrue(t, errors.Is(errs[1], ErrInvalidSong))
+		td.CmpError(t, errs[2])
+		td.CmpNoError(t, errs[3])
+		td.Cmp(t, names(pl), []string{"a", "c"}, "невалидные песни пропущены")
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		pl, _ := NewPlayer()
+		td.CmpNil(t, pl.AddSongs(ctx))
+		td.CmpNil(t, pl.first())
+	})
+}
@@ -0,0 +1,16 @@
+module player/boltstore
+
+go 1.21
+
+require (
+	github.com/maxatome/go-testdeep v1.12.0
+	go.etcd.io/bbolt v1.3.10
+	player v0.0.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	golang.org/x/sys v0.4.0 // indirect
+)
+
+replace player => ../
@@ -0,0 +1,190 @@
+// Package boltstore - хранилище плеера во встроенной базе bbolt.
+//
+// Пакет вынесен в отдельный модуль, чтобы основной модуль плеера
+// оставался без внешних зависимостей:
+//
+//	s, err := boltstore.Open("player.db")
+//	if err != nil { ... }
+//	defer s.Close()
+//	pl, err := player.New(player.WithStorage(s))
+package boltstore
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"player"
+)
+
+var (
+	// playlistsBucket - песни плейлистов по названию
+	playlistsBucket = []byte("playlists")
+	// historyBucket - записи истории по возрастающему номеру
+	historyBucket = []byte("history")
+	// stateBucket - состояние плеера под ключом stateKey
+	stateBucket = []byte("state")
+	// zonesBucket - состояние зон по названию
+	zonesBucket = []byte("zones")
+
+	stateKey = []byte("player")
+)
+
+// Store - хранилище плеера в файле базы bbolt. Каждое изменение записывается
+// в отдельной транзакции, поэтому после падения процесса база остаётся целой.
+// Реализует player.Storage и player.ZoneStateStorage.
+type Store struct {
+	db *bolt.DB
+}
+
+var (
+	_ player.Storage          = (*Store)(nil)
+	_ player.ZoneStateStorage = (*Store)(nil)
+)
+
+// Open - открывает базу в файле path, создавая её при необходимости.
+// Файл может открыть только один процесс: если он занят, Open ждёт секунду
+// и возвращает ошибку.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open database: %v", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{playlistsBucket, historyBucket, stateBucket, zonesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("create buckets: %v", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close - закрывает базу.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) SavePlaylist(_ context.Context, name string, songs []player.Song) error {
+	return s.put(playlistsBucket, []byte(name), songs)
+}
+
+func (s *Store) LoadPlaylist(_ context.Context, name string) ([]player.Song, error) {
+	var songs []player.Song
+	ok, err := s.get(playlistsBucket, []byte(name), &songs)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, player.ErrPlaylistNotFound
+	}
+
+	return songs, nil
+}
+
+func (s *Store) AppendHistory(_ context.Context, entry player.HistoryEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshal history entry: %v", err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(historyBucket)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		// номер в big-endian, чтобы курсор обходил записи по порядку
+		key := binary.BigEndian.AppendUint64(nil, seq)
+		return b.Put(key, data)
+	})
+}
+
+func (s *Store) LoadHistory(_ context.Context) ([]player.HistoryEntry, error) {
+	var entries []player.HistoryEntry
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(historyBucket).ForEach(func(k, v []byte) error {
+			var e player.HistoryEntry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return fmt.Errorf("history entry %d: %v", binary.BigEndian.Uint64(k), err)
+			}
+			entries = append(entries, e)
+			return nil
+		})
+	})
+
+	return entries, err
+}
+
+func (s *Store) SaveState(_ context.Context, state player.PlayerState) error {
+	return s.put(stateBucket, stateKey, state)
+}
+
+func (s *Store) LoadState(_ context.Context) (player.PlayerState, error) {
+	return s.loadState(stateBucket, stateKey)
+}
+
+func (s *Store) SaveZoneState(_ context.Context, zone string, state player.PlayerState) error {
+	return s.put(zonesBucket, []byte(zone), state)
+}
+
+func (s *Store) LoadZoneState(_ context.Context, zone string) (player.PlayerState, error) {
+	return s.loadState(zonesBucket, []byte(zone))
+}
+
+// loadState - читает состояние по ключу key, player.ErrStateNotFound если его нет.
+func (s *Store) loadState(bucket, key []byte) (player.PlayerState, error) {
+	var state player.PlayerState
+	ok, err := s.get(bucket, key, &state)
+	if err != nil {
+		return player.PlayerState{}, err
+	}
+	if !ok {
+		return player.PlayerState{}, player.ErrStateNotFound
+	}
+
+	return state, nil
+}
+
+// put - записывает v в формате JSON под ключом key, заменяя прежнее значение.
+func (s *Store) put(bucket, key []byte, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal %s: %v", bucket, err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put(key, data)
+	})
+}
+
+// get - читает значение по ключу key в v, false если ключа нет.
+func (s *Store) get(bucket, key []byte, v any) (bool, error) {
+	var data []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		// значение действительно только внутри транзакции
+		data = append(data, tx.Bucket(bucket).Get(key)...)
+		return nil
+	})
+	if err != nil || data == nil {
+		return false, err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("unmarshal %s: %v", bucket, err)
+	}
+
+	return true, nil
+}
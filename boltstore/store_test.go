@@ -0,0 +1,99 @@
+package boltstore
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"player"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	song := player.Song{Name: "Михаил Шуфутинский - 3 сентября", Duration: 3 * time.Minute}
+	path := filepath.Join(t.TempDir(), "player.db")
+
+	s, err := Open(path)
+	td.CmpNoError(t, err)
+
+	t.Run("empty", func(t *testing.T) {
+		_, err := s.LoadState(ctx)
+		td.Cmp(t, err, player.ErrStateNotFound)
+
+		_, err = s.LoadZoneState(ctx, "кухня")
+		td.Cmp(t, err, player.ErrStateNotFound)
+
+		_, err = s.LoadPlaylist(ctx, "рок/поп")
+		td.Cmp(t, err, player.ErrPlaylistNotFound)
+
+		history, err := s.LoadHistory(ctx)
+		td.CmpNoError(t, err)
+		td.CmpEmpty(t, history)
+	})
+
+	t.Run("busy", func(t *testing.T) {
+		_, err := Open(path)
+		td.CmpContains(t, err, "open database")
+	})
+
+	state := player.PlayerState{
+		Active:    player.DefaultPlaylist,
+		Playlists: []player.PlaylistState{{Name: player.DefaultPlaylist, Songs: []player.Song{song}}},
+		Queue:     []player.Song{song},
+	}
+	td.CmpNoError(t, s.SaveState(ctx, state))
+	td.CmpNoError(t, s.SaveZoneState(ctx, "кухня", player.PlayerState{Active: "кухня"}))
+	td.CmpNoError(t, s.SavePlaylist(ctx, "рок/поп", []player.Song{song}))
+	for i := 1; i <= 3; i++ {
+		td.CmpNoError(t, s.AppendHistory(ctx, player.HistoryEntry{Song: song, Played: time.Duration(i) * time.Second}))
+	}
+	td.CmpNoError(t, s.Close())
+
+	s, err = Open(path)
+	td.CmpNoError(t, err)
+	defer s.Close()
+
+	t.Run("reopened", func(t *testing.T) {
+		got, err := s.LoadState(ctx)
+		td.CmpNoError(t, err)
+		td.Cmp(t, got, state)
+
+		zone, err := s.LoadZoneState(ctx, "кухня")
+		td.CmpNoError(t, err)
+		td.Cmp(t, zone.Active, "кухня")
+
+		songs, err := s.LoadPlaylist(ctx, "рок/поп")
+		td.CmpNoError(t, err)
+		td.Cmp(t, songs, []player.Song{song})
+
+		history, err := s.LoadHistory(ctx)
+		td.CmpNoError(t, err)
+		td.Cmp(t, history, td.Len(3))
+		for i, e := range history {
+			td.Cmp(t, e.Played, time.Duration(i+1)*time.Second, "записи по порядку")
+		}
+	})
+
+	t.Run("player survives restart", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := Open(filepath.Join(dir, "player.db"))
+		td.CmpNoError(t, err)
+
+		pl, err := player.New(player.WithStorage(s), player.WithSongs(song))
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, pl.Close(ctx))
+		td.CmpNoError(t, s.Close())
+
+		s, err = Open(filepath.Join(dir, "player.db"))
+		td.CmpNoError(t, err)
+		defer s.Close()
+
+		pl, err = player.New(player.WithStorage(s))
+		td.CmpNoError(t, err)
+		defer pl.Close(ctx)
+		td.Cmp(t, pl.Status(ctx).Song, &song, "плейлист восстановлен из базы")
+	})
+}
@@ -0,0 +1,148 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// ErrBookmarkNotFound - закладка с таким названием не существует.
+var ErrBookmarkNotFound = errors.New("bookmark not found")
+
+// Bookmark - сохранённая позиция внутри песни.
+type Bookmark struct {
+	// Label - название закладки
+	Label string
+	// Playlist - плейлист, в котором находится песня
+	Playlist string
+	// Song - песня
+	Song Song
+	// Position - позиция внутри песни
+	Position time.Duration
+	// CreatedAt - когда закладка создана
+	CreatedAt time.Time
+}
+
+// bookmark - закладка вместе с узлом песни.
+type bookmark struct {
+	Bookmark
+	node *playerNode
+}
+
+// Bookmark - сохраняет текущую песню и позицию под названием label.
+// Закладка с тем же названием перезаписывается.
+func (p *playerImpl) Bookmark(ctx context.Context, label string) error {
+	if label == "" {
+		return errors.New("bookmark label is empty")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if p.current == nil {
+		return errors.New("playlist is empty")
+	}
+
+	p.bookmarks[label] = &bookmark{
+		Bookmark: Bookmark{
+			Label:     label,
+			Playlist:  p.active,
+			Song:      *p.current.song,
+			Position:  p.elapsedLocked(),
+			CreatedAt: p.now(),
+		},
+		node: p.current,
+	}
+	p.logger.InfoContext(ctx, "bookmark saved", slog.String("label", label), songAttr(*p.current.song))
+
+	return nil
+}
+
+// ListBookmarks - возвращает закладки, упорядоченные по названию.
+func (p *playerImpl) ListBookmarks(_ context.Context) []Bookmark {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	bookmarks := make([]Bookmark, 0, len(p.bookmarks))
+	for _, b := range p.bookmarks {
+		bookmarks = append(bookmarks, b.Bookmark)
+	}
+
+	sort.Slice(bookmarks, func(i, j int) bool {
+		return bookmarks[i].Label < bookmarks[j].Label
+	})
+
+	return bookmarks
+}
+
+// DeleteBookmark - удаляет закладку.
+func (p *playerImpl) DeleteBookmark(ctx context.Context, label string) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if _, ok := p.bookmarks[label]; !ok {
+		return ErrBookmarkNotFound
+	}
+
+	delete(p.bookmarks, label)
+	return nil
+}
+
+// PlayBookmark - переключается на плейлист и песню закладки
+// и начинает воспроизведение с сохранённой позиции.
+func (p *playerImpl) PlayBookmark(ctx context.Context, label string) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	b, ok := p.bookmarks[label]
+	if !ok {
+		return ErrBookmarkNotFound
+	}
+
+	target, ok := p.playlistLocked(b.Playlist)
+	if !ok {
+		return ErrPlaylistNotFound
+	}
+
+	// проверяется до остановки и переключения, чтобы ошибка ничего не меняла
+	if !target.contains(b.node) {
+		return errors.New("bookmarked song is no longer in playlist")
+	}
+
+	song := *b.node.song
+	p.auditLocked(ctx, AuditEntry{Op: AuditBookmark, Song: &song, Detail: label})
+	if p.current != nil {
+		p.skipLocked()
+	}
+	p.haltLocked(ctx)
+
+	if err := p.switchPlaylistLocked(ctx, b.Playlist); err != nil {
+		return err
+	}
+
+	p.moveToLocked(b.node)
+	p.playedTime = b.Position
+	p.section = nil
+
+	return p.playLocked(ctx)
+}
@@ -0,0 +1,68 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Bookmarks(t *testing.T) {
+	ctx := context.Background()
+	sg := Song{Name: "Сектор Газа - 30 лет", Duration: 30 * time.Second}
+	ap := Song{Name: "Александр Пушной - Почему я идиот?", Duration: 30 * time.Second}
+	shuff := Song{Name: "Михаил Шуфутинский - 3 сентября", Duration: 30 * time.Second}
+
+	empty, _ := NewPlayer()
+	td.CmpError(t, empty.Bookmark(ctx, "x"), "пустой плейлист")
+
+	pl, _ := NewPlayer(sg, ap)
+	_ = pl.CreatePlaylist(ctx, "chanson")
+	_, _ = pl.AddSongTo(ctx, "chanson", shuff)
+
+	td.CmpError(t, pl.Bookmark(ctx, ""), "пустое название")
+
+	pl.current = pl.last()
+	pl.playedTime = 10 * time.Second
+	td.CmpNoError(t, pl.Bookmark(ctx, "пушной"))
+
+	_ = pl.SwitchPlaylist(ctx, "chanson")
+	pl.playedTime = time.Second
+	td.CmpNoError(t, pl.Bookmark(ctx, "шуф"))
+
+	td.Cmp(t, pl.ListBookmarks(ctx), td.Slice([]Bookmark{}, td.ArrayEntries{
+		0: td.Struct(Bookmark{Label: "пушной", Playlist: DefaultPlaylist, Song: ap, Position: 10 * time.Second},
+			td.StructFields{"CreatedAt": td.NotZero()}),
+		1: td.Struct(Bookmark{Label: "шуф", Playlist: "chanson", Song: shuff, Position: time.Second},
+			td.StructFields{"CreatedAt": td.NotZero()}),
+	}))
+
+	td.Cmp(t, pl.PlayBookmark(ctx, "unknown"), ErrBookmarkNotFound)
+
+	td.CmpNoError(t, pl.PlayBookmark(ctx, "пушной"))
+	td.CmpTrue(t, pl.isPlaying)
+	td.Cmp(t, pl.active, DefaultPlaylist, "переключились на плейлист закладки")
+	td.Cmp(t, *pl.current.song, ap)
+	td.Cmp(t, pl.elapsedLocked(), td.Between(10*time.Second, 11*time.Second))
+	_ = pl.Pause(ctx)
+
+	// песню закладки удалили из её плейлиста
+	td.CmpNoError(t, pl.SwitchPlaylist(ctx, "chanson"))
+	td.CmpNoError(t, pl.RemoveAt(ctx, 0))
+	td.CmpNoError(t, pl.SwitchPlaylist(ctx, DefaultPlaylist))
+	td.CmpNoError(t, pl.PlayAt(ctx, 0))
+	skips := pl.Metrics(ctx).Skips
+
+	td.CmpString(t, pl.PlayBookmark(ctx, "шуф"), "bookmarked song is no longer in playlist")
+	st := pl.Status(ctx)
+	td.CmpTrue(t, st.Playing, "воспроизведение не остановлено")
+	td.Cmp(t, st.Playlist, DefaultPlaylist, "плейлист не переключён")
+	td.Cmp(t, *st.Song, sg)
+	td.Cmp(t, pl.Metrics(ctx).Skips, skips, "пропуск не записан")
+	_ = pl.Pause(ctx)
+
+	td.CmpNoError(t, pl.DeleteBookmark(ctx, "шуф"))
+	td.Cmp(t, pl.DeleteBookmark(ctx, "шуф"), ErrBookmarkNotFound)
+	td.Cmp(t, pl.ListBookmarks(ctx), td.Len(1))
+}
@@ -0,0 +1,238 @@
+// Package bot - управление плеером командами чата.
+//
+// Bot переводит команды (/play, /pause, /next, /prev, /queue <name>, /np)
+// в вызовы player.Player и готовит текстовые ответы. Bot не зависит от
+// мессенджера: Telegram и DiscordHandler только доставляют ему сообщения,
+// а для других чатов достаточно вызывать Handle.
+//
+//	b := bot.New(pl, bot.LibraryResolver(lib))
+//	tg := &bot.Telegram{Token: os.Getenv("TELEGRAM_TOKEN")}
+//	err := tg.Run(ctx, b)
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"player"
+)
+
+// ErrNoMatch - Resolver не нашёл песню по запросу.
+var ErrNoMatch = errors.New("no matching song")
+
+// Resolver - находит песню по запросу из команды /queue.
+type Resolver func(ctx context.Context, query string) (player.Song, error)
+
+// LibraryResolver - ищет песню в библиотеке и берёт первое совпадение.
+func LibraryResolver(lib *player.Library) Resolver {
+	return func(_ context.Context, query string) (player.Song, error) {
+		entries := lib.Search(query)
+		if len(entries) == 0 {
+			return player.Song{}, fmt.Errorf("%w: %q", ErrNoMatch, query)
+		}
+
+		return entries[0].Song, nil
+	}
+}
+
+// queuer - плеер, который умеет добавлять песни от имени пользователя и показывать очередь.
+type queuer interface {
+	AddSongAs(ctx context.Context, userID string, song player.Song) (player.SongID, error)
+	Queue(ctx context.Context) []player.QueuedSong
+}
+
+// queueLimit - сколько песен очереди показывает /queue без аргументов.
+const queueLimit = 10
+
+// help - ответ на /help и неизвестные команды.
+const help = `/play - start playback
+/pause - pause playback
+/next - next song
+/prev - previous song
+/queue <name> - add a song
+/queue - show the queue
+/np - now playing`
+
+// Bot - обработчик команд чата.
+type Bot struct {
+	pl      player.Player
+	resolve Resolver
+}
+
+// New - создаёт бота для плеера pl. resolve нужен для /queue <name>,
+// без него команда отвечает ошибкой.
+func New(pl player.Player, resolve Resolver) *Bot {
+	return &Bot{pl: pl, resolve: resolve}
+}
+
+// Handle - выполняет команду text от пользователя userID и возвращает ответ.
+// Для сообщений, которые не являются командами, возвращается пустая строка.
+// Ошибки плеера не возвращаются, а попадают в ответ, чтобы их увидел пользователь.
+// Воспроизведение, запущенное командой, не останавливается при отмене ctx,
+// поэтому ctx может быть контекстом HTTP-запроса с сообщением.
+func (b *Bot) Handle(ctx context.Context, userID, text string) string {
+	cmd, arg, ok := parseCommand(text)
+	if !ok {
+		return ""
+	}
+
+	// плеер играет, пока жив контекст запустившей воспроизведение команды
+	playCtx := context.WithoutCancel(ctx)
+
+	var err error
+	switch cmd {
+	case "play":
+		if err = b.pl.Play(playCtx); err == nil {
+			return b.nowPlaying(ctx)
+		}
+	case "pause":
+		if err = b.pl.Pause(ctx); err == nil {
+			return "paused"
+		}
+	case "next":
+		if err = b.pl.Next(playCtx); err == nil {
+			return b.nowPlaying(ctx)
+		}
+	case "prev":
+		if err = b.pl.Prev(playCtx); err == nil {
+			return b.nowPlaying(ctx)
+		}
+	case "np":
+		return b.nowPlaying(ctx)
+	case "queue":
+		if arg == "" {
+			return b.queue(ctx)
+		}
+
+		var reply string
+		if reply, err = b.enqueue(ctx, userID, arg); err == nil {
+			return reply
+		}
+	default:
+		return help
+	}
+
+	return "error: " + err.Error()
+}
+
+// nowPlaying - ответ на /np.
+func (b *Bot) nowPlaying(ctx context.Context) string {
+	return FormatNowPlaying(b.pl.Status(ctx))
+}
+
+// enqueue - добавляет песню по запросу query.
+func (b *Bot) enqueue(ctx context.Context, userID, query string) (string, error) {
+	if b.resolve == nil {
+		return "", errors.New("queueing by name is not configured")
+	}
+
+	song, err := b.resolve(ctx, query)
+	if err != nil {
+		return "", err
+	}
+
+	if q, ok := b.pl.(queuer); ok {
+		_, err = q.AddSongAs(ctx, userID, song)
+	} else {
+		_, err = b.pl.AddSong(ctx, song)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return "queued: " + FormatSong(song), nil
+}
+
+// queue - ответ на /queue без аргументов.
+func (b *Bot) queue(ctx context.Context) string {
+	q, ok := b.pl.(queuer)
+	if !ok {
+		return "error: queue listing is not supported"
+	}
+
+	return FormatQueue(q.Queue(ctx), b.pl.Status(ctx).SongID, queueLimit)
+}
+
+// parseCommand - разбирает "/cmd@bot arg" на команду в нижнем регистре и аргумент.
+func parseCommand(text string) (cmd, arg string, ok bool) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "/") {
+		return "", "", false
+	}
+
+	cmd, arg, _ = strings.Cut(text[1:], " ")
+	// в группах Telegram команда приходит как /np@my_bot
+	cmd, _, _ = strings.Cut(cmd, "@")
+	return strings.ToLower(cmd), strings.TrimSpace(arg), cmd != ""
+}
+
+// FormatSong - "Исполнитель - Название [3:20]", исполнитель и длительность - если известны.
+func FormatSong(song player.Song) string {
+	s := song.Name
+	if song.Artist != "" {
+		s = song.Artist + " - " + s
+	}
+
+	if song.Duration > 0 {
+		s += " [" + player.FormatDuration(song.Duration) + "]"
+	}
+
+	return s
+}
+
+// nowPlayingTemplate - шаблон ответа /np для player.RenderNowPlaying.
+const nowPlayingTemplate = "{{if .Playing}}▶{{else}}⏸{{end}} {{with .Artist}}{{.}} - {{end}}{{.Title}}" +
+	"{{with .Chapter}} ({{.}}){{end}}{{with .Duration}} {{$.Elapsed}}/{{.}}{{end}}"
+
+// FormatNowPlaying - ответ "что играет": "▶ Исполнитель - Название 1:05/3:20".
+func FormatNowPlaying(st player.Status) string {
+	s, err := player.RenderNowPlaying(nowPlayingTemplate, st, player.English)
+	if err != nil {
+		return "nothing to play, the playlist is empty"
+	}
+
+	return s
+}
+
+// FormatQueue - список песен очереди, текущая отмечена "▶".
+// Показывается не больше limit песен, начиная с текущей, 0 - без ограничения.
+func FormatQueue(queue []player.QueuedSong, current player.SongID, limit int) string {
+	if len(queue) == 0 {
+		return "the queue is empty"
+	}
+
+	start := 0
+	for i, s := range queue {
+		if s.ID == current {
+			start = i
+			break
+		}
+	}
+
+	shown := queue[start:]
+	if limit > 0 && len(shown) > limit {
+		shown = shown[:limit]
+	}
+
+	lines := make([]string, 0, len(shown)+1)
+	for i, s := range shown {
+		marker := fmt.Sprintf("%d.", start+i+1)
+		if s.ID == current {
+			marker = "▶"
+		}
+
+		line := marker + " " + FormatSong(s.Song)
+		if s.AddedBy != "" {
+			line += " (" + s.AddedBy + ")"
+		}
+		lines = append(lines, line)
+	}
+
+	if rest := len(queue) - start - len(shown); rest > 0 {
+		lines = append(lines, fmt.Sprintf("…and %d more", rest))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"player"
+	"player/playertest"
+)
+
+func TestBot_Handle(t *testing.T) {
+	ctx := context.Background()
+	songs := []player.Song{
+		{Name: "Intro", Artist: "Band", Duration: 3*time.Minute + 20*time.Second},
+		{Name: "Outro", Duration: time.Minute},
+	}
+
+	lib := player.NewLibrary()
+	_, err := lib.Add(player.Song{Name: "Bohemian Rhapsody", Artist: "Queen", Duration: 6 * time.Minute})
+	td.CmpNoError(t, err)
+
+	t.Run("playback", func(t *testing.T) {
+		fake := playertest.NewFake(songs...)
+		b := New(fake, nil)
+
+		td.Cmp(t, b.Handle(ctx, "u", "привет"), "", "не команда")
+		td.Cmp(t, b.Handle(ctx, "u", "/np"), "⏸ Band - Intro 0:00/3:20")
+		td.Cmp(t, b.Handle(ctx, "u", "/play"), "▶ Band - Intro 0:00/3:20")
+
+		fake.Advance(65 * time.Second)
+		td.Cmp(t, b.Handle(ctx, "u", "/NP@jukebox_bot"), "▶ Band - Intro 1:05/3:20", "команда группы Telegram")
+		td.Cmp(t, b.Handle(ctx, "u", "/next"), "▶ Outro 0:00/1:00")
+		td.Cmp(t, b.Handle(ctx, "u", "/prev"), "▶ Band - Intro 0:00/3:20")
+		td.Cmp(t, b.Handle(ctx, "u", "/pause"), "paused")
+		td.Cmp(t, b.Handle(ctx, "u", "/dance"), help)
+
+		fake.FailNext("Play", errors.New("device is busy"))
+		td.Cmp(t, b.Handle(ctx, "u", "/play"), "error: device is busy")
+
+		td.Cmp(t, b.Handle(ctx, "u", "/queue Queen"), "error: queueing by name is not configured")
+		td.Cmp(t, b.Handle(ctx, "u", "/queue"), "error: queue listing is not supported")
+	})
+
+	t.Run("queue", func(t *testing.T) {
+		fake := playertest.NewFake(songs...)
+		b := New(fake, LibraryResolver(lib))
+
+		td.Cmp(t, b.Handle(ctx, "u", "/queue  queen "), "queued: Queen - Bohemian Rhapsody [6:00]")
+		td.Cmp(t, fake.Songs(), td.Len(3))
+		td.Cmp(t, b.Handle(ctx, "u", "/queue Metallica"), `error: no matching song: "Metallica"`)
+	})
+
+	t.Run("player", func(t *testing.T) {
+		pl, err := player.NewPlayer(songs...)
+		td.CmpNoError(t, err)
+		b := New(pl, LibraryResolver(lib))
+
+		td.Cmp(t, b.Handle(ctx, "tg:1", "/queue rhapsody"), "queued: Queen - Bohemian Rhapsody [6:00]")
+		td.Cmp(t, b.Handle(ctx, "tg:1", "/queue"), "▶ Band - Intro [3:20]\n2. Outro [1:00]\n3. Queen - Bohemian Rhapsody [6:00] (tg:1)")
+	})
+}
+
+func TestFormatQueue(t *testing.T) {
+	song := func(name string) player.Song { return player.Song{Name: name} }
+	queue := []player.QueuedSong{{ID: 1, Song: song("a")}, {ID: 2, Song: song("b")}, {ID: 3, Song: song("c")}, {ID: 4, Song: song("d")}}
+
+	td.Cmp(t, FormatQueue(nil, 0, 0), "the queue is empty")
+	td.Cmp(t, FormatQueue(queue, 2, 2), "▶ b\n3. c\n…and 1 more", "с текущей песни")
+	td.Cmp(t, FormatQueue(queue, 0, 0), "1. a\n2. b\n3. c\n4. d", "ничего не играет")
+}
+
+func TestFormatNowPlaying(t *testing.T) {
+	td.Cmp(t, FormatNowPlaying(player.Status{}), "nothing to play, the playlist is empty")
+	td.Cmp(t, FormatNowPlaying(player.Status{
+		Song:     &player.Song{Name: "live"},
+		Playing:  true,
+		Position: time.Minute,
+	}), "▶ live", "поток без длительности")
+	td.Cmp(t, FormatNowPlaying(player.Status{
+		Song:     &player.Song{Name: "book", Duration: time.Hour},
+		Position: 90 * time.Second,
+		Chapter:  &player.Chapter{Title: "Глава 1"},
+	}), "⏸ book (Глава 1) 1:30/1:00:00", "от часа - с часами")
+}
@@ -0,0 +1,114 @@
+package bot
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Типы взаимодействий и ответов Discord.
+const (
+	discordPing               = 1
+	discordApplicationCommand = 2
+
+	discordPong           = 1
+	discordChannelMessage = 4
+)
+
+// discordInteraction - входящее взаимодействие Discord.
+type discordInteraction struct {
+	Type int `json:"type"`
+	Data struct {
+		Name    string `json:"name"`
+		Options []struct {
+			Value any `json:"value"`
+		} `json:"options"`
+	} `json:"data"`
+	Member *struct {
+		User discordUser `json:"user"`
+	} `json:"member"`
+	User *discordUser `json:"user"`
+}
+
+type discordUser struct {
+	ID string `json:"id"`
+}
+
+// DiscordHandler - HTTP-обработчик Interactions Endpoint URL приложения Discord.
+// Слэш-команды (/play, /queue name:...) передаются боту, значения опций
+// склеиваются через пробел. publicKey - Public Key приложения из Developer Portal,
+// им проверяется подпись каждого запроса. Пользователь для AddSongAs - "discord:<id>".
+func DiscordHandler(b *Bot, publicKey ed25519.PublicKey) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, "cannot read body", http.StatusBadRequest)
+			return
+		}
+
+		sig, err := hex.DecodeString(r.Header.Get("X-Signature-Ed25519"))
+		timestamp := r.Header.Get("X-Signature-Timestamp")
+		if err != nil || len(publicKey) != ed25519.PublicKeySize ||
+			!ed25519.Verify(publicKey, append([]byte(timestamp), body...), sig) {
+			http.Error(w, "invalid request signature", http.StatusUnauthorized)
+			return
+		}
+
+		var in discordInteraction
+		if err := json.Unmarshal(body, &in); err != nil {
+			http.Error(w, "invalid interaction", http.StatusBadRequest)
+			return
+		}
+
+		switch in.Type {
+		case discordPing:
+			writeDiscord(w, map[string]any{"type": discordPong})
+		case discordApplicationCommand:
+			reply := b.Handle(r.Context(), "discord:"+in.userID(), in.command())
+			writeDiscord(w, map[string]any{
+				"type": discordChannelMessage,
+				"data": map[string]any{"content": reply},
+			})
+		default:
+			http.Error(w, "unsupported interaction type", http.StatusBadRequest)
+		}
+	})
+}
+
+// command - слэш-команда в виде текста "/queue song name".
+func (in *discordInteraction) command() string {
+	parts := []string{"/" + in.Data.Name}
+	for _, o := range in.Data.Options {
+		parts = append(parts, fmt.Sprint(o.Value))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// userID - автор команды: в гильдии он в member.user, в личных сообщениях в user.
+func (in *discordInteraction) userID() string {
+	if in.Member != nil {
+		return in.Member.User.ID
+	}
+
+	if in.User != nil {
+		return in.User.ID
+	}
+
+	return ""
+}
+
+func writeDiscord(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"player"
+	"player/playertest"
+)
+
+func TestDiscordHandler(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	td.CmpNoError(t, err)
+
+	fake := playertest.NewFake(player.Song{Name: "a", Duration: time.Minute})
+	lib := player.NewLibrary()
+	_, err = lib.Add(player.Song{Name: "b", Duration: time.Minute})
+	td.CmpNoError(t, err)
+	h := DiscordHandler(New(fake, LibraryResolver(lib)), pub)
+
+	send := func(body string, key ed25519.PrivateKey) *httptest.ResponseRecorder {
+		const timestamp = "1700000000"
+		r := httptest.NewRequest(http.MethodPost, "/interactions", strings.NewReader(body))
+		r.Header.Set("X-Signature-Timestamp", timestamp)
+		r.Header.Set("X-Signature-Ed25519", hex.EncodeToString(ed25519.Sign(key, []byte(timestamp+body))))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	reply := func(w *httptest.ResponseRecorder) any {
+		var v any
+		td.CmpNoError(t, json.Unmarshal(w.Body.Bytes(), &v))
+		return v
+	}
+
+	w := send(`{"type": 1}`, priv)
+	td.Cmp(t, w.Code, http.StatusOK)
+	td.CmpJSON(t, reply(w), `{"type": 1}`, nil, "ответ на PING")
+
+	w = send(`{"type": 2, "data": {"name": "play"}, "member": {"user": {"id": "9"}}}`, priv)
+	td.CmpJSON(t, reply(w), `{"type": 4, "data": {"content": "▶ a 0:00/1:00"}}`, nil)
+	td.Cmp(t, fake.Called("Play"), 1)
+
+	w = send(`{"type": 2, "data": {"name": "queue", "options": [{"name": "song", "value": "b"}]}, "user": {"id": "9"}}`, priv)
+	td.CmpJSON(t, reply(w), `{"type": 4, "data": {"content": "queued: b [1:00]"}}`, nil, "опции склеиваются в аргумент")
+
+	_, other, _ := ed25519.GenerateKey(nil)
+	td.Cmp(t, send(`{"type": 2, "data": {"name": "next"}}`, other).Code, http.StatusUnauthorized, "чужая подпись")
+	td.Cmp(t, fake.Called("Next"), 0)
+
+	td.Cmp(t, send(`{"type": 3}`, priv).Code, http.StatusBadRequest)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/interactions", nil))
+	td.Cmp(t, w.Code, http.StatusMethodNotAllowed)
+}
+
+func TestDiscordHandler_playbackOutlivesRequest(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	td.CmpNoError(t, err)
+
+	pl, err := player.NewPlayer(player.Song{Name: "a", Duration: time.Minute}, player.Song{Name: "b", Duration: time.Minute})
+	td.CmpNoError(t, err)
+	defer pl.Close(context.Background())
+	h := DiscordHandler(New(pl, nil), pub)
+
+	for _, cmd := range []string{"play", "next", "prev"} {
+		body := `{"type": 2, "data": {"name": "` + cmd + `"}, "member": {"user": {"id": "9"}}}`
+		const timestamp = "1700000000"
+		ctx, cancel := context.WithCancel(context.Background())
+		r := httptest.NewRequest(http.MethodPost, "/interactions", strings.NewReader(body)).WithContext(ctx)
+		r.Header.Set("X-Signature-Timestamp", timestamp)
+		r.Header.Set("X-Signature-Ed25519", hex.EncodeToString(ed25519.Sign(priv, []byte(timestamp+body))))
+		h.ServeHTTP(httptest.NewRecorder(), r)
+		// http.Server отменяет контекст запроса после ответа
+		cancel()
+
+		time.Sleep(10 * time.Millisecond)
+		td.CmpTrue(t, pl.Status(context.Background()).Playing, "/%s: играет после ответа", cmd)
+	}
+}
@@ -0,0 +1,167 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// telegramAPI - адрес Telegram Bot API по умолчанию.
+const telegramAPI = "https://api.telegram.org"
+
+// Telegram - доставляет боту сообщения из Telegram через long polling getUpdates.
+type Telegram struct {
+	// Token - токен бота от @BotFather
+	Token string
+	// Client - HTTP-клиент, по умолчанию http.DefaultClient
+	Client *http.Client
+	// BaseURL - адрес Bot API, по умолчанию https://api.telegram.org
+	BaseURL string
+	// PollTimeout - сколько Telegram держит getUpdates без новых сообщений, по умолчанию 30 секунд
+	PollTimeout time.Duration
+	// Logger - журнал ошибок отправки, по умолчанию slog.Default
+	Logger *slog.Logger
+}
+
+// telegramUpdate - обновление getUpdates.
+type telegramUpdate struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Text string `json:"text"`
+		From *struct {
+			ID int64 `json:"id"`
+		} `json:"from"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+	} `json:"message"`
+}
+
+// telegramResponse - ответ Bot API.
+type telegramResponse struct {
+	OK          bool            `json:"ok"`
+	Description string          `json:"description"`
+	Result      json.RawMessage `json:"result"`
+}
+
+// Run - получает сообщения и отвечает на команды, пока не отменён ctx.
+// Пользователь для AddSongAs - "tg:<id отправителя>".
+// Возвращает ошибку, если Bot API отклонил запрос getUpdates, например из-за неверного токена.
+func (t *Telegram) Run(ctx context.Context, b *Bot) error {
+	if t.Token == "" {
+		return errors.New("telegram token is empty")
+	}
+
+	var offset int64
+	for {
+		updates, err := t.updates(ctx, offset)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if u.Message == nil || u.Message.From == nil {
+				continue
+			}
+
+			reply := b.Handle(ctx, "tg:"+strconv.FormatInt(u.Message.From.ID, 10), u.Message.Text)
+			if reply == "" {
+				continue
+			}
+
+			if err := t.send(ctx, u.Message.Chat.ID, reply); err != nil {
+				t.logger().WarnContext(ctx, "telegram reply failed", slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// updates - вызывает getUpdates.
+func (t *Telegram) updates(ctx context.Context, offset int64) ([]telegramUpdate, error) {
+	timeout := t.PollTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	var updates []telegramUpdate
+	err := t.call(ctx, "getUpdates", map[string]any{
+		"offset":          offset,
+		"timeout":         int(timeout / time.Second),
+		"allowed_updates": []string{"message"},
+	}, &updates)
+	return updates, err
+}
+
+// send - вызывает sendMessage.
+func (t *Telegram) send(ctx context.Context, chatID int64, text string) error {
+	return t.call(ctx, "sendMessage", map[string]any{"chat_id": chatID, "text": text}, nil)
+}
+
+// call - вызывает метод Bot API и разбирает result в v.
+func (t *Telegram) call(ctx context.Context, method string, params any, v any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	base := t.BaseURL
+	if base == "" {
+		base = telegramAPI
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+"/bot"+t.Token+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// ошибка клиента содержит URL с токеном
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var res telegramResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("telegram %s: %s", method, resp.Status)
+	}
+
+	if !res.OK {
+		return fmt.Errorf("telegram %s: %s", method, res.Description)
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	return json.Unmarshal(res.Result, v)
+}
+
+func (t *Telegram) logger() *slog.Logger {
+	if t.Logger != nil {
+		return t.Logger
+	}
+
+	return slog.Default()
+}
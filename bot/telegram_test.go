@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"player"
+	"player/playertest"
+)
+
+func TestTelegram_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		offsets []float64
+		sent    []map[string]any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]any
+		td.CmpNoError(t, json.NewDecoder(r.Body).Decode(&params))
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.URL.Path {
+		case "/bottoken/getUpdates":
+			offsets = append(offsets, params["offset"].(float64))
+			if len(offsets) > 1 {
+				// больше обновлений нет, ждём остановки Run
+				cancel()
+				_, _ = w.Write([]byte(`{"ok": true, "result": []}`))
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"ok": true, "result": [
+				{"update_id": 10, "message": {"text": "/play", "from": {"id": 7}, "chat": {"id": 42}}},
+				{"update_id": 11, "message": {"text": "всем привет", "from": {"id": 7}, "chat": {"id": 42}}},
+				{"update_id": 12, "edited_message": {}}
+			]}`))
+		case "/bottoken/sendMessage":
+			sent = append(sent, params)
+			_, _ = w.Write([]byte(`{"ok": true, "result": {}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"ok": false, "description": "Not Found"}`))
+		}
+	}))
+	defer srv.Close()
+
+	fake := playertest.NewFake(player.Song{Name: "a", Duration: time.Minute})
+	tg := &Telegram{Token: "token", BaseURL: srv.URL, Client: srv.Client()}
+
+	td.Cmp(t, tg.Run(ctx, New(fake, nil)), context.Canceled)
+	td.Cmp(t, fake.Called("Play"), 1)
+
+	mu.Lock()
+	td.Cmp(t, offsets, []float64{0, 13}, "подтверждены все обновления")
+	td.Cmp(t, sent, []map[string]any{{"chat_id": 42.0, "text": "▶ a 0:00/1:00"}}, "ответ только на команду")
+	mu.Unlock()
+
+	t.Run("errors", func(t *testing.T) {
+		td.CmpString(t, (&Telegram{}).Run(context.Background(), New(fake, nil)), "telegram token is empty")
+
+		tg := &Telegram{Token: "bad", BaseURL: srv.URL, Client: srv.Client()}
+		td.CmpString(t, tg.Run(context.Background(), New(fake, nil)), "telegram getUpdates: Not Found")
+	})
+}
@@ -0,0 +1,356 @@
+package player
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// defaultCachePrefetch - сколько следующих песен скачивается заранее по умолчанию
+	defaultCachePrefetch = 2
+	// cacheExt - расширение файлов кэша
+	cacheExt = ".song"
+)
+
+// CacheEvent - обращение к кэшу при запуске песни.
+type CacheEvent struct {
+	// Song - запущенная песня
+	Song Song
+	// Hit - песня уже была скачана
+	Hit bool
+	// State - состояние плеера в момент запуска
+	State State
+}
+
+// CacheHook - обработчик обращений к кэшу.
+type CacheHook func(event CacheEvent)
+
+// songCache - кэш скачанных песен на диске с вытеснением давно не игравших.
+type songCache struct {
+	dir      string
+	maxBytes int64
+	prefetch int
+
+	size int64
+	// lru - записи от недавно использованных к давно не использованным
+	lru     *list.List
+	entries map[string]*list.Element
+	// loading - песни, которые скачиваются сейчас или не скачались
+	loading map[string]bool
+
+	hits   int
+	misses int
+	hooks  []CacheHook
+}
+
+// cacheEntry - скачанная песня.
+type cacheEntry struct {
+	key  string
+	size int64
+}
+
+// cachedSource - Source, который читает песню из кэша.
+type cachedSource struct {
+	Source
+	path string
+}
+
+func (s cachedSource) Open(context.Context) (io.ReadCloser, error) {
+	return os.Open(s.path)
+}
+
+// WithCache - скачивает следующие песни с Source в каталог dir заранее, пока
+// играет текущая, чтобы при нестабильной сети песни начинались без задержки.
+// Бэкенд получает песню, Source которой читает скачанный файл.
+// Когда кэш превышает maxBytes, удаляются давно не игравшие песни.
+// Файлы из dir, оставшиеся с прошлого запуска, используются повторно.
+func WithCache(dir string, maxBytes int64) Option {
+	return func(p *playerImpl) error {
+		if dir == "" {
+			return errors.New("cache dir is empty")
+		}
+		if maxBytes <= 0 {
+			return errors.New("cache size must be positive")
+		}
+
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create cache dir: %v", err)
+		}
+
+		c := &songCache{
+			dir:      dir,
+			maxBytes: maxBytes,
+			prefetch: defaultCachePrefetch,
+			lru:      list.New(),
+			entries:  make(map[string]*list.Element),
+			loading:  make(map[string]bool),
+		}
+		if err := c.load(); err != nil {
+			return err
+		}
+
+		p.cache = c
+		return nil
+	}
+}
+
+// WithCachePrefetch - сколько следующих песен скачивать заранее, по умолчанию 2.
+// Задаётся после WithCache.
+func WithCachePrefetch(n int) Option {
+	return func(p *playerImpl) error {
+		if n <= 0 {
+			return errors.New("prefetch count must be positive")
+		}
+		if p.cache == nil {
+			return errors.New("cache is not configured")
+		}
+
+		p.cache.prefetch = n
+		return nil
+	}
+}
+
+// OnCacheLookup - регистрирует обработчик, который вызывается при запуске
+// каждой песни, которую можно кэшировать, с признаком попадания в кэш.
+// Обработчики вызываются по порядку в отдельной горутине.
+func (p *playerImpl) OnCacheLookup(ctx context.Context, hook CacheHook) error {
+	if hook == nil {
+		return errors.New("hook is nil")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if p.cache == nil {
+		return errors.New("cache is not configured")
+	}
+
+	p.cache.hooks = append(p.cache.hooks, hook)
+	return nil
+}
+
+// cacheKey - ключ песни в кэше, не зависит от того, известна ли длительность.
+func cacheKey(song Song) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{song.Name, song.Artist, song.Album, song.URL}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheable - сообщает, есть ли смысл скачивать песню.
+func cacheable(song Song) bool {
+	if song.Source == nil {
+		return false
+	}
+
+	// локальные файлы и так читаются быстро
+	_, local := song.Source.(FileSource)
+	return !local
+}
+
+// path - путь к файлу песни с ключом key.
+func (c *songCache) path(key string) string {
+	return filepath.Join(c.dir, key+cacheExt)
+}
+
+// load - находит файлы, скачанные при прошлом запуске.
+func (c *songCache) load() error {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("read cache dir: %v", err)
+	}
+
+	type cached struct {
+		key  string
+		size int64
+		mod  int64
+	}
+	var found []cached
+	for _, f := range files {
+		key, ok := strings.CutSuffix(f.Name(), cacheExt)
+		if !ok || f.IsDir() {
+			continue
+		}
+
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		found = append(found, cached{key: key, size: info.Size(), mod: info.ModTime().UnixNano()})
+	}
+
+	// свежие файлы считаются недавно использованными
+	sort.Slice(found, func(i, j int) bool { return found[i].mod > found[j].mod })
+	for _, f := range found {
+		c.entries[f.key] = c.lru.PushBack(&cacheEntry{key: f.key, size: f.size})
+		c.size += f.size
+	}
+	c.evict()
+
+	return nil
+}
+
+// add - добавляет скачанную песню в кэш.
+func (c *songCache) add(key string, size int64) {
+	if el, ok := c.entries[key]; ok {
+		c.size -= el.Value.(*cacheEntry).size
+		c.lru.Remove(el)
+	}
+
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, size: size})
+	c.size += size
+	c.evict()
+}
+
+// evict - удаляет давно не использованные песни, пока кэш больше maxBytes.
+func (c *songCache) evict() {
+	for c.size > c.maxBytes && c.lru.Len() > 0 {
+		el := c.lru.Back()
+		e := el.Value.(*cacheEntry)
+		c.lru.Remove(el)
+		delete(c.entries, e.key)
+		c.size -= e.size
+		_ = os.Remove(c.path(e.key))
+	}
+}
+
+// cachedLocked - возвращает песню, которую нужно передать бэкенду:
+// если песня скачана, её Source читает файл из кэша.
+// Учитывает попадание в кэш.
+// Вызывается под блокировкой.
+func (p *playerImpl) cachedLocked(song Song) Song {
+	c := p.cache
+	if c == nil || !cacheable(song) {
+		return song
+	}
+
+	key := cacheKey(song)
+	el, hit := c.entries[key]
+	if hit {
+		c.hits++
+		c.lru.MoveToFront(el)
+		song.Source = cachedSource{Source: song.Source, path: c.path(key)}
+	} else {
+		c.misses++
+	}
+
+	if len(c.hooks) > 0 {
+		event := CacheEvent{Song: song, Hit: hit, State: p.stateLocked()}
+		hooks := append([]CacheHook(nil), c.hooks...)
+		p.hookQueue.push(func() {
+			for _, h := range hooks {
+				h(event)
+			}
+		})
+	}
+
+	return song
+}
+
+// prefetchDueLocked - сообщает, что одну из следующих песен нужно скачать.
+// Вызывается под блокировкой.
+func (p *playerImpl) prefetchDueLocked() bool {
+	return len(p.prefetchNodesLocked()) > 0
+}
+
+// prefetchNodesLocked - возвращает следующие песни, которые ещё не скачаны и не скачиваются.
+// Вызывается под блокировкой.
+func (p *playerImpl) prefetchNodesLocked() []*playerNode {
+	c := p.cache
+	if c == nil {
+		return nil
+	}
+
+	var nodes []*playerNode
+	node := p.afterLocked(p.current)
+	for i := 0; i < c.prefetch && node != nil; i, node = i+1, p.afterLocked(node) {
+		if !cacheable(*node.song) {
+			continue
+		}
+
+		key := cacheKey(*node.song)
+		if _, ok := c.entries[key]; !ok && !c.loading[key] {
+			nodes = append(nodes, node)
+		}
+	}
+
+	return nodes
+}
+
+// prefetchLocked - скачивает следующие песни в отдельных горутинах.
+// Вызывается под блокировкой.
+func (p *playerImpl) prefetchLocked(ctx context.Context) {
+	for _, node := range p.prefetchNodesLocked() {
+		song := *node.song
+		key := cacheKey(song)
+		p.cache.loading[key] = true
+
+		go func() {
+			size, tmp, err := p.cache.download(ctx, song)
+
+			p.mu.Lock()
+			defer p.mu.Unlock()
+
+			if err != nil {
+				// песня остаётся в loading, чтобы не скачивать её по кругу
+				p.logger.WarnContext(ctx, "song prefetch failed", songAttr(song), slog.Any("error", err))
+				return
+			}
+
+			if p.closed {
+				_ = os.Remove(tmp)
+				return
+			}
+
+			delete(p.cache.loading, key)
+			if err := os.Rename(tmp, p.cache.path(key)); err != nil {
+				_ = os.Remove(tmp)
+				p.logger.WarnContext(ctx, "song prefetch failed", songAttr(song), slog.Any("error", err))
+				return
+			}
+
+			p.cache.add(key, size)
+			p.logger.DebugContext(ctx, "song prefetched", songAttr(song), slog.Int64("bytes", size))
+		}()
+	}
+}
+
+// download - скачивает песню во временный файл и возвращает его размер и путь.
+// Вызывается без блокировки.
+func (c *songCache) download(ctx context.Context, song Song) (int64, string, error) {
+	r, err := song.Source.Open(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+	defer r.Close()
+
+	f, err := os.CreateTemp(c.dir, "download-*")
+	if err != nil {
+		return 0, "", fmt.Errorf("create cache file: %v", err)
+	}
+
+	size, err := io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return 0, "", fmt.Errorf("download: %v", err)
+	}
+
+	return size, f.Name(), nil
+}
@@ -0,0 +1,133 @@
+package player
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// readingOutput - бэкенд, который читает песни через Source.
+type readingOutput struct {
+	nopOutput
+
+	mu    sync.Mutex
+	reads []string
+}
+
+func (o *readingOutput) Start(ctx context.Context, song Song, _ time.Duration) error {
+	r, err := song.Source.Open(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.reads = append(o.reads, string(data))
+	return nil
+}
+
+func (o *readingOutput) Reads() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return append([]string(nil), o.reads...)
+}
+
+func TestPlayerImpl_Cache(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		mu        sync.Mutex
+		downloads []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		downloads = append(downloads, r.URL.Path)
+		mu.Unlock()
+		_, _ = io.WriteString(w, strings.Repeat(strings.TrimPrefix(r.URL.Path, "/"), 10))
+	}))
+	defer srv.Close()
+
+	song := func(name string) Song {
+		return Song{Name: name, Duration: 40 * time.Millisecond, Source: HTTPSource{URL: srv.URL + "/" + name}}
+	}
+	downloaded := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), downloads...)
+	}
+
+	dir := filepath.Join(t.TempDir(), "cache")
+	out := &readingOutput{}
+	pl, err := New(
+		WithValidator(ValidationPolicy{}), WithOutput(out),
+		WithCache(dir, 15), WithCachePrefetch(1),
+		WithSongs(song("a"), song("b"), song("c")),
+	)
+	td.CmpNoError(t, err)
+
+	var (
+		eventsMu sync.Mutex
+		events   []bool
+	)
+	td.CmpNoError(t, pl.OnCacheLookup(ctx, func(e CacheEvent) {
+		eventsMu.Lock()
+		defer eventsMu.Unlock()
+		events = append(events, e.Hit)
+	}))
+
+	td.CmpNoError(t, pl.Play(ctx))
+	waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	td.CmpNoError(t, pl.WaitFor(waitCtx, func(st Status) bool { return !st.Playing }))
+	td.CmpNoError(t, pl.Close(ctx))
+
+	td.Cmp(t, out.Reads(), []string{
+		strings.Repeat("a", 10), strings.Repeat("b", 10), strings.Repeat("c", 10),
+	}, "бэкенд читает те же данные")
+	td.Cmp(t, downloaded(), []string{"/a", "/b", "/c"}, "скачивается только следующая песня, первая - самим бэкендом")
+
+	m := pl.Metrics(ctx)
+	td.Cmp(t, m.CacheMisses, 1, "первая песня не была скачана")
+	td.Cmp(t, m.CacheHits, 2)
+	td.Cmp(t, events, []bool{false, true, true})
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"+cacheExt))
+	td.Cmp(t, files, td.Len(1), "старая песня вытеснена: лимит 15 байт")
+	_, err = os.Stat(filepath.Join(dir, cacheKey(song("b"))+cacheExt))
+	td.CmpTrue(t, os.IsNotExist(err), "вытеснена давно игравшая b")
+
+	t.Run("после перезапуска", func(t *testing.T) {
+		out := &readingOutput{}
+		pl, err := New(WithValidator(ValidationPolicy{}), WithOutput(out), WithCache(dir, 100), WithSongs(song("c")))
+		td.CmpNoError(t, err)
+		defer pl.Close(ctx)
+
+		td.CmpNoError(t, pl.Play(ctx))
+		td.Cmp(t, pl.Metrics(ctx).CacheHits, 1, "файл с прошлого запуска")
+	})
+
+	t.Run("ошибки", func(t *testing.T) {
+		_, err := New(WithCache("", 1))
+		td.CmpString(t, err, "cache dir is empty")
+		_, err = New(WithCache(dir, 0))
+		td.CmpString(t, err, "cache size must be positive")
+		_, err = New(WithCachePrefetch(1))
+		td.CmpString(t, err, "cache is not configured")
+	})
+}
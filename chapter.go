@@ -0,0 +1,206 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ErrNoChapter - у текущей песни нет такой главы.
+var ErrNoChapter = errors.New("chapter not found")
+
+// Chapter - глава песни, например аудиокниги или длинного микса.
+type Chapter struct {
+	// Title - название главы
+	Title string `json:"title"`
+	// Start - начало главы от начала песни
+	Start time.Duration `json:"start"`
+}
+
+// ChapterHook - обработчик смены главы текущей песни.
+type ChapterHook func(song Song, index int, chapter Chapter)
+
+// NextChapter - переходит к началу следующей главы текущей песни.
+func (p *playerImpl) NextChapter(ctx context.Context) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if p.current == nil {
+		return ErrNoChapter
+	}
+
+	return p.seekChapterLocked(ctx, chapterAt(*p.current.song, p.elapsedLocked())+1)
+}
+
+// PrevChapter - переходит к началу предыдущей главы, а если задан WithPrevRestart
+// и текущая глава играет дольше порога - к началу текущей, как Prev.
+func (p *playerImpl) PrevChapter(ctx context.Context) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if p.current == nil {
+		return ErrNoChapter
+	}
+
+	pos := p.elapsedLocked()
+	i := chapterAt(*p.current.song, pos)
+	if i < 0 {
+		return ErrNoChapter
+	}
+
+	if p.prevRestart == 0 || pos-p.current.song.Chapters[i].Start <= p.prevRestart {
+		i = max(i-1, 0)
+	}
+
+	return p.seekChapterLocked(ctx, i)
+}
+
+// SeekToChapter - переходит к началу главы index текущей песни.
+func (p *playerImpl) SeekToChapter(ctx context.Context, index int) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if p.current == nil {
+		return ErrNoChapter
+	}
+
+	return p.seekChapterLocked(ctx, index)
+}
+
+// OnChapterChanged - регистрирует обработчик, который вызывается, когда
+// во время воспроизведения начинается другая глава, в том числе первая глава новой песни.
+// Обработчики вызываются по порядку в отдельной горутине.
+func (p *playerImpl) OnChapterChanged(ctx context.Context, hook ChapterHook) error {
+	if hook == nil {
+		return errors.New("hook is nil")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	p.chapterHooks = append(p.chapterHooks, hook)
+	p.rescheduleLocked()
+	return nil
+}
+
+// seekChapterLocked - переносит позицию на начало главы index.
+// Вызывается под блокировкой.
+func (p *playerImpl) seekChapterLocked(ctx context.Context, index int) error {
+	chapters := p.current.song.Chapters
+	if index < 0 || index >= len(chapters) {
+		return ErrNoChapter
+	}
+
+	if err := p.requireStateLocked("seek", StatePlaying, StateTransitioning, StatePaused); err != nil {
+		return err
+	}
+
+	p.section = nil
+	p.seekLocked(ctx, chapters[index].Start)
+	p.rescheduleLocked()
+
+	p.logger.DebugContext(ctx, "chapter selected", songAttr(*p.current.song),
+		slog.Int("chapter", index), slog.String("title", chapters[index].Title))
+	return nil
+}
+
+// chapterDueLocked - сообщает, что за главами текущей песни нужно следить.
+// Вызывается под блокировкой.
+func (p *playerImpl) chapterDueLocked() bool {
+	return len(p.chapterHooks) > 0 && !p.inGap && len(p.current.song.Chapters) > 0
+}
+
+// untilChapterLocked - возвращает время до начала следующей главы
+// или 0, если о текущей главе ещё не сообщили.
+// Вызывается под блокировкой.
+func (p *playerImpl) untilChapterLocked() time.Duration {
+	pos := p.elapsedLocked()
+	chapters := p.current.song.Chapters
+	i := chapterAt(*p.current.song, pos)
+	if p.chapterNode != p.current || p.chapterIndex != i {
+		return 0
+	}
+
+	if i+1 >= len(chapters) {
+		return unbounded
+	}
+
+	return chapters[i+1].Start - pos
+}
+
+// chapterChangedLocked - запоминает текущую главу и ставит в очередь обработчики.
+// Вызывается под блокировкой.
+func (p *playerImpl) chapterChangedLocked(ctx context.Context) {
+	song := *p.current.song
+	i := chapterAt(song, p.elapsedLocked())
+	p.chapterNode, p.chapterIndex = p.current, i
+
+	// позиция до начала первой главы
+	if i < 0 {
+		return
+	}
+
+	hooks := append([]ChapterHook(nil), p.chapterHooks...)
+	chapter := song.Chapters[i]
+	p.hookQueue.push(func() {
+		for _, h := range hooks {
+			h(song, i, chapter)
+		}
+	})
+
+	p.logger.DebugContext(ctx, "chapter started", songAttr(song), slog.Int("chapter", i), slog.String("title", chapter.Title))
+}
+
+// chapterAt - возвращает индекс главы песни на позиции pos или -1,
+// если глав нет или позиция раньше первой главы.
+func chapterAt(song Song, pos time.Duration) int {
+	i := -1
+	for j, c := range song.Chapters {
+		if c.Start > pos {
+			break
+		}
+		i = j
+	}
+
+	return i
+}
+
+// validateChapters - проверяет, что главы идут по порядку в пределах песни.
+func validateChapters(song Song) error {
+	for i, c := range song.Chapters {
+		if c.Start < 0 || !song.IsStream() && c.Start >= song.Duration {
+			return fmt.Errorf("%w: chapter %d starts outside the song", ErrInvalidSong, i)
+		}
+		if i > 0 && c.Start <= song.Chapters[i-1].Start {
+			return fmt.Errorf("%w: chapter %d starts before the previous one", ErrInvalidSong, i)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Chapters(t *testing.T) {
+	ctx := context.Background()
+	book := Song{Name: "книга", Duration: time.Hour, Chapters: []Chapter{
+		{Title: "пролог", Start: 0},
+		{Title: "глава 1", Start: 10 * time.Minute},
+		{Title: "глава 2", Start: 30 * time.Minute},
+	}}
+
+	t.Run("navigation", func(t *testing.T) {
+		pl, _ := New(WithSongs(book))
+		td.Cmp(t, pl.Status(ctx).Chapter, &book.Chapters[0])
+		td.Cmp(t, pl.NextChapter(ctx), StateError{Op: "seek", State: StateStopped}, "воспроизведение не начато")
+
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpNoError(t, pl.Pause(ctx))
+		td.CmpNoError(t, pl.NextChapter(ctx))
+		td.Cmp(t, pl.Status(ctx).Position, 10*time.Minute)
+		td.Cmp(t, pl.Status(ctx).Chapter, &book.Chapters[1])
+
+		td.CmpNoError(t, pl.SeekToChapter(ctx, 2))
+		td.Cmp(t, pl.Status(ctx).Chapter.Title, "глава 2")
+		td.CmpTrue(t, errors.Is(pl.NextChapter(ctx), ErrNoChapter), "глава последняя")
+		td.CmpTrue(t, errors.Is(pl.SeekToChapter(ctx, 3), ErrNoChapter))
+
+		td.CmpNoError(t, pl.PrevChapter(ctx))
+		td.Cmp(t, pl.Status(ctx).Position, 10*time.Minute)
+		td.CmpNoError(t, pl.PrevChapter(ctx))
+		td.CmpNoError(t, pl.PrevChapter(ctx), "на первой главе остаётся первая")
+		td.Cmp(t, pl.Status(ctx).Position, time.Duration(0))
+	})
+
+	t.Run("prev restart", func(t *testing.T) {
+		pl, _ := New(WithSongs(book), WithPrevRestart(3*time.Second))
+		td.CmpNoError(t, pl.PlayFrom(ctx, 0, 15*time.Minute))
+		_ = pl.Pause(ctx)
+
+		td.CmpNoError(t, pl.PrevChapter(ctx))
+		td.Cmp(t, pl.Status(ctx).Position, 10*time.Minute, "глава играет долго - сначала")
+		td.CmpNoError(t, pl.PrevChapter(ctx))
+		td.Cmp(t, pl.Status(ctx).Position, time.Duration(0))
+	})
+
+	t.Run("no chapters", func(t *testing.T) {
+		pl, _ := New(WithSongs(Song{Name: "a", Duration: time.Minute}))
+		td.CmpNil(t, pl.Status(ctx).Chapter)
+		td.CmpTrue(t, errors.Is(pl.NextChapter(ctx), ErrNoChapter))
+		td.CmpTrue(t, errors.Is(pl.PrevChapter(ctx), ErrNoChapter))
+	})
+
+	t.Run("hooks", func(t *testing.T) {
+		mix := Song{Name: "микс", Duration: time.Minute, Chapters: []Chapter{
+			{Title: "начало", Start: 0},
+			{Title: "середина", Start: 30 * time.Millisecond},
+		}}
+		pl, _ := New(WithSongs(mix))
+
+		var mu sync.Mutex
+		var titles []string
+		td.CmpNoError(t, pl.OnChapterChanged(ctx, func(song Song, i int, c Chapter) {
+			mu.Lock()
+			defer mu.Unlock()
+			titles = append(titles, c.Title)
+		}))
+		td.CmpString(t, pl.OnChapterChanged(ctx, nil), "hook is nil")
+
+		_ = pl.Play(ctx)
+		time.Sleep(60 * time.Millisecond)
+		_ = pl.Pause(ctx)
+		td.CmpNoError(t, pl.hookQueue.wait(ctx))
+
+		mu.Lock()
+		defer mu.Unlock()
+		td.Cmp(t, titles, []string{"начало", "середина"})
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		bad := Song{Name: "a", Duration: time.Minute, Chapters: []Chapter{
+			{Title: "1", Start: 20 * time.Second},
+			{Title: "2", Start: 10 * time.Second},
+		}}
+		td.CmpTrue(t, errors.Is(DefaultValidationPolicy.Validate(bad), ErrInvalidSong))
+
+		bad.Chapters = []Chapter{{Title: "1", Start: time.Minute}}
+		td.CmpTrue(t, errors.Is(DefaultValidationPolicy.Validate(bad), ErrInvalidSong), "глава за концом песни")
+	})
+}
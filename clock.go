@@ -0,0 +1,215 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRealClock - операция доступна только с FakeClock.
+var ErrRealClock = errors.New("player does not use a fake clock")
+
+// Clock - источник времени для воспроизведения: позиции, переходов, истории и лимитов.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock - системное время.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// FakeClock - часы, время которых идёт только при вызове Advance.
+// С ними плеер переходит между песнями не по таймерам, а по мере
+// продвижения часов, что делает тесты детерминированными.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+	// waiters - ожидающие наступления момента времени
+	waiters map[*clockWaiter]struct{}
+}
+
+// clockWaiter - ожидание момента времени на FakeClock.
+type clockWaiter struct {
+	at time.Time
+	c  chan struct{}
+}
+
+// NewFakeClock - создаёт часы, показывающие start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start, waiters: make(map[*clockWaiter]struct{})}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Advance - сдвигает время вперёд на d и будит горутину воспроизведения,
+// если пора сделать шаг.
+func (c *FakeClock) Advance(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	for w := range c.waiters {
+		if !w.at.After(c.now) {
+			close(w.c)
+			delete(c.waiters, w)
+		}
+	}
+}
+
+// after - канал, который закрывается, когда часы дойдут до момента через d,
+// и функция отмены ожидания.
+func (c *FakeClock) after(d time.Duration) (<-chan struct{}, func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := &clockWaiter{c: make(chan struct{})}
+	if d <= 0 {
+		close(w.c)
+		return w.c, func() {}
+	}
+
+	// unbounded не наступает
+	if d < unbounded {
+		w.at = c.now.Add(d)
+		c.waiters[w] = struct{}{}
+	}
+
+	return w.c, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.waiters, w)
+	}
+}
+
+// WithClock - задаёт источник времени, по умолчанию системное время.
+// С FakeClock доступен SimulatePlayback.
+func WithClock(c Clock) Option {
+	return func(p *playerImpl) error {
+		if c == nil {
+			return errors.New("clock is nil")
+		}
+
+		p.clock = c
+		return nil
+	}
+}
+
+// now - текущее время по часам плеера.
+func (p *playerImpl) now() time.Time {
+	return p.clock.Now()
+}
+
+// since - время, прошедшее с t по часам плеера.
+func (p *playerImpl) since(t time.Time) time.Duration {
+	return p.now().Sub(t)
+}
+
+// SimulatePlayback - мгновенно проигрывает wallTime времени воспроизведения:
+// сдвигает FakeClock и выполняет все наступившие шаги по порядку.
+// Возвращает песни, которые играли за это время, начиная с текущей.
+// Если воспроизведение остановилось раньше, оставшееся время часы
+// всё равно проходят. Без FakeClock возвращает ErrRealClock.
+func (p *playerImpl) SimulatePlayback(ctx context.Context, wallTime time.Duration) ([]Song, error) {
+	if wallTime < 0 {
+		return nil, errors.New("simulated time is negative")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrClosed
+	}
+
+	clock, ok := p.clock.(*FakeClock)
+	if !ok {
+		return nil, ErrRealClock
+	}
+
+	var played []Song
+	if p.isPlaying {
+		played = append(played, *p.current.song)
+	}
+
+	left := wallTime
+	for p.isPlaying {
+		if err := ctx.Err(); err != nil {
+			return played, err
+		}
+
+		wait := max(min(p.untilStepLocked(), p.untilFadeLocked()), 0)
+		if wait > left {
+			break
+		}
+
+		clock.Advance(wait)
+		left -= wait
+
+		// затухающая песня останавливается по порядку, а не когда её горутина получит блокировку
+		if p.fading != nil && p.untilFadeLocked() <= 0 {
+			p.stopFadeLocked(ctx)
+			continue
+		}
+
+		cur := p.current
+		p.stepLocked(ctx)
+		if p.current != cur && p.isPlaying {
+			played = append(played, *p.current.song)
+		}
+	}
+
+	clock.Advance(left)
+	if p.isPlaying {
+		p.beat(p.untilStepLocked())
+	}
+	return played, nil
+}
+
+// after - канал, который закрывается через d по часам плеера,
+// и функция отмены ожидания.
+func (p *playerImpl) after(d time.Duration) (<-chan struct{}, func()) {
+	if c, ok := p.clock.(*FakeClock); ok {
+		return c.after(d)
+	}
+
+	due := make(chan struct{})
+	timer := time.AfterFunc(d, func() { close(due) })
+	return due, func() { timer.Stop() }
+}
+
+// afterFunc - вызывает f в отдельной горутине через d по часам плеера
+// и возвращает функцию отмены вызова.
+func (p *playerImpl) afterFunc(d time.Duration, f func()) func() {
+	due, cancel := p.after(d)
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-due:
+			f()
+		case <-stop:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			close(stop)
+		})
+	}
+}
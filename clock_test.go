@@ -0,0 +1,93 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_SimulatePlayback(t *testing.T) {
+	ctx := context.Background()
+	songs := []Song{
+		{Name: "a", Duration: 3 * time.Minute},
+		{Name: "b", Duration: 4 * time.Minute},
+		{Name: "c", Duration: 5 * time.Minute},
+	}
+
+	t.Run("transitions", func(t *testing.T) {
+		clock := NewFakeClock(testStart)
+		pl, _ := New(WithClock(clock), WithSongs(songs...))
+		td.CmpNoError(t, pl.Play(ctx))
+
+		got, err := pl.SimulatePlayback(ctx, 8*time.Minute)
+		td.CmpNoError(t, err)
+		td.Cmp(t, titles(got), []string{"a", "b", "c"})
+		td.Cmp(t, clock.Now(), testStart.Add(8*time.Minute))
+		td.Cmp(t, pl.Status(ctx).Position, time.Minute, "c играет минуту")
+
+		entries := history(t, pl, 0)
+		td.Cmp(t, len(entries), 2)
+		td.Cmp(t, entries[0].FinishedAt, testStart.Add(7*time.Minute), "история по часам плеера")
+
+		got, err = pl.SimulatePlayback(ctx, time.Hour)
+		td.CmpNoError(t, err)
+		td.Cmp(t, titles(got), []string{"c"})
+		td.CmpFalse(t, pl.Status(ctx).Playing, "плейлист кончился")
+		td.Cmp(t, pl.Status(ctx).Song.Name, "a")
+		td.Cmp(t, clock.Now(), testStart.Add(68*time.Minute), "время идёт и после остановки")
+
+		got, err = pl.SimulatePlayback(ctx, time.Minute)
+		td.CmpNoError(t, err)
+		td.CmpEmpty(t, got, "на паузе ничего не играет")
+	})
+
+	t.Run("gap and hooks", func(t *testing.T) {
+		clock := NewFakeClock(testStart)
+		pl, _ := New(WithClock(clock), WithGap(time.Second), WithSongs(songs...))
+
+		var transitions []time.Time
+		td.CmpNoError(t, pl.OnTrackTransition(ctx, func(e TrackTransition) {
+			transitions = append(transitions, e.At)
+		}))
+		td.CmpNoError(t, pl.Play(ctx))
+
+		got, err := pl.SimulatePlayback(ctx, 3*time.Minute+time.Second+4*time.Minute)
+		td.CmpNoError(t, err)
+		td.Cmp(t, titles(got), []string{"a", "b", "c"})
+		td.Cmp(t, pl.Status(ctx).Position, time.Duration(0), "c начинается после паузы")
+
+		td.CmpNoError(t, pl.Pause(ctx))
+		td.CmpNoError(t, pl.hookQueue.wait(ctx))
+		td.Cmp(t, transitions, []time.Time{testStart.Add(3 * time.Minute), testStart.Add(7*time.Minute + time.Second)})
+	})
+
+	t.Run("advance", func(t *testing.T) {
+		clock := NewFakeClock(testStart)
+		pl, _ := New(WithClock(clock), WithSongs(songs...))
+		td.CmpNoError(t, pl.Play(ctx))
+
+		clock.Advance(3 * time.Minute)
+		td.CmpTrue(t, eventually(func() bool { return pl.Status(ctx).Song.Name == "b" }), "Advance будит воспроизведение")
+		td.Cmp(t, pl.Status(ctx).Position, time.Duration(0))
+		td.CmpNoError(t, pl.Pause(ctx))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		pl, _ := New(WithSongs(songs...))
+		_, err := pl.SimulatePlayback(ctx, time.Minute)
+		td.Cmp(t, err, ErrRealClock)
+
+		_, err = New(WithClock(nil))
+		td.CmpString(t, err, "clock is nil")
+
+		pl, _ = New(WithClock(NewFakeClock(testStart)))
+		_, err = pl.SimulatePlayback(ctx, -time.Second)
+		td.CmpString(t, err, "simulated time is negative")
+
+		td.CmpNoError(t, pl.Close(ctx))
+		_, err = pl.SimulatePlayback(ctx, time.Minute)
+		td.Cmp(t, err, ErrClosed)
+	})
+}
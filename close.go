@@ -0,0 +1,45 @@
+package player
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrClosed - плеер закрыт.
+var ErrClosed = errors.New("player is closed")
+
+// Close - останавливает воспроизведение, сохраняя позицию, отменяет таймеры,
+// сохраняет состояние в хранилище и дожидается выполнения поставленных
+// в очередь обработчиков окончания песен и записи истории.
+// После закрытия методы плеера возвращают ErrClosed.
+// Если ctx завершится раньше обработчиков, возвращается ошибка ctx,
+// но плеер всё равно считается закрытым. Если же ctx завершится раньше,
+// чем освободится блокировка плеера, плеер не закрывается.
+func (p *playerImpl) Close(ctx context.Context) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	if p.closed {
+		p.mu.Unlock()
+		return ErrClosed
+	}
+
+	p.pauseLocked(ctx)
+	p.cancelSleepLocked()
+	for _, s := range p.schedules {
+		p.cancelScheduleLocked(s)
+	}
+	p.closed = true
+	close(p.playbackErrors)
+	close(p.done)
+	p.closeSubscribersLocked()
+	p.notifyLocked()
+	p.mu.Unlock()
+
+	p.logger.InfoContext(ctx, "player closed")
+	if err := p.Persist(ctx); err != nil {
+		return errors.Join(err, p.hookQueue.wait(ctx))
+	}
+
+	return p.hookQueue.wait(ctx)
+}
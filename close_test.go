@@ -0,0 +1,72 @@
+package player
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Close(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stops playback and rejects calls", func(t *testing.T) {
+		out := &recordingOutput{}
+		pl, _ := New(WithOutput(out), WithResumePositions(), WithSongs(
+			Song{Name: "a", Duration: 30 * time.Second},
+			Song{Name: "b", Duration: 30 * time.Second},
+		))
+
+		_ = pl.Play(ctx)
+		time.Sleep(10 * time.Millisecond)
+		td.CmpNoError(t, pl.Close(ctx))
+
+		td.CmpFalse(t, pl.isPlaying, "воспроизведение остановлено")
+		td.Cmp(t, out.Calls(), []string{"start a", "stop a"})
+		td.Cmp(t, pl.resume, td.ContainsKey(songKey(*pl.first().song)), "позиция сохранена")
+
+		td.Cmp(t, pl.Close(ctx), ErrClosed, "повторное закрытие")
+		td.Cmp(t, pl.Play(ctx), ErrClosed)
+		td.Cmp(t, pl.Next(ctx), ErrClosed)
+		td.Cmp(t, pl.SetVolume(ctx, 10), ErrClosed)
+		_, err := pl.AddSong(ctx, Song{Name: "c", Duration: time.Minute})
+		td.Cmp(t, err, ErrClosed)
+		td.Cmp(t, pl.AddSongs(ctx, Song{Name: "c", Duration: time.Minute}), []error{ErrClosed})
+		td.Cmp(t, pl.ClearPlaylist(ctx), ErrClosed)
+		_, err = pl.Deduplicate(ctx)
+		td.Cmp(t, err, ErrClosed)
+		td.Cmp(t, pl.Len(ctx), 2, "песни не добавлены и не удалены")
+		td.Cmp(t, pl.Status(ctx).Song.Name, "a", "состояние доступно для чтения")
+	})
+
+	t.Run("waits for hooks", func(t *testing.T) {
+		var finished atomic.Bool
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithSongs(Song{Name: "a", Duration: 10 * time.Millisecond}))
+		_ = pl.OnSongFinished(ctx, func(Song, bool) {
+			time.Sleep(50 * time.Millisecond)
+			finished.Store(true)
+		})
+
+		_ = pl.Play(ctx)
+		time.Sleep(20 * time.Millisecond)
+		td.CmpNoError(t, pl.Close(ctx))
+		td.CmpTrue(t, finished.Load(), "обработчик выполнен до возврата из Close")
+	})
+
+	t.Run("context expires", func(t *testing.T) {
+		release := make(chan struct{})
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithSongs(Song{Name: "a", Duration: 10 * time.Millisecond}))
+		_ = pl.OnSongFinished(ctx, func(Song, bool) { <-release })
+
+		_ = pl.Play(ctx)
+		time.Sleep(20 * time.Millisecond)
+
+		cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		td.Cmp(t, pl.Close(cctx), context.DeadlineExceeded)
+		td.Cmp(t, pl.Play(ctx), ErrClosed, "плеер закрыт несмотря на ошибку")
+		close(release)
+	})
+}
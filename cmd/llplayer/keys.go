@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"player"
+)
+
+// controls - методы плеера, которыми управляет терминал.
+type controls interface {
+	player.Player
+	Snapshot(ctx context.Context) (player.PlayerState, error)
+}
+
+// handleKey - выполняет команду, соответствующую клавише.
+func handleKey(ctx context.Context, pl controls, key byte, keys <-chan byte, restore func()) error {
+	switch key {
+	case ' ':
+		if pl.Status(ctx).Playing {
+			return pl.Pause(ctx)
+		}
+		return pl.Play(ctx)
+	case 'n':
+		return pl.Next(ctx)
+	case 'p':
+		return pl.Prev(ctx)
+	case 'a':
+		restore()
+		defer rawTerminal()
+
+		song, err := promptSong(keys)
+		if err != nil {
+			return err
+		}
+		_, err = pl.AddSong(ctx, song)
+		return err
+	}
+
+	return nil
+}
+
+// promptSong - запрашивает название и длительность новой песни.
+func promptSong(keys <-chan byte) (player.Song, error) {
+	fmt.Print("\r\nname: ")
+	name := readLine(keys)
+	fmt.Print("duration (e.g. 3m20s): ")
+	d, err := time.ParseDuration(readLine(keys))
+	if err != nil {
+		return player.Song{}, fmt.Errorf("parse duration: %v", err)
+	}
+
+	return player.NewSong(name, d)
+}
+
+// readLine - читает строку из потока клавиш.
+func readLine(keys <-chan byte) string {
+	var sb strings.Builder
+	for b := range keys {
+		if b == '\n' || b == '\r' {
+			break
+		}
+		sb.WriteByte(b)
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// rawTerminal - переводит терминал в посимвольный режим без эха
+// и возвращает функцию восстановления. Если терминал не поддерживает
+// stty, команды вводятся с подтверждением Enter.
+func rawTerminal() (restore func()) {
+	saved, err := stty("-g")
+	if err != nil {
+		return func() {}
+	}
+
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return func() {}
+	}
+
+	return func() {
+		_, _ = stty(strings.TrimSpace(saved))
+	}
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+
+	out, err := cmd.Output()
+	return string(out), err
+}
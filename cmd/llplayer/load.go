@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"player"
+)
+
+// loadPlaylist - загружает песни из файла M3U, XSPF, PLS или JSON по расширению.
+func loadPlaylist(path string) ([]player.Song, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".m3u", ".m3u8":
+		return readM3U(f)
+	case ".xspf":
+		return player.ReadPlaylist(f, player.FormatXSPF)
+	case ".pls":
+		return player.ReadPlaylist(f, player.FormatPLS)
+	case ".json":
+		return readJSON(f)
+	default:
+		return nil, fmt.Errorf("unsupported playlist format %q", filepath.Ext(path))
+	}
+}
+
+// readJSON - читает массив песен в формате [{"name": "...", "duration": 1000000000}].
+func readJSON(r io.Reader) ([]player.Song, error) {
+	var songs []player.Song
+	if err := json.NewDecoder(r).Decode(&songs); err != nil {
+		return nil, fmt.Errorf("decode json: %v", err)
+	}
+
+	return songs, nil
+}
+
+// readM3U - читает расширенный M3U: название и длительность берутся из #EXTINF,
+// который обязателен для каждой записи. Отрицательная длительность означает поток,
+// его адрес берётся из строки записи.
+func readM3U(r io.Reader) ([]player.Song, error) {
+	var (
+		songs []player.Song
+		info  *player.Song
+	)
+
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+
+		switch {
+		case text == "" || text == "#EXTM3U":
+		case strings.HasPrefix(text, "#EXTINF:"):
+			secs, name, ok := strings.Cut(strings.TrimPrefix(text, "#EXTINF:"), ",")
+			if !ok {
+				return nil, fmt.Errorf("line %d: malformed EXTINF", line)
+			}
+
+			n, err := strconv.Atoi(strings.TrimSpace(secs))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: parse duration: %v", line, err)
+			}
+			info = &player.Song{Name: strings.TrimSpace(name), Duration: time.Duration(max(n, 0)) * time.Second}
+		case strings.HasPrefix(text, "#"):
+		default:
+			if info == nil {
+				return nil, fmt.Errorf("line %d: missing EXTINF for %q", line, text)
+			}
+			if info.IsStream() {
+				info.URL = text
+			}
+			songs = append(songs, *info)
+			info = nil
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	return songs, nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"player"
+)
+
+func TestReadM3U(t *testing.T) {
+	songs, err := readM3U(strings.NewReader(`#EXTM3U
+#EXTINF:30,Сектор Газа - 30 лет
+music/sg.mp3
+
+# комментарий
+#EXTINF:11,Александр Пушной - Почему я идиот?
+music/ap.mp3
+#EXTINF:-1,Радио Шансон
+http://chanson.example/stream
+`))
+	td.CmpNoError(t, err)
+	td.Cmp(t, songs, []player.Song{
+		{Name: "Сектор Газа - 30 лет", Duration: 30 * time.Second},
+		{Name: "Александр Пушной - Почему я идиот?", Duration: 11 * time.Second},
+		{Name: "Радио Шансон", URL: "http://chanson.example/stream"},
+	})
+
+	_, err = readM3U(strings.NewReader("#EXTINF:abc,name\nfile.mp3\n"))
+	td.CmpError(t, err, "длительность не число")
+
+	_, err = readM3U(strings.NewReader("file.mp3\n"))
+	td.CmpError(t, err, "нет EXTINF")
+}
+
+func TestReadJSON(t *testing.T) {
+	songs, err := readJSON(strings.NewReader(`[{"name": "Сектор Газа - 30 лет", "duration": 30000000000}]`))
+	td.CmpNoError(t, err)
+	td.Cmp(t, songs, []player.Song{{Name: "Сектор Газа - 30 лет", Duration: 30 * time.Second}})
+}
+
+func TestProgressBar(t *testing.T) {
+	td.Cmp(t, progressBar(0, time.Minute), "["+strings.Repeat("-", progressWidth)+"] 0:00/1:00")
+	td.Cmp(t, progressBar(30*time.Second, time.Minute),
+		"["+strings.Repeat("#", progressWidth/2)+strings.Repeat("-", progressWidth/2)+"] 0:30/1:00")
+	td.Cmp(t, progressBar(2*time.Minute, time.Minute), "["+strings.Repeat("#", progressWidth)+"] 2:00/1:00")
+	td.Cmp(t, progressBar(90*time.Second, 0), "[live] 1:30", "поток")
+}
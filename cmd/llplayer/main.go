@@ -0,0 +1,101 @@
+// Команда llplayer - интерактивный терминальный плеер.
+//
+// Загружает плейлист из файла M3U, XSPF, PLS или JSON, показывает очередь и прогресс
+// текущей песни и управляется клавишами:
+//
+//	пробел - пауза/воспроизведение
+//	n      - следующая песня
+//	p      - предыдущая песня
+//	a      - добавить песню
+//	q      - выход
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"player"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: llplayer [playlist.m3u|playlist.xspf|playlist.pls|playlist.json]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(flag.Arg(0)); err != nil {
+		fmt.Fprintln(os.Stderr, "llplayer:", err)
+		os.Exit(1)
+	}
+}
+
+func run(path string) error {
+	var songs []player.Song
+	if path != "" {
+		var err error
+		if songs, err = loadPlaylist(path); err != nil {
+			return fmt.Errorf("load playlist: %v", err)
+		}
+	}
+
+	pl, err := player.NewPlayer(songs...)
+	if err != nil {
+		return fmt.Errorf("create player: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		_ = pl.Close(ctx)
+	}()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	restore := rawTerminal()
+	defer restore()
+
+	keys := make(chan byte)
+	in := bufio.NewReader(os.Stdin)
+	go func() {
+		for {
+			b, err := in.ReadByte()
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- b
+		}
+	}()
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	status := ""
+	for {
+		if err := render(ctx, os.Stdout, pl, status); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case key, ok := <-keys:
+			if !ok || key == 'q' {
+				return nil
+			}
+
+			status = ""
+			if err := handleKey(ctx, pl, key, keys, restore); err != nil {
+				status = err.Error()
+			}
+		}
+	}
+}
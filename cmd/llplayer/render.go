@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"player"
+)
+
+// progressWidth - ширина полосы прогресса в символах.
+const progressWidth = 40
+
+// nowPlayingTemplate - строка текущей песни над полосой прогресса.
+const nowPlayingTemplate = "{{if .Playing}}|>{{else}}||{{end}} {{with .Artist}}{{.}} – {{end}}{{.Title}}"
+
+// render - перерисовывает экран: очередь, прогресс текущей песни и подсказку.
+func render(ctx context.Context, w io.Writer, pl controls, status string) error {
+	state, err := pl.Snapshot(ctx)
+	if err != nil {
+		return fmt.Errorf("snapshot: %v", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\033[H\033[2J")
+
+	var queue []string
+	for _, ps := range state.Playlists {
+		if ps.Name != state.Active {
+			continue
+		}
+
+		for i, s := range ps.Songs {
+			marker := "  "
+			if i == ps.Cursor {
+				marker = "> "
+			}
+			length := "live"
+			if !s.IsStream() {
+				length = player.FormatDuration(s.Duration)
+			}
+			queue = append(queue, fmt.Sprintf("%s%2d. %s [%s]", marker, i+1, s.Name, length))
+		}
+
+		if ps.Cursor >= 0 {
+			cur := ps.Songs[ps.Cursor]
+			st := player.Status{Playlist: ps.Name, Song: &cur, Position: ps.PlayedTime, Playing: state.IsPlaying}
+			line, err := player.RenderNowPlaying(nowPlayingTemplate, st, player.English)
+			if err != nil {
+				return err
+			}
+			queue = append(queue, "", line, progressBar(ps.PlayedTime, cur.Duration))
+		}
+	}
+
+	if len(queue) == 0 {
+		queue = append(queue, "playlist is empty, press 'a' to add a song")
+	}
+
+	sb.WriteString(strings.Join(queue, "\r\n"))
+	sb.WriteString("\r\n\r\n[space] play/pause  [n] next  [p] prev  [a] add  [q] quit\r\n")
+	if status != "" {
+		sb.WriteString("error: " + status + "\r\n")
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// progressBar - рисует полосу прогресса вида [#####-----] 1:05/3:20.
+// Для потока без длительности показывается только время воспроизведения.
+func progressBar(elapsed, total time.Duration) string {
+	if total == 0 {
+		return fmt.Sprintf("[live] %s", player.FormatDuration(elapsed))
+	}
+
+	filled := 0
+	if total > 0 {
+		filled = int(int64(progressWidth) * int64(elapsed) / int64(total))
+	}
+	if filled > progressWidth {
+		filled = progressWidth
+	}
+
+	return fmt.Sprintf("[%s%s] %s/%s",
+		strings.Repeat("#", filled), strings.Repeat("-", progressWidth-filled),
+		player.FormatDuration(elapsed), player.FormatDuration(total))
+}
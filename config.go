@@ -0,0 +1,269 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Duration - длительность, которая в JSON и YAML записывается строкой
+// time.Duration, например "1m30s".
+type Duration time.Duration
+
+// MarshalText - кодирует длительность строкой, например "1m30s".
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText - разбирает длительность в формате time.ParseDuration.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+	return nil
+}
+
+// Config - настройки воспроизведения, которые можно поменять на ходу через Apply
+// и прочитать через CurrentConfig, например чтобы хранить их в системе контроля версий
+// в JSON или YAML. Нулевое значение поля - настройка по умолчанию или выключена.
+type Config struct {
+	// Edge - поведение на краях плейлиста, EdgeWrap - повтор плейлиста, см. WithEdgeBehavior
+	Edge EdgeBehavior `json:"edge" yaml:"edge"`
+	// Shuffle - случайный порядок песен, см. Shuffle
+	Shuffle bool `json:"shuffle,omitempty" yaml:"shuffle,omitempty"`
+	// Crossfade - наложение песен, см. WithCrossfade
+	Crossfade Duration `json:"crossfade,omitempty" yaml:"crossfade,omitempty"`
+	// Gap - пауза между песнями, см. WithGap
+	Gap Duration `json:"gap,omitempty" yaml:"gap,omitempty"`
+	// FadeIn - нарастание звука, см. WithFade
+	FadeIn Duration `json:"fade_in,omitempty" yaml:"fade_in,omitempty"`
+	// FadeOut - затухание звука, см. WithFade
+	FadeOut Duration `json:"fade_out,omitempty" yaml:"fade_out,omitempty"`
+	// PrevRestart - порог Prev, см. WithPrevRestart
+	PrevRestart Duration `json:"prev_restart,omitempty" yaml:"prev_restart,omitempty"`
+	// Volume - громкость от 0 до 100, в отличие от остальных полей 0 - тишина
+	Volume int `json:"volume" yaml:"volume"`
+	// Muted - звук выключен
+	Muted bool `json:"muted,omitempty" yaml:"muted,omitempty"`
+	// ReplayGainLUFS - целевая громкость, см. WithReplayGain
+	ReplayGainLUFS float64 `json:"replay_gain_lufs,omitempty" yaml:"replay_gain_lufs,omitempty"`
+	// ErrorPolicy - реакция на ошибку запуска песни, см. WithErrorPolicy
+	ErrorPolicy ErrorPolicy `json:"error_policy" yaml:"error_policy"`
+	// MaxPlaylistSize - наибольшее количество песен в плейлисте, см. WithMaxPlaylistSize
+	MaxPlaylistSize int `json:"max_playlist_size,omitempty" yaml:"max_playlist_size,omitempty"`
+	// MaxTotalDuration - наибольшая длительность плейлиста, см. WithMaxTotalDuration
+	MaxTotalDuration Duration `json:"max_total_duration,omitempty" yaml:"max_total_duration,omitempty"`
+	// Eviction - политика заполненного плейлиста, см. WithEvictionPolicy
+	Eviction EvictionPolicy `json:"eviction" yaml:"eviction"`
+	// SongCooldown - защита от повторов, см. WithSongCooldown
+	SongCooldown Duration `json:"song_cooldown,omitempty" yaml:"song_cooldown,omitempty"`
+	// CooldownOnAdd - защита от повторов при добавлении, см. WithCooldownOnAdd
+	CooldownOnAdd bool `json:"cooldown_on_add,omitempty" yaml:"cooldown_on_add,omitempty"`
+	// UserQueueLimit - песен на пользователя, см. WithUserQueueLimit
+	UserQueueLimit int `json:"user_queue_limit,omitempty" yaml:"user_queue_limit,omitempty"`
+	// SkipVotes - голосов для пропуска, см. WithSkipVotes
+	SkipVotes int `json:"skip_votes,omitempty" yaml:"skip_votes,omitempty"`
+	// SkipFraction - доля слушателей для пропуска, см. WithSkipFraction
+	SkipFraction float64 `json:"skip_fraction,omitempty" yaml:"skip_fraction,omitempty"`
+}
+
+// options - настройки плеера, задающие c. Нулевые поля не дают настроек.
+func (c Config) options() []Option {
+	opts := []Option{
+		WithEdgeBehavior(c.Edge),
+		WithCrossfade(time.Duration(c.Crossfade)),
+		WithGap(time.Duration(c.Gap)),
+		WithFade(time.Duration(c.FadeIn), time.Duration(c.FadeOut)),
+		WithPrevRestart(time.Duration(c.PrevRestart)),
+		WithErrorPolicy(c.ErrorPolicy),
+		WithEvictionPolicy(c.Eviction),
+	}
+	if c.Shuffle {
+		opts = append(opts, WithSequencer(Shuffle()))
+	}
+	if c.ReplayGainLUFS != 0 {
+		opts = append(opts, WithReplayGain(c.ReplayGainLUFS))
+	}
+	if c.MaxPlaylistSize != 0 {
+		opts = append(opts, WithMaxPlaylistSize(c.MaxPlaylistSize))
+	}
+	if c.MaxTotalDuration != 0 {
+		opts = append(opts, WithMaxTotalDuration(time.Duration(c.MaxTotalDuration)))
+	}
+	if c.SongCooldown != 0 {
+		opts = append(opts, WithSongCooldown(time.Duration(c.SongCooldown)))
+	}
+	if c.CooldownOnAdd {
+		opts = append(opts, WithCooldownOnAdd())
+	}
+	if c.UserQueueLimit != 0 {
+		opts = append(opts, WithUserQueueLimit(c.UserQueueLimit))
+	}
+	if c.SkipVotes != 0 {
+		opts = append(opts, WithSkipVotes(c.SkipVotes))
+	}
+	if c.SkipFraction != 0 {
+		opts = append(opts, WithSkipFraction(c.SkipFraction))
+	}
+
+	return opts
+}
+
+// WithConfig - задаёт настройки c при создании плеера, как Apply.
+// Настройки, переданные после WithConfig, её переопределяют.
+func WithConfig(c Config) Option {
+	return func(p *playerImpl) error {
+		next, err := p.checkConfig(c)
+		if err != nil {
+			return err
+		}
+
+		p.volume, p.muted = c.Volume, c.Muted
+		p.adoptConfig(c, next)
+		return nil
+	}
+}
+
+// Apply - заменяет настройки воспроизведения на c целиком: поля с нулевым
+// значением возвращают настройку по умолчанию. Если какое-то поле неверно,
+// не меняется ничего. Секвенсор, отличный от Shuffle, сохраняется при
+// Shuffle: false. Ограничения размера действуют на следующие добавления,
+// уже добавленные песни не удаляются.
+func (p *playerImpl) Apply(ctx context.Context, c Config) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	next, err := p.checkConfig(c)
+	if err != nil {
+		return err
+	}
+
+	// громкость первой, чтобы при ошибке бэкенда не поменялось ничего
+	if err := p.setVolumeLocked(ctx, c.Volume, c.Muted); err != nil {
+		return err
+	}
+
+	_, shuffled := p.sequencer.(shuffle)
+	p.adoptConfig(c, next)
+
+	// песня после текущей выбирается заново новым секвенсором
+	if shuffled != c.Shuffle {
+		p.resetSequenceLocked()
+		p.sequenceLocked()
+	}
+	p.rescheduleLocked()
+	p.auditLocked(ctx, AuditEntry{Op: AuditConfig})
+	return nil
+}
+
+// checkConfig - проверяет c и возвращает пустой плеер с его настройками.
+// Вызывается под блокировкой или при создании плеера.
+func (p *playerImpl) checkConfig(c Config) (*playerImpl, error) {
+	if c.Volume < 0 || c.Volume > MaxVolume {
+		return nil, fmt.Errorf("volume %d out of range [0, %d]", c.Volume, MaxVolume)
+	}
+
+	// настройки проверяются на пустом плеере, чтобы не применить их частично
+	next := &playerImpl{}
+	for _, opt := range c.options() {
+		if err := opt(next); err != nil {
+			return nil, err
+		}
+	}
+
+	if next.crossfade > 0 && next.gap > 0 {
+		return nil, errors.New("crossfade and gap are mutually exclusive")
+	}
+	if p.transition != nil && (next.crossfade > 0 || next.gap > 0) {
+		return nil, errors.New("transition is incompatible with crossfade and gap")
+	}
+
+	return next, nil
+}
+
+// adoptConfig - переносит в настройки плеера проверенные checkConfig настройки c
+// из next, кроме громкости.
+// Вызывается под блокировкой или при создании плеера.
+func (p *playerImpl) adoptConfig(c Config, next *playerImpl) {
+	p.edge = next.edge
+	p.crossfade, p.gap = next.crossfade, next.gap
+	p.envelope = next.envelope
+	p.prevRestart = next.prevRestart
+	p.replayGain, p.targetLUFS = next.replayGain, next.targetLUFS
+	p.errorPolicy = next.errorPolicy
+	p.limits = next.limits
+	p.cooldown.window, p.cooldown.rejectAdds = next.cooldown.window, next.cooldown.rejectAdds
+	p.userQueueLimit = next.userQueueLimit
+	p.skipVotes, p.skipFraction = next.skipVotes, next.skipFraction
+
+	_, shuffled := p.sequencer.(shuffle)
+	switch {
+	case c.Shuffle:
+		p.sequencer = next.sequencer
+	case shuffled:
+		p.sequencer = nil
+	}
+}
+
+// CurrentConfig - текущие настройки воспроизведения, которые можно передать в Apply.
+func (p *playerImpl) CurrentConfig(_ context.Context) Config {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	_, shuffled := p.sequencer.(shuffle)
+	return Config{
+		Edge:             p.edge,
+		Shuffle:          shuffled,
+		Crossfade:        Duration(p.crossfade),
+		Gap:              Duration(p.gap),
+		FadeIn:           Duration(p.envelope.in),
+		FadeOut:          Duration(p.envelope.out),
+		PrevRestart:      Duration(p.prevRestart),
+		Volume:           p.volume,
+		Muted:            p.muted,
+		ReplayGainLUFS:   p.replayGainLUFSLocked(),
+		ErrorPolicy:      p.errorPolicy,
+		MaxPlaylistSize:  p.limits.songs,
+		MaxTotalDuration: Duration(p.limits.total),
+		Eviction:         p.limits.policy,
+		SongCooldown:     Duration(p.cooldown.window),
+		CooldownOnAdd:    p.cooldown.rejectAdds,
+		UserQueueLimit:   p.userQueueLimit,
+		SkipVotes:        p.skipVotes,
+		SkipFraction:     p.skipFraction,
+	}
+}
+
+// replayGainLUFSLocked - целевая громкость WithReplayGain, 0 - выравнивание выключено.
+// Вызывается под блокировкой.
+func (p *playerImpl) replayGainLUFSLocked() float64 {
+	if !p.replayGain {
+		return 0
+	}
+
+	return p.targetLUFS
+}
+
+// parseEnum - значение перечисления от first до last, String которого равен text.
+func parseEnum[T interface {
+	~int
+	fmt.Stringer
+}](text []byte, first, last T, name string) (T, error) {
+	for v := first; v <= last; v++ {
+		if v.String() == string(text) {
+			return v, nil
+		}
+	}
+
+	return first, fmt.Errorf("unknown %s %q", name, text)
+}
@@ -0,0 +1,131 @@
+package player
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Config(t *testing.T) {
+	ctx := context.Background()
+
+	full := Config{
+		Edge:             EdgeWrap,
+		Shuffle:          true,
+		Crossfade:        Duration(5 * time.Second),
+		FadeIn:           Duration(time.Second),
+		FadeOut:          Duration(2 * time.Second),
+		PrevRestart:      Duration(3 * time.Second),
+		Volume:           40,
+		Muted:            true,
+		ReplayGainLUFS:   -14,
+		ErrorPolicy:      ErrorSkip,
+		MaxPlaylistSize:  100,
+		MaxTotalDuration: Duration(10 * time.Hour),
+		Eviction:         EvictOldestUnplayed,
+		SongCooldown:     Duration(time.Hour),
+		CooldownOnAdd:    true,
+		UserQueueLimit:   3,
+		SkipFraction:     0.5,
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		pl, err := New()
+		td.CmpNoError(t, err)
+
+		c := pl.CurrentConfig(ctx)
+		td.Cmp(t, c, Config{Volume: MaxVolume})
+
+		data, err := json.Marshal(c)
+		td.CmpNoError(t, err)
+		td.CmpJSON(t, json.RawMessage(data), `{"edge": "restart", "volume": 100, "error_policy": "continue", "eviction": "reject"}`, nil)
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSongs("a", "b")...))
+
+		td.CmpNoError(t, pl.Apply(ctx, full))
+		td.Cmp(t, pl.CurrentConfig(ctx), full)
+		td.Cmp(t, pl.Status(ctx).Muted, true)
+		td.Cmp(t, pl.Volume(ctx), 40)
+		td.Cmp(t, pl.AuditLog(ctx, time.Time{})[0].Op, AuditConfig)
+
+		data, err := json.Marshal(full)
+		td.CmpNoError(t, err)
+		td.CmpJSON(t, json.RawMessage(data), `{
+			"edge": "wrap",
+			"shuffle": true,
+			"crossfade": "5s",
+			"fade_in": "1s",
+			"fade_out": "2s",
+			"prev_restart": "3s",
+			"volume": 40,
+			"muted": true,
+			"replay_gain_lufs": -14,
+			"error_policy": "skip",
+			"max_playlist_size": 100,
+			"max_total_duration": "10h0m0s",
+			"eviction": "oldest_unplayed",
+			"song_cooldown": "1h0m0s",
+			"cooldown_on_add": true,
+			"user_queue_limit": 3,
+			"skip_fraction": 0.5
+		}`, nil)
+
+		var decoded Config
+		td.CmpNoError(t, json.Unmarshal(data, &decoded))
+		td.Cmp(t, decoded, full, "JSON читается обратно")
+
+		// нулевые поля возвращают настройки по умолчанию
+		td.CmpNoError(t, pl.Apply(ctx, Config{Volume: MaxVolume}))
+		td.Cmp(t, pl.CurrentConfig(ctx), Config{Volume: MaxVolume})
+		td.CmpNil(t, pl.sequencer, "случайный порядок выключен")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		pl, err := New(WithSequencer(Sequential()))
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, pl.Apply(ctx, full))
+
+		for name, c := range map[string]Config{
+			"crossfade and gap are mutually exclusive": {Crossfade: Duration(time.Second), Gap: Duration(time.Second)},
+			"volume 101 out of range [0, 100]":         {Volume: 101},
+			"gap is negative":                          {Gap: Duration(-time.Second)},
+			"unknown edge behavior":                    {Edge: 7},
+			"skip fraction must be in (0, 1]":          {SkipFraction: 2},
+		} {
+			td.CmpString(t, pl.Apply(ctx, c), name)
+		}
+		td.Cmp(t, pl.CurrentConfig(ctx), full, "неверная настройка не меняет ничего")
+
+		var c Config
+		td.CmpString(t, json.Unmarshal([]byte(`{"edge": "loop"}`), &c), `unknown edge behavior "loop"`)
+		td.CmpString(t, json.Unmarshal([]byte(`{"eviction": "newest"}`), &c), `unknown eviction policy "newest"`)
+		td.CmpContains(t, json.Unmarshal([]byte(`{"gap": "soon"}`), &c), "invalid duration")
+
+		td.CmpNoError(t, pl.Close(ctx))
+		td.CmpTrue(t, errors.Is(pl.Apply(ctx, full), ErrClosed))
+	})
+
+	t.Run("custom sequencer", func(t *testing.T) {
+		pl, err := New(WithSequencer(BestMatch(func(_, _ Song) float64 { return 0 })))
+		td.CmpNoError(t, err)
+
+		td.CmpNoError(t, pl.Apply(ctx, Config{Volume: 50}))
+		td.CmpNotNil(t, pl.sequencer, "секвенсор, отличный от Shuffle, сохраняется")
+		td.CmpFalse(t, pl.CurrentConfig(ctx).Shuffle)
+	})
+
+	t.Run("with config", func(t *testing.T) {
+		pl, err := New(WithConfig(full), WithGap(0))
+		td.CmpNoError(t, err)
+		td.Cmp(t, pl.CurrentConfig(ctx), full)
+
+		_, err = New(WithConfig(Config{Volume: -1}))
+		td.CmpString(t, err, "volume -1 out of range [0, 100]")
+	})
+}
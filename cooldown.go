@@ -0,0 +1,113 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrRecentlyPlayed - песня доиграла меньше, чем WithSongCooldown назад.
+var ErrRecentlyPlayed = errors.New("song was played recently")
+
+// cooldown - защита от повторов песен.
+type cooldown struct {
+	// window - сколько песня не повторяется после окончания, 0 - защита выключена
+	window time.Duration
+	// rejectAdds - AddSong отклоняет песни, которые ещё не остыли
+	rejectAdds bool
+	// finished - когда песни закончились, по dedupKey
+	finished map[string]time.Time
+}
+
+// WithSongCooldown - песня, которая доиграла или была пропущена меньше d назад,
+// пропускается при автоматическом переходе к следующей песне.
+// Next, Prev и PlayByID играют такие песни как обычно.
+func WithSongCooldown(d time.Duration) Option {
+	return func(p *playerImpl) error {
+		if d <= 0 {
+			return errors.New("cooldown must be positive")
+		}
+
+		p.cooldown.window = d
+		return nil
+	}
+}
+
+// WithCooldownOnAdd - AddSong и AddSongAs отклоняют песни, которые ещё не вышли
+// из окна WithSongCooldown, ошибкой ErrRecentlyPlayed.
+func WithCooldownOnAdd() Option {
+	return func(p *playerImpl) error {
+		p.cooldown.rejectAdds = true
+		return nil
+	}
+}
+
+// cooldownFinishLocked - запоминает, когда закончилась песня.
+// Вызывается под блокировкой.
+func (p *playerImpl) cooldownFinishLocked(song Song, now time.Time) {
+	if p.cooldown.window == 0 {
+		return
+	}
+
+	if p.cooldown.finished == nil {
+		p.cooldown.finished = make(map[string]time.Time)
+	}
+
+	// остывшие песни больше не нужны
+	for key, at := range p.cooldown.finished {
+		if now.Sub(at) >= p.cooldown.window {
+			delete(p.cooldown.finished, key)
+		}
+	}
+
+	p.cooldown.finished[dedupKey(song)] = now
+}
+
+// coolingLocked - сколько ещё песня не может повториться, 0 - может.
+// Вызывается под блокировкой.
+func (p *playerImpl) coolingLocked(song Song, now time.Time) time.Duration {
+	at, ok := p.cooldown.finished[dedupKey(song)]
+	if !ok {
+		return 0
+	}
+
+	return max(p.cooldown.window-now.Sub(at), 0)
+}
+
+// cooledDownLocked - первая песня, начиная с node, которая не на cooldown, nil если таких нет.
+// Вызывается под блокировкой.
+func (p *playerImpl) cooledDownLocked(ctx context.Context, node *playerNode) *playerNode {
+	now := p.now()
+	for ; node != nil; node = node.next() {
+		if p.coolingLocked(*node.song, now) == 0 {
+			return node
+		}
+
+		p.logger.DebugContext(ctx, "song skipped by cooldown", songAttr(*node.song))
+	}
+
+	return nil
+}
+
+// checkCooldownLocked - ошибка AddSong для песни, которая ещё не остыла.
+// Вызывается под блокировкой.
+func (p *playerImpl) checkCooldownLocked(song Song) error {
+	if !p.cooldown.rejectAdds {
+		return nil
+	}
+
+	if left := p.coolingLocked(song, p.now()); left > 0 {
+		return fmt.Errorf("%w: %q can be added in %v", ErrRecentlyPlayed, song.Name, left.Round(time.Second))
+	}
+
+	return nil
+}
+
+// CooldownRemaining - сколько ещё песня не будет повторяться автоматически, 0 - уже может.
+func (p *playerImpl) CooldownRemaining(_ context.Context, song Song) time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.coolingLocked(song, p.now())
+}
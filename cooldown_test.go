@@ -0,0 +1,89 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestSongCooldown(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("options", func(t *testing.T) {
+		_, err := New(WithSongCooldown(0))
+		td.CmpString(t, err, "cooldown must be positive")
+	})
+
+	t.Run("automatic advancement", func(t *testing.T) {
+		out := &recordingOutput{}
+		a := Song{Name: "a", Duration: 30 * time.Millisecond}
+		pl, err := New(WithValidator(ValidationPolicy{}), WithOutput(out), WithSongCooldown(time.Hour), WithSongs(
+			a,
+			Song{Name: "b", Duration: 30 * time.Millisecond},
+			a,
+			Song{Name: "c", Duration: 30 * time.Second},
+		))
+		td.CmpNoError(t, err)
+
+		td.Cmp(t, pl.CooldownRemaining(ctx, a), time.Duration(0))
+
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpTrue(t, eventually(func() bool { return pl.Status(ctx).Song.Name == "c" }))
+		td.CmpNoError(t, pl.Pause(ctx))
+
+		td.Cmp(t, out.Calls(), []string{"start a", "stop a", "start b", "stop b", "start c", "stop c"}, "повтор a пропущен")
+		td.Cmp(t, pl.CooldownRemaining(ctx, a), td.Between(59*time.Minute, time.Hour))
+
+		// вручную песню на cooldown можно включить
+		td.CmpNoError(t, pl.Prev(ctx))
+		td.Cmp(t, pl.Status(ctx).Song.Name, "a")
+		td.Cmp(t, pl.Status(ctx).SongID, pl.Queue(ctx)[2].ID)
+	})
+
+	t.Run("playlist end", func(t *testing.T) {
+		a := Song{Name: "a", Duration: 30 * time.Millisecond}
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithSongCooldown(time.Hour), WithSongs(a, a))
+
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpTrue(t, eventually(func() bool { return !pl.Status(ctx).Playing }), "остальные песни остывают")
+		td.Cmp(t, pl.Status(ctx).SongID, pl.Queue(ctx)[0].ID)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithSongCooldown(time.Hour))
+		a := Song{Name: "a", Duration: time.Minute}
+
+		pl.mu.Lock()
+		pl.cooldownFinishLocked(a, time.Now().Add(-2*time.Hour))
+		pl.cooldownFinishLocked(Song{Name: "b", Duration: time.Minute}, time.Now())
+		td.Cmp(t, pl.cooldown.finished, td.Len(1), "остывшие песни забыты")
+		pl.mu.Unlock()
+
+		td.Cmp(t, pl.CooldownRemaining(ctx, a), time.Duration(0))
+	})
+
+	t.Run("add", func(t *testing.T) {
+		a := Song{Name: "a", Duration: 30 * time.Millisecond}
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithSongCooldown(time.Hour), WithSongs(a, Song{Name: "b", Duration: time.Minute}))
+
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpTrue(t, eventually(func() bool { return pl.Status(ctx).Song.Name == "b" }))
+
+		_, err := pl.AddSong(ctx, a)
+		td.CmpNoError(t, err, "без WithCooldownOnAdd добавление разрешено")
+
+		td.CmpNoError(t, WithCooldownOnAdd()(pl))
+		_, err = pl.AddSong(ctx, a)
+		td.CmpTrue(t, errors.Is(err, ErrRecentlyPlayed))
+		td.CmpString(t, err, `song was played recently: "a" can be added in 1h0m0s`)
+
+		_, err = pl.AddSongAs(ctx, "вася", a)
+		td.CmpTrue(t, errors.Is(err, ErrRecentlyPlayed))
+
+		_, err = pl.AddSong(ctx, Song{Name: "a", Duration: time.Minute})
+		td.CmpNoError(t, err, "другая песня с тем же названием")
+	})
+}
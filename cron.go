@@ -0,0 +1,155 @@
+package player
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule - расписание в формате cron из пяти полей:
+// минуты, часы, дни месяца, месяцы и дни недели.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domAny, dowAny - поле дня задано как *, тогда день выбирается только по другому полю
+	domAny, dowAny bool
+}
+
+// cronField - допустимый диапазон поля cron.
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = [5]cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// cronHorizon - насколько далеко вперёд ищется следующее срабатывание.
+const cronHorizon = 5 * 366 * 24 * time.Hour
+
+// parseCron - разбирает выражение вида "30 7 * * 1-5".
+// Поле может быть *, числом, диапазоном a-b, списком через запятую
+// и иметь шаг /n. Воскресенье - 0 или 7.
+func parseCron(expr string) (*cronSchedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("cron expression %q: expected %d fields", expr, len(cronFields))
+	}
+
+	var bits [5]uint64
+	for i, part := range parts {
+		b, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("cron expression %q: %v", expr, err)
+		}
+		bits[i] = b
+	}
+
+	c := &cronSchedule{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: parts[2] == "*",
+		dowAny: parts[4] == "*",
+	}
+	// 7 - тоже воскресенье
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+
+	return c, nil
+}
+
+// parseCronField - разбирает поле cron в битовую маску допустимых значений.
+func parseCronField(s string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(s, ",") {
+		rng, step := item, 1
+		if i := strings.IndexByte(item, '/'); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%s: invalid step in %q", f.name, item)
+			}
+			rng, step = item[:i], n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var errA, errB error
+			lo, errA = strconv.Atoi(a)
+			hi, errB = strconv.Atoi(b)
+			if errA != nil || errB != nil || lo > hi {
+				return 0, fmt.Errorf("%s: invalid range %q", f.name, rng)
+			}
+		default:
+			n, err := strconv.Atoi(rng)
+			if err != nil {
+				return 0, fmt.Errorf("%s: invalid value %q", f.name, rng)
+			}
+			lo, hi = n, n
+			if step > 1 {
+				hi = f.max
+			}
+		}
+
+		if lo < f.min || hi > f.max {
+			return 0, fmt.Errorf("%s: %q out of range %d-%d", f.name, item, f.min, f.max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+
+	return bits, nil
+}
+
+// next - возвращает ближайшее время срабатывания строго после after
+// или false, если его нет в ближайшие годы, например для 30 февраля.
+func (c *cronSchedule) next(after time.Time) (time.Time, bool) {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Add(cronHorizon)
+
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// dayMatches - проверяет день по дню месяца и дню недели.
+// Если заданы оба поля, достаточно совпадения одного из них, как в cron.
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<int(t.Weekday())) != 0
+
+	switch {
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
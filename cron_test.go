@@ -0,0 +1,53 @@
+package player
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestParseCron(t *testing.T) {
+	for _, expr := range []string{
+		"* * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+	} {
+		_, err := parseCron(expr)
+		td.CmpError(t, err, expr)
+	}
+
+	at := func(s string) time.Time {
+		tm, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+		td.Require(t).CmpNoError(err)
+		return tm
+	}
+
+	for _, tc := range []struct {
+		expr, after, next string
+	}{
+		{"* * * * *", "2024-03-01 10:00", "2024-03-01 10:01"},
+		{"30 7 * * *", "2024-03-01 10:00", "2024-03-02 07:30"},
+		{"*/15 9-10 * * *", "2024-03-01 09:50", "2024-03-01 10:00"},
+		{"0 9 * * 1-5", "2024-03-01 09:00", "2024-03-04 09:00"}, // пятница -> понедельник
+		{"0 0 * * 7", "2024-03-01 00:00", "2024-03-03 00:00"},   // 7 - воскресенье
+		{"0 12 1,15 * *", "2024-03-02 00:00", "2024-03-15 12:00"},
+		{"0 0 29 2 *", "2024-03-01 00:00", "2028-02-29 00:00"},
+		{"0 0 13 * 5", "2024-03-02 00:00", "2024-03-08 00:00"}, // день месяца или день недели
+	} {
+		c, err := parseCron(tc.expr)
+		td.Require(t).CmpNoError(err, tc.expr)
+
+		next, ok := c.next(at(tc.after))
+		td.CmpTrue(t, ok, tc.expr)
+		td.Cmp(t, next, at(tc.next), tc.expr)
+	}
+
+	c, _ := parseCron("0 0 30 2 *")
+	_, ok := c.next(time.Now())
+	td.CmpFalse(t, ok, "30 февраля не бывает")
+}
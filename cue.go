@@ -0,0 +1,317 @@
+package player
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cueFrame - кадр CUE, 1/75 секунды.
+const cueFrame = time.Second / 75
+
+// CueTrack - дорожка CUE внутри песни: для альбома одним файлом
+// песня - весь файл, а дорожки - песни альбома.
+type CueTrack struct {
+	// Number - номер дорожки из CUE
+	Number int `json:"number"`
+	// Title - название дорожки
+	Title string `json:"title,omitempty"`
+	// Performer - исполнитель дорожки, пусто если совпадает с исполнителем песни
+	Performer string `json:"performer,omitempty"`
+	// Start - начало дорожки от начала песни
+	Start time.Duration `json:"start"`
+}
+
+// nextCueLocked - переносит позицию на начало следующей дорожки CUE текущей песни.
+// false - дорожек дальше нет, позиция не менялась.
+// Вызывается под блокировкой.
+func (p *playerImpl) nextCueLocked(ctx context.Context) bool {
+	song := *p.current.song
+	i := cueAt(song, p.elapsedLocked())
+	if len(song.Cues) == 0 || i+1 >= len(song.Cues) {
+		return false
+	}
+
+	p.seekCueLocked(ctx, i+1)
+	return true
+}
+
+// prevCueLocked - переносит позицию на начало предыдущей дорожки CUE текущей песни,
+// а если задан WithPrevRestart и текущая дорожка играет дольше порога - на начало текущей.
+// false - текущая дорожка первая, позиция не менялась.
+// Вызывается под блокировкой.
+func (p *playerImpl) prevCueLocked(ctx context.Context) bool {
+	song := *p.current.song
+	pos := p.elapsedLocked()
+	i := cueAt(song, pos)
+	if i < 0 {
+		return false
+	}
+
+	switch {
+	case p.prevRestart > 0 && pos-song.Cues[i].Start > p.prevRestart:
+		p.seekCueLocked(ctx, i)
+	case i > 0:
+		p.seekCueLocked(ctx, i-1)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// seekCueLocked - переносит позицию на начало дорожки index.
+// Вызывается под блокировкой.
+func (p *playerImpl) seekCueLocked(ctx context.Context, index int) {
+	cue := p.current.song.Cues[index]
+	p.section = nil
+	p.seekLocked(ctx, cue.Start)
+	p.rescheduleLocked()
+
+	p.logger.DebugContext(ctx, "cue track selected", songAttr(*p.current.song),
+		slog.Int("track", cue.Number), slog.String("title", cue.Title))
+}
+
+// cueAt - возвращает индекс дорожки CUE песни на позиции pos или -1,
+// если дорожек нет или позиция раньше первой дорожки.
+func cueAt(song Song, pos time.Duration) int {
+	i := -1
+	for j, c := range song.Cues {
+		if c.Start > pos {
+			break
+		}
+		i = j
+	}
+
+	return i
+}
+
+// validateCues - проверяет, что дорожки CUE идут по порядку в пределах песни.
+func validateCues(song Song) error {
+	for i, c := range song.Cues {
+		if c.Start < 0 || !song.IsStream() && c.Start >= song.Duration {
+			return fmt.Errorf("%w: cue track %d starts outside the song", ErrInvalidSong, i)
+		}
+		if i > 0 && c.Start <= song.Cues[i-1].Start {
+			return fmt.Errorf("%w: cue track %d starts before the previous one", ErrInvalidSong, i)
+		}
+	}
+
+	return nil
+}
+
+// writeCue - записывает CUE: каждая песня - FILE, её дорожки - TRACK.
+// Песня без дорожек записывается одной дорожкой с её названием.
+func writeCue(w *bufio.Writer, songs []Song) {
+	number := 0
+	for _, s := range songs {
+		fmt.Fprintf(w, "FILE %s WAVE\n", cueQuote(location(s)))
+
+		cues := s.Cues
+		if len(cues) == 0 {
+			cues = []CueTrack{{Title: s.Name}}
+		}
+		for _, c := range cues {
+			number++
+			fmt.Fprintf(w, "  TRACK %02d AUDIO\n", number)
+			if c.Title != "" {
+				fmt.Fprintf(w, "    TITLE %s\n", cueQuote(c.Title))
+			}
+			if performer := firstNonEmpty(c.Performer, s.Artist); performer != "" {
+				fmt.Fprintf(w, "    PERFORMER %s\n", cueQuote(performer))
+			}
+			fmt.Fprintf(w, "    INDEX 01 %s\n", cueTime(c.Start))
+		}
+	}
+}
+
+// readCue - читает CUE: каждый FILE становится песней, его дорожки - CueTrack.
+// Длительность файла в CUE не записана, поэтому песня считается потоком,
+// пока ей не задана Duration. Файл с одной дорожкой с начала становится
+// обычной песней с названием и исполнителем дорожки.
+func readCue(r io.Reader) ([]Song, error) {
+	var (
+		songs     []Song
+		album     Song
+		track     *CueTrack
+		performer string
+	)
+
+	// flush - дописывает прочитанную дорожку к песне
+	flush := func() {
+		if track == nil {
+			return
+		}
+		if performer != album.Artist {
+			track.Performer = performer
+		}
+		song := &songs[len(songs)-1]
+		song.Cues = append(song.Cues, *track)
+		track = nil
+	}
+
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		fields, err := cueFields(sc.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		cmd, args := strings.ToUpper(fields[0]), fields[1:]
+		switch cmd {
+		case "TITLE", "PERFORMER":
+			if len(args) == 0 {
+				return nil, fmt.Errorf("line %d: %s without value", line, cmd)
+			}
+			switch {
+			case track != nil && cmd == "TITLE":
+				track.Title = args[0]
+			case track != nil:
+				performer = args[0]
+			case cmd == "TITLE":
+				album.Name, album.Album = args[0], args[0]
+			default:
+				album.Artist = args[0]
+			}
+		case "FILE":
+			if len(args) == 0 {
+				return nil, fmt.Errorf("line %d: FILE without name", line)
+			}
+			flush()
+			s := album
+			if s.Name == "" {
+				s.Name = nameFromLocation(args[0])
+			}
+			s.URL = args[0]
+			songs = append(songs, s)
+		case "TRACK":
+			if len(songs) == 0 {
+				return nil, fmt.Errorf("line %d: TRACK before FILE", line)
+			}
+			if len(args) == 0 {
+				return nil, fmt.Errorf("line %d: TRACK without number", line)
+			}
+			n, err := strconv.Atoi(args[0])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: parse track number: %v", line, err)
+			}
+			flush()
+			track, performer = &CueTrack{Number: n, Start: -1}, album.Artist
+		case "INDEX":
+			if track == nil {
+				return nil, fmt.Errorf("line %d: INDEX before TRACK", line)
+			}
+			if len(args) < 2 {
+				return nil, fmt.Errorf("line %d: malformed INDEX", line)
+			}
+			start, err := parseCueTime(args[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: parse index: %v", line, err)
+			}
+			// INDEX 01 - начало дорожки, INDEX 00 - начало паузы перед ней
+			if args[0] == "01" || track.Start < 0 {
+				track.Start = start
+			}
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	for i := range songs {
+		s := &songs[i]
+		for j, c := range s.Cues {
+			if c.Start < 0 {
+				return nil, fmt.Errorf("track %d: no INDEX", c.Number)
+			}
+			if j > 0 && c.Start <= s.Cues[j-1].Start {
+				return nil, fmt.Errorf("track %d: starts before the previous track", c.Number)
+			}
+		}
+
+		if len(s.Cues) == 1 && s.Cues[0].Start == 0 {
+			c := s.Cues[0]
+			s.Name, s.Artist, s.Cues = firstNonEmpty(c.Title, s.Name), firstNonEmpty(c.Performer, s.Artist), nil
+		}
+	}
+
+	return songs, nil
+}
+
+// cueFields - разбивает строку CUE на слова, значения в кавычках - одно слово.
+func cueFields(line string) ([]string, error) {
+	var fields []string
+	for line = strings.TrimSpace(line); line != ""; line = strings.TrimSpace(line) {
+		if line[0] != '"' {
+			word, rest, _ := strings.Cut(line, " ")
+			fields = append(fields, word)
+			line = rest
+			continue
+		}
+
+		end := strings.IndexByte(line[1:], '"')
+		if end < 0 {
+			return nil, errors.New("unterminated quote")
+		}
+		fields = append(fields, line[1:end+1])
+		line = line[end+2:]
+	}
+
+	return fields, nil
+}
+
+// parseCueTime - разбирает время CUE "мм:сс:кк", где кк - кадры по 1/75 секунды.
+func parseCueTime(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("malformed time %q", s)
+	}
+
+	var n [3]int
+	for i, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("malformed time %q", s)
+		}
+		n[i] = v
+	}
+	if n[1] >= 60 || n[2] >= 75 {
+		return 0, fmt.Errorf("malformed time %q", s)
+	}
+
+	return time.Duration(n[0])*time.Minute + time.Duration(n[1])*time.Second + time.Duration(n[2])*cueFrame, nil
+}
+
+// cueTime - время в формате CUE, с точностью до кадра.
+func cueTime(d time.Duration) string {
+	frames := int64(d / cueFrame)
+	return fmt.Sprintf("%02d:%02d:%02d", frames/75/60, frames/75%60, frames%75)
+}
+
+// cueQuote - значение CUE в кавычках. Кавычки внутри значения в CUE не экранируются
+// и заменяются апострофами.
+func cueQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "'") + `"`
+}
+
+// firstNonEmpty - первое непустое значение.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,172 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+const albumCue = `REM GENRE Rock
+PERFORMER "Band"
+TITLE "Album"
+FILE "album.flac" WAVE
+  TRACK 01 AUDIO
+    TITLE "Intro"
+    INDEX 01 00:00:00
+  TRACK 02 AUDIO
+    TITLE "Song"
+    PERFORMER "Band feat. Guest"
+    INDEX 00 03:58:00
+    INDEX 01 04:00:37
+  TRACK 03 AUDIO
+    TITLE "Outro"
+    INDEX 01 09:30:00
+FILE "bonus.wav" WAVE
+  TRACK 04 AUDIO
+    TITLE "Bonus"
+    INDEX 01 00:00:00
+`
+
+func TestReadCue(t *testing.T) {
+	songs, err := ReadPlaylist(strings.NewReader(albumCue), FormatCUE)
+	td.CmpNoError(t, err)
+	td.Cmp(t, songs, []Song{
+		{
+			Name:   "Album",
+			Artist: "Band",
+			Album:  "Album",
+			URL:    "album.flac",
+			Cues: []CueTrack{
+				{Number: 1, Title: "Intro"},
+				{Number: 2, Title: "Song", Performer: "Band feat. Guest", Start: 4*time.Minute + 37*cueFrame},
+				{Number: 3, Title: "Outro", Start: 9*time.Minute + 30*time.Second},
+			},
+		},
+		{Name: "Bonus", Artist: "Band", Album: "Album", URL: "bonus.wav"},
+	}, "файл с одной дорожкой - обычная песня")
+
+	for name, cue := range map[string]string{
+		"track before file":   "TRACK 01 AUDIO\n",
+		"index before track":  "FILE \"a.wav\" WAVE\nINDEX 01 00:00:00\n",
+		"malformed time":      "FILE \"a.wav\" WAVE\nTRACK 01 AUDIO\nINDEX 01 00:61:00\n",
+		"no index":            "FILE \"a.wav\" WAVE\nTRACK 01 AUDIO\n",
+		"unterminated quote":  "TITLE \"Album\n",
+		"tracks out of order": "FILE \"a.wav\" WAVE\nTRACK 01 AUDIO\nINDEX 01 01:00:00\nTRACK 02 AUDIO\nINDEX 01 00:30:00\n",
+	} {
+		_, err := ReadPlaylist(strings.NewReader(cue), FormatCUE)
+		td.CmpError(t, err, name)
+	}
+}
+
+func TestWriteCue(t *testing.T) {
+	songs, err := ReadPlaylist(strings.NewReader(albumCue), FormatCUE)
+	td.CmpNoError(t, err)
+
+	var buf bytes.Buffer
+	td.CmpNoError(t, WritePlaylist(&buf, FormatCUE, songs))
+	td.Cmp(t, buf.String(), `FILE "album.flac" WAVE
+  TRACK 01 AUDIO
+    TITLE "Intro"
+    PERFORMER "Band"
+    INDEX 01 00:00:00
+  TRACK 02 AUDIO
+    TITLE "Song"
+    PERFORMER "Band feat. Guest"
+    INDEX 01 04:00:37
+  TRACK 03 AUDIO
+    TITLE "Outro"
+    PERFORMER "Band"
+    INDEX 01 09:30:00
+FILE "bonus.wav" WAVE
+  TRACK 04 AUDIO
+    TITLE "Bonus"
+    PERFORMER "Band"
+    INDEX 01 00:00:00
+`)
+
+	again, err := ReadPlaylist(&buf, FormatCUE)
+	td.CmpNoError(t, err)
+	td.Cmp(t, again, td.Len(2))
+	td.Cmp(t, again[0].Cues, td.Len(3))
+	for i, c := range again[0].Cues {
+		td.Cmp(t, c.Title, songs[0].Cues[i].Title, "дорожки сохраняются")
+		td.Cmp(t, c.Start, songs[0].Cues[i].Start)
+	}
+}
+
+func TestPlayerImpl_Cues(t *testing.T) {
+	ctx := context.Background()
+
+	album := Song{
+		Name:     "Album",
+		Duration: 10 * time.Minute,
+		Cues: []CueTrack{
+			{Number: 1, Title: "Intro"},
+			{Number: 2, Title: "Song", Start: 4 * time.Minute},
+			{Number: 3, Title: "Outro", Start: 9 * time.Minute},
+		},
+	}
+
+	newPlayer := func(t *testing.T, opts ...Option) *playerImpl {
+		return newFakePlayer(t, append([]Option{WithSongs(album, minuteSong("next"))}, opts...)...)
+	}
+
+	t.Run("next and prev", func(t *testing.T) {
+		pl := newPlayer(t)
+		td.CmpNoError(t, pl.Play(ctx))
+		_, err := pl.SimulatePlayback(ctx, time.Minute)
+		td.CmpNoError(t, err)
+
+		st := pl.Status(ctx)
+		td.Cmp(t, st.Cue, &album.Cues[0])
+		td.Cmp(t, st.CuePosition, time.Minute)
+
+		td.CmpNoError(t, pl.Next(ctx))
+		st = pl.Status(ctx)
+		td.Cmp(t, st.Song.Name, "Album", "та же песня")
+		td.Cmp(t, st.Position, 4*time.Minute)
+		td.Cmp(t, st.Cue.Title, "Song")
+		td.Cmp(t, st.CuePosition, time.Duration(0))
+
+		td.CmpNoError(t, pl.Next(ctx))
+		td.Cmp(t, pl.Status(ctx).Cue.Title, "Outro")
+		td.CmpNoError(t, pl.Next(ctx))
+		td.Cmp(t, pl.Status(ctx).Song.Name, "next", "после последней дорожки - следующая песня")
+
+		td.CmpNoError(t, pl.Prev(ctx))
+		td.Cmp(t, pl.Status(ctx).Song.Name, "Album")
+		_, err = pl.SimulatePlayback(ctx, 5*time.Minute)
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, pl.Prev(ctx))
+		td.Cmp(t, pl.Status(ctx).Cue.Title, "Intro")
+		td.CmpTrue(t, pl.Status(ctx).Playing)
+	})
+
+	t.Run("prev restart", func(t *testing.T) {
+		pl := newPlayer(t, WithPrevRestart(3*time.Second))
+		td.CmpNoError(t, pl.Play(ctx))
+		_, err := pl.SimulatePlayback(ctx, 5*time.Minute)
+		td.CmpNoError(t, err)
+
+		td.CmpNoError(t, pl.Prev(ctx))
+		td.Cmp(t, pl.Status(ctx).Position, 4*time.Minute, "начало текущей дорожки")
+		td.CmpNoError(t, pl.Prev(ctx))
+		td.Cmp(t, pl.Status(ctx).Position, time.Duration(0), "предыдущая дорожка")
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		bad := album
+		bad.Cues = []CueTrack{{Number: 1, Start: 11 * time.Minute}}
+		errs := newPlayer(t).AddSongs(ctx, bad)
+		td.Cmp(t, errs, td.Len(1))
+		td.CmpContains(t, errs[0], "cue track 0 starts outside the song")
+
+		bad.Cues = []CueTrack{{Number: 1, Start: time.Minute}, {Number: 2}}
+		td.CmpTrue(t, errors.Is(DefaultValidationPolicy.Validate(bad), ErrInvalidSong))
+	})
+}
@@ -0,0 +1,97 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// ClearPlaylist - останавливает воспроизведение и удаляет все песни активного плейлиста.
+func (p *playerImpl) ClearPlaylist(ctx context.Context) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	p.auditLocked(ctx, AuditEntry{Op: AuditClear, Song: p.currentSongLocked(), Detail: p.active})
+	if p.current != nil {
+		p.skipLocked()
+		p.haltLocked(ctx)
+	}
+
+	if p.songs.Len() > 0 {
+		p.recordEditLocked(p.reorderEdit("clear", p.nodes(), nil, p.current))
+	}
+	p.playlist = playlist{}
+	p.interruption = nil
+	p.section = nil
+	p.prepared = nil
+	p.logger.InfoContext(ctx, "playlist cleared", slog.String("playlist", p.active))
+	return nil
+}
+
+// Deduplicate - удаляет из активного плейлиста повторы песен с тем же названием
+// и длительностью, оставляя первое вхождение, а если повторяется текущая песня - её.
+// Воспроизведение не прерывается. Возвращает количество удалённых песен.
+func (p *playerImpl) Deduplicate(ctx context.Context) (int, error) {
+	if err := p.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return 0, ErrClosed
+	}
+
+	// текущая песня во время вставки - прерванная
+	keep := p.current
+	if in := p.interruption; in != nil {
+		keep = in.node
+	}
+
+	seen := make(map[string]*playerNode)
+	if keep != nil {
+		seen[dedupKey(*keep.song)] = keep
+	}
+
+	before := p.nodes()
+	removed := 0
+	for n := p.first(); n != nil; {
+		next := n.next()
+
+		key := dedupKey(*n.song)
+		if kept, ok := seen[key]; ok && kept != n {
+			if p.prepared == n {
+				p.prepared = nil
+			}
+			if p.section != nil && p.section.node == n {
+				p.section = nil
+			}
+
+			p.unlink(n)
+			removed++
+		} else {
+			seen[key] = n
+		}
+
+		n = next
+	}
+
+	if removed > 0 {
+		p.recordEditLocked(p.reorderEdit("dedup", before, p.nodes(), p.current))
+		p.rescheduleLocked()
+		p.logger.InfoContext(ctx, "playlist deduplicated", slog.String("playlist", p.active), slog.Int("removed", removed))
+	}
+
+	return removed, nil
+}
+
+// dedupKey - ключ, по которому песни считаются повторами.
+// Повторное добавление одного трека библиотеки тоже даёт совпадающий ключ.
+func dedupKey(song Song) string {
+	return fmt.Sprintf("%s\x00%d", song.Name, song.Duration)
+}
@@ -0,0 +1,56 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_ClearPlaylist(t *testing.T) {
+	ctx := context.Background()
+	out := &recordingOutput{}
+	pl, _ := New(WithOutput(out), WithSongs(
+		Song{Name: "a", Duration: 30 * time.Second},
+		Song{Name: "b", Duration: 30 * time.Second},
+	))
+
+	_ = pl.Play(ctx)
+	td.CmpNoError(t, pl.ClearPlaylist(ctx))
+
+	td.CmpFalse(t, pl.isPlaying, "воспроизведение остановлено")
+	td.CmpNil(t, pl.first())
+	td.CmpNil(t, pl.current)
+	td.Cmp(t, out.Calls(), []string{"start a", "stop a"})
+	td.CmpNoError(t, pl.Play(ctx), "пустой плейлист нечего играть")
+
+	_, _ = pl.AddSong(ctx, Song{Name: "c", Duration: 30 * time.Second})
+	td.Cmp(t, names(pl), []string{"c"}, "после очистки можно добавлять песни")
+}
+
+func TestPlayerImpl_Deduplicate(t *testing.T) {
+	ctx := context.Background()
+	a := Song{Name: "a", Duration: 30 * time.Second}
+	b := Song{Name: "b", Duration: 30 * time.Second}
+
+	pl, _ := NewPlayer(a, b, a, Song{Name: "a", Duration: time.Minute}, b, a)
+
+	// текущей выбрана третья песня - повтор первой
+	pl.current = pl.first().next().next()
+	_ = pl.Play(ctx)
+	playing := pl.current
+
+	removed, err := pl.Deduplicate(ctx)
+	td.CmpNoError(t, err)
+	td.Cmp(t, removed, 3)
+	td.Cmp(t, names(pl), []string{"b", "a", "a"})
+	td.Cmp(t, pl.first().next(), playing, "текущая песня сохранена вместо первого вхождения")
+	td.Cmp(t, pl.last().song.Duration, time.Minute, "песни разной длительности не повторы")
+	td.CmpTrue(t, pl.isPlaying, "воспроизведение не прервано")
+
+	removed, err = pl.Deduplicate(ctx)
+	td.CmpNoError(t, err)
+	td.Cmp(t, removed, 0, "повторов больше нет")
+	_ = pl.Pause(ctx)
+}
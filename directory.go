@@ -0,0 +1,94 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"strings"
+)
+
+// audioExtensions - расширения файлов, которые LoadDirectory считает аудио.
+var audioExtensions = map[string]bool{
+	".mp3":  true,
+	".flac": true,
+	".ogg":  true,
+	".opus": true,
+	".m4a":  true,
+	".wav":  true,
+}
+
+// TagReader - читает метаданные аудиофайла.
+type TagReader interface {
+	// ReadTags - возвращает песню с длительностью и тегами файла path.
+	// Пустое название заменяется именем файла.
+	ReadTags(ctx context.Context, path string) (Song, error)
+}
+
+// WithTagReader - задаёт чтение тегов для LoadDirectory.
+func WithTagReader(r TagReader) Option {
+	return func(p *playerImpl) error {
+		if r == nil {
+			return errors.New("tag reader is nil")
+		}
+
+		p.tagReader = r
+		return nil
+	}
+}
+
+// LoadDirectory - обходит каталог path с подкаталогами и добавляет аудиофайлы
+// в конец активного плейлиста в порядке их путей.
+// Файлы, теги которых не удалось прочитать, пропускаются, а их ошибки
+// возвращаются вместе с количеством добавленных песен.
+func (p *playerImpl) LoadDirectory(ctx context.Context, path string) (int, error) {
+	if p.tagReader == nil {
+		return 0, errors.New("tag reader is not set")
+	}
+
+	var (
+		songs []Song
+		files []string
+		errs  []error
+	)
+	err := filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || !audioExtensions[strings.ToLower(filepath.Ext(file))] {
+			return nil
+		}
+
+		song, err := p.tagReader.ReadTags(ctx, file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			return nil
+		}
+		if song.Name == "" {
+			song.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		}
+
+		songs = append(songs, song)
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("walk %s: %w", path, err)
+	}
+
+	added := len(songs)
+	for i, err := range p.AddSongs(ctx, songs...) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", files[i], err))
+			added--
+		}
+	}
+
+	p.logger.InfoContext(ctx, "directory loaded", slog.String("path", path), slog.Int("added", added), slog.Int("failed", len(errs)))
+	return added, errors.Join(errs...)
+}
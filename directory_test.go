@@ -0,0 +1,60 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// mapTagReader - теги по имени файла.
+type mapTagReader map[string]Song
+
+func (r mapTagReader) ReadTags(_ context.Context, path string) (Song, error) {
+	song, ok := r[filepath.Base(path)]
+	if !ok {
+		return Song{}, errors.New("no tags")
+	}
+
+	return song, nil
+}
+
+func TestPlayerImpl_LoadDirectory(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	for _, name := range []string{"b.mp3", "a.FLAC", "cover.jpg", "sub/c.ogg", "broken.mp3", "short.wav"} {
+		path := filepath.Join(dir, name)
+		td.CmpNoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		td.CmpNoError(t, os.WriteFile(path, nil, 0o644))
+	}
+
+	reader := mapTagReader{
+		"a.FLAC":    {Name: "Лирика", Artist: "Сектор Газа", Album: "Наркологический университет миллионов", Duration: 3 * time.Minute},
+		"b.mp3":     {Artist: "Пушной", Duration: 2 * time.Minute},
+		"c.ogg":     {Name: "3 сентября", Duration: 4 * time.Minute},
+		"short.wav": {Name: "short", Duration: time.Millisecond},
+	}
+
+	pl, _ := NewPlayer()
+	_, err := pl.LoadDirectory(ctx, dir)
+	td.CmpError(t, err, "без TagReader")
+
+	pl, _ = New(WithTagReader(reader))
+	added, err := pl.LoadDirectory(ctx, dir)
+	td.Cmp(t, added, 3)
+	td.CmpError(t, err, "ошибки пропущенных файлов")
+	td.CmpContains(t, err.Error(), "broken.mp3")
+	td.CmpContains(t, err.Error(), "short.wav")
+
+	td.Cmp(t, names(pl), []string{"Лирика", "b", "3 сентября"}, "название по имени файла, если тега нет")
+	td.Cmp(t, pl.first().song.Artist, "Сектор Газа")
+	td.Cmp(t, pl.Library().Len(), 3)
+
+	_, err = pl.LoadDirectory(ctx, filepath.Join(dir, "missing"))
+	td.CmpError(t, err)
+}
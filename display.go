@@ -0,0 +1,211 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultNowPlaying - шаблон NowPlayingString по умолчанию:
+// "Исполнитель – Название [1:05/3:20]", для потока - без длительности.
+const DefaultNowPlaying = "{{with .Artist}}{{.}} – {{end}}{{.Title}} [{{.Elapsed}}{{with .Duration}}/{{.}}{{end}}]"
+
+// NowPlayingInfo - данные шаблона NowPlayingString.
+// Длительности уже отформатированы FormatDuration; исходные значения
+// доступны в Position и Length, например для {{words .Position}}.
+type NowPlayingInfo struct {
+	// Title - название песни
+	Title string
+	// Artist - исполнитель
+	Artist string
+	// Album - альбом
+	Album string
+	// Playlist - название активного плейлиста
+	Playlist string
+	// Chapter - название текущей главы
+	Chapter string
+	// Elapsed - позиция воспроизведения, "1:05"
+	Elapsed string
+	// Duration - длительность песни, пусто для потока
+	Duration string
+	// Remaining - сколько осталось до конца песни, пусто для потока
+	Remaining string
+	// Position - позиция воспроизведения
+	Position time.Duration
+	// Length - длительность песни, 0 для потока
+	Length time.Duration
+	// Playing - идёт ли воспроизведение
+	Playing bool
+	// Stream - песня является потоком
+	Stream bool
+	// Volume - громкость от 0 до 100
+	Volume int
+}
+
+// NowPlayingData - данные шаблона для состояния воспроизведения st,
+// false - песни нет, плейлист пуст.
+func NowPlayingData(st Status) (NowPlayingInfo, bool) {
+	if st.Song == nil {
+		return NowPlayingInfo{}, false
+	}
+
+	info := NowPlayingInfo{
+		Title:    st.Song.Name,
+		Artist:   st.Song.Artist,
+		Album:    st.Song.Album,
+		Playlist: st.Playlist,
+		Elapsed:  FormatDuration(st.Position),
+		Position: st.Position,
+		Length:   st.Song.Duration,
+		Playing:  st.Playing,
+		Stream:   st.Song.IsStream(),
+		Volume:   st.Volume,
+	}
+	if st.Chapter != nil {
+		info.Chapter = st.Chapter.Title
+	}
+	if !info.Stream {
+		info.Duration = FormatDuration(st.Song.Duration)
+		info.Remaining = FormatDuration(st.Song.Duration - st.Position)
+	}
+
+	return info, true
+}
+
+// RenderNowPlaying - подставляет состояние st в шаблон text/template templ
+// с данными NowPlayingInfo, "" - DefaultNowPlaying. В шаблоне доступны
+// функции clock - FormatDuration и words - Locale.Duration для locale.
+// Для пустого плейлиста возвращает ошибку.
+func RenderNowPlaying(templ string, st Status, locale Locale) (string, error) {
+	if templ == "" {
+		templ = DefaultNowPlaying
+	}
+
+	t, err := template.New("now playing").Funcs(template.FuncMap{
+		"clock": FormatDuration,
+		"words": locale.Duration,
+	}).Parse(templ)
+	if err != nil {
+		return "", fmt.Errorf("parse template: %w", err)
+	}
+
+	info, ok := NowPlayingData(st)
+	if !ok {
+		return "", errors.New("playlist is empty")
+	}
+
+	var sb strings.Builder
+	if err := t.Execute(&sb, info); err != nil {
+		return "", fmt.Errorf("execute template: %w", err)
+	}
+
+	return sb.String(), nil
+}
+
+// NowPlayingString - текущая песня по шаблону templ, см. RenderNowPlaying.
+// Длительности словами выводятся на языке WithLocale.
+func (p *playerImpl) NowPlayingString(ctx context.Context, templ string) (string, error) {
+	if err := p.rlock(ctx); err != nil {
+		return "", err
+	}
+	closed, locale := p.closed, p.locale
+	p.mu.RUnlock()
+
+	if closed {
+		return "", ErrClosed
+	}
+
+	return RenderNowPlaying(templ, p.Status(ctx), locale)
+}
+
+// FormatDuration - длительность как на часах: "м:сс", от часа - "ч:мм:сс".
+// Доли секунды отбрасываются, отрицательная длительность считается нулевой.
+func FormatDuration(d time.Duration) string {
+	d = max(d, 0).Truncate(time.Second)
+
+	h, m, s := int(d/time.Hour), int(d/time.Minute)%60, int(d/time.Second)%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+// Locale - слова для длительностей на языке интерфейса.
+type Locale struct {
+	// Unit - единица unit (time.Hour, time.Minute или time.Second)
+	// с числом n в нужной форме, например "2 minutes"
+	Unit func(n int, unit time.Duration) string
+}
+
+var (
+	// English - длительности по-английски: "1 hour 5 minutes".
+	English = Locale{Unit: func(n int, unit time.Duration) string {
+		word := map[time.Duration]string{time.Hour: "hour", time.Minute: "minute", time.Second: "second"}[unit]
+		if n != 1 {
+			word += "s"
+		}
+		return fmt.Sprintf("%d %s", n, word)
+	}}
+
+	// Russian - длительности по-русски: "1 час 5 минут".
+	Russian = Locale{Unit: func(n int, unit time.Duration) string {
+		forms := map[time.Duration][3]string{
+			time.Hour:   {"час", "часа", "часов"},
+			time.Minute: {"минута", "минуты", "минут"},
+			time.Second: {"секунда", "секунды", "секунд"},
+		}[unit]
+		return fmt.Sprintf("%d %s", n, forms[russianPlural(n)])
+	}}
+)
+
+// russianPlural - номер формы слова для числа n: 1 минута, 2 минуты, 5 минут.
+func russianPlural(n int) int {
+	switch n10, n100 := n%10, n%100; {
+	case n10 == 1 && n100 != 11:
+		return 0
+	case n10 >= 2 && n10 <= 4 && (n100 < 12 || n100 > 14):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Duration - длительность словами: часы, минуты и секунды, нулевые пропускаются,
+// "1 hour 5 minutes". Доли секунды отбрасываются.
+func (l Locale) Duration(d time.Duration) string {
+	unit := l.Unit
+	if unit == nil {
+		unit = English.Unit
+	}
+
+	d = max(d, 0).Truncate(time.Second)
+	var parts []string
+	for _, u := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		if n := int(d / u); n > 0 {
+			parts = append(parts, unit(n, u))
+			d -= time.Duration(n) * u
+		}
+	}
+
+	if len(parts) == 0 {
+		return unit(0, time.Second)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// WithLocale - язык длительностей словами в NowPlayingString, по умолчанию English.
+func WithLocale(l Locale) Option {
+	return func(p *playerImpl) error {
+		if l.Unit == nil {
+			return errors.New("locale unit is nil")
+		}
+
+		p.locale = l
+		return nil
+	}
+}
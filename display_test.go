@@ -0,0 +1,70 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestFormatDuration(t *testing.T) {
+	td.Cmp(t, FormatDuration(0), "0:00")
+	td.Cmp(t, FormatDuration(65*time.Second+500*time.Millisecond), "1:05", "доли секунды отбрасываются")
+	td.Cmp(t, FormatDuration(59*time.Minute+59*time.Second), "59:59")
+	td.Cmp(t, FormatDuration(time.Hour+2*time.Minute+3*time.Second), "1:02:03", "от часа - с часами")
+	td.Cmp(t, FormatDuration(-time.Second), "0:00")
+}
+
+func TestLocale_Duration(t *testing.T) {
+	td.Cmp(t, English.Duration(time.Hour+5*time.Minute), "1 hour 5 minutes")
+	td.Cmp(t, English.Duration(time.Second), "1 second")
+	td.Cmp(t, English.Duration(0), "0 seconds")
+	td.Cmp(t, Locale{}.Duration(2*time.Minute), "2 minutes", "без Unit - по-английски")
+
+	td.Cmp(t, Russian.Duration(time.Hour+5*time.Minute), "1 час 5 минут")
+	td.Cmp(t, Russian.Duration(2*time.Hour+21*time.Minute+12*time.Second), "2 часа 21 минута 12 секунд")
+	td.Cmp(t, Russian.Duration(11*time.Minute+22*time.Second), "11 минут 22 секунды")
+}
+
+func TestPlayerImpl_NowPlayingString(t *testing.T) {
+	ctx := context.Background()
+
+	pl, err := New(
+		WithClock(NewFakeClock(testStart)),
+		WithLocale(Russian),
+		WithSongs(Song{Name: "Intro", Artist: "Band", Duration: 3*time.Minute + 20*time.Second}, Song{Name: "radio", URL: "http://radio"}),
+	)
+	td.CmpNoError(t, err)
+
+	s, err := pl.NowPlayingString(ctx, "")
+	td.CmpNoError(t, err)
+	td.Cmp(t, s, "Band – Intro [0:00/3:20]", "шаблон по умолчанию")
+
+	td.CmpNoError(t, pl.Play(ctx))
+	_, err = pl.SimulatePlayback(ctx, 65*time.Second)
+	td.CmpNoError(t, err)
+
+	s, err = pl.NowPlayingString(ctx, "{{.Title}}: {{words .Position}} из {{clock .Length}}, осталось {{.Remaining}}")
+	td.CmpNoError(t, err)
+	td.Cmp(t, s, "Intro: 1 минута 5 секунд из 3:20, осталось 2:15", "длительности словами на языке WithLocale")
+
+	td.CmpNoError(t, pl.Next(ctx))
+	s, err = pl.NowPlayingString(ctx, "")
+	td.CmpNoError(t, err)
+	td.Cmp(t, s, "radio [0:00]", "поток без длительности")
+
+	_, err = pl.NowPlayingString(ctx, "{{.Title")
+	td.CmpContains(t, err, "parse template")
+
+	_, err = RenderNowPlaying("", Status{}, English)
+	td.CmpString(t, err, "playlist is empty")
+
+	_, err = New(WithLocale(Locale{}))
+	td.CmpString(t, err, "locale unit is nil")
+
+	td.CmpNoError(t, pl.Close(ctx))
+	_, err = pl.NowPlayingString(ctx, "")
+	td.CmpTrue(t, errors.Is(err, ErrClosed))
+}
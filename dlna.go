@@ -0,0 +1,342 @@
+package player
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// dlnaAVTransport - сервис UPnP, управляющий воспроизведением
+	dlnaAVTransport = "urn:schemas-upnp-org:service:AVTransport:1"
+	// dlnaRenderingControl - сервис UPnP, управляющий громкостью
+	dlnaRenderingControl = "urn:schemas-upnp-org:service:RenderingControl:1"
+	// dlnaMediaRenderer - тип устройства, который ищет SSDPDiscovery
+	dlnaMediaRenderer = "urn:schemas-upnp-org:device:MediaRenderer:1"
+	// ssdpAddr - групповой адрес SSDP
+	ssdpAddr = "239.255.255.250:1900"
+)
+
+// DLNARenderer - Renderer для DLNA/UPnP MediaRenderer, управляемого
+// через SOAP-команды сервисов AVTransport и RenderingControl.
+type DLNARenderer struct {
+	// FriendlyName - название устройства
+	FriendlyName string
+	// AVTransportURL - адрес управления сервисом AVTransport
+	AVTransportURL string
+	// RenderingControlURL - адрес управления сервисом RenderingControl,
+	// пусто если устройство не управляет громкостью
+	RenderingControlURL string
+	// Client - HTTP-клиент, по умолчанию http.DefaultClient
+	Client *http.Client
+}
+
+// dlnaDescription - описание устройства UPnP.
+type dlnaDescription struct {
+	URLBase string     `xml:"URLBase"`
+	Device  dlnaDevice `xml:"device"`
+}
+
+type dlnaDevice struct {
+	FriendlyName string `xml:"friendlyName"`
+	Services     []struct {
+		Type       string `xml:"serviceType"`
+		ControlURL string `xml:"controlURL"`
+	} `xml:"serviceList>service"`
+	Devices []dlnaDevice `xml:"deviceList>device"`
+}
+
+// NewDLNARenderer - загружает описание устройства по адресу location,
+// который устройство сообщает при обнаружении, и находит адреса управления.
+func NewDLNARenderer(ctx context.Context, location string, client *http.Client) (*DLNARenderer, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get device description: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get device description: %s", resp.Status)
+	}
+
+	var desc dlnaDescription
+	if err := xml.NewDecoder(resp.Body).Decode(&desc); err != nil {
+		return nil, fmt.Errorf("decode device description: %v", err)
+	}
+
+	base, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("parse location: %v", err)
+	}
+	if desc.URLBase != "" {
+		if base, err = url.Parse(desc.URLBase); err != nil {
+			return nil, fmt.Errorf("parse url base: %v", err)
+		}
+	}
+
+	r := &DLNARenderer{FriendlyName: desc.Device.FriendlyName, Client: client}
+	devices := []dlnaDevice{desc.Device}
+	for len(devices) > 0 {
+		d := devices[0]
+		devices = append(devices[1:], d.Devices...)
+
+		for _, s := range d.Services {
+			ref, err := url.Parse(strings.TrimSpace(s.ControlURL))
+			if err != nil {
+				return nil, fmt.Errorf("parse control url: %v", err)
+			}
+
+			switch strings.TrimSpace(s.Type) {
+			case dlnaAVTransport:
+				r.AVTransportURL = base.ResolveReference(ref).String()
+			case dlnaRenderingControl:
+				r.RenderingControlURL = base.ResolveReference(ref).String()
+			}
+		}
+	}
+
+	if r.AVTransportURL == "" {
+		return nil, fmt.Errorf("device %q has no AVTransport service", r.FriendlyName)
+	}
+
+	return r, nil
+}
+
+func (r *DLNARenderer) Name() string {
+	return r.FriendlyName
+}
+
+// Load - передаёт устройству адрес песни и её метаданные DIDL-Lite.
+func (r *DLNARenderer) Load(ctx context.Context, song Song) error {
+	return r.call(ctx, r.AVTransportURL, dlnaAVTransport, "SetAVTransportURI",
+		"InstanceID", "0",
+		"CurrentURI", song.URL,
+		"CurrentURIMetaData", didlLite(song),
+	)
+}
+
+func (r *DLNARenderer) Play(ctx context.Context) error {
+	return r.call(ctx, r.AVTransportURL, dlnaAVTransport, "Play", "InstanceID", "0", "Speed", "1")
+}
+
+func (r *DLNARenderer) Pause(ctx context.Context) error {
+	return r.call(ctx, r.AVTransportURL, dlnaAVTransport, "Pause", "InstanceID", "0")
+}
+
+func (r *DLNARenderer) Seek(ctx context.Context, pos time.Duration) error {
+	return r.call(ctx, r.AVTransportURL, dlnaAVTransport, "Seek",
+		"InstanceID", "0",
+		"Unit", "REL_TIME",
+		"Target", dlnaTime(pos),
+	)
+}
+
+func (r *DLNARenderer) Stop(ctx context.Context) error {
+	return r.call(ctx, r.AVTransportURL, dlnaAVTransport, "Stop", "InstanceID", "0")
+}
+
+// SetVolume - устанавливает громкость. Если устройство не управляет громкостью, ничего не делает.
+func (r *DLNARenderer) SetVolume(ctx context.Context, volume int) error {
+	if r.RenderingControlURL == "" {
+		return nil
+	}
+
+	return r.call(ctx, r.RenderingControlURL, dlnaRenderingControl, "SetVolume",
+		"InstanceID", "0",
+		"Channel", "Master",
+		"DesiredVolume", strconv.Itoa(volume),
+	)
+}
+
+// dlnaFault - ошибка UPnP в ответе SOAP.
+type dlnaFault struct {
+	Code        int    `xml:"Body>Fault>detail>UPnPError>errorCode"`
+	Description string `xml:"Body>Fault>detail>UPnPError>errorDescription"`
+}
+
+// call - отправляет команду action сервису service, args - пары имя, значение.
+func (r *DLNARenderer) call(ctx context.Context, controlURL, service, action string, args ...string) error {
+	var body bytes.Buffer
+	body.WriteString(`<?xml version="1.0" encoding="utf-8"?>`)
+	body.WriteString(`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>`)
+	fmt.Fprintf(&body, `<u:%s xmlns:u="%s">`, action, service)
+	for i := 0; i+1 < len(args); i += 2 {
+		fmt.Fprintf(&body, "<%s>", args[i])
+		_ = xml.EscapeText(&body, []byte(args[i+1]))
+		fmt.Fprintf(&body, "</%s>", args[i])
+	}
+	fmt.Fprintf(&body, `</u:%s></s:Body></s:Envelope>`, action)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, controlURL, &body)
+	if err != nil {
+		return fmt.Errorf("create request: %v", err)
+	}
+	req.Header.Set("Content-Type", `text/xml; charset="utf-8"`)
+	req.Header.Set("SOAPAction", fmt.Sprintf(`"%s#%s"`, service, action))
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %v", action, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var fault dlnaFault
+		if err := xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&fault); err == nil && fault.Code != 0 {
+			return fmt.Errorf("%s: upnp error %d: %s", action, fault.Code, fault.Description)
+		}
+		return fmt.Errorf("%s: %s", action, resp.Status)
+	}
+
+	return nil
+}
+
+// didlLite - метаданные песни в формате DIDL-Lite.
+func didlLite(song Song) string {
+	var b bytes.Buffer
+	b.WriteString(`<DIDL-Lite xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:upnp="urn:schemas-upnp-org:metadata-1-0/upnp/">`)
+	b.WriteString(`<item id="0" parentID="-1" restricted="1">`)
+	writeElement(&b, "dc:title", song.Name)
+	if song.Artist != "" {
+		writeElement(&b, "upnp:artist", song.Artist)
+	}
+	if song.Album != "" {
+		writeElement(&b, "upnp:album", song.Album)
+	}
+	b.WriteString(`<upnp:class>object.item.audioItem.musicTrack</upnp:class>`)
+	if song.IsStream() {
+		b.WriteString(`<res protocolInfo="http-get:*:*:*">`)
+	} else {
+		fmt.Fprintf(&b, `<res protocolInfo="http-get:*:*:*" duration="%s">`, dlnaTime(song.Duration))
+	}
+	_ = xml.EscapeText(&b, []byte(song.URL))
+	b.WriteString(`</res></item></DIDL-Lite>`)
+
+	return b.String()
+}
+
+// writeElement - пишет элемент name с экранированным текстом.
+func writeElement(b *bytes.Buffer, name, text string) {
+	fmt.Fprintf(b, "<%s>", name)
+	_ = xml.EscapeText(b, []byte(text))
+	fmt.Fprintf(b, "</%s>", name)
+}
+
+// dlnaTime - позиция в формате H:MM:SS.
+func dlnaTime(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
+
+// SSDPDiscovery - Discovery, который ищет DLNA-рендереры в локальной сети
+// запросом SSDP M-SEARCH и загружает их описания.
+// Устройства, описание которых не удалось загрузить, пропускаются.
+type SSDPDiscovery struct {
+	// Timeout - сколько ждать ответов устройств, по умолчанию 2 секунды
+	Timeout time.Duration
+	// Addr - куда отправлять запрос, по умолчанию групповой адрес SSDP
+	Addr string
+	// Client - HTTP-клиент для загрузки описаний, по умолчанию http.DefaultClient
+	Client *http.Client
+}
+
+func (d SSDPDiscovery) Discover(ctx context.Context) ([]Renderer, error) {
+	timeout, addr := d.Timeout, d.Addr
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	if addr == "" {
+		addr = ssdpAddr
+	}
+
+	raddr, err := net.ResolveUDPAddr("udp4", addr)
+	if err != nil {
+		return nil, fmt.Errorf("resolve ssdp address: %v", err)
+	}
+
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return nil, fmt.Errorf("listen: %v", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(timeout)
+	if dl, ok := ctx.Deadline(); ok && dl.Before(deadline) {
+		deadline = dl
+	}
+	_ = conn.SetDeadline(deadline)
+
+	search := "M-SEARCH * HTTP/1.1\r\n" +
+		"HOST: " + ssdpAddr + "\r\n" +
+		"MAN: \"ssdp:discover\"\r\n" +
+		"MX: " + strconv.Itoa(max(1, int(timeout/time.Second))) + "\r\n" +
+		"ST: " + dlnaMediaRenderer + "\r\n\r\n"
+	if _, err := conn.WriteTo([]byte(search), raddr); err != nil {
+		return nil, fmt.Errorf("send m-search: %v", err)
+	}
+
+	var locations []string
+	seen := make(map[string]bool)
+	buf := make([]byte, 2048)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				break
+			}
+			return nil, fmt.Errorf("read ssdp response: %v", err)
+		}
+
+		resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(buf[:n])), nil)
+		if err != nil {
+			continue
+		}
+		resp.Body.Close()
+
+		loc := resp.Header.Get("Location")
+		if loc != "" && !seen[loc] {
+			seen[loc] = true
+			locations = append(locations, loc)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	renderers := make([]Renderer, 0, len(locations))
+	for _, loc := range locations {
+		r, err := NewDLNARenderer(ctx, loc, d.Client)
+		if err != nil {
+			continue
+		}
+		renderers = append(renderers, r)
+	}
+
+	return renderers, nil
+}
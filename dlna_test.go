@@ -0,0 +1,181 @@
+package player
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// testDLNADevice - тестовый DLNA-рендерер: отдаёт описание и записывает команды.
+type testDLNADevice struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	actions  []string
+	metadata string
+}
+
+func newTestDLNADevice(t *testing.T) *testDLNADevice {
+	d := &testDLNADevice{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/description.xml", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+  <device>
+    <deviceType>urn:schemas-upnp-org:device:MediaRenderer:1</deviceType>
+    <friendlyName>Гостиная</friendlyName>
+    <serviceList>
+      <service>
+        <serviceType>urn:schemas-upnp-org:service:RenderingControl:1</serviceType>
+        <controlURL>/rc/control</controlURL>
+      </service>
+    </serviceList>
+    <deviceList>
+      <device>
+        <serviceList>
+          <service>
+            <serviceType>urn:schemas-upnp-org:service:AVTransport:1</serviceType>
+            <controlURL>avt/control</controlURL>
+          </service>
+        </serviceList>
+      </device>
+    </deviceList>
+  </device>
+</root>`)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+
+		var env struct {
+			Body struct {
+				Action struct {
+					XMLName  xml.Name
+					Target   string `xml:"Target"`
+					Volume   string `xml:"DesiredVolume"`
+					URI      string `xml:"CurrentURI"`
+					Metadata string `xml:"CurrentURIMetaData"`
+				} `xml:",any"`
+			} `xml:"Body"`
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := xml.Unmarshal(body, &env); err != nil {
+			t.Errorf("плохой SOAP-запрос: %v", err)
+		}
+
+		a := env.Body.Action
+		action := r.URL.Path + " " + a.XMLName.Local + strings.TrimRight(" "+a.Target+a.Volume+a.URI, " ")
+		td.Cmp(t, r.Header.Get("SOAPAction"), td.HasSuffix("#"+a.XMLName.Local+`"`))
+
+		d.mu.Lock()
+		d.actions = append(d.actions, action)
+		if a.Metadata != "" {
+			d.metadata = a.Metadata
+		}
+		d.mu.Unlock()
+
+		if a.XMLName.Local == "Seek" && a.Target == "9:00:00" {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><s:Fault>
+<faultcode>s:Client</faultcode><faultstring>UPnPError</faultstring>
+<detail><UPnPError xmlns="urn:schemas-upnp-org:control-1-0"><errorCode>711</errorCode><errorDescription>Illegal seek target</errorDescription></UPnPError></detail>
+</s:Fault></s:Body></s:Envelope>`)
+		}
+	})
+	d.Server = httptest.NewServer(mux)
+	t.Cleanup(d.Close)
+
+	return d
+}
+
+func (d *testDLNADevice) Actions() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]string(nil), d.actions...)
+}
+
+func TestDLNARenderer(t *testing.T) {
+	ctx := context.Background()
+	device := newTestDLNADevice(t)
+
+	r, err := NewDLNARenderer(ctx, device.URL+"/description.xml", nil)
+	td.CmpNoError(t, err)
+	td.Cmp(t, r.Name(), "Гостиная")
+	td.Cmp(t, r.AVTransportURL, device.URL+"/avt/control", "адрес из вложенного устройства")
+	td.Cmp(t, r.RenderingControlURL, device.URL+"/rc/control")
+
+	song := Song{Name: "Rock & Roll", Artist: "Led Zeppelin", Duration: 220 * time.Second, URL: "http://nas/rr.mp3?a=1&b=2"}
+	td.CmpNoError(t, r.Load(ctx, song))
+	td.CmpNoError(t, r.Seek(ctx, 65*time.Second))
+	td.CmpNoError(t, r.Play(ctx))
+	td.CmpNoError(t, r.SetVolume(ctx, 35))
+	td.CmpNoError(t, r.Pause(ctx))
+	td.CmpNoError(t, r.Stop(ctx))
+	td.CmpString(t, r.Seek(ctx, 9*time.Hour), "Seek: upnp error 711: Illegal seek target")
+
+	td.Cmp(t, device.Actions(), []string{
+		"/avt/control SetAVTransportURI http://nas/rr.mp3?a=1&b=2",
+		"/avt/control Seek 0:01:05",
+		"/avt/control Play",
+		"/rc/control SetVolume 35",
+		"/avt/control Pause",
+		"/avt/control Stop",
+		"/avt/control Seek 9:00:00",
+	})
+
+	device.mu.Lock()
+	metadata := device.metadata
+	device.mu.Unlock()
+	td.Cmp(t, metadata, td.All(
+		td.Contains("<dc:title>Rock &amp; Roll</dc:title>"),
+		td.Contains("<upnp:artist>Led Zeppelin</upnp:artist>"),
+		td.Contains(`duration="0:03:40">http://nas/rr.mp3?a=1&amp;b=2</res>`),
+	))
+
+	_, err = NewDLNARenderer(ctx, device.URL+"/missing.xml", nil)
+	td.CmpError(t, err)
+}
+
+func TestSSDPDiscovery(t *testing.T) {
+	ctx := context.Background()
+	device := newTestDLNADevice(t)
+
+	// вместо группового адреса отвечает локальный сокет
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	td.CmpNoError(t, err)
+	defer conn.Close()
+
+	go func() {
+		buf := make([]byte, 2048)
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if !strings.Contains(string(buf[:n]), "ST: urn:schemas-upnp-org:device:MediaRenderer:1") {
+			return
+		}
+
+		for _, loc := range []string{device.URL + "/description.xml", device.URL + "/description.xml", device.URL + "/gone.xml"} {
+			resp := "HTTP/1.1 200 OK\r\nST: urn:schemas-upnp-org:device:MediaRenderer:1\r\nLOCATION: " + loc + "\r\n\r\n"
+			_, _ = conn.WriteTo([]byte(resp), from)
+		}
+	}()
+
+	renderers, err := SSDPDiscovery{Addr: conn.LocalAddr().String(), Timeout: 200 * time.Millisecond}.Discover(ctx)
+	td.CmpNoError(t, err)
+	td.Cmp(t, renderers, td.Len(1), "повторы и недоступные устройства пропущены")
+	td.Cmp(t, renderers[0].Name(), "Гостиная")
+}
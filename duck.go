@@ -0,0 +1,131 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+)
+
+// Duck - приглушает звук до доли level громкости, например на время объявления.
+// Громкость плеера не меняется, Unduck возвращает её бэкенду.
+// Работает с бэкендами, реализующими VolumeOutput.
+func (p *playerImpl) Duck(ctx context.Context, level float64) error {
+	if level < 0 || level > 1 || math.IsNaN(level) {
+		return fmt.Errorf("duck level %g out of range [0, 1]", level)
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	ducked, duckLevel := p.ducked, p.duckLevel
+	p.ducked, p.duckLevel = true, level
+	if err := p.applyVolumeLocked(ctx); err != nil {
+		p.ducked, p.duckLevel = ducked, duckLevel
+		return err
+	}
+
+	return nil
+}
+
+// Unduck - отменяет Duck.
+func (p *playerImpl) Unduck(ctx context.Context) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if !p.ducked {
+		return nil
+	}
+
+	p.ducked = false
+	if err := p.applyVolumeLocked(ctx); err != nil {
+		p.ducked = true
+		return err
+	}
+
+	return nil
+}
+
+// AutoPauseOn - приостанавливает воспроизведение по внешнему сигналу, например
+// при входящем звонке, и продолжает его по следующему сигналу. Закрытие signal
+// тоже продолжает воспроизведение и прекращает слежение, как и завершение ctx
+// или закрытие плеера. Воспроизведение продолжается, только если его
+// приостановил сигнал и с тех пор его не меняли.
+func (p *playerImpl) AutoPauseOn(ctx context.Context, signal <-chan struct{}) error {
+	if signal == nil {
+		return errors.New("signal is nil")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	go p.watchSignal(ctx, signal)
+	return nil
+}
+
+// watchSignal - горутина AutoPauseOn.
+func (p *playerImpl) watchSignal(watchCtx context.Context, signal <-chan struct{}) {
+	ctx := context.WithoutCancel(watchCtx)
+	// paused - узел, воспроизведение которого приостановил сигнал
+	var paused *playerNode
+	active := false
+
+	for {
+		var ok bool
+		select {
+		case _, ok = <-signal:
+		case <-watchCtx.Done():
+		case <-p.done:
+			return
+		}
+
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return
+		}
+
+		switch {
+		case ok && !active:
+			active = true
+			if p.isPlaying {
+				paused = p.current
+				p.pauseLocked(ctx)
+				p.logger.InfoContext(ctx, "playback paused by signal", songAttr(*paused.song))
+			}
+
+		case active:
+			active = false
+			if paused != nil && paused == p.current && p.stateLocked() == StatePaused {
+				if err := p.playLocked(ctx); err != nil {
+					p.logger.ErrorContext(ctx, "resume after signal failed", songAttr(*paused.song), slog.Any("error", err))
+				}
+			}
+			paused = nil
+		}
+		p.mu.Unlock()
+
+		if !ok || watchCtx.Err() != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Duck(t *testing.T) {
+	ctx := context.Background()
+	out := &volumeOutput{}
+	pl, _ := New(WithOutput(out), WithSongs(Song{Name: "a", Duration: 30 * time.Second}))
+
+	td.CmpNoError(t, pl.SetVolume(ctx, 50))
+	td.CmpNoError(t, pl.Duck(ctx, 0.2))
+	td.CmpNoError(t, pl.SetVolume(ctx, 80))
+	td.CmpNoError(t, pl.Mute(ctx))
+	td.CmpNoError(t, pl.Unmute(ctx))
+	td.CmpNoError(t, pl.Unduck(ctx))
+	td.CmpNoError(t, pl.Unduck(ctx), "повторно ничего не делает")
+
+	td.Cmp(t, out.volumes, []int{50, 10, 16, 0, 16, 80})
+	td.Cmp(t, pl.Volume(ctx), 80, "громкость плеера не приглушается")
+
+	td.CmpString(t, pl.Duck(ctx, 1.5), "duck level 1.5 out of range [0, 1]")
+
+	td.CmpNoError(t, pl.Close(ctx))
+	td.Cmp(t, pl.Duck(ctx, 0.5), ErrClosed)
+	td.Cmp(t, pl.Unduck(ctx), ErrClosed)
+}
+
+func TestPlayerImpl_AutoPauseOn(t *testing.T) {
+	ctx := context.Background()
+
+	waitState := func(t *testing.T, pl *playerImpl, want State) {
+		t.Helper()
+
+		waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		td.CmpNoError(t, pl.WaitFor(waitCtx, func(Status) bool { return pl.State(ctx) == want }), "ждём %s", want)
+	}
+
+	t.Run("звонок", func(t *testing.T) {
+		pl, _ := NewPlayer(Song{Name: "a", Duration: time.Minute})
+		defer pl.Close(ctx)
+
+		call := make(chan struct{})
+		td.CmpNoError(t, pl.AutoPauseOn(ctx, call))
+		td.CmpNoError(t, pl.Play(ctx))
+
+		call <- struct{}{}
+		waitState(t, pl, StatePaused)
+
+		call <- struct{}{}
+		waitState(t, pl, StatePlaying)
+
+		td.CmpNoError(t, pl.Pause(ctx))
+		call <- struct{}{}
+		call <- struct{}{}
+		time.Sleep(10 * time.Millisecond)
+		td.Cmp(t, pl.State(ctx), StatePaused, "не продолжает то, что приостановил пользователь")
+	})
+
+	t.Run("пользователь продолжил сам", func(t *testing.T) {
+		pl, _ := NewPlayer(Song{Name: "a", Duration: time.Minute}, Song{Name: "b", Duration: time.Minute})
+		defer pl.Close(ctx)
+
+		bell := make(chan struct{})
+		td.CmpNoError(t, pl.AutoPauseOn(ctx, bell))
+		td.CmpNoError(t, pl.Play(ctx))
+
+		bell <- struct{}{}
+		waitState(t, pl, StatePaused)
+		td.CmpNoError(t, pl.Next(ctx))
+		td.CmpNoError(t, pl.Pause(ctx))
+
+		close(bell)
+		time.Sleep(10 * time.Millisecond)
+		td.Cmp(t, pl.State(ctx), StatePaused, "песня сменилась")
+	})
+
+	t.Run("закрытие сигнала", func(t *testing.T) {
+		pl, _ := NewPlayer(Song{Name: "a", Duration: time.Minute})
+		defer pl.Close(ctx)
+
+		announce := make(chan struct{}, 1)
+		td.CmpNoError(t, pl.AutoPauseOn(ctx, announce))
+		td.CmpNoError(t, pl.Play(ctx))
+
+		announce <- struct{}{}
+		waitState(t, pl, StatePaused)
+		close(announce)
+		waitState(t, pl, StatePlaying)
+	})
+
+	pl, _ := NewPlayer()
+	td.CmpString(t, pl.AutoPauseOn(ctx, nil), "signal is nil")
+	td.CmpNoError(t, pl.Close(ctx))
+	td.Cmp(t, pl.AutoPauseOn(ctx, make(chan struct{})), ErrClosed)
+}
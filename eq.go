@@ -0,0 +1,115 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+)
+
+const (
+	// EQBands - количество полос эквалайзера: 31, 62, 125, 250, 500 Гц, 1, 2, 4, 8 и 16 кГц.
+	EQBands = 10
+	// MaxEQGain - наибольшее усиление или ослабление полосы в децибелах.
+	MaxEQGain = 12
+)
+
+// ErrUnknownPreset - нет предустановки эквалайзера с таким названием.
+var ErrUnknownPreset = errors.New("unknown eq preset")
+
+// EQOutput - бэкенд, поддерживающий эквалайзер.
+type EQOutput interface {
+	// SetEQ - задаёт усиление полос в децибелах
+	SetEQ(ctx context.Context, bands []float64) error
+}
+
+// eqPresets - предустановки эквалайзера по названию.
+var eqPresets = map[string][]float64{
+	"flat":   {0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+	"rock":   {5, 4, 3, 1, -1, -1, 1, 3, 4, 5},
+	"pop":    {-1, 1, 3, 4, 3, 0, -1, -1, 1, 2},
+	"bass":   {6, 5, 4, 2, 0, 0, 0, 0, 0, 0},
+	"speech": {-6, -4, -2, 1, 3, 4, 4, 2, 0, -2},
+}
+
+// EQPresets - возвращает названия предустановок эквалайзера по алфавиту.
+func EQPresets() []string {
+	names := make([]string, 0, len(eqPresets))
+	for name := range eqPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// SetEQ - задаёт усиление EQBands полос эквалайзера в децибелах от -12 до 12.
+// Настройка хранится в плеере и передаётся бэкенду, если он реализует EQOutput.
+func (p *playerImpl) SetEQ(ctx context.Context, bands []float64) error {
+	if err := validateEQ(bands); err != nil {
+		return err
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	p.eq = append([]float64(nil), bands...)
+	return p.applyEQLocked(ctx)
+}
+
+// SetEQPreset - задаёт эквалайзер по названию предустановки из EQPresets.
+func (p *playerImpl) SetEQPreset(ctx context.Context, name string) error {
+	bands, ok := eqPresets[name]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownPreset, name)
+	}
+
+	return p.SetEQ(ctx, bands)
+}
+
+// EQ - возвращает усиление полос эквалайзера, nil если эквалайзер не задавался.
+func (p *playerImpl) EQ(_ context.Context) []float64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return append([]float64(nil), p.eq...)
+}
+
+// validateEQ - проверяет количество полос и их усиление.
+func validateEQ(bands []float64) error {
+	if len(bands) != EQBands {
+		return fmt.Errorf("eq must have %d bands, got %d", EQBands, len(bands))
+	}
+
+	for i, g := range bands {
+		if g < -MaxEQGain || g > MaxEQGain {
+			return fmt.Errorf("eq band %d gain %g out of range [%d, %d]", i, g, -MaxEQGain, MaxEQGain)
+		}
+	}
+
+	return nil
+}
+
+// applyEQLocked - передаёт эквалайзер бэкенду, если он его поддерживает.
+// Вызывается под блокировкой.
+func (p *playerImpl) applyEQLocked(ctx context.Context) error {
+	p.logger.InfoContext(ctx, "eq changed", slog.Any("bands", p.eq))
+
+	eo, ok := p.output.(EQOutput)
+	if !ok || p.eq == nil {
+		return nil
+	}
+
+	if err := eo.SetEQ(ctx, append([]float64(nil), p.eq...)); err != nil {
+		return fmt.Errorf("set output eq: %v", err)
+	}
+
+	return nil
+}
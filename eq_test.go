@@ -0,0 +1,73 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+type eqOutput struct {
+	nopOutput
+	bands [][]float64
+}
+
+func (o *eqOutput) SetEQ(_ context.Context, bands []float64) error {
+	o.bands = append(o.bands, bands)
+	return nil
+}
+
+func TestPlayerImpl_EQ(t *testing.T) {
+	ctx := context.Background()
+	rock := []float64{5, 4, 3, 1, -1, -1, 1, 3, 4, 5}
+
+	t.Run("set and preset", func(t *testing.T) {
+		out := &eqOutput{}
+		pl, _ := New(WithOutput(out))
+		td.CmpNil(t, pl.EQ(ctx), "эквалайзер не задавался")
+
+		td.CmpError(t, pl.SetEQ(ctx, []float64{1, 2}))
+		td.CmpError(t, pl.SetEQ(ctx, []float64{0, 0, 0, 0, 0, 0, 0, 0, 0, 13}))
+
+		td.CmpNoError(t, pl.SetEQPreset(ctx, "rock"))
+		td.Cmp(t, pl.EQ(ctx), rock)
+		td.CmpTrue(t, errors.Is(pl.SetEQPreset(ctx, "metal"), ErrUnknownPreset))
+
+		bands := []float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}
+		td.CmpNoError(t, pl.SetEQ(ctx, bands))
+		bands[0] = 10
+		td.Cmp(t, pl.EQ(ctx)[0], 1.0, "плеер хранит копию")
+
+		td.Cmp(t, out.bands, [][]float64{rock, {1, 1, 1, 1, 1, 1, 1, 1, 1, 1}})
+	})
+
+	t.Run("snapshot and restore", func(t *testing.T) {
+		pl, _ := NewPlayer()
+		_ = pl.SetEQPreset(ctx, "rock")
+		state, _ := pl.Snapshot(ctx)
+		td.Cmp(t, state.EQ, rock)
+
+		out := &eqOutput{}
+		restored, _ := New(WithOutput(out))
+		td.CmpNoError(t, restored.RestoreState(ctx, state))
+		td.Cmp(t, restored.EQ(ctx), rock)
+		td.Cmp(t, out.bands, [][]float64{rock}, "эквалайзер передан бэкенду")
+
+		state.EQ = []float64{1}
+		td.CmpError(t, restored.RestoreState(ctx, state))
+	})
+
+	t.Run("presets", func(t *testing.T) {
+		td.Cmp(t, EQPresets(), td.SuperBagOf("flat", "rock", "speech"))
+		for _, name := range EQPresets() {
+			td.CmpNoError(t, validateEQ(eqPresets[name]), name)
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		pl, _ := NewPlayer()
+		_ = pl.Close(ctx)
+		td.Cmp(t, pl.SetEQPreset(ctx, "rock"), ErrClosed)
+	})
+}
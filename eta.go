@@ -0,0 +1,187 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrEndless - воспроизведение не закончится само: впереди поток, повтор участка песни
+// или повтор плейлиста при EdgeWrap.
+var ErrEndless = errors.New("playback is endless")
+
+// TotalDuration - возвращает, сколько будет играть активный плейлист от начала до конца
+// с учётом наложений, пауз между песнями и вставок, если начать его сейчас.
+func (p *playerImpl) TotalDuration(ctx context.Context) (time.Duration, error) {
+	if err := p.rlock(ctx); err != nil {
+		return 0, err
+	}
+	defer p.mu.RUnlock()
+
+	if p.first() == nil {
+		return 0, nil
+	}
+
+	est := p.newEstimateLocked(p.now(), true)
+	for n := p.first(); n != nil; n = n.next() {
+		if err := est.song(n, 0); err != nil {
+			return 0, err
+		}
+	}
+
+	return est.total, nil
+}
+
+// RemainingDuration - возвращает, сколько осталось играть до конца активного плейлиста
+// с текущей позиции с учётом наложений, пауз между песнями и вставок.
+// Длительность будущих вставок оценивается по последней сыгранной вставке.
+// На паузе считается, как если бы воспроизведение продолжилось сейчас.
+func (p *playerImpl) RemainingDuration(ctx context.Context) (time.Duration, error) {
+	if err := p.rlock(ctx); err != nil {
+		return 0, err
+	}
+	defer p.mu.RUnlock()
+
+	return p.remainingLocked(p.now())
+}
+
+// EstimatedEndTime - возвращает, когда закончится активный плейлист, как RemainingDuration.
+func (p *playerImpl) EstimatedEndTime(ctx context.Context) (time.Time, error) {
+	if err := p.rlock(ctx); err != nil {
+		return time.Time{}, err
+	}
+	defer p.mu.RUnlock()
+
+	now := p.now()
+	d, err := p.remainingLocked(now)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return now.Add(d), nil
+}
+
+// remainingLocked - оценивает оставшееся время воспроизведения от момента now.
+// Вызывается под блокировкой.
+func (p *playerImpl) remainingLocked(now time.Time) (time.Duration, error) {
+	if p.current == nil {
+		return 0, nil
+	}
+
+	if p.loopActiveLocked() || p.edge == EdgeWrap {
+		return 0, ErrEndless
+	}
+
+	est := p.newEstimateLocked(now, false)
+	if err := est.song(p.current, p.elapsedLocked()); err != nil {
+		return 0, err
+	}
+	if p.inGap {
+		est.total += max(p.startedAt.Sub(now), 0)
+	}
+
+	// песни очереди Enqueue играют раньше продолжения плейлиста
+	for _, s := range p.upNext.Values() {
+		if err := est.song(&playerNode{song: &s}, 0); err != nil {
+			return 0, err
+		}
+	}
+
+	from := p.current
+	// после вставки продолжается прерванная песня, вставки перед ней не бывает
+	if in := p.interruption; in != nil {
+		est.resumed = true
+		if err := est.song(in.node, in.position); err != nil {
+			return 0, err
+		}
+		from = in.node
+	}
+
+	for n := from.next(); n != nil; n = n.next() {
+		if err := est.song(n, 0); err != nil {
+			return 0, err
+		}
+	}
+
+	return est.total, nil
+}
+
+// estimate - оценка времени воспроизведения последовательности песен.
+type estimate struct {
+	p   *playerImpl
+	now time.Time
+	// total - время от начала первой песни до конца последней
+	total time.Duration
+	// last - длительность последней учтённой песни, -1 - песен ещё не было
+	last time.Duration
+	// resumed - следующая песня продолжается после вставки
+	resumed bool
+
+	// songs, since - счётчики вставок на момент учтённой песни
+	songs int
+	since time.Time
+}
+
+// newEstimateLocked - начинает оценку от момента now.
+// Если fresh, счётчики вставок считаются сброшенными.
+// Вызывается под блокировкой.
+func (p *playerImpl) newEstimateLocked(now time.Time, fresh bool) *estimate {
+	est := &estimate{p: p, now: now, last: -1, since: now}
+	if is := p.interstitials; is != nil && !fresh {
+		est.songs, est.since = is.songs, is.last
+	}
+
+	return est
+}
+
+// song - учитывает песню узла n, начатую с позиции offset,
+// и вставку перед ней, если она ожидается.
+func (e *estimate) song(n *playerNode, offset time.Duration) error {
+	if n.song.IsStream() {
+		return ErrEndless
+	}
+
+	if e.last >= 0 && !e.resumed {
+		e.interstitial()
+	}
+	e.resumed = false
+
+	e.add(n.song.playLength(), max(offset-n.song.LeadIn, 0))
+	return nil
+}
+
+// interstitial - учитывает вставку между песнями так же, как interstitialLocked.
+func (e *estimate) interstitial() {
+	is := e.p.interstitials
+	if is == nil {
+		return
+	}
+
+	at := e.now.Add(e.total)
+	e.songs++
+	due := is.everySongs > 0 && e.songs >= is.everySongs ||
+		is.every > 0 && at.Sub(e.since) >= is.every
+	if !due {
+		return
+	}
+
+	e.songs, e.since = 0, at
+	if is.length > 0 {
+		e.add(is.length, 0)
+	}
+}
+
+// add - добавляет песню длительностью d, начатую с позиции offset,
+// с наложением на предыдущую или паузой перед ней.
+func (e *estimate) add(d, offset time.Duration) {
+	if e.last >= 0 {
+		if e.p.crossfade > 0 {
+			e.total -= min(e.p.crossfade, e.last, d)
+		} else {
+			e.total += e.p.gap
+		}
+	}
+
+	e.total += max(d-offset, 0)
+	e.last = d
+}
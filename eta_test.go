@@ -0,0 +1,90 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_ETA(t *testing.T) {
+	ctx := context.Background()
+	songs := []Song{
+		{Name: "a", Duration: time.Minute},
+		{Name: "b", Duration: time.Minute},
+		{Name: "c", Duration: time.Minute},
+	}
+
+	t.Run("total", func(t *testing.T) {
+		empty, _ := New()
+		total, err := empty.TotalDuration(ctx)
+		td.CmpNoError(t, err)
+		td.Cmp(t, total, time.Duration(0))
+
+		pl, _ := New(WithSongs(songs...))
+		total, _ = pl.TotalDuration(ctx)
+		td.Cmp(t, total, 3*time.Minute)
+
+		pl, _ = New(WithSongs(songs...), WithCrossfade(5*time.Second))
+		total, _ = pl.TotalDuration(ctx)
+		td.Cmp(t, total, 3*time.Minute-10*time.Second, "наложения сокращают время")
+
+		pl, _ = New(WithSongs(songs...), WithGap(2*time.Second))
+		total, _ = pl.TotalDuration(ctx)
+		td.Cmp(t, total, 3*time.Minute+4*time.Second, "паузы удлиняют время")
+	})
+
+	t.Run("remaining", func(t *testing.T) {
+		pl, _ := New(WithSongs(songs...))
+		td.CmpNoError(t, pl.PlayFrom(ctx, 1, 20*time.Second))
+		_ = pl.Pause(ctx)
+
+		remaining, err := pl.RemainingDuration(ctx)
+		td.CmpNoError(t, err)
+		td.Cmp(t, remaining, 2*time.Minute-pl.Elapsed(ctx))
+
+		before := time.Now()
+		end, err := pl.EstimatedEndTime(ctx)
+		td.CmpNoError(t, err)
+		td.Cmp(t, end, td.Between(before.Add(remaining), time.Now().Add(remaining)))
+	})
+
+	t.Run("interstitials", func(t *testing.T) {
+		announce := InterstitialFunc(func(_ context.Context, _, next Song) (Song, bool) {
+			return Song{Name: "далее " + next.Name, Duration: 10 * time.Millisecond}, true
+		})
+		short := append([]Song{{Name: "intro", Duration: 20 * time.Millisecond}}, songs...)
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithInterstitials(announce, 1, 0), WithSongs(short...))
+		_ = pl.Play(ctx)
+
+		waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		td.CmpNoError(t, pl.WaitFor(waitCtx, func(st Status) bool { return st.Song.Name == "a" }))
+		_ = pl.Pause(ctx)
+
+		remaining, _ := pl.RemainingDuration(ctx)
+		td.Cmp(t, remaining, 3*time.Minute+20*time.Millisecond-pl.Elapsed(ctx),
+			"вставки оцениваются по последней")
+	})
+
+	t.Run("endless", func(t *testing.T) {
+		pl, _ := New(WithSongs(songs[0], Song{Name: "radio", URL: "http://radio"}))
+		_, err := pl.TotalDuration(ctx)
+		td.CmpTrue(t, errors.Is(err, ErrEndless), "впереди поток")
+		_, err = pl.RemainingDuration(ctx)
+		td.CmpTrue(t, errors.Is(err, ErrEndless))
+
+		pl, _ = New(WithSongs(songs...))
+		td.CmpNoError(t, pl.SetLoopSection(ctx, 0, 10*time.Second))
+		_, err = pl.EstimatedEndTime(ctx)
+		td.CmpTrue(t, errors.Is(err, ErrEndless), "участок повторяется")
+
+		pl, _ = New(WithEdgeBehavior(EdgeWrap), WithSongs(songs...))
+		_, err = pl.RemainingDuration(ctx)
+		td.CmpTrue(t, errors.Is(err, ErrEndless), "плейлист повторяется")
+		_, err = pl.TotalDuration(ctx)
+		td.CmpNoError(t, err, "один проход конечен")
+	})
+}
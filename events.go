@@ -0,0 +1,454 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// EventKind - вид события шины Subscribe.
+type EventKind int
+
+const (
+	// EventTransition - сменилась текущая песня, Data - TrackTransition
+	EventTransition EventKind = iota
+	// EventSongFinished - песня доиграла или была пропущена, Data - HistoryEntry
+	EventSongFinished
+	// EventMilestone - текущая песня дошла до отметки прогресса, Data - MilestoneEvent
+	EventMilestone
+	// EventError - ошибка бэкенда вывода, Data - PlaybackError
+	EventError
+	// EventQuota - исчерпан лимит прослушивания, Data - QuotaExceeded
+	EventQuota
+	// EventProgress - позиция воспроизведения, Data - ProgressEvent.
+	// Приходит только подписчикам с SubscribeProgress.
+	EventProgress
+	// EventStoppedAfterCurrent - сработала остановка StopAfterCurrent
+	// или PauseAfterCurrent, Data - StoppedAfterCurrent
+	EventStoppedAfterCurrent
+	// EventDiagnostic - сторожевой таймер WithWatchdog нашёл неполадку, Data - Diagnostic
+	EventDiagnostic
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventTransition:
+		return "transition"
+	case EventSongFinished:
+		return "song_finished"
+	case EventMilestone:
+		return "milestone"
+	case EventError:
+		return "error"
+	case EventQuota:
+		return "quota"
+	case EventProgress:
+		return "progress"
+	case EventStoppedAfterCurrent:
+		return "stopped_after_current"
+	case EventDiagnostic:
+		return "diagnostic"
+	default:
+		return fmt.Sprintf("EventKind(%d)", int(k))
+	}
+}
+
+// Event - событие шины Subscribe.
+type Event struct {
+	// Kind - вид события
+	Kind EventKind
+	// Time - момент события
+	Time time.Time
+	// Data - данные события, тип зависит от Kind
+	Data any
+}
+
+// ProgressEvent - позиция воспроизведения текущей песни.
+type ProgressEvent struct {
+	// Song - песня
+	Song Song
+	// SongID - ID песни в плейлисте
+	SongID SongID
+	// Position - позиция воспроизведения
+	Position time.Duration
+	// State - состояние плеера в момент события
+	State State
+}
+
+// Backpressure - что делать с событием, когда подписчик не успевает их читать
+// и его буфер заполнен.
+type Backpressure int
+
+const (
+	// DropOldest - отбросить самое старое непрочитанное событие
+	DropOldest Backpressure = iota
+	// DropNewest - отбросить новое событие
+	DropNewest
+	// Block - ждать места в буфере не дольше SubscribeBlockTimeout, затем отбросить новое событие.
+	// Пока подписчик не читает, воспроизведение стоит.
+	Block
+	// CoalesceProgress - держать в буфере не больше одного события EventProgress,
+	// заменяя его свежим; для остальных событий - как DropOldest
+	CoalesceProgress
+)
+
+func (b Backpressure) String() string {
+	switch b {
+	case DropOldest:
+		return "drop_oldest"
+	case DropNewest:
+		return "drop_newest"
+	case Block:
+		return "block"
+	case CoalesceProgress:
+		return "coalesce_progress"
+	default:
+		return fmt.Sprintf("Backpressure(%d)", int(b))
+	}
+}
+
+const (
+	// defaultSubscribeBuffer - размер буфера подписчика, если SubscribeBuffer не задан
+	defaultSubscribeBuffer = 64
+	// defaultBlockTimeout - ожидание места в буфере для Block, если SubscribeBlockTimeout не задан
+	defaultBlockTimeout = 100 * time.Millisecond
+)
+
+// subscribeOptions - параметры Subscribe.
+type subscribeOptions struct {
+	buffer       int
+	backpressure Backpressure
+	blockTimeout time.Duration
+	// kinds - нужные виды событий, nil - все, кроме EventProgress без progress
+	kinds []EventKind
+	// progress - период EventProgress, 0 - не присылать
+	progress time.Duration
+}
+
+// SubscribeOption - параметр подписки.
+type SubscribeOption func(o *subscribeOptions)
+
+// SubscribeBuffer - сколько непрочитанных событий хранит подписка, по умолчанию 64.
+func SubscribeBuffer(n int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.buffer = n
+	}
+}
+
+// SubscribeBackpressure - поведение при заполненном буфере, по умолчанию DropOldest.
+func SubscribeBackpressure(b Backpressure) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.backpressure = b
+	}
+}
+
+// SubscribeBlockTimeout - сколько Block ждёт места в буфере, по умолчанию 100мс.
+func SubscribeBlockTimeout(d time.Duration) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.blockTimeout = d
+	}
+}
+
+// SubscribeKinds - присылает только события перечисленных видов.
+func SubscribeKinds(kinds ...EventKind) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.kinds = append([]EventKind(nil), kinds...)
+	}
+}
+
+// SubscribeProgress - присылает EventProgress с периодом interval, пока песня играет.
+func SubscribeProgress(interval time.Duration) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.progress = interval
+	}
+}
+
+// Subscription - подписка на события плеера.
+type Subscription struct {
+	// C - события по порядку. Закрывается, когда подписка завершена.
+	C <-chan Event
+
+	p    *playerImpl
+	opts subscribeOptions
+	c    chan Event
+
+	mu      sync.Mutex
+	queue   []Event
+	dropped int
+	// ready - в queue появилось событие
+	ready chan struct{}
+	// space - из queue забрали событие
+	space chan struct{}
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Subscribe - подписывает на события плеера. События доставляются через C в порядке
+// возникновения; если подписчик не успевает их читать, поведение задаёт
+// SubscribeBackpressure, а отброшенные события считает Dropped.
+// Подписка завершается по Close, отмене ctx или закрытию плеера.
+func (p *playerImpl) Subscribe(ctx context.Context, opts ...SubscribeOption) (*Subscription, error) {
+	o := subscribeOptions{buffer: defaultSubscribeBuffer, blockTimeout: defaultBlockTimeout}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	switch {
+	case o.buffer <= 0:
+		return nil, errors.New("subscription buffer must be positive")
+	case o.backpressure < DropOldest || o.backpressure > CoalesceProgress:
+		return nil, fmt.Errorf("unknown backpressure policy %v", o.backpressure)
+	case o.backpressure == Block && o.blockTimeout <= 0:
+		return nil, errors.New("block timeout must be positive")
+	case o.progress < 0:
+		return nil, errors.New("progress interval is negative")
+	}
+	for _, k := range o.kinds {
+		if k < EventTransition || k > EventDiagnostic {
+			return nil, fmt.Errorf("unknown event kind %v", k)
+		}
+	}
+
+	c := make(chan Event)
+	s := &Subscription{
+		C:     c,
+		p:     p,
+		opts:  o,
+		c:     c,
+		ready: make(chan struct{}, 1),
+		space: make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrClosed
+	}
+
+	p.subscribers = append(p.subscribers, s)
+	p.rescheduleLocked()
+
+	go s.pump()
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Close()
+		case <-s.done:
+		}
+	}()
+	if o.progress > 0 {
+		// первый тик отсчитывается от подписки
+		due, cancel := p.after(o.progress)
+		go s.progress(ctx, due, cancel)
+	}
+
+	return s, nil
+}
+
+// Dropped - сколько событий отброшено из-за того, что подписчик не успевал их читать.
+func (s *Subscription) Dropped() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.dropped
+}
+
+// Close - завершает подписку. C закрывается, непрочитанные события отбрасываются.
+func (s *Subscription) Close() {
+	if !s.stop() {
+		return
+	}
+
+	s.p.mu.Lock()
+	defer s.p.mu.Unlock()
+
+	s.p.subscribers = slices.DeleteFunc(s.p.subscribers, func(o *Subscription) bool { return o == s })
+	s.p.droppedEvents += s.Dropped()
+}
+
+// stop - завершает доставку, false если подписка уже завершена.
+func (s *Subscription) stop() bool {
+	stopped := false
+	s.closeOnce.Do(func() {
+		close(s.done)
+		stopped = true
+	})
+
+	return stopped
+}
+
+// wants - сообщает, нужны ли подписчику события вида kind.
+func (s *Subscription) wants(kind EventKind) bool {
+	if kind == EventProgress {
+		return s.opts.progress > 0
+	}
+
+	return s.opts.kinds == nil || slices.Contains(s.opts.kinds, kind)
+}
+
+// publish - ставит событие в очередь подписчика по его политике.
+// Для Block может ждать до blockTimeout.
+func (s *Subscription) publish(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.opts.backpressure == CoalesceProgress && e.Kind == EventProgress {
+		s.queue = slices.DeleteFunc(s.queue, func(q Event) bool { return q.Kind == EventProgress })
+	}
+
+	if len(s.queue) >= s.opts.buffer {
+		switch s.opts.backpressure {
+		case DropNewest:
+			s.dropped++
+			return
+		case Block:
+			if !s.waitSpace() {
+				s.dropped++
+				return
+			}
+		default:
+			s.queue = slices.Delete(s.queue, 0, 1)
+			s.dropped++
+		}
+	}
+
+	s.queue = append(s.queue, e)
+	signal(s.ready)
+}
+
+// waitSpace - ждёт места в очереди не дольше blockTimeout, false если не дождались.
+// Вызывается под s.mu и отпускает его на время ожидания.
+func (s *Subscription) waitSpace() bool {
+	timer := time.NewTimer(s.opts.blockTimeout)
+	defer timer.Stop()
+
+	for len(s.queue) >= s.opts.buffer {
+		s.mu.Unlock()
+		var stop bool
+		select {
+		case <-s.space:
+		case <-s.done:
+			stop = true
+		case <-timer.C:
+			stop = true
+		}
+		s.mu.Lock()
+
+		if stop {
+			return len(s.queue) < s.opts.buffer
+		}
+	}
+
+	return true
+}
+
+// pump - передаёт события из очереди в C, пока подписка не завершена.
+func (s *Subscription) pump() {
+	defer close(s.c)
+
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			s.mu.Unlock()
+			select {
+			case <-s.ready:
+				continue
+			case <-s.done:
+				return
+			}
+		}
+		e := s.queue[0]
+		s.queue = slices.Delete(s.queue, 0, 1)
+		s.mu.Unlock()
+		signal(s.space)
+
+		select {
+		case s.c <- e:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// progress - присылает EventProgress с периодом opts.progress по часам плеера,
+// пока песня играет. due и cancel - ожидание первого тика.
+func (s *Subscription) progress(ctx context.Context, due <-chan struct{}, cancel func()) {
+	for {
+		select {
+		case <-s.done:
+			cancel()
+			return
+		case <-due:
+		}
+
+		due, cancel = s.p.after(s.opts.progress)
+		if err := s.p.rlock(ctx); err != nil {
+			cancel()
+			return
+		}
+		if s.p.isPlaying && s.p.current != nil {
+			s.publish(Event{Kind: EventProgress, Time: s.p.now(), Data: ProgressEvent{
+				Song:     *s.p.current.song,
+				SongID:   s.p.current.id,
+				Position: s.p.elapsedLocked(),
+				State:    s.p.stateLocked(),
+			}})
+		}
+		s.p.mu.RUnlock()
+	}
+}
+
+// signal - неблокирующе сигналит в канал с буфером 1.
+func signal(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
+
+// subscribedLocked - сообщает, есть ли подписчики на события вида kind.
+// Вызывается под блокировкой.
+func (p *playerImpl) subscribedLocked(kind EventKind) bool {
+	return slices.ContainsFunc(p.subscribers, func(s *Subscription) bool { return s.wants(kind) })
+}
+
+// publishLocked - отправляет событие подписчикам. Подписчики с Block
+// могут задержать его до своего SubscribeBlockTimeout.
+// Вызывается под блокировкой.
+func (p *playerImpl) publishLocked(kind EventKind, data any) {
+	e := Event{Kind: kind, Time: p.now(), Data: data}
+	for _, s := range p.subscribers {
+		if s.wants(kind) {
+			s.publish(e)
+		}
+	}
+}
+
+// droppedEventsLocked - сколько событий отброшено подписчиками за всё время.
+// Вызывается под блокировкой.
+func (p *playerImpl) droppedEventsLocked() int {
+	n := p.droppedEvents
+	for _, s := range p.subscribers {
+		n += s.Dropped()
+	}
+
+	return n
+}
+
+// closeSubscribersLocked - завершает все подписки при закрытии плеера.
+// Вызывается под блокировкой.
+func (p *playerImpl) closeSubscribersLocked() {
+	for _, s := range p.subscribers {
+		s.stop()
+		p.droppedEvents += s.Dropped()
+	}
+	p.subscribers = nil
+}
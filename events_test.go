@@ -0,0 +1,199 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Subscribe(t *testing.T) {
+	ctx := context.Background()
+
+	// next - следующее событие подписки или провал теста через секунду.
+	next := func(t *testing.T, s *Subscription) Event {
+		t.Helper()
+		select {
+		case e, ok := <-s.C:
+			td.CmpTrue(t, ok, "подписка не закрыта")
+			return e
+		case <-time.After(time.Second):
+			t.Fatal("нет события")
+			return Event{}
+		}
+	}
+
+	// fill - отправляет подписке n событий о завершении песен с именами 0, 1, ...
+	fill := func(pl *playerImpl, n int) {
+		pl.mu.Lock()
+		defer pl.mu.Unlock()
+		for i := 0; i < n; i++ {
+			pl.publishLocked(EventSongFinished, HistoryEntry{Song: Song{Name: string(rune('0' + i))}})
+		}
+	}
+
+	// drain - имена песен из событий, накопившихся в подписке.
+	drain := func(t *testing.T, s *Subscription, n int) []string {
+		var got []string
+		for i := 0; i < n; i++ {
+			e := next(t, s)
+			got = append(got, e.Data.(HistoryEntry).Song.Name)
+		}
+		return got
+	}
+
+	t.Run("events", func(t *testing.T) {
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithMilestones(AtPercent(50)), WithSongs(
+			Song{Name: "a", Duration: 60 * time.Millisecond},
+			Song{Name: "b", Duration: time.Minute},
+		))
+		s, err := pl.Subscribe(ctx)
+		td.CmpNoError(t, err)
+
+		td.CmpNoError(t, pl.Play(ctx))
+
+		e := next(t, s)
+		td.Cmp(t, e.Kind, EventMilestone)
+		td.Cmp(t, e.Data.(MilestoneEvent).Song.Name, "a")
+
+		e = next(t, s)
+		td.Cmp(t, e.Kind, EventSongFinished)
+		td.Cmp(t, e.Data.(HistoryEntry).Completed, true)
+
+		e = next(t, s)
+		td.Cmp(t, e.Kind, EventTransition)
+		td.Cmp(t, e.Data.(TrackTransition).To.Name, "b")
+		td.Cmp(t, e.Kind.String(), "transition")
+
+		td.CmpNoError(t, pl.Close(ctx))
+		_, ok := <-s.C
+		td.CmpFalse(t, ok, "закрытие плеера закрывает подписку")
+	})
+
+	t.Run("kinds", func(t *testing.T) {
+		pl, _ := New(WithSongs(Song{Name: "a", Duration: time.Minute}, Song{Name: "b", Duration: time.Minute}))
+		s, err := pl.Subscribe(ctx, SubscribeKinds(EventTransition))
+		td.CmpNoError(t, err)
+
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpNoError(t, pl.Next(ctx))
+		td.Cmp(t, next(t, s).Kind, EventTransition, "без EventSongFinished")
+		td.CmpNoError(t, pl.Pause(ctx))
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		pl, _ := New()
+		s, _ := pl.Subscribe(ctx, SubscribeBuffer(2))
+		fill(pl, 5)
+
+		// одно событие может уже ждать отправки в C вне буфера
+		td.Cmp(t, s.Dropped(), td.Between(2, 3))
+		got := drain(t, s, 5-s.Dropped())
+		td.Cmp(t, got[len(got)-2:], []string{"3", "4"}, "остаются новые")
+	})
+
+	t.Run("drop newest", func(t *testing.T) {
+		pl, _ := New()
+		s, _ := pl.Subscribe(ctx, SubscribeBuffer(2), SubscribeBackpressure(DropNewest))
+		fill(pl, 5)
+
+		td.Cmp(t, s.Dropped(), td.Between(2, 3))
+		got := drain(t, s, 5-s.Dropped())
+		td.Cmp(t, got[:2], []string{"0", "1"}, "остаются старые")
+	})
+
+	t.Run("block", func(t *testing.T) {
+		pl, _ := New()
+		s, _ := pl.Subscribe(ctx, SubscribeBuffer(1), SubscribeBackpressure(Block), SubscribeBlockTimeout(20*time.Millisecond))
+
+		start := time.Now()
+		fill(pl, 4)
+		td.Cmp(t, time.Since(start), td.Gte(20*time.Millisecond), "ждёт подписчика")
+		td.Cmp(t, s.Dropped(), td.Between(1, 2))
+
+		// подписчик читает - место освобождается
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			drain(t, s, 4-s.Dropped())
+		}()
+		fill(pl, 2)
+		td.Cmp(t, s.Dropped(), td.Between(1, 2), "новые события дождались места")
+	})
+
+	t.Run("coalesce progress", func(t *testing.T) {
+		pl, _ := New(WithSongs(Song{Name: "a", Duration: time.Minute}))
+		s, err := pl.Subscribe(ctx, SubscribeBackpressure(CoalesceProgress), SubscribeProgress(5*time.Millisecond))
+		td.CmpNoError(t, err)
+
+		td.CmpNoError(t, pl.Play(ctx))
+		time.Sleep(60 * time.Millisecond)
+		td.CmpNoError(t, pl.Pause(ctx))
+
+		s.mu.Lock()
+		td.Cmp(t, len(s.queue), 1, "копится не больше одного события прогресса")
+		s.mu.Unlock()
+		td.Cmp(t, s.Dropped(), 0, "замена - не потеря")
+
+		e := next(t, s)
+		td.Cmp(t, e.Kind, EventProgress)
+		td.Cmp(t, e.Data.(ProgressEvent).Song.Name, "a")
+		td.Cmp(t, e.Data.(ProgressEvent).Position, td.Gt(time.Duration(0)))
+
+		s.Close()
+		for range s.C {
+		}
+		_, ok := <-s.C
+		td.CmpFalse(t, ok)
+	})
+
+	t.Run("progress clock", func(t *testing.T) {
+		clock := NewFakeClock(testStart)
+		pl, _ := New(WithClock(clock), WithSongs(minuteSong("a")))
+		s, err := pl.Subscribe(ctx, SubscribeProgress(time.Second))
+		td.CmpNoError(t, err)
+		defer s.Close()
+
+		td.CmpNoError(t, pl.Play(ctx))
+		defer pl.Pause(ctx)
+		for i := 1; i <= 2; i++ {
+			clock.Advance(time.Second)
+			e := next(t, s)
+			td.Cmp(t, e.Kind, EventProgress)
+			td.Cmp(t, e.Time, testStart.Add(time.Duration(i)*time.Second), "по часам плеера")
+			td.Cmp(t, e.Data.(ProgressEvent).Position, time.Duration(i)*time.Second)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		pl, _ := New()
+		cctx, cancel := context.WithCancel(ctx)
+		s, err := pl.Subscribe(cctx)
+		td.CmpNoError(t, err)
+
+		cancel()
+		_, ok := <-s.C
+		td.CmpFalse(t, ok)
+		td.CmpTrue(t, eventually(func() bool {
+			pl.mu.Lock()
+			defer pl.mu.Unlock()
+			return len(pl.subscribers) == 0
+		}))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		pl, _ := New()
+		_, err := pl.Subscribe(ctx, SubscribeBuffer(0))
+		td.CmpString(t, err, "subscription buffer must be positive")
+		_, err = pl.Subscribe(ctx, SubscribeBackpressure(Backpressure(9)))
+		td.CmpString(t, err, "unknown backpressure policy Backpressure(9)")
+		_, err = pl.Subscribe(ctx, SubscribeBackpressure(Block), SubscribeBlockTimeout(0))
+		td.CmpString(t, err, "block timeout must be positive")
+		_, err = pl.Subscribe(ctx, SubscribeKinds(EventKind(-1)))
+		td.CmpString(t, err, "unknown event kind EventKind(-1)")
+
+		td.CmpNoError(t, pl.Close(ctx))
+		_, err = pl.Subscribe(ctx)
+		td.Cmp(t, err, ErrClosed)
+	})
+}
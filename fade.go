@@ -0,0 +1,87 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// FadeOutput - бэкенд, поддерживающий плавное изменение усиления песни.
+// Start начинает песню с полным усилением.
+type FadeOutput interface {
+	// Ramp - выставляет усиление песни в from и плавно меняет его до to за время d.
+	// Усиление меняется от 0 до 1.
+	Ramp(ctx context.Context, song Song, from, to float64, d time.Duration) error
+}
+
+// envelope - длительности нарастания и затухания звука.
+type envelope struct {
+	in  time.Duration
+	out time.Duration
+}
+
+// WithFade - плавно нарастает звук в начале воспроизведения и затухает
+// в конце песни, при паузе и остановке. Работает с бэкендами, реализующими FadeOutput.
+func WithFade(in, out time.Duration) Option {
+	return func(p *playerImpl) error {
+		if in < 0 || out < 0 {
+			return errors.New("fade is negative")
+		}
+
+		p.envelope = envelope{in: in, out: out}
+		return nil
+	}
+}
+
+// rampLocked - отправляет бэкенду команду изменения усиления.
+// Ошибки логируются, так как вызывающая горутина не может их вернуть.
+// Вызывается под блокировкой.
+func (p *playerImpl) rampLocked(ctx context.Context, song Song, from, to float64, d time.Duration) {
+	fo, ok := p.output.(FadeOutput)
+	if !ok || d <= 0 {
+		return
+	}
+
+	if err := fo.Ramp(ctx, song, from, to, d); err != nil {
+		p.playbackErrorLocked(ctx, song, StageRamp, err)
+	}
+}
+
+// fadeInLocked - нарастание звука только что начатой песни.
+// Вызывается под блокировкой.
+func (p *playerImpl) fadeInLocked(ctx context.Context, song Song) {
+	p.rampLocked(ctx, song, 0, 1, p.envelope.in)
+}
+
+// canFadeOutLocked - сообщает, нужно ли плавно затухать при остановке.
+// Вызывается под блокировкой.
+func (p *playerImpl) canFadeOutLocked() bool {
+	_, ok := p.output.(FadeOutput)
+	return ok && p.envelope.out > 0
+}
+
+// envelopeOutLocked - длительность затухания в конце текущей песни.
+// Вызывается под блокировкой.
+func (p *playerImpl) envelopeOutLocked() time.Duration {
+	return min(p.envelope.out, p.current.song.playLength())
+}
+
+// endFadeDueLocked - сообщает, что в конце текущей песни ещё предстоит затухание.
+// При наложении песен и с WithTransition затухание выполняет сам переход.
+// Вызывается под блокировкой.
+func (p *playerImpl) endFadeDueLocked() bool {
+	return p.transition == nil && p.canFadeOutLocked() && !p.inGap && !p.current.song.IsStream() && p.rampedOut != p.current && p.crossfadeLocked() == 0
+}
+
+// untilEndFadeLocked - возвращает время до начала затухания в конце текущей песни.
+// Вызывается под блокировкой.
+func (p *playerImpl) untilEndFadeLocked() time.Duration {
+	return p.current.song.End() - p.envelopeOutLocked() - p.elapsedLocked()
+}
+
+// endFadeLocked - начинает затухание в конце текущей песни.
+// Вызывается под блокировкой.
+func (p *playerImpl) endFadeLocked(ctx context.Context) {
+	p.rampedOut = p.current
+	p.rampLocked(ctx, *p.current.song, 1, 0, p.envelopeOutLocked())
+}
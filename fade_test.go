@@ -0,0 +1,91 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// fadeOutput - бэкенд, запоминающий вызовы вместе с изменениями усиления.
+type fadeOutput struct {
+	recordingOutput
+}
+
+func (o *fadeOutput) Ramp(_ context.Context, song Song, from, to float64, d time.Duration) error {
+	o.record(fmt.Sprintf("ramp %s %g->%g %v", song.Name, from, to, d))
+	return nil
+}
+
+func TestWithFade(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := New(WithFade(-time.Second, 0))
+	td.CmpError(t, err)
+
+	t.Run("play, end and pause", func(t *testing.T) {
+		out := &fadeOutput{}
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithOutput(out), WithFade(10*time.Millisecond, 20*time.Millisecond), WithSongs(
+			Song{Name: "a", Duration: 50 * time.Millisecond},
+			Song{Name: "b", Duration: 30 * time.Second},
+		))
+
+		_ = pl.Play(ctx)
+		time.Sleep(70 * time.Millisecond)
+		td.Cmp(t, out.Calls(), []string{
+			"start a",
+			"ramp a 0->1 10ms",
+			"ramp a 1->0 20ms",
+			"stop a",
+			"start b",
+			"ramp b 0->1 10ms",
+		}, "затухание начинается до конца песни")
+
+		_ = pl.Pause(ctx)
+		td.Cmp(t, out.Calls()[6:], []string{"ramp b 1->0 20ms"}, "остановка после затухания")
+
+		time.Sleep(30 * time.Millisecond)
+		td.Cmp(t, out.Calls()[6:], []string{"ramp b 1->0 20ms", "stop b"})
+	})
+
+	t.Run("resume while fading", func(t *testing.T) {
+		out := &fadeOutput{}
+		pl, _ := New(WithOutput(out), WithFade(0, time.Second), WithSongs(Song{Name: "a", Duration: 30 * time.Second}))
+
+		_ = pl.Play(ctx)
+		_ = pl.Pause(ctx)
+		_ = pl.Play(ctx)
+		td.Cmp(t, out.Calls(), []string{"start a", "ramp a 1->0 1s", "stop a", "start a"})
+		_ = pl.Pause(ctx)
+	})
+
+	t.Run("crossfade ramps both songs", func(t *testing.T) {
+		out := &fadeOutput{}
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithOutput(out), WithCrossfade(20*time.Millisecond), WithSongs(
+			Song{Name: "a", Duration: 30 * time.Millisecond},
+			Song{Name: "b", Duration: 30 * time.Second},
+		))
+
+		_ = pl.Play(ctx)
+		time.Sleep(50 * time.Millisecond)
+		td.Cmp(t, out.Calls(), []string{
+			"start a",
+			"start b",
+			"ramp b 0->1 20ms",
+			"ramp a 1->0 20ms",
+			"stop a",
+		})
+		_ = pl.Pause(ctx)
+	})
+
+	t.Run("without fade output", func(t *testing.T) {
+		out := &recordingOutput{}
+		pl, _ := New(WithOutput(out), WithFade(time.Second, time.Second), WithSongs(Song{Name: "a", Duration: 30 * time.Second}))
+
+		_ = pl.Play(ctx)
+		_ = pl.Pause(ctx)
+		td.Cmp(t, out.Calls(), []string{"start a", "stop a"}, "остановка сразу")
+	})
+}
@@ -0,0 +1,207 @@
+package player
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileStorage - встроенное хранилище в каталоге на диске без внешних зависимостей.
+// Состояние и плейлисты хранятся в JSON-файлах, которые заменяются атомарно,
+// история - в журнале, куда записи только дописываются по одной JSON-строке.
+type FileStorage struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewFileStorage - открывает хранилище в каталоге dir, создавая его при необходимости.
+func NewFileStorage(dir string) (*FileStorage, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "playlists"), 0o755); err != nil {
+		return nil, fmt.Errorf("create storage dir: %v", err)
+	}
+
+	return &FileStorage{dir: dir}, nil
+}
+
+func (s *FileStorage) SavePlaylist(_ context.Context, name string, songs []Song) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.writeJSON(s.playlistPath(name), songs)
+}
+
+func (s *FileStorage) LoadPlaylist(_ context.Context, name string) ([]Song, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var songs []Song
+	err := s.readJSON(s.playlistPath(name), &songs)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrPlaylistNotFound
+	}
+
+	return songs, err
+}
+
+func (s *FileStorage) AppendHistory(_ context.Context, entry HistoryEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshal history entry: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.historyPath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func (s *FileStorage) LoadHistory(_ context.Context) ([]HistoryEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.historyPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []HistoryEntry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for line := 1; sc.Scan(); line++ {
+		var e HistoryEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			// последняя запись могла быть оборвана при падении процесса
+			if !sc.Scan() {
+				break
+			}
+			return nil, fmt.Errorf("history line %d: %v", line, err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, sc.Err()
+}
+
+func (s *FileStorage) SaveState(_ context.Context, state PlayerState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.writeJSON(s.statePath(), state)
+}
+
+func (s *FileStorage) LoadState(_ context.Context) (PlayerState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var state PlayerState
+	err := s.readJSON(s.statePath(), &state)
+	if errors.Is(err, fs.ErrNotExist) {
+		return PlayerState{}, ErrStateNotFound
+	}
+
+	return state, err
+}
+
+func (s *FileStorage) SaveZoneState(_ context.Context, zone string, state PlayerState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.zoneStatePath(zone)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return s.writeJSON(path, state)
+}
+
+func (s *FileStorage) LoadZoneState(_ context.Context, zone string) (PlayerState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var state PlayerState
+	err := s.readJSON(s.zoneStatePath(zone), &state)
+	if errors.Is(err, fs.ErrNotExist) {
+		return PlayerState{}, ErrStateNotFound
+	}
+
+	return state, err
+}
+
+func (s *FileStorage) statePath() string {
+	return filepath.Join(s.dir, "state.json")
+}
+
+func (s *FileStorage) historyPath() string {
+	return filepath.Join(s.dir, "history.jsonl")
+}
+
+func (s *FileStorage) zoneStatePath(zone string) string {
+	return filepath.Join(s.dir, "zones", url.PathEscape(zone)+".json")
+}
+
+func (s *FileStorage) playlistPath(name string) string {
+	return filepath.Join(s.dir, "playlists", url.PathEscape(name)+".json")
+}
+
+// writeJSON - атомарно заменяет файл path на v в формате JSON.
+func (s *FileStorage) writeJSON(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal %s: %v", filepath.Base(path), err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// readJSON - читает файл path в v.
+func (s *FileStorage) readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("unmarshal %s: %v", filepath.Base(path), err)
+	}
+
+	return nil
+}
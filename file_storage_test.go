@@ -0,0 +1,90 @@
+package player
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestFileStorage(t *testing.T) {
+	ctx := context.Background()
+	song := Song{Name: "Михаил Шуфутинский - 3 сентября", Duration: 3 * time.Minute}
+
+	dir := t.TempDir()
+	s, err := NewFileStorage(dir)
+	td.CmpNoError(t, err)
+
+	t.Run("empty", func(t *testing.T) {
+		_, err := s.LoadState(ctx)
+		td.Cmp(t, err, ErrStateNotFound)
+
+		_, err = s.LoadPlaylist(ctx, "рок/поп")
+		td.Cmp(t, err, ErrPlaylistNotFound)
+
+		history, err := s.LoadHistory(ctx)
+		td.CmpNoError(t, err)
+		td.CmpEmpty(t, history)
+	})
+
+	t.Run("playlist with unsafe name", func(t *testing.T) {
+		td.CmpNoError(t, s.SavePlaylist(ctx, "рок/поп", []Song{song}))
+
+		songs, err := s.LoadPlaylist(ctx, "рок/поп")
+		td.CmpNoError(t, err)
+		td.Cmp(t, songs, []Song{song})
+	})
+
+	t.Run("truncated history", func(t *testing.T) {
+		entry := HistoryEntry{Song: song, Played: time.Minute}
+		td.CmpNoError(t, s.AppendHistory(ctx, entry))
+
+		f, err := os.OpenFile(filepath.Join(dir, "history.jsonl"), os.O_APPEND|os.O_WRONLY, 0)
+		td.CmpNoError(t, err)
+		_, _ = f.WriteString(`{"song":{"na`)
+		_ = f.Close()
+
+		history, err := s.LoadHistory(ctx)
+		td.CmpNoError(t, err, "оборванная последняя запись пропускается")
+		td.Cmp(t, history, td.Len(1))
+		td.Cmp(t, history[0].Played, time.Minute)
+	})
+
+	t.Run("state", func(t *testing.T) {
+		state := PlayerState{Active: DefaultPlaylist, Playlists: []PlaylistState{{Name: DefaultPlaylist, Songs: []Song{song}}}}
+		td.CmpNoError(t, s.SaveState(ctx, state))
+
+		s, err := NewFileStorage(dir)
+		td.CmpNoError(t, err)
+		got, err := s.LoadState(ctx)
+		td.CmpNoError(t, err, "состояние читается после переоткрытия")
+		td.Cmp(t, got, state)
+
+		entries, err := os.ReadDir(dir)
+		td.CmpNoError(t, err)
+		for _, e := range entries {
+			td.CmpFalse(t, strings.HasPrefix(e.Name(), ".tmp-"), "временные файлы удалены")
+		}
+	})
+
+	t.Run("zones", func(t *testing.T) {
+		_, err := s.LoadZoneState(ctx, "кухня")
+		td.Cmp(t, err, ErrStateNotFound)
+
+		state := PlayerState{Active: "кухня", IsPlaying: true}
+		td.CmpNoError(t, s.SaveZoneState(ctx, "кухня", state))
+		got, err := s.LoadZoneState(ctx, "кухня")
+		td.CmpNoError(t, err)
+		td.Cmp(t, got, state)
+	})
+
+	t.Run("corrupted state", func(t *testing.T) {
+		td.CmpNoError(t, os.WriteFile(filepath.Join(dir, "state.json"), []byte("{"), 0o644))
+		_, err := s.LoadState(ctx)
+		td.CmpContains(t, err, "unmarshal state.json")
+	})
+}
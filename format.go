@@ -0,0 +1,536 @@
+package player
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnknownFormat - формат плейлиста не поддерживается.
+var ErrUnknownFormat = errors.New("unknown playlist format")
+
+// PlaylistFormat - формат файла плейлиста для Export и Import.
+type PlaylistFormat int
+
+const (
+	// FormatM3U - расширенный M3U с #EXTINF, #EXTART и #EXTALB
+	FormatM3U PlaylistFormat = iota
+	// FormatXSPF - XML Shareable Playlist Format
+	FormatXSPF
+	// FormatPLS - PLS версии 2
+	FormatPLS
+	// FormatCUE - CUE sheet: альбом одним файлом с дорожками Song.Cues
+	FormatCUE
+)
+
+func (f PlaylistFormat) String() string {
+	switch f {
+	case FormatM3U:
+		return "m3u"
+	case FormatXSPF:
+		return "xspf"
+	case FormatPLS:
+		return "pls"
+	case FormatCUE:
+		return "cue"
+	default:
+		return fmt.Sprintf("PlaylistFormat(%d)", int(f))
+	}
+}
+
+// Export - записывает активный плейлист в w в формате format.
+func (p *playerImpl) Export(ctx context.Context, w io.Writer, format PlaylistFormat) error {
+	if err := p.rlock(ctx); err != nil {
+		return err
+	}
+	songs := make([]Song, 0, p.songs.Len())
+	for n := p.first(); n != nil; n = n.next() {
+		songs = append(songs, *n.song)
+	}
+	p.mu.RUnlock()
+
+	if err := WritePlaylist(w, format, songs); err != nil {
+		return err
+	}
+
+	p.logger.DebugContext(ctx, "playlist exported", slog.String("format", format.String()), slog.Int("songs", len(songs)))
+	return nil
+}
+
+// Import - читает плейлист из r в формате format и добавляет его песни
+// в конец активного плейлиста, как AddSongs. Возвращает количество добавленных
+// песен и ошибки песен, не прошедших проверку.
+func (p *playerImpl) Import(ctx context.Context, r io.Reader, format PlaylistFormat) (int, error) {
+	songs, err := ReadPlaylist(r, format)
+	if err != nil {
+		return 0, err
+	}
+
+	return p.addImported(ctx, songs, format.String())
+}
+
+// addImported - добавляет импортированные песни в конец активного плейлиста.
+// Возвращает количество добавленных песен и ошибки остальных.
+func (p *playerImpl) addImported(ctx context.Context, songs []Song, source string) (int, error) {
+	var errs []error
+	for i, err := range p.AddSongs(ctx, songs...) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("entry %d: %w", i+1, err))
+		}
+	}
+
+	added := len(songs) - len(errs)
+	p.logger.InfoContext(ctx, "playlist imported", slog.String("source", source), slog.Int("added", added), slog.Int("failed", len(errs)))
+	return added, errors.Join(errs...)
+}
+
+// WritePlaylist - записывает песни в w в формате format.
+// XSPF и PLS сохраняют все поля песни, кроме дорожек CUE, M3U - всё, кроме
+// громкости, глав и обрезки, CUE - только адрес, исполнителя и дорожки.
+// В M3U и PLS длительность округляется до секунд, в XSPF - до миллисекунд.
+// Для песни без адреса вместо адреса записывается её название.
+func WritePlaylist(w io.Writer, format PlaylistFormat, songs []Song) error {
+	bw := bufio.NewWriter(w)
+
+	var err error
+	switch format {
+	case FormatM3U:
+		writeM3U(bw, songs)
+	case FormatXSPF:
+		err = writeXSPF(bw, songs)
+	case FormatPLS:
+		writePLS(bw, songs)
+	case FormatCUE:
+		writeCue(bw, songs)
+	default:
+		return fmt.Errorf("%w: %v", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return fmt.Errorf("write %v: %v", format, err)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("write %v: %v", format, err)
+	}
+	return nil
+}
+
+// ReadPlaylist - читает песни из r в формате format.
+// Песни не проверяются, это делает Import.
+func ReadPlaylist(r io.Reader, format PlaylistFormat) ([]Song, error) {
+	var (
+		songs []Song
+		err   error
+	)
+	switch format {
+	case FormatM3U:
+		songs, err = readM3U(r)
+	case FormatXSPF:
+		songs, err = readXSPF(r)
+	case FormatPLS:
+		songs, err = readPLS(r)
+	case FormatCUE:
+		songs, err = readCue(r)
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %v: %v", format, err)
+	}
+
+	return songs, nil
+}
+
+// location - адрес песни для записи в плейлист.
+func location(song Song) string {
+	if song.URL != "" {
+		return song.URL
+	}
+
+	return song.Name
+}
+
+// fromLocation - восстанавливает адрес песни, записанный location.
+func fromLocation(song *Song, loc string) {
+	if loc != song.Name {
+		song.URL = loc
+	}
+}
+
+// lengthSeconds - длительность в секундах для M3U и PLS, -1 для потока.
+func lengthSeconds(song Song) int64 {
+	if song.IsStream() {
+		return -1
+	}
+
+	return int64(math.Round(song.Duration.Seconds()))
+}
+
+// nameFromLocation - название песни без метаданных, по имени файла.
+func nameFromLocation(loc string) string {
+	base := path.Base(loc)
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
+// writeM3U - записывает расширенный M3U.
+func writeM3U(w *bufio.Writer, songs []Song) {
+	w.WriteString("#EXTM3U\n")
+	for _, s := range songs {
+		fmt.Fprintf(w, "#EXTINF:%d,%s\n", lengthSeconds(s), s.Name)
+		if s.Artist != "" {
+			fmt.Fprintf(w, "#EXTART:%s\n", s.Artist)
+		}
+		if s.Album != "" {
+			fmt.Fprintf(w, "#EXTALB:%s\n", s.Album)
+		}
+		fmt.Fprintf(w, "%s\n", location(s))
+	}
+}
+
+// readM3U - читает расширенный M3U. Запись без #EXTINF считается
+// потоком с названием по имени файла.
+func readM3U(r io.Reader) ([]Song, error) {
+	var (
+		songs []Song
+		info  Song
+	)
+
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+
+		switch {
+		case text == "" || text == "#EXTM3U":
+		case strings.HasPrefix(text, "#EXTINF:"):
+			secs, name, ok := strings.Cut(strings.TrimPrefix(text, "#EXTINF:"), ",")
+			if !ok {
+				return nil, fmt.Errorf("line %d: malformed EXTINF", line)
+			}
+
+			n, err := strconv.Atoi(strings.TrimSpace(secs))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: parse duration: %v", line, err)
+			}
+			info.Name, info.Duration = strings.TrimSpace(name), time.Duration(max(n, 0))*time.Second
+		case strings.HasPrefix(text, "#EXTART:"):
+			info.Artist = strings.TrimPrefix(text, "#EXTART:")
+		case strings.HasPrefix(text, "#EXTALB:"):
+			info.Album = strings.TrimPrefix(text, "#EXTALB:")
+		case strings.HasPrefix(text, "#"):
+		default:
+			if info.Name == "" {
+				info.Name = nameFromLocation(text)
+			}
+			fromLocation(&info, text)
+			songs = append(songs, info)
+			info = Song{}
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	return songs, nil
+}
+
+// xspfApplication - идентификатор плеера в расширениях XSPF.
+const xspfApplication = "https://github.com/default23/go-ll-player"
+
+// meta плеера: громкость песни в LUFS и обрезка тишины в миллисекундах.
+const (
+	xspfLoudnessRel = xspfApplication + "#loudness_lufs"
+	xspfLeadInRel   = xspfApplication + "#lead_in"
+	xspfLeadOutRel  = xspfApplication + "#lead_out"
+)
+
+type xspfPlaylist struct {
+	XMLName xml.Name    `xml:"http://xspf.org/ns/0/ playlist"`
+	Version string      `xml:"version,attr"`
+	Tracks  []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location  string         `xml:"location,omitempty"`
+	Title     string         `xml:"title"`
+	Creator   string         `xml:"creator,omitempty"`
+	Album     string         `xml:"album,omitempty"`
+	Duration  int64          `xml:"duration,omitempty"`
+	Meta      []xspfMeta     `xml:"meta"`
+	Extension *xspfExtension `xml:"extension"`
+}
+
+type xspfMeta struct {
+	Rel   string `xml:"rel,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xspfExtension struct {
+	Application string        `xml:"application,attr"`
+	Chapters    []xspfChapter `xml:"chapter"`
+}
+
+type xspfChapter struct {
+	// Start - начало главы в миллисекундах
+	Start int64  `xml:"start,attr"`
+	Title string `xml:",chardata"`
+}
+
+// writeXSPF - записывает XSPF. Громкость хранится в meta, главы - в extension плеера.
+func writeXSPF(w *bufio.Writer, songs []Song) error {
+	pl := xspfPlaylist{Version: "1", Tracks: make([]xspfTrack, 0, len(songs))}
+	for _, s := range songs {
+		t := xspfTrack{
+			Location: location(s),
+			Title:    s.Name,
+			Creator:  s.Artist,
+			Album:    s.Album,
+			Duration: s.Duration.Milliseconds(),
+		}
+		if s.LoudnessLUFS != 0 {
+			t.Meta = append(t.Meta, xspfMeta{Rel: xspfLoudnessRel, Value: strconv.FormatFloat(s.LoudnessLUFS, 'g', -1, 64)})
+		}
+		if s.LeadIn != 0 {
+			t.Meta = append(t.Meta, xspfMeta{Rel: xspfLeadInRel, Value: strconv.FormatInt(s.LeadIn.Milliseconds(), 10)})
+		}
+		if s.LeadOut != 0 {
+			t.Meta = append(t.Meta, xspfMeta{Rel: xspfLeadOutRel, Value: strconv.FormatInt(s.LeadOut.Milliseconds(), 10)})
+		}
+		if len(s.Chapters) > 0 {
+			t.Extension = &xspfExtension{Application: xspfApplication}
+			for _, c := range s.Chapters {
+				t.Extension.Chapters = append(t.Extension.Chapters, xspfChapter{Start: c.Start.Milliseconds(), Title: c.Title})
+			}
+		}
+		pl.Tracks = append(pl.Tracks, t)
+	}
+
+	w.WriteString(xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(pl); err != nil {
+		return err
+	}
+
+	_, err := w.WriteString("\n")
+	return err
+}
+
+// readXSPF - читает XSPF, неизвестные meta и расширения пропускаются.
+func readXSPF(r io.Reader) ([]Song, error) {
+	var pl xspfPlaylist
+	if err := xml.NewDecoder(r).Decode(&pl); err != nil {
+		return nil, err
+	}
+
+	songs := make([]Song, 0, len(pl.Tracks))
+	for i, t := range pl.Tracks {
+		s := Song{
+			Name:     t.Title,
+			Artist:   t.Creator,
+			Album:    t.Album,
+			Duration: time.Duration(t.Duration) * time.Millisecond,
+		}
+		if s.Name == "" {
+			s.Name = nameFromLocation(t.Location)
+		}
+		fromLocation(&s, t.Location)
+
+		for _, m := range t.Meta {
+			value := strings.TrimSpace(m.Value)
+			switch m.Rel {
+			case xspfLoudnessRel:
+				v, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return nil, fmt.Errorf("track %d: parse loudness: %v", i+1, err)
+				}
+				s.LoudnessLUFS = v
+			case xspfLeadInRel, xspfLeadOutRel:
+				ms, err := strconv.ParseInt(value, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("track %d: parse trim: %v", i+1, err)
+				}
+				if m.Rel == xspfLeadInRel {
+					s.LeadIn = time.Duration(ms) * time.Millisecond
+				} else {
+					s.LeadOut = time.Duration(ms) * time.Millisecond
+				}
+			}
+		}
+
+		if ext := t.Extension; ext != nil && ext.Application == xspfApplication {
+			for _, c := range ext.Chapters {
+				s.Chapters = append(s.Chapters, Chapter{Title: c.Title, Start: time.Duration(c.Start) * time.Millisecond})
+			}
+		}
+
+		songs = append(songs, s)
+	}
+
+	return songs, nil
+}
+
+// writePLS - записывает PLS. Кроме стандартных File, Title и Length записываются
+// ArtistN, AlbumN, LoudnessN, обрезка LeadInN и LeadOutN в миллисекундах
+// и главы ChapterN_M=начало в миллисекундах,название.
+func writePLS(w *bufio.Writer, songs []Song) {
+	w.WriteString("[playlist]\n")
+	for i, s := range songs {
+		n := i + 1
+		fmt.Fprintf(w, "File%d=%s\n", n, location(s))
+		fmt.Fprintf(w, "Title%d=%s\n", n, s.Name)
+		fmt.Fprintf(w, "Length%d=%d\n", n, lengthSeconds(s))
+		if s.Artist != "" {
+			fmt.Fprintf(w, "Artist%d=%s\n", n, s.Artist)
+		}
+		if s.Album != "" {
+			fmt.Fprintf(w, "Album%d=%s\n", n, s.Album)
+		}
+		if s.LoudnessLUFS != 0 {
+			fmt.Fprintf(w, "Loudness%d=%s\n", n, strconv.FormatFloat(s.LoudnessLUFS, 'g', -1, 64))
+		}
+		if s.LeadIn != 0 {
+			fmt.Fprintf(w, "LeadIn%d=%d\n", n, s.LeadIn.Milliseconds())
+		}
+		if s.LeadOut != 0 {
+			fmt.Fprintf(w, "LeadOut%d=%d\n", n, s.LeadOut.Milliseconds())
+		}
+		for j, c := range s.Chapters {
+			fmt.Fprintf(w, "Chapter%d_%d=%d,%s\n", n, j+1, c.Start.Milliseconds(), c.Title)
+		}
+	}
+	fmt.Fprintf(w, "NumberOfEntries=%d\nVersion=2\n", len(songs))
+}
+
+// readPLS - читает PLS, записанный writePLS или другим плеером.
+func readPLS(r io.Reader) ([]Song, error) {
+	type entry struct {
+		song Song
+		file string
+		// chapters - главы по номеру
+		chapters map[int]Chapter
+	}
+	entries := make(map[int]*entry)
+	last := 0
+
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "[") || strings.HasPrefix(text, ";") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: malformed entry", line)
+		}
+
+		name, n, sub := splitPLSKey(key)
+		if n == 0 {
+			// NumberOfEntries, Version
+			continue
+		}
+
+		e := entries[n]
+		if e == nil {
+			e = &entry{}
+			entries[n] = e
+		}
+		last = max(last, n)
+
+		switch strings.ToLower(name) {
+		case "file":
+			e.file = value
+		case "title":
+			e.song.Name = value
+		case "length":
+			secs, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: parse length: %v", line, err)
+			}
+			e.song.Duration = time.Duration(max(secs, 0)) * time.Second
+		case "artist":
+			e.song.Artist = value
+		case "album":
+			e.song.Album = value
+		case "loudness":
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: parse loudness: %v", line, err)
+			}
+			e.song.LoudnessLUFS = v
+		case "leadin", "leadout":
+			ms, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: parse trim: %v", line, err)
+			}
+			if strings.EqualFold(name, "leadin") {
+				e.song.LeadIn = time.Duration(ms) * time.Millisecond
+			} else {
+				e.song.LeadOut = time.Duration(ms) * time.Millisecond
+			}
+		case "chapter":
+			ms, title, _ := strings.Cut(value, ",")
+			start, err := strconv.ParseInt(ms, 10, 64)
+			if err != nil || sub == 0 {
+				return nil, fmt.Errorf("line %d: malformed chapter", line)
+			}
+			if e.chapters == nil {
+				e.chapters = make(map[int]Chapter)
+			}
+			e.chapters[sub] = Chapter{Title: title, Start: time.Duration(start) * time.Millisecond}
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	songs := make([]Song, 0, len(entries))
+	for n := 1; n <= last; n++ {
+		e := entries[n]
+		if e == nil || e.file == "" {
+			continue
+		}
+
+		if e.song.Name == "" {
+			e.song.Name = nameFromLocation(e.file)
+		}
+		fromLocation(&e.song, e.file)
+		nums := make([]int, 0, len(e.chapters))
+		for j := range e.chapters {
+			nums = append(nums, j)
+		}
+		slices.Sort(nums)
+		for _, j := range nums {
+			e.song.Chapters = append(e.song.Chapters, e.chapters[j])
+		}
+		songs = append(songs, e.song)
+	}
+
+	return songs, nil
+}
+
+// splitPLSKey - разбирает ключ PLS вида Title3 или Chapter3_2
+// на имя, номер записи и номер внутри записи. Для ключей без номера n равен 0.
+func splitPLSKey(key string) (name string, n, sub int) {
+	i := len(key)
+	for i > 0 && (key[i-1] >= '0' && key[i-1] <= '9' || key[i-1] == '_') {
+		i--
+	}
+
+	num, subNum, _ := strings.Cut(key[i:], "_")
+	n, _ = strconv.Atoi(num)
+	sub, _ = strconv.Atoi(subNum)
+	return key[:i], n, sub
+}
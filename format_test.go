@@ -0,0 +1,109 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlaylistFormats(t *testing.T) {
+	songs := []Song{
+		{
+			Name: "a", Artist: "artist", Album: "album", Duration: 3 * time.Minute,
+			URL: "/music/a.mp3", LoudnessLUFS: -9.5, LeadIn: 2 * time.Second, LeadOut: 5 * time.Second,
+			Chapters: []Chapter{{Title: "intro", Start: 0}, {Title: "drop", Start: 90 * time.Second}},
+		},
+		{Name: "b", Duration: 2 * time.Minute},
+		{Name: "radio", URL: "http://radio.example/stream"},
+	}
+
+	for _, format := range []PlaylistFormat{FormatXSPF, FormatPLS} {
+		t.Run(format.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			td.CmpNoError(t, WritePlaylist(&buf, format, songs))
+
+			got, err := ReadPlaylist(&buf, format)
+			td.CmpNoError(t, err)
+			td.Cmp(t, got, songs, "все поля сохраняются")
+		})
+	}
+
+	t.Run("m3u", func(t *testing.T) {
+		var buf bytes.Buffer
+		td.CmpNoError(t, WritePlaylist(&buf, FormatM3U, songs))
+		td.Cmp(t, buf.String(), `#EXTM3U
+#EXTINF:180,a
+#EXTART:artist
+#EXTALB:album
+/music/a.mp3
+#EXTINF:120,b
+b
+#EXTINF:-1,radio
+http://radio.example/stream
+`)
+
+		got, err := ReadPlaylist(&buf, FormatM3U)
+		td.CmpNoError(t, err)
+		want := append([]Song(nil), songs...)
+		want[0].LoudnessLUFS, want[0].Chapters, want[0].LeadIn, want[0].LeadOut = 0, nil, 0, 0
+		td.Cmp(t, got, want, "без громкости, глав и обрезки")
+	})
+
+	t.Run("foreign pls", func(t *testing.T) {
+		got, err := ReadPlaylist(strings.NewReader(`[playlist]
+File1=http://radio.example/jazz
+Title1=Jazz
+Length1=-1
+File2=/music/song.ogg
+Length2=200
+NumberOfEntries=2
+Version=2
+`), FormatPLS)
+		td.CmpNoError(t, err)
+		td.Cmp(t, got, []Song{
+			{Name: "Jazz", URL: "http://radio.example/jazz"},
+			{Name: "song", URL: "/music/song.ogg", Duration: 200 * time.Second},
+		})
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		td.CmpTrue(t, errors.Is(WritePlaylist(&bytes.Buffer{}, PlaylistFormat(9), songs), ErrUnknownFormat))
+		_, err := ReadPlaylist(strings.NewReader(""), PlaylistFormat(9))
+		td.CmpTrue(t, errors.Is(err, ErrUnknownFormat))
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := ReadPlaylist(strings.NewReader("<playlist"), FormatXSPF)
+		td.CmpError(t, err)
+		_, err = ReadPlaylist(strings.NewReader("File1=a\nLength1=x\n"), FormatPLS)
+		td.CmpError(t, err)
+	})
+}
+
+func TestPlayerImpl_ExportImport(t *testing.T) {
+	ctx := context.Background()
+	src, _ := New(WithSongs(
+		Song{Name: "a", Artist: "artist", Duration: time.Minute},
+		Song{Name: "b", Duration: 2 * time.Minute},
+	))
+
+	var buf bytes.Buffer
+	td.CmpNoError(t, src.Export(ctx, &buf, FormatXSPF))
+	td.CmpContains(t, buf.String(), `<playlist xmlns="http://xspf.org/ns/0/" version="1">`)
+
+	dst, _ := New()
+	added, err := dst.Import(ctx, &buf, FormatXSPF)
+	td.CmpNoError(t, err)
+	td.Cmp(t, added, 2)
+	td.Cmp(t, names(dst), []string{"a", "b"})
+	td.Cmp(t, dst.first().song, src.first().song, "метаданные сохраняются")
+
+	added, err = dst.Import(ctx, strings.NewReader("#EXTINF:0,short\nshort\n"), FormatM3U)
+	td.Cmp(t, added, 0)
+	td.CmpTrue(t, errors.Is(err, ErrInvalidSong), "песни проверяются")
+}
@@ -1,6 +1,6 @@
 module player
 
-go 1.19
+go 1.21
 
 require github.com/maxatome/go-testdeep v1.12.0
 
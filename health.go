@@ -0,0 +1,252 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+)
+
+const (
+	// healthTimeout - сколько проверка ждёт плеер, бэкенд и хранилище,
+	// если ctx не ограничивает время раньше
+	healthTimeout = time.Second
+	// playbackGrace - насколько горутина воспроизведения может опоздать
+	// к своему шагу, прежде чем считаться зависшей
+	playbackGrace = 5 * time.Second
+)
+
+// PingableOutput - бэкенд, который умеет сообщать, что отвечает. Readyz
+// проверяет только такие бэкенды, остальные считаются отвечающими.
+type PingableOutput interface {
+	// Ping - возвращает ошибку, если бэкенд не готов воспроизводить
+	Ping(ctx context.Context) error
+}
+
+// HealthCheck - результат одной проверки.
+type HealthCheck struct {
+	// Name - что проверялось: player, playback, backend, subscribers или storage
+	Name string `json:"name"`
+	// OK - проверка пройдена
+	OK bool `json:"ok"`
+	// Error - причина, если проверка не пройдена
+	Error string `json:"error,omitempty"`
+}
+
+// HealthReport - результат Healthz или Readyz.
+type HealthReport struct {
+	// OK - все проверки пройдены
+	OK bool `json:"ok"`
+	// Checks - проверки по порядку
+	Checks []HealthCheck `json:"checks"`
+}
+
+// Diagnostic - данные EventDiagnostic: сторожевой таймер нашёл неполадку.
+type Diagnostic struct {
+	// Check - что проверялось, как в HealthCheck
+	Check string
+	// Error - найденная неполадка
+	Error string
+	// Restarted - горутина воспроизведения перезапущена
+	Restarted bool
+}
+
+// healthState - снимок состояния плеера для проверок, снятый под блокировкой.
+type healthState struct {
+	closed   bool
+	playback error
+	stalled  int
+	output   Output
+	storage  Storage
+}
+
+// Healthz - проверка живости: плеер отвечает, а горутина воспроизведения,
+// если песня играет, не опаздывает к своему шагу.
+func (p *playerImpl) Healthz(ctx context.Context) HealthReport {
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+
+	st, err := p.healthState(ctx)
+	return newHealthReport(liveChecks(st, err))
+}
+
+// Readyz - проверка готовности: вдобавок к Healthz бэкенд отвечает на Ping,
+// подписчики успевают забирать события, а хранилище читается.
+func (p *playerImpl) Readyz(ctx context.Context) HealthReport {
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+
+	st, err := p.healthState(ctx)
+	checks := liveChecks(st, err)
+	if err == nil && st.closed {
+		err = ErrClosed
+	}
+
+	backend, subscribers, storage := err, err, err
+	if err == nil {
+		if o, ok := st.output.(PingableOutput); ok {
+			backend = o.Ping(ctx)
+		}
+		if st.stalled > 0 {
+			subscribers = fmt.Errorf("%d subscribers are not draining events", st.stalled)
+		}
+		if _, err := st.storage.LoadState(ctx); err != nil && !errors.Is(err, ErrStateNotFound) {
+			storage = err
+		}
+	}
+
+	checks = append(checks,
+		healthCheck("backend", backend),
+		healthCheck("subscribers", subscribers),
+		healthCheck("storage", storage),
+	)
+	return newHealthReport(checks)
+}
+
+// healthState - снимает состояние плеера. Если блокировку не удалось
+// получить до завершения ctx, плеер считается не отвечающим.
+func (p *playerImpl) healthState(ctx context.Context) (healthState, error) {
+	if err := p.rlock(ctx); err != nil {
+		return healthState{}, errors.New("player is unresponsive")
+	}
+	defer p.mu.RUnlock()
+
+	return healthState{
+		closed:   p.closed,
+		playback: p.playbackHealthLocked(),
+		stalled:  p.stalledSubscribersLocked(),
+		output:   p.output,
+		storage:  p.storage,
+	}, nil
+}
+
+// liveChecks - проверки Healthz по снимку st, err - снимок не получен.
+func liveChecks(st healthState, err error) []HealthCheck {
+	player, playback := err, err
+	if err == nil {
+		playback = st.playback
+		if st.closed {
+			player = ErrClosed
+		}
+	}
+
+	return []HealthCheck{healthCheck("player", player), healthCheck("playback", playback)}
+}
+
+// healthCheck - результат проверки name с ошибкой err.
+func healthCheck(name string, err error) HealthCheck {
+	if err != nil {
+		return HealthCheck{Name: name, Error: err.Error()}
+	}
+
+	return HealthCheck{Name: name, OK: true}
+}
+
+// newHealthReport - сводит проверки в отчёт.
+func newHealthReport(checks []HealthCheck) HealthReport {
+	r := HealthReport{OK: true, Checks: checks}
+	for _, c := range checks {
+		r.OK = r.OK && c.OK
+	}
+
+	return r
+}
+
+// playbackHealthLocked - ошибка, если горутина воспроизведения опаздывает
+// к своему шагу дольше playbackGrace.
+// Вызывается под блокировкой.
+func (p *playerImpl) playbackHealthLocked() error {
+	if p.stopCh == nil {
+		return nil
+	}
+
+	deadline := p.loopDeadline.Load()
+	now := p.now().UnixNano()
+	if now <= deadline || time.Duration(now-deadline) <= playbackGrace {
+		return nil
+	}
+
+	return fmt.Errorf("playback goroutine is %v late", time.Duration(now-deadline))
+}
+
+// stalledSubscribersLocked - сколько подписчиков не забирают события: их очередь полна.
+// Вызывается под блокировкой.
+func (p *playerImpl) stalledSubscribersLocked() int {
+	n := 0
+	for _, s := range p.subscribers {
+		s.mu.Lock()
+		if len(s.queue) >= s.opts.buffer {
+			n++
+		}
+		s.mu.Unlock()
+	}
+
+	return n
+}
+
+// beat - отмечает, что горутина воспроизведения проснётся через wait.
+// Безопасно вызывать под блокировкой на чтение.
+func (p *playerImpl) beat(wait time.Duration) {
+	now := p.now().UnixNano()
+	wait = max(wait, 0)
+	if int64(wait) > math.MaxInt64-now {
+		p.loopDeadline.Store(math.MaxInt64)
+		return
+	}
+
+	p.loopDeadline.Store(now + int64(wait))
+}
+
+// WithWatchdog - раз в interval проверяет горутину воспроизведения и перезапускает её,
+// если она опаздывает к своему шагу дольше 5 секунд, с событием EventDiagnostic.
+// Если горутина зависла, удерживая блокировку плеера, перезапустить её нельзя -
+// такое видно по Healthz.
+func WithWatchdog(interval time.Duration) Option {
+	return func(p *playerImpl) error {
+		if interval <= 0 {
+			return errors.New("watchdog interval must be positive")
+		}
+
+		p.watchdog = interval
+		return nil
+	}
+}
+
+// watch - горутина сторожевого таймера, due - первое срабатывание.
+func (p *playerImpl) watch(due <-chan struct{}, cancel func()) {
+	for {
+		select {
+		case <-p.done:
+			cancel()
+			return
+		case <-due:
+		}
+
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return
+		}
+		if err := p.playbackHealthLocked(); err != nil {
+			p.restartLoopLocked(err)
+		}
+		due, cancel = p.after(p.watchdog)
+		p.mu.Unlock()
+	}
+}
+
+// restartLoopLocked - заменяет зависшую горутину воспроизведения новой.
+// Вызывается под блокировкой.
+func (p *playerImpl) restartLoopLocked(cause error) {
+	close(p.stopCh)
+	stop := make(chan struct{})
+	p.stopCh = stop
+	p.beat(p.untilStepLocked())
+
+	p.logger.ErrorContext(p.loopCtx, "playback goroutine restarted", songAttr(*p.current.song), slog.Any("error", cause))
+	p.publishLocked(EventDiagnostic, Diagnostic{Check: "playback", Error: cause.Error(), Restarted: true})
+
+	go p.loop(p.loopCtx, stop)
+}
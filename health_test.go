@@ -0,0 +1,177 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// pingOutput - бэкенд, отвечающий на Ping ошибкой err.
+type pingOutput struct {
+	nopOutput
+	err error
+}
+
+func (o pingOutput) Ping(context.Context) error { return o.err }
+
+// brokenStorage - хранилище, чтение состояния из которого возвращает err.
+type brokenStorage struct {
+	*MemoryStorage
+	err error
+}
+
+func (s *brokenStorage) LoadState(ctx context.Context) (PlayerState, error) {
+	if s.err != nil {
+		return PlayerState{}, s.err
+	}
+	return s.MemoryStorage.LoadState(ctx)
+}
+
+func TestPlayerImpl_Health(t *testing.T) {
+	ctx := context.Background()
+
+	// failed - непройденные проверки отчёта с причинами.
+	failed := func(r HealthReport) map[string]string {
+		m := map[string]string{}
+		for _, c := range r.Checks {
+			if !c.OK {
+				m[c.Name] = c.Error
+			}
+		}
+		return m
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSong("a")))
+		td.CmpNoError(t, pl.Play(ctx))
+		_, err := pl.SimulatePlayback(ctx, 30*time.Second)
+		td.CmpNoError(t, err)
+
+		td.Cmp(t, pl.Healthz(ctx), HealthReport{OK: true, Checks: []HealthCheck{
+			{Name: "player", OK: true},
+			{Name: "playback", OK: true},
+		}})
+		td.Cmp(t, pl.Readyz(ctx), HealthReport{OK: true, Checks: []HealthCheck{
+			{Name: "player", OK: true},
+			{Name: "playback", OK: true},
+			{Name: "backend", OK: true},
+			{Name: "subscribers", OK: true},
+			{Name: "storage", OK: true},
+		}})
+
+		td.CmpNoError(t, pl.Close(ctx))
+		td.Cmp(t, failed(pl.Healthz(ctx)), map[string]string{"player": "player is closed"})
+	})
+
+	t.Run("readiness", func(t *testing.T) {
+		storage := &brokenStorage{MemoryStorage: NewMemoryStorage()}
+		pl, err := New(WithOutput(pingOutput{err: errors.New("device unplugged")}), WithStorage(storage))
+		td.CmpNoError(t, err)
+		storage.err = errors.New("disk is gone")
+
+		s, err := pl.Subscribe(ctx, SubscribeBuffer(1))
+		td.CmpNoError(t, err)
+		defer s.Close()
+		// publish - публикует событие, которое никто не читает.
+		publish := func() {
+			pl.mu.Lock()
+			pl.publishLocked(EventQuota, nil)
+			pl.mu.Unlock()
+		}
+		// queued - сколько событий ждёт в очереди подписчика.
+		queued := func() int {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return len(s.queue)
+		}
+
+		publish()
+		// первое событие забирает горутина доставки, она ждёт читателя
+		for queued() > 0 {
+			time.Sleep(time.Millisecond)
+		}
+		publish()
+
+		td.CmpTrue(t, pl.Healthz(ctx).OK, "живость не зависит от готовности")
+		r := pl.Readyz(ctx)
+		td.CmpFalse(t, r.OK)
+		td.Cmp(t, failed(r), map[string]string{
+			"backend":     "device unplugged",
+			"subscribers": "1 subscribers are not draining events",
+			"storage":     "disk is gone",
+		})
+	})
+
+	t.Run("unresponsive", func(t *testing.T) {
+		pl, err := New()
+		td.CmpNoError(t, err)
+
+		pl.mu.Lock()
+		tctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		r := pl.Healthz(tctx)
+		cancel()
+		pl.mu.Unlock()
+
+		td.Cmp(t, failed(r), map[string]string{"player": "player is unresponsive", "playback": "player is unresponsive"})
+	})
+
+	t.Run("watchdog", func(t *testing.T) {
+		clock := NewFakeClock(testStart)
+		pl, err := New(WithClock(clock), WithWatchdog(time.Second), WithSongs(minuteSongs("a", "b")...))
+		td.CmpNoError(t, err)
+		defer pl.Close(ctx)
+
+		s, err := pl.Subscribe(ctx, SubscribeKinds(EventDiagnostic))
+		td.CmpNoError(t, err)
+		defer s.Close()
+
+		td.CmpNoError(t, pl.Play(ctx))
+
+		// горутина воспроизведения завершилась, не остановив воспроизведение
+		pl.mu.Lock()
+		old := pl.stopCh
+		pl.stopCh = make(chan struct{})
+		close(old)
+		pl.loopDeadline.Store(testStart.UnixNano())
+		pl.mu.Unlock()
+
+		clock.Advance(10 * time.Second)
+		select {
+		case e := <-s.C:
+			td.Cmp(t, e.Data, Diagnostic{Check: "playback", Error: "playback goroutine is 10s late", Restarted: true})
+		case <-time.After(time.Second):
+			t.Fatal("нет события диагностики")
+		}
+
+		td.CmpTrue(t, pl.Healthz(ctx).OK, "горутина перезапущена")
+		_, err = pl.SimulatePlayback(ctx, 50*time.Second)
+		td.CmpNoError(t, err)
+		td.Cmp(t, pl.Status(ctx).Song.Name, "b", "воспроизведение продолжается")
+
+		_, err = New(WithWatchdog(0))
+		td.CmpString(t, err, "watchdog interval must be positive")
+	})
+
+	t.Run("http", func(t *testing.T) {
+		storage := &brokenStorage{MemoryStorage: NewMemoryStorage()}
+		pl, err := New(WithStorage(storage))
+		td.CmpNoError(t, err)
+		h, err := pl.HTTPHandler(WithAuth(TokenAuth(nil)))
+		td.CmpNoError(t, err)
+
+		w := serve(h, http.MethodGet, "/healthz", "", "")
+		td.Cmp(t, w.Code, http.StatusOK, "без аутентификации")
+		td.CmpJSON(t, decode(t, w), `{"ok": true, "checks": [{"name": "player", "ok": true}, {"name": "playback", "ok": true}]}`, nil)
+
+		storage.err = errors.New("disk is gone")
+		w = serve(h, http.MethodGet, "/readyz", "", "")
+		td.Cmp(t, w.Code, http.StatusServiceUnavailable)
+		td.CmpJSON(t, decode(t, w), `{"ok": false, "checks": Len(5)}`, nil)
+
+		td.Cmp(t, serve(h, http.MethodPost, "/healthz", "", "").Code, http.StatusUnauthorized, "только GET")
+	})
+}
@@ -0,0 +1,63 @@
+package player
+
+import (
+	"context"
+	"time"
+)
+
+// historySize - максимальное количество записей в истории.
+const historySize = 100
+
+// HistoryEntry - запись истории воспроизведения.
+type HistoryEntry struct {
+	// Song - песня
+	Song Song
+	// FinishedAt - когда песня доиграла или была пропущена
+	FinishedAt time.Time
+	// Played - сколько песня играла
+	Played time.Duration
+	// Completed - песня доиграла до конца, а не была пропущена
+	Completed bool
+}
+
+// History - возвращает последние limit записей истории, начиная с самой свежей.
+// Если limit <= 0, возвращается вся история.
+func (p *playerImpl) History(ctx context.Context, limit int) ([]HistoryEntry, error) {
+	if err := p.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer p.mu.RUnlock()
+
+	if limit <= 0 || limit > len(p.history) {
+		limit = len(p.history)
+	}
+
+	entries := make([]HistoryEntry, 0, limit)
+	for i := len(p.history) - 1; i >= len(p.history)-limit; i-- {
+		entries = append(entries, p.history[i])
+	}
+
+	return entries, nil
+}
+
+// ClearHistory - очищает историю воспроизведения.
+func (p *playerImpl) ClearHistory(ctx context.Context) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	p.history = nil
+	return nil
+}
+
+// recordHistoryLocked - добавляет запись в историю, вытесняя самые старые.
+// Вызывается под блокировкой.
+func (p *playerImpl) recordHistoryLocked(entry HistoryEntry) {
+	if len(p.history) == historySize {
+		copy(p.history, p.history[1:])
+		p.history = p.history[:historySize-1]
+	}
+
+	p.history = append(p.history, entry)
+}
@@ -0,0 +1,72 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_History(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("completed and skipped", func(t *testing.T) {
+		sg := Song{Name: "Сектор Газа - 30 лет", Duration: 50 * time.Millisecond}
+		ap := Song{Name: "Александр Пушной - Почему я идиот?", Duration: 30 * time.Second}
+		shuff := Song{Name: "Михаил Шуфутинский - 3 сентября", Duration: 30 * time.Second}
+
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithSongs(sg, ap, shuff))
+		_ = pl.Play(ctx)
+		time.Sleep(70 * time.Millisecond)
+		_ = pl.Next(ctx)
+		_ = pl.Pause(ctx)
+
+		td.Cmp(t, history(t, pl, 0), td.Slice([]HistoryEntry{}, td.ArrayEntries{
+			0: td.Struct(HistoryEntry{Song: ap, Completed: false}, td.StructFields{
+				"FinishedAt": td.NotZero(),
+				"Played":     td.Gte(15 * time.Millisecond),
+			}),
+			1: td.Struct(HistoryEntry{Song: sg, Played: sg.Duration, Completed: true}, td.StructFields{
+				"FinishedAt": td.NotZero(),
+			}),
+		}))
+		td.Cmp(t, history(t, pl, 1), td.Len(1), "ограничение по количеству")
+
+		td.CmpNoError(t, pl.ClearHistory(ctx))
+		td.CmpEmpty(t, history(t, pl, 0), "история очищена")
+	})
+
+	t.Run("not played song is not skipped", func(t *testing.T) {
+		pl, _ := NewPlayer(
+			Song{Name: "1", Duration: time.Second},
+			Song{Name: "2", Duration: time.Second},
+		)
+		_ = pl.Next(ctx)
+		_ = pl.Pause(ctx)
+
+		td.CmpEmpty(t, history(t, pl, 0))
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		pl, _ := NewPlayer()
+		for i := 0; i < historySize+10; i++ {
+			pl.recordHistoryLocked(HistoryEntry{Song: Song{Name: fmt.Sprintf("%d", i)}})
+		}
+
+		entries := history(t, pl, 0)
+		td.Cmp(t, entries, td.Len(historySize))
+		td.Cmp(t, entries[0].Song.Name, fmt.Sprintf("%d", historySize+9), "самая свежая запись первая")
+		td.Cmp(t, entries[historySize-1].Song.Name, "10", "самые старые записи вытеснены")
+	})
+}
+
+// history - записи History без ошибки.
+func history(t *testing.T, pl *playerImpl, limit int) []HistoryEntry {
+	t.Helper()
+
+	entries, err := pl.History(context.Background(), limit)
+	td.CmpNoError(t, err)
+	return entries
+}
@@ -0,0 +1,142 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+// SongHook - обработчик окончания песни.
+// completed - песня доиграла до конца, а не была пропущена.
+type SongHook func(song Song, completed bool)
+
+// OnSongFinished - регистрирует обработчик, который вызывается,
+// когда любая песня доиграла или была пропущена.
+// Обработчики вызываются по порядку в отдельной горутине.
+func (p *playerImpl) OnSongFinished(ctx context.Context, hook SongHook) error {
+	if hook == nil {
+		return errors.New("hook is nil")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	p.hooks = append(p.hooks, hook)
+	return nil
+}
+
+// AddSongWithHook - добавляет в конец плейлиста песню с собственным обработчиком окончания
+// и возвращает её ID.
+func (p *playerImpl) AddSongWithHook(ctx context.Context, song Song, hook SongHook) (SongID, error) {
+	if hook == nil {
+		return 0, errors.New("hook is nil")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return 0, err
+	}
+	if p.closed {
+		p.mu.Unlock()
+		return 0, ErrClosed
+	}
+	if err := p.admitLocked(ctx, p.active, song); err != nil {
+		p.mu.Unlock()
+		return 0, err
+	}
+	node := p.newNode(song, hook)
+	p.appendNode(node)
+	p.recordEditLocked(p.addEdit(node))
+	p.auditLocked(ctx, AuditEntry{Op: AuditAdd, Song: &song, Detail: p.active})
+	p.rescheduleLocked()
+	active := p.active
+	p.mu.Unlock()
+
+	p.logger.DebugContext(ctx, "song added", songAttr(song), slog.String("playlist", active))
+	return node.id, nil
+}
+
+// runHooksLocked - ставит в очередь обработчики окончания песни node.
+// Вызывается под блокировкой.
+func (p *playerImpl) runHooksLocked(node *playerNode, completed bool) {
+	if node.hook == nil && len(p.hooks) == 0 {
+		return
+	}
+
+	song := *node.song
+	hooks := make([]SongHook, 0, len(p.hooks)+1)
+	if node.hook != nil {
+		hooks = append(hooks, node.hook)
+	}
+	hooks = append(hooks, p.hooks...)
+
+	p.hookQueue.push(func() {
+		for _, h := range hooks {
+			h(song, completed)
+		}
+	})
+}
+
+// hookQueue - очередь функций, выполняемых по порядку в отдельной горутине.
+// Горутина запускается при появлении задач и завершается, когда очередь пуста.
+type hookQueue struct {
+	mu      sync.Mutex
+	pending []func()
+	running bool
+	// idle - закрывается, когда горутина опустошила очередь
+	idle chan struct{}
+}
+
+func (q *hookQueue) push(f func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pending = append(q.pending, f)
+	if !q.running {
+		q.running = true
+		q.idle = make(chan struct{})
+		go q.run()
+	}
+}
+
+func (q *hookQueue) run() {
+	for {
+		q.mu.Lock()
+		if len(q.pending) == 0 {
+			q.running = false
+			close(q.idle)
+			q.mu.Unlock()
+			return
+		}
+
+		f := q.pending[0]
+		q.pending = q.pending[1:]
+		q.mu.Unlock()
+
+		f()
+	}
+}
+
+// wait - дожидается выполнения всех поставленных в очередь функций.
+func (q *hookQueue) wait(ctx context.Context) error {
+	q.mu.Lock()
+	running, idle := q.running, q.idle
+	q.mu.Unlock()
+
+	if !running {
+		return nil
+	}
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,57 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Hooks(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	record := func(prefix string) SongHook {
+		return func(song Song, completed bool) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			calls = append(calls, fmt.Sprintf("%s %s %t", prefix, song.Name, completed))
+		}
+	}
+
+	pl, _ := New(WithValidator(ValidationPolicy{}), WithSongs(Song{Name: "a", Duration: 20 * time.Millisecond}))
+	td.CmpError(t, pl.OnSongFinished(ctx, nil))
+	_, err := pl.AddSongWithHook(ctx, Song{Name: "b"}, nil)
+	td.CmpError(t, err)
+
+	td.CmpNoError(t, pl.OnSongFinished(ctx, record("global")))
+	_, err = pl.AddSongWithHook(ctx, Song{Name: "b", Duration: 30 * time.Second}, record("song"))
+	td.CmpNoError(t, err)
+	_, _ = pl.AddSong(ctx, Song{Name: "c", Duration: 30 * time.Second})
+
+	// обработчик может обращаться к плееру
+	td.CmpNoError(t, pl.OnSongFinished(ctx, func(Song, bool) {
+		_, _ = pl.History(ctx, 1)
+	}))
+
+	_ = pl.Play(ctx)
+	time.Sleep(30 * time.Millisecond)
+	_ = pl.Next(ctx)
+	_ = pl.Pause(ctx)
+	time.Sleep(10 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	td.Cmp(t, calls, []string{
+		"global a true",
+		"song b false",
+		"global b false",
+	})
+}
@@ -0,0 +1,190 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// ErrSongNotFound - песни с таким ID нет в активном плейлисте.
+var ErrSongNotFound = errors.New("song not found")
+
+// SongID - уникальный ID песни в плейлисте.
+// Одна и та же песня, добавленная дважды, получает два разных ID.
+type SongID uint64
+
+// lastSongID - последний выданный ID песни.
+var lastSongID atomic.Uint64
+
+// newSongID - выдаёт новый ID песни.
+func newSongID() SongID {
+	return SongID(lastSongID.Add(1))
+}
+
+// find - ищет узел по ID песни.
+func (pl *playlist) find(id SongID) *playerNode {
+	return pl.byID[id]
+}
+
+// unlink - исключает узел из списка, курсор не трогает.
+func (pl *playlist) unlink(node *playerNode) {
+	pl.songs.Remove(node.elem)
+	node.elem = nil
+	delete(pl.byID, node.id)
+	pl.total -= node.counted
+}
+
+// insertAt - вставляет узел на позицию index.
+// Если index за пределами списка, узел добавляется в конец.
+func (pl *playlist) insertAt(node *playerNode, index int) {
+	if index >= pl.songs.Len() {
+		pl.appendNode(node)
+		return
+	}
+
+	pl.index(node)
+	node.elem = pl.songs.Insert(index, node)
+}
+
+// RemoveSong - удаляет песню из активного плейлиста.
+// Если удаляется текущая песня, курсор переходит на следующую,
+// а при воспроизведении она сразу начинает играть.
+func (p *playerImpl) RemoveSong(ctx context.Context, id SongID) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	node := p.find(id)
+	if node == nil {
+		return ErrSongNotFound
+	}
+
+	p.recordEditLocked(p.removeEdit(node))
+	song := *node.song
+	p.auditLocked(ctx, AuditEntry{Op: AuditRemove, Song: &song})
+	return p.removeLocked(ctx, node)
+}
+
+// removeLocked - удаляет узел из активного плейлиста.
+// Вызывается под блокировкой.
+func (p *playerImpl) removeLocked(ctx context.Context, node *playerNode) error {
+	replacement := node.next()
+	if replacement == nil {
+		replacement = node.prev()
+	}
+
+	if p.prepared == node {
+		p.prepared = nil
+	}
+	if p.section != nil && p.section.node == node {
+		p.section = nil
+	}
+	if in := p.interruption; in != nil && in.node == node {
+		p.retargetInterruptionLocked(replacement)
+	}
+
+	var err error
+	if p.current == node {
+		playing := p.isPlaying
+		p.skipLocked()
+		p.haltLocked(ctx)
+		p.unlink(node)
+		p.moveToLocked(replacement)
+
+		if playing {
+			err = p.playLocked(ctx)
+		}
+	} else {
+		p.unlink(node)
+		p.rescheduleLocked()
+	}
+
+	p.logger.DebugContext(ctx, "song removed", songAttr(*node.song), slog.String("playlist", p.active))
+	return err
+}
+
+// MoveSong - переставляет песню активного плейлиста на позицию index, считая с нуля.
+// Если index за пределами плейлиста, песня переносится в конец.
+// Воспроизведение не прерывается.
+func (p *playerImpl) MoveSong(ctx context.Context, id SongID, index int) error {
+	if index < 0 {
+		return errors.New("index is negative")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	node := p.find(id)
+	if node == nil {
+		return ErrSongNotFound
+	}
+
+	song := *node.song
+	p.auditLocked(ctx, AuditEntry{Op: AuditMove, Song: &song, Detail: strconv.Itoa(index)})
+	p.recordEditLocked(p.moveEdit(node, index))
+	p.moveNodeLocked(node, index)
+	return nil
+}
+
+// PlayByID - начинает играть песню активного плейлиста с начала.
+func (p *playerImpl) PlayByID(ctx context.Context, id SongID) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	node := p.find(id)
+	if node == nil {
+		return ErrSongNotFound
+	}
+
+	song := *node.song
+	p.auditLocked(ctx, AuditEntry{Op: AuditPlayByID, Song: &song, Detail: strconv.FormatUint(uint64(id), 10)})
+	return p.playNodeLocked(ctx, node, 0)
+}
+
+// playNodeLocked - начинает играть узел активного плейлиста с позиции offset.
+// Вызывается под блокировкой.
+func (p *playerImpl) playNodeLocked(ctx context.Context, node *playerNode, offset time.Duration) error {
+	p.skipLocked()
+	p.haltLocked(ctx)
+	p.section = nil
+	p.cancelInterruptionLocked()
+	p.moveToLocked(node)
+	p.playedTime = offset
+
+	return p.playLocked(ctx)
+}
+
+// retargetInterruptionLocked - после удаления прерванной песни
+// вставка продолжится песней node с начала.
+// Вызывается под блокировкой.
+func (p *playerImpl) retargetInterruptionLocked(node *playerNode) {
+	in := p.interruption
+	if node == nil {
+		in.jingle.around = nil
+		p.interruption = nil
+		return
+	}
+
+	in.node, in.position = node, 0
+	in.jingle.around = node
+}
@@ -0,0 +1,119 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// names - возвращает названия песен активного плейлиста по порядку.
+func names(pl *playerImpl) []string {
+	var res []string
+	for n := pl.first(); n != nil; n = n.next() {
+		res = append(res, n.song.Name)
+	}
+
+	return res
+}
+
+// testStart - время, которое показывают FakeClock в тестах.
+var testStart = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// minuteSong - песня name длительностью в минуту.
+func minuteSong(name string) Song {
+	return Song{Name: name, Duration: time.Minute}
+}
+
+// minuteSongs - песни names длительностью в минуту.
+func minuteSongs(names ...string) []Song {
+	songs := make([]Song, len(names))
+	for i, name := range names {
+		songs[i] = minuteSong(name)
+	}
+
+	return songs
+}
+
+// titles - названия песен по порядку.
+func titles(songs []Song) []string {
+	var res []string
+	for _, s := range songs {
+		res = append(res, s.Name)
+	}
+
+	return res
+}
+
+// newFakePlayer - плеер на FakeClock, показывающих testStart, с настройками opts.
+func newFakePlayer(t *testing.T, opts ...Option) *playerImpl {
+	t.Helper()
+
+	pl, err := New(append([]Option{WithClock(NewFakeClock(testStart))}, opts...)...)
+	td.CmpNoError(t, err)
+	return pl
+}
+
+func TestPlayerImpl_SongIDs(t *testing.T) {
+	ctx := context.Background()
+	song := Song{Name: "Сектор Газа - 30 лет", Duration: 30 * time.Second}
+
+	t.Run("duplicates get own ids", func(t *testing.T) {
+		pl, _ := NewPlayer()
+		first, _ := pl.AddSong(ctx, song)
+		second, _ := pl.AddSong(ctx, song)
+		td.Cmp(t, first, td.Not(second), "одинаковые песни различаются по ID")
+
+		td.CmpNoError(t, pl.PlayByID(ctx, second))
+		td.Cmp(t, pl.Status(ctx).SongID, second, "играет вторая копия")
+		td.Cmp(t, pl.current, pl.last())
+
+		td.CmpNoError(t, pl.RemoveSong(ctx, first))
+		td.Cmp(t, pl.first(), pl.last(), "удалена только первая копия")
+		td.Cmp(t, pl.first().id, second)
+		td.CmpTrue(t, pl.isPlaying, "текущая песня продолжает играть")
+		_ = pl.Pause(ctx)
+
+		td.Cmp(t, pl.RemoveSong(ctx, first), ErrSongNotFound)
+		td.Cmp(t, pl.PlayByID(ctx, first), ErrSongNotFound)
+		td.Cmp(t, pl.MoveSong(ctx, first, 0), ErrSongNotFound)
+	})
+
+	t.Run("remove current", func(t *testing.T) {
+		out := &recordingOutput{}
+		pl, _ := New(WithOutput(out))
+		a, _ := pl.AddSong(ctx, Song{Name: "a", Duration: 30 * time.Second})
+		b, _ := pl.AddSong(ctx, Song{Name: "b", Duration: 30 * time.Second})
+
+		_ = pl.Play(ctx)
+		td.CmpNoError(t, pl.RemoveSong(ctx, a))
+		td.Cmp(t, pl.Status(ctx).SongID, b, "курсор перешёл на следующую песню")
+		td.CmpTrue(t, pl.isPlaying, "воспроизведение продолжается")
+
+		td.CmpNoError(t, pl.RemoveSong(ctx, b))
+		td.CmpNil(t, pl.current, "плейлист пуст")
+		td.CmpFalse(t, pl.isPlaying)
+		td.Cmp(t, out.Calls(), []string{"start a", "stop a", "start b", "stop b"})
+		td.Cmp(t, history(t, pl, 0), td.Len(2), "удалённые песни пропущены")
+	})
+
+	t.Run("move", func(t *testing.T) {
+		pl, _ := NewPlayer(minuteSongs("a", "b", "c")...)
+		c := pl.last().id
+		a := pl.first().id
+
+		td.CmpNoError(t, pl.MoveSong(ctx, c, 0))
+		td.Cmp(t, names(pl), []string{"c", "a", "b"})
+
+		td.CmpNoError(t, pl.MoveSong(ctx, a, 100))
+		td.Cmp(t, names(pl), []string{"c", "b", "a"})
+		td.Cmp(t, pl.last().prev().prev(), pl.first(), "обратные ссылки согласованы")
+
+		td.CmpNoError(t, pl.MoveSong(ctx, a, 1))
+		td.Cmp(t, names(pl), []string{"c", "a", "b"})
+		td.Cmp(t, pl.current.id, a, "курсор остался на той же песне")
+
+		td.CmpError(t, pl.MoveSong(ctx, a, -1))
+	})
+}
@@ -0,0 +1,112 @@
+package player
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Importer - переводит плейлист другого сервиса в песни.
+type Importer interface {
+	// Import - читает плейлист из r
+	Import(ctx context.Context, r io.Reader) ([]Song, error)
+}
+
+// ImporterFunc - функция, удовлетворяющая интерфейсу Importer.
+type ImporterFunc func(ctx context.Context, r io.Reader) ([]Song, error)
+
+func (f ImporterFunc) Import(ctx context.Context, r io.Reader) ([]Song, error) {
+	return f(ctx, r)
+}
+
+// ImportFrom - читает плейлист из r через imp и добавляет его песни
+// в конец активного плейлиста, как Import.
+func (p *playerImpl) ImportFrom(ctx context.Context, r io.Reader, imp Importer) (int, error) {
+	if imp == nil {
+		return 0, errors.New("importer is nil")
+	}
+
+	songs, err := imp.Import(ctx, r)
+	if err != nil {
+		return 0, fmt.Errorf("import: %w", err)
+	}
+
+	return p.addImported(ctx, songs, fmt.Sprintf("%T", imp))
+}
+
+// SpotifyImporter - Importer для ответов Spotify Web API: объекта плейлиста
+// GET /v1/playlists/{id} или страницы его треков GET /v1/playlists/{id}/tracks.
+// Адресом песни становится URI Spotify, удалённые и недоступные треки пропускаются.
+type SpotifyImporter struct{}
+
+// spotifyTrack - трек или эпизод подкаста в ответе Spotify.
+type spotifyTrack struct {
+	Name       string `json:"name"`
+	URI        string `json:"uri"`
+	DurationMs int64  `json:"duration_ms"`
+	Artists    []struct {
+		Name string `json:"name"`
+	} `json:"artists"`
+	Album *struct {
+		Name string `json:"name"`
+	} `json:"album"`
+	// Show - подкаст эпизода
+	Show *struct {
+		Name      string `json:"name"`
+		Publisher string `json:"publisher"`
+	} `json:"show"`
+}
+
+type spotifyPage struct {
+	Items []struct {
+		Track *spotifyTrack `json:"track"`
+	} `json:"items"`
+}
+
+func (SpotifyImporter) Import(_ context.Context, r io.Reader) ([]Song, error) {
+	var resp struct {
+		spotifyPage
+		Tracks *spotifyPage `json:"tracks"`
+	}
+	if err := json.NewDecoder(r).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("decode spotify playlist: %v", err)
+	}
+
+	page := resp.spotifyPage
+	if resp.Tracks != nil {
+		page = *resp.Tracks
+	}
+
+	songs := make([]Song, 0, len(page.Items))
+	for _, item := range page.Items {
+		t := item.Track
+		if t == nil || t.Name == "" {
+			continue
+		}
+
+		song := Song{Name: t.Name, URL: t.URI, Duration: time.Duration(t.DurationMs) * time.Millisecond}
+		artists := make([]string, 0, len(t.Artists))
+		for _, a := range t.Artists {
+			artists = append(artists, a.Name)
+		}
+		song.Artist = strings.Join(artists, ", ")
+
+		switch {
+		case t.Album != nil:
+			song.Album = t.Album.Name
+		case t.Show != nil:
+			song.Album = t.Show.Name
+			if song.Artist == "" {
+				song.Artist = t.Show.Publisher
+			}
+		}
+
+		songs = append(songs, song)
+	}
+
+	return songs, nil
+}
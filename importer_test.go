@@ -0,0 +1,68 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+const spotifyTracks = `{
+  "items": [
+    {"track": {
+      "name": "Song 2", "uri": "spotify:track:3GfOAdcoc3X5GPiiXmpBjK", "duration_ms": 121000,
+      "artists": [{"name": "Blur"}], "album": {"name": "Blur"}
+    }},
+    {"track": null},
+    {"track": {
+      "name": "Episode 1", "uri": "spotify:episode:1", "duration_ms": 1800000,
+      "artists": [], "show": {"name": "Podcast", "publisher": "Studio"}
+    }},
+    {"track": {
+      "name": "Duet", "uri": "spotify:track:2", "duration_ms": 200500,
+      "artists": [{"name": "A"}, {"name": "B"}], "album": {"name": "Together"}
+    }}
+  ],
+  "next": null
+}`
+
+func TestSpotifyImporter(t *testing.T) {
+	ctx := context.Background()
+	want := []Song{
+		{Name: "Song 2", Artist: "Blur", Album: "Blur", Duration: 121 * time.Second, URL: "spotify:track:3GfOAdcoc3X5GPiiXmpBjK"},
+		{Name: "Episode 1", Artist: "Studio", Album: "Podcast", Duration: 30 * time.Minute, URL: "spotify:episode:1"},
+		{Name: "Duet", Artist: "A, B", Album: "Together", Duration: 200500 * time.Millisecond, URL: "spotify:track:2"},
+	}
+
+	songs, err := SpotifyImporter{}.Import(ctx, strings.NewReader(spotifyTracks))
+	td.CmpNoError(t, err)
+	td.Cmp(t, songs, want, "удалённые треки пропускаются")
+
+	songs, err = SpotifyImporter{}.Import(ctx, strings.NewReader(`{"name": "Mix", "tracks": `+spotifyTracks+`}`))
+	td.CmpNoError(t, err)
+	td.Cmp(t, songs, want, "объект плейлиста")
+
+	_, err = SpotifyImporter{}.Import(ctx, strings.NewReader("{"))
+	td.CmpError(t, err)
+}
+
+func TestPlayerImpl_ImportFrom(t *testing.T) {
+	ctx := context.Background()
+	pl, _ := New()
+
+	added, err := pl.ImportFrom(ctx, strings.NewReader(spotifyTracks), SpotifyImporter{})
+	td.CmpNoError(t, err)
+	td.Cmp(t, added, 3)
+	td.Cmp(t, names(pl), []string{"Song 2", "Episode 1", "Duet"})
+
+	failing := ImporterFunc(func(context.Context, io.Reader) ([]Song, error) { return nil, io.ErrUnexpectedEOF })
+	_, err = pl.ImportFrom(ctx, strings.NewReader(""), failing)
+	td.CmpTrue(t, errors.Is(err, io.ErrUnexpectedEOF))
+
+	_, err = pl.ImportFrom(ctx, strings.NewReader(""), nil)
+	td.CmpString(t, err, "importer is nil")
+}
@@ -0,0 +1,117 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// ErrIndexOutOfRange - в активном плейлисте нет песни с таким индексом.
+var ErrIndexOutOfRange = errors.New("index out of range")
+
+// Len - возвращает количество песен в активном плейлисте.
+func (p *playerImpl) Len(_ context.Context) int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.songs.Len()
+}
+
+// SongAt - возвращает песню активного плейлиста с индексом index, считая с нуля, и её ID.
+func (p *playerImpl) SongAt(ctx context.Context, index int) (Song, SongID, error) {
+	if err := p.rlock(ctx); err != nil {
+		return Song{}, 0, err
+	}
+	defer p.mu.RUnlock()
+
+	node := p.nodeAt(index)
+	if node == nil {
+		return Song{}, 0, ErrIndexOutOfRange
+	}
+
+	return *node.song, node.id, nil
+}
+
+// IndexOf - возвращает индекс песни в активном плейлисте или -1, если её там нет.
+func (p *playerImpl) IndexOf(_ context.Context, id SongID) int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	node := p.find(id)
+	if node == nil {
+		return -1
+	}
+
+	return p.position(node)
+}
+
+// IndexOfName - возвращает индекс первой песни с названием name
+// в активном плейлисте или -1, если такой нет.
+func (p *playerImpl) IndexOfName(_ context.Context, name string) int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	i := 0
+	for n := p.first(); n != nil; n = n.next() {
+		if n.song.Name == name {
+			return i
+		}
+		i++
+	}
+
+	return -1
+}
+
+// PlayAt - начинает играть песню активного плейлиста с индексом index с начала.
+func (p *playerImpl) PlayAt(ctx context.Context, index int) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	node := p.nodeAt(index)
+	if node == nil {
+		return ErrIndexOutOfRange
+	}
+
+	song := *node.song
+	p.auditLocked(ctx, AuditEntry{Op: AuditPlayAt, Song: &song, Detail: strconv.Itoa(index)})
+	return p.playNodeLocked(ctx, node, 0)
+}
+
+// RemoveAt - удаляет песню активного плейлиста с индексом index.
+func (p *playerImpl) RemoveAt(ctx context.Context, index int) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	node := p.nodeAt(index)
+	if node == nil {
+		return ErrIndexOutOfRange
+	}
+
+	p.recordEditLocked(p.removeEdit(node))
+	song := *node.song
+	p.auditLocked(ctx, AuditEntry{Op: AuditRemove, Song: &song})
+	return p.removeLocked(ctx, node)
+}
+
+// nodeAt - возвращает узел с индексом index или nil.
+// Обход начинается с ближайшего к индексу конца списка.
+func (pl *playlist) nodeAt(index int) *playerNode {
+	return nodeOf(pl.songs.At(index))
+}
+
+// position - возвращает индекс узла, принадлежащего списку.
+func (pl *playlist) position(node *playerNode) int {
+	return pl.songs.Index(node.elem)
+}
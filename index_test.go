@@ -0,0 +1,50 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Index(t *testing.T) {
+	ctx := context.Background()
+	a := Song{Name: "a", Duration: 30 * time.Second}
+	b := Song{Name: "b", Duration: 30 * time.Second}
+	c := Song{Name: "c", Duration: 30 * time.Second}
+
+	pl, _ := NewPlayer(a, b, c, a)
+	td.Cmp(t, pl.Len(ctx), 4)
+
+	song, id, err := pl.SongAt(ctx, 3)
+	td.CmpNoError(t, err)
+	td.Cmp(t, song, a)
+	td.Cmp(t, id, pl.last().id)
+
+	song, _, _ = pl.SongAt(ctx, 1)
+	td.Cmp(t, song, b)
+
+	_, _, err = pl.SongAt(ctx, 4)
+	td.Cmp(t, err, ErrIndexOutOfRange)
+	_, _, err = pl.SongAt(ctx, -1)
+	td.Cmp(t, err, ErrIndexOutOfRange)
+
+	td.Cmp(t, pl.IndexOf(ctx, id), 3)
+	td.Cmp(t, pl.IndexOf(ctx, id+100), -1)
+	td.Cmp(t, pl.IndexOfName(ctx, "a"), 0, "первое вхождение")
+	td.Cmp(t, pl.IndexOfName(ctx, "z"), -1)
+
+	td.CmpNoError(t, pl.PlayAt(ctx, 2))
+	td.Cmp(t, *pl.current.song, c)
+	td.Cmp(t, pl.PlayAt(ctx, 10), ErrIndexOutOfRange)
+
+	td.CmpNoError(t, pl.RemoveAt(ctx, 0))
+	td.Cmp(t, pl.Len(ctx), 3)
+	td.Cmp(t, pl.IndexOf(ctx, id), 2)
+	td.Cmp(t, pl.RemoveAt(ctx, 3), ErrIndexOutOfRange)
+
+	td.CmpNoError(t, pl.ClearPlaylist(ctx))
+	td.Cmp(t, pl.Len(ctx), 0)
+	td.Cmp(t, pl.IndexOf(ctx, id), -1)
+}
@@ -0,0 +1,74 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// interruption - песня, прерванная вставкой, и позиция, с которой она продолжится.
+type interruption struct {
+	// jingle - узел вставки, не входит в плейлист
+	jingle *playerNode
+	// node - прерванная песня
+	node *playerNode
+	// position - позиция прерванной песни
+	position time.Duration
+	// queued - вставка - песня очереди Enqueue
+	queued bool
+}
+
+// InterruptWith - сразу начинает играть song, не добавляя её в плейлист,
+// а после её окончания или пропуска продолжает прерванную песню с того же места.
+// Вставка во время другой вставки заменяет её.
+func (p *playerImpl) InterruptWith(ctx context.Context, song Song) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if p.current == nil {
+		return errors.New("playlist is empty")
+	}
+
+	if err := p.validator.Validate(song); err != nil {
+		return err
+	}
+
+	in := p.interruption
+	if in == nil || p.current != in.jingle {
+		in = &interruption{node: p.current, position: p.elapsedLocked()}
+	}
+
+	p.haltLocked(ctx)
+	p.section = nil
+
+	// вставка ссылается на прерванную песню в обе стороны,
+	// поэтому Next, Prev и окончание вставки возвращают к ней
+	in.jingle = &playerNode{song: &song, around: in.node}
+	in.queued = false
+	p.interruption = in
+	p.current = in.jingle
+	p.playedTime = 0
+	p.notifyLocked()
+
+	return p.playLocked(ctx)
+}
+
+// cancelInterruptionLocked - прекращает вставку и возвращает курсор на прерванную песню.
+// Вызывается под блокировкой.
+func (p *playerImpl) cancelInterruptionLocked() {
+	in := p.interruption
+	if in == nil {
+		return
+	}
+
+	if p.current == in.jingle {
+		p.current, p.playedTime = in.node, in.position
+	}
+	p.interruption = nil
+}
@@ -0,0 +1,58 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_InterruptWith(t *testing.T) {
+	ctx := context.Background()
+	jingle := Song{Name: "jingle", Duration: 30 * time.Second}
+
+	t.Run("empty playlist", func(t *testing.T) {
+		pl, _ := NewPlayer()
+		td.CmpError(t, pl.InterruptWith(ctx, jingle))
+	})
+
+	t.Run("resumes after jingle", func(t *testing.T) {
+		out := &recordingOutput{}
+		pl := newFakePlayer(t, WithOutput(out), WithSongs(minuteSongs("a", "b")...))
+
+		td.CmpNoError(t, pl.Play(ctx))
+		_, err := pl.SimulatePlayback(ctx, 20*time.Second)
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, pl.InterruptWith(ctx, jingle))
+		td.Cmp(t, pl.Status(ctx).Song.Name, "jingle")
+
+		state, _ := pl.Snapshot(ctx)
+		td.Cmp(t, state.Playlists[0].Cursor, 0, "в снимке - прерванная песня")
+		td.Cmp(t, state.Playlists[0].Songs, td.Len(2), "вставка не попала в плейлист")
+
+		played, err := pl.SimulatePlayback(ctx, 40*time.Second)
+		td.CmpNoError(t, err)
+		td.Cmp(t, titles(played), []string{"jingle", "a"}, "вернулись к прерванной песне")
+		td.CmpNoError(t, pl.Pause(ctx))
+
+		td.Cmp(t, pl.Elapsed(ctx), 30*time.Second, "с того же места")
+		td.Cmp(t, out.Calls(), []string{"start a", "stop a", "start jingle", "stop jingle", "start a", "stop a"})
+	})
+
+	t.Run("next skips jingle", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSongs("a", "b")...))
+		td.CmpNoError(t, pl.Play(ctx))
+		_, err := pl.SimulatePlayback(ctx, 5*time.Second)
+		td.CmpNoError(t, err)
+
+		td.CmpNoError(t, pl.InterruptWith(ctx, Song{Name: "first", Duration: time.Second}))
+		td.CmpNoError(t, pl.InterruptWith(ctx, Song{Name: "second", Duration: time.Second}))
+		td.Cmp(t, pl.Status(ctx).Song.Name, "second")
+
+		td.CmpNoError(t, pl.Next(ctx))
+		td.CmpNoError(t, pl.Pause(ctx))
+		td.Cmp(t, pl.Status(ctx).Song.Name, "a", "вложенная вставка возвращает к исходной песне")
+		td.Cmp(t, pl.Elapsed(ctx), 5*time.Second)
+	})
+}
@@ -0,0 +1,94 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Interstitial - источник вставок между песнями: объявлений, рекламы, позывных.
+// Interstitial вызывается под блокировкой плеера, поэтому должен отвечать быстро
+// и не обращаться к плееру.
+type Interstitial interface {
+	// Interstitial - возвращает вставку между prev и next или false, если вставка не нужна
+	Interstitial(ctx context.Context, prev, next Song) (Song, bool)
+}
+
+// InterstitialFunc - функция, удовлетворяющая интерфейсу Interstitial.
+type InterstitialFunc func(ctx context.Context, prev, next Song) (Song, bool)
+
+func (f InterstitialFunc) Interstitial(ctx context.Context, prev, next Song) (Song, bool) {
+	return f(ctx, prev, next)
+}
+
+// interstitials - настройки и счётчики вставок между песнями.
+type interstitials struct {
+	provider Interstitial
+	// everySongs - вставка после каждых everySongs песен, 0 - не учитывается
+	everySongs int
+	// every - вставка не реже, чем раз в every, 0 - не учитывается
+	every time.Duration
+
+	// songs - сколько песен доиграло после последней вставки
+	songs int
+	// last - когда была последняя вставка
+	last time.Time
+	// length - длительность последней вставки для оценки оставшегося времени
+	length time.Duration
+}
+
+// WithInterstitials - между песнями, доигравшими до конца, плеер спрашивает вставку
+// у provider после каждых everySongs песен или если после прошлой вставки
+// прошло every. Нулевое значение отключает соответствующее условие.
+// Вставка играет как InterruptWith и не попадает в плейлист.
+func WithInterstitials(provider Interstitial, everySongs int, every time.Duration) Option {
+	return func(p *playerImpl) error {
+		if provider == nil {
+			return errors.New("interstitial provider is nil")
+		}
+		if everySongs < 0 || every < 0 {
+			return errors.New("interstitial interval is negative")
+		}
+		if everySongs == 0 && every == 0 {
+			return errors.New("interstitial interval is not set")
+		}
+
+		p.interstitials = &interstitials{
+			provider:   provider,
+			everySongs: everySongs,
+			every:      every,
+		}
+		return nil
+	}
+}
+
+// interstitialLocked - после окончания prev возвращает узел вставки перед next
+// или next, если вставка не нужна.
+// Вызывается под блокировкой.
+func (p *playerImpl) interstitialLocked(ctx context.Context, prev, next *playerNode) *playerNode {
+	is := p.interstitials
+	// после вставки сразу идёт песня
+	if is == nil || p.interruption != nil {
+		return next
+	}
+
+	is.songs++
+	due := is.everySongs > 0 && is.songs >= is.everySongs ||
+		is.every > 0 && p.since(is.last) >= is.every
+	if !due {
+		return next
+	}
+
+	song, ok := is.provider.Interstitial(ctx, *prev.song, *next.song)
+	if !ok {
+		return next
+	}
+
+	is.songs, is.last, is.length = 0, p.now(), song.Duration
+	in := &interruption{node: next, position: p.resumePositionLocked(*next.song)}
+	in.jingle = &playerNode{song: &song, around: next}
+	p.interruption = in
+
+	p.logger.DebugContext(ctx, "interstitial started", songAttr(song))
+	return in.jingle
+}
@@ -0,0 +1,90 @@
+package player
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Interstitials(t *testing.T) {
+	ctx := context.Background()
+	songs := []Song{
+		{Name: "a", Duration: 20 * time.Millisecond},
+		{Name: "b", Duration: 20 * time.Millisecond},
+		{Name: "c", Duration: 20 * time.Millisecond},
+		{Name: "d", Duration: 30 * time.Second},
+	}
+	announce := InterstitialFunc(func(_ context.Context, prev, next Song) (Song, bool) {
+		return Song{Name: "далее " + next.Name, Duration: 10 * time.Millisecond}, true
+	})
+	starts := func(out *recordingOutput) []string {
+		var res []string
+		for _, c := range out.Calls() {
+			if name, ok := strings.CutPrefix(c, "start "); ok {
+				res = append(res, name)
+			}
+		}
+		return res
+	}
+
+	t.Run("every songs", func(t *testing.T) {
+		out := &recordingOutput{}
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithOutput(out), WithInterstitials(announce, 2, 0), WithSongs(songs...))
+		_ = pl.Play(ctx)
+
+		waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		td.CmpNoError(t, pl.WaitFor(waitCtx, func(st Status) bool { return st.Song.Name == "d" }))
+		_ = pl.Pause(ctx)
+
+		td.Cmp(t, starts(out), []string{"a", "b", "далее c", "c", "d"})
+		td.Cmp(t, pl.Len(ctx), len(songs), "вставка не попала в плейлист")
+	})
+
+	t.Run("interval", func(t *testing.T) {
+		out := &recordingOutput{}
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithOutput(out), WithInterstitials(announce, 0, 50*time.Millisecond), WithSongs(songs...))
+		_ = pl.Play(ctx)
+
+		waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		td.CmpNoError(t, pl.WaitFor(waitCtx, func(st Status) bool { return st.Song.Name == "d" }))
+		_ = pl.Pause(ctx)
+
+		td.Cmp(t, starts(out), []string{"a", "b", "c", "далее d", "d"})
+	})
+
+	t.Run("provider declines", func(t *testing.T) {
+		out := &recordingOutput{}
+		none := InterstitialFunc(func(context.Context, Song, Song) (Song, bool) { return Song{}, false })
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithOutput(out), WithInterstitials(none, 1, 0), WithSongs(songs[0], songs[3]))
+		_ = pl.Play(ctx)
+		time.Sleep(30 * time.Millisecond)
+		_ = pl.Pause(ctx)
+
+		td.Cmp(t, starts(out), []string{"a", "d"})
+	})
+
+	t.Run("next skips interstitial", func(t *testing.T) {
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithInterstitials(announce, 1, 0), WithSongs(songs[0], songs[3]))
+		_ = pl.Play(ctx)
+		time.Sleep(25 * time.Millisecond)
+		td.Cmp(t, pl.Status(ctx).Song.Name, "далее d")
+
+		_ = pl.Next(ctx)
+		td.Cmp(t, pl.Status(ctx).Song.Name, "d")
+		_ = pl.Pause(ctx)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		_, err := New(WithInterstitials(nil, 1, 0))
+		td.CmpError(t, err)
+		_, err = New(WithInterstitials(announce, 0, 0))
+		td.CmpError(t, err)
+		_, err = New(WithInterstitials(announce, -1, 0))
+		td.CmpError(t, err)
+	})
+}
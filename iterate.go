@@ -0,0 +1,35 @@
+package player
+
+import "context"
+
+// ForEach - вызывает fn для песен активного плейлиста по порядку, пока fn возвращает true.
+// Обход идёт по копии плейлиста, снятой под блокировкой, поэтому fn может
+// обращаться к плееру, а песни, добавленные во время обхода, в него не попадают.
+// Если ctx завершится во время обхода, возвращается его ошибка.
+func (p *playerImpl) ForEach(ctx context.Context, fn func(id SongID, song Song) bool) error {
+	type entry struct {
+		id   SongID
+		song Song
+	}
+
+	if err := p.rlock(ctx); err != nil {
+		return err
+	}
+	entries := make([]entry, 0, p.songs.Len())
+	for n := p.first(); n != nil; n = n.next() {
+		entries = append(entries, entry{id: n.id, song: *n.song})
+	}
+	p.mu.RUnlock()
+
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if !fn(e.id, e.song) {
+			return nil
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_ForEach(t *testing.T) {
+	ctx := context.Background()
+
+	songs := make([]Song, 100)
+	for i := range songs {
+		songs[i] = Song{Name: fmt.Sprintf("%d", i), Duration: time.Minute}
+	}
+
+	t.Run("order and stop", func(t *testing.T) {
+		pl, _ := NewPlayer(songs...)
+
+		var got []string
+		td.CmpNoError(t, pl.ForEach(ctx, func(id SongID, song Song) bool {
+			td.Cmp(t, pl.IndexOf(ctx, id), len(got), "ID совпадает с песней")
+			got = append(got, song.Name)
+			return len(got) < 3
+		}))
+		td.Cmp(t, got, []string{"0", "1", "2"})
+	})
+
+	t.Run("concurrent add", func(t *testing.T) {
+		pl, _ := NewPlayer(songs...)
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				_, _ = pl.AddSong(ctx, Song{Name: "new", Duration: time.Minute})
+			}
+		}()
+
+		for i := 0; i < 10; i++ {
+			count := 0
+			_ = pl.ForEach(ctx, func(SongID, Song) bool {
+				_, _ = pl.AddSong(ctx, Song{Name: "from fn", Duration: time.Minute})
+				count++
+				return true
+			})
+			td.Cmp(t, count, td.Gte(len(songs)))
+		}
+		wg.Wait()
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		pl, _ := NewPlayer(songs...)
+		ctx, cancel := context.WithCancel(ctx)
+
+		count := 0
+		err := pl.ForEach(ctx, func(SongID, Song) bool {
+			count++
+			cancel()
+			return true
+		})
+		td.Cmp(t, err, context.Canceled)
+		td.Cmp(t, count, 1)
+	})
+}
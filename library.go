@@ -0,0 +1,315 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrTrackNotFound - в библиотеке нет трека с таким ID.
+var ErrTrackNotFound = errors.New("track not found")
+
+// TrackID - идентификатор трека в библиотеке.
+type TrackID uint64
+
+// LibraryEntry - трек библиотеки.
+type LibraryEntry struct {
+	// ID - идентификатор трека
+	ID TrackID
+	// Song - песня
+	Song Song
+	// AddedAt - когда трек добавлен в библиотеку
+	AddedAt time.Time
+}
+
+// Library - хранилище песен, независимое от плейлистов.
+// Плейлисты ссылаются на треки библиотеки, поэтому удаление песни
+// из плейлиста не удаляет её из библиотеки, а один трек может
+// встречаться в плейлистах несколько раз.
+// Библиотеку можно разделить между несколькими плеерами.
+type Library struct {
+	mu sync.RWMutex
+
+	tracks map[TrackID]*track
+	// byKey - треки по содержимому песни, чтобы не дублировать одинаковые
+	byKey map[string]*track
+	// order - порядок добавления
+	order  []TrackID
+	lastID TrackID
+	// index - индекс для поиска
+	index *searchIndex
+}
+
+// track - трек библиотеки, на песню которого ссылаются узлы плейлистов.
+// Песня трека не меняется на месте: resolve заменяет её копией,
+// поэтому узлы читают её под блокировкой своего плеера.
+type track struct {
+	id      TrackID
+	song    *Song
+	addedAt time.Time
+}
+
+// NewLibrary - конструктор для Library.
+func NewLibrary() *Library {
+	return &Library{
+		tracks: make(map[TrackID]*track),
+		byKey:  make(map[string]*track),
+		index:  newSearchIndex(),
+	}
+}
+
+// Add - добавляет песню в библиотеку и возвращает ID трека.
+// Для уже добавленной песни возвращается ID существующего трека.
+func (l *Library) Add(song Song) (TrackID, error) {
+	if song.Name == "" {
+		return 0, errors.New("song name is empty")
+	}
+
+	return l.add(song, time.Now()).id, nil
+}
+
+// Remove - удаляет трек из библиотеки.
+// Плейлисты, в которых встречается трек, не меняются.
+func (l *Library) Remove(id TrackID) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	t, ok := l.tracks[id]
+	if !ok {
+		return ErrTrackNotFound
+	}
+
+	delete(l.tracks, id)
+	delete(l.byKey, libraryKey(*t.song))
+	l.index.remove(id)
+	for i, oid := range l.order {
+		if oid == id {
+			l.order = append(l.order[:i], l.order[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+// Get - возвращает трек по ID.
+func (l *Library) Get(id TrackID) (LibraryEntry, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	t, ok := l.tracks[id]
+	if !ok {
+		return LibraryEntry{}, ErrTrackNotFound
+	}
+
+	return t.entry(), nil
+}
+
+// Len - возвращает количество треков.
+func (l *Library) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return len(l.order)
+}
+
+// Entries - возвращает все треки в порядке добавления.
+func (l *Library) Entries() []LibraryEntry {
+	return l.Search("")
+}
+
+// Search - возвращает треки, в названии, исполнителе или альбоме которых
+// встречается query без учёта регистра, в порядке добавления.
+func (l *Library) Search(query string) []LibraryEntry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	ids := l.order
+	if query != "" {
+		ids = make([]TrackID, 0)
+		for id := range l.index.match(query, 0) {
+			ids = append(ids, id)
+		}
+		// ID выдаются по порядку добавления
+		slices.Sort(ids)
+	}
+
+	var entries []LibraryEntry
+	for _, id := range ids {
+		entries = append(entries, l.tracks[id].entry())
+	}
+
+	return entries
+}
+
+// match - возвращает треки, подходящие под запрос, как Player.Search.
+func (l *Library) match(query string, fuzzy float64) map[TrackID]libraryMatch {
+	if query == "" {
+		return nil
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	matches := make(map[TrackID]libraryMatch)
+	for id, score := range l.index.match(query, fuzzy) {
+		matches[id] = libraryMatch{song: *l.tracks[id].song, score: score}
+	}
+
+	return matches
+}
+
+// add - добавляет песню или возвращает копию существующего трека с такой же песней.
+func (l *Library) add(song Song, now time.Time) *track {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	t := *l.addLocked(song, now)
+	return &t
+}
+
+// resolve - сохраняет длительность d песни song трека id, узнанную у источника,
+// и возвращает копию трека с новой песней. Если трек с такой песней уже есть,
+// возвращается он. Если трек удалён из библиотеки или его песня уже другая,
+// возвращается песня вне библиотеки.
+func (l *Library) resolve(id TrackID, song Song, d time.Duration) *track {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	oldKey := libraryKey(song)
+	song.Duration = d
+	key := libraryKey(song)
+	if t, ok := l.byKey[key]; ok {
+		c := *t
+		return &c
+	}
+
+	t, ok := l.tracks[id]
+	if !ok || libraryKey(*t.song) != oldKey {
+		return &track{id: id, song: &song}
+	}
+
+	// ключ включает длительность
+	if l.byKey[oldKey] == t {
+		delete(l.byKey, oldKey)
+	}
+	t.song = &song
+	l.byKey[key] = t
+
+	c := *t
+	return &c
+}
+
+// addLocked - добавляет песню, если её ещё нет, и возвращает её трек,
+// now - время добавления.
+// Вызывается под блокировкой.
+func (l *Library) addLocked(song Song, now time.Time) *track {
+	key := libraryKey(song)
+	if t, ok := l.byKey[key]; ok {
+		return t
+	}
+
+	l.lastID++
+	t := &track{id: l.lastID, song: &song, addedAt: now}
+	l.tracks[t.id] = t
+	l.byKey[key] = t
+	l.order = append(l.order, t.id)
+	l.index.add(t.id, song)
+
+	return t
+}
+
+// get - возвращает копию трека по ID.
+func (l *Library) get(id TrackID) (*track, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	t, ok := l.tracks[id]
+	if !ok {
+		return nil, false
+	}
+
+	c := *t
+	return &c, true
+}
+
+// all - возвращает копии всех треков в порядке добавления.
+func (l *Library) all() []*track {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	tracks := make([]*track, 0, len(l.order))
+	for _, id := range l.order {
+		t := *l.tracks[id]
+		tracks = append(tracks, &t)
+	}
+
+	return tracks
+}
+
+func (t *track) entry() LibraryEntry {
+	return LibraryEntry{ID: t.id, Song: *t.song, AddedAt: t.addedAt}
+}
+
+// libraryKey - ключ, по которому одинаковые песни становятся одним треком.
+func libraryKey(song Song) string {
+	key := song.Name + "\x00" + song.Artist + "\x00" + song.Album + "\x00" + strconv.FormatInt(int64(song.Duration), 10) + "\x00" + song.URL
+	// одна и та же запись с разной обрезкой - разные треки
+	if song.LeadIn != 0 || song.LeadOut != 0 {
+		key += "\x00" + strconv.FormatInt(int64(song.LeadIn), 10) + "\x00" + strconv.FormatInt(int64(song.LeadOut), 10)
+	}
+	return key
+}
+
+// WithLibrary - задаёт библиотеку, например общую для нескольких плееров.
+// По умолчанию у каждого плеера своя библиотека.
+func WithLibrary(l *Library) Option {
+	return func(p *playerImpl) error {
+		if l == nil {
+			return errors.New("library is nil")
+		}
+
+		p.library = l
+		return nil
+	}
+}
+
+// Library - возвращает библиотеку плеера.
+func (p *playerImpl) Library() *Library {
+	return p.library
+}
+
+// AddTrack - добавляет трек библиотеки в конец активного плейлиста
+// и возвращает ID песни в плейлисте. Один трек можно добавить несколько раз.
+func (p *playerImpl) AddTrack(ctx context.Context, id TrackID) (SongID, error) {
+	t, ok := p.library.get(id)
+	if !ok {
+		return 0, ErrTrackNotFound
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return 0, ErrClosed
+	}
+
+	if err := p.admitLocked(ctx, p.active, *t.song); err != nil {
+		return 0, err
+	}
+
+	node := p.playlist.appendTrack(t, nil)
+	p.recordEditLocked(p.addEdit(node))
+	p.rescheduleLocked()
+	song := *t.song
+	p.auditLocked(ctx, AuditEntry{Op: AuditAdd, Song: &song, Detail: p.active})
+	p.logger.DebugContext(ctx, "song added", songAttr(*t.song), slog.String("playlist", p.active))
+
+	return node.id, nil
+}
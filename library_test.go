@@ -0,0 +1,72 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestLibrary(t *testing.T) {
+	sg := Song{Name: "30 лет", Artist: "Сектор Газа", Album: "Гуляй, мужик!", Duration: 30 * time.Second}
+	ap := Song{Name: "Почему я идиот?", Artist: "Александр Пушной", Duration: 30 * time.Second}
+
+	lib := NewLibrary()
+	_, err := lib.Add(Song{})
+	td.CmpError(t, err, "пустое название")
+
+	sgID, _ := lib.Add(sg)
+	apID, _ := lib.Add(ap)
+	againID, _ := lib.Add(sg)
+	td.Cmp(t, againID, sgID, "одинаковая песня - один трек")
+	td.Cmp(t, lib.Len(), 2)
+
+	entry, err := lib.Get(apID)
+	td.CmpNoError(t, err)
+	td.Cmp(t, entry, td.Struct(LibraryEntry{ID: apID, Song: ap}, td.StructFields{"AddedAt": td.NotZero()}))
+
+	td.Cmp(t, lib.Search("ГАЗА"), td.Len(1), "поиск по исполнителю без учёта регистра")
+	td.Cmp(t, lib.Search("мужик")[0].ID, sgID, "поиск по альбому")
+	td.Cmp(t, lib.Search("идиот")[0].ID, apID, "поиск по названию")
+	td.CmpEmpty(t, lib.Search("шуфутинский"))
+	td.Cmp(t, lib.Entries(), td.Len(2))
+
+	td.CmpNoError(t, lib.Remove(sgID))
+	td.Cmp(t, lib.Remove(sgID), ErrTrackNotFound)
+	_, err = lib.Get(sgID)
+	td.Cmp(t, err, ErrTrackNotFound)
+	td.Cmp(t, lib.Len(), 1)
+}
+
+func TestPlayerImpl_Library(t *testing.T) {
+	ctx := context.Background()
+	sg := Song{Name: "30 лет", Artist: "Сектор Газа", Duration: 30 * time.Second}
+
+	_, err := New(WithLibrary(nil))
+	td.CmpError(t, err)
+
+	lib := NewLibrary()
+	pl, _ := New(WithLibrary(lib), WithSongs(sg))
+	td.Cmp(t, lib.Len(), 1, "песни плейлиста попадают в библиотеку")
+
+	id := lib.Entries()[0].ID
+	first, err := pl.AddTrack(ctx, id)
+	td.CmpNoError(t, err)
+	second, err := pl.AddTrack(ctx, id)
+	td.CmpNoError(t, err)
+	td.Cmp(t, first, td.Not(second), "у каждого добавления свой ID")
+	_, err = pl.AddTrack(ctx, id+100)
+	td.Cmp(t, err, ErrTrackNotFound)
+
+	td.Cmp(t, pl.Metrics(ctx).PlaylistLength, 3, "трек добавлен несколько раз")
+	td.Cmp(t, pl.last().track, id)
+	td.Cmp(t, lib.Len(), 1)
+
+	// удаление из библиотеки не трогает плейлист
+	td.CmpNoError(t, lib.Remove(id))
+	td.Cmp(t, *pl.first().song, sg)
+
+	other, _ := New(WithLibrary(lib))
+	td.CmpTrue(t, other.Library() == pl.Library(), "библиотека общая")
+}
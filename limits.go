@@ -0,0 +1,185 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ErrPlaylistFull - в плейлисте нет места для песни по WithMaxPlaylistSize
+// или WithMaxTotalDuration.
+var ErrPlaylistFull = errors.New("playlist is full")
+
+// EvictionPolicy - что делать с новой песней, когда плейлист заполнен.
+type EvictionPolicy int
+
+const (
+	// EvictReject - отказать в добавлении с ErrPlaylistFull, поведение по умолчанию
+	EvictReject EvictionPolicy = iota
+	// EvictOldestUnplayed - удалять ближайшие к текущей ещё не сыгранные песни,
+	// пока новая не поместится. Текущая и уже сыгранные песни не удаляются.
+	EvictOldestUnplayed
+)
+
+func (e EvictionPolicy) String() string {
+	switch e {
+	case EvictReject:
+		return "reject"
+	case EvictOldestUnplayed:
+		return "oldest_unplayed"
+	default:
+		return fmt.Sprintf("EvictionPolicy(%d)", int(e))
+	}
+}
+
+// MarshalText - кодирует политику строкой, например "oldest_unplayed".
+func (e EvictionPolicy) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+// UnmarshalText - разбирает политику из строки MarshalText.
+func (e *EvictionPolicy) UnmarshalText(text []byte) (err error) {
+	*e, err = parseEnum(text, EvictReject, EvictOldestUnplayed, "eviction policy")
+	return err
+}
+
+// limits - ограничения размера плейлистов.
+type limits struct {
+	// songs - наибольшее количество песен, 0 - без ограничения
+	songs int
+	// total - наибольшая суммарная длительность, 0 - без ограничения
+	total time.Duration
+	// policy - что делать, когда плейлист заполнен
+	policy EvictionPolicy
+}
+
+// WithMaxPlaylistSize - ограничивает количество песен в каждом плейлисте:
+// AddSong, AddSongs и другие способы добавления песен возвращают ErrPlaylistFull
+// или освобождают место по WithEvictionPolicy.
+func WithMaxPlaylistSize(n int) Option {
+	return func(p *playerImpl) error {
+		if n <= 0 {
+			return errors.New("max playlist size must be positive")
+		}
+
+		p.limits.songs = n
+		return nil
+	}
+}
+
+// WithMaxTotalDuration - ограничивает суммарную длительность песен в каждом плейлисте,
+// как WithMaxPlaylistSize. Потоки длительности не добавляют.
+func WithMaxTotalDuration(d time.Duration) Option {
+	return func(p *playerImpl) error {
+		if d <= 0 {
+			return errors.New("max total duration must be positive")
+		}
+
+		p.limits.total = d
+		return nil
+	}
+}
+
+// WithEvictionPolicy - что делать с новой песней в заполненном плейлисте,
+// по умолчанию EvictReject.
+func WithEvictionPolicy(policy EvictionPolicy) Option {
+	return func(p *playerImpl) error {
+		if policy != EvictReject && policy != EvictOldestUnplayed {
+			return fmt.Errorf("unknown eviction policy %v", policy)
+		}
+
+		p.limits.policy = policy
+		return nil
+	}
+}
+
+// fit - помещаются ли в ограничения count песен общей длительностью total.
+func (l limits) fit(count int, total time.Duration) bool {
+	return (l.songs == 0 || count <= l.songs) && (l.total == 0 || total <= l.total)
+}
+
+// fitSongs - помещаются ли в ограничения плейлист из songs.
+func (l limits) fitSongs(songs []Song) bool {
+	var total time.Duration
+	for _, s := range songs {
+		total += s.Duration
+	}
+
+	return l.fit(len(songs), total)
+}
+
+// fitNodes - помещаются ли в ограничения песни плейлиста pl вместе с nodes.
+func (l limits) fitNodes(pl *playlist, nodes ...*playerNode) bool {
+	total := pl.total
+	for _, n := range nodes {
+		total += n.song.Duration
+	}
+
+	return l.fit(pl.songs.Len()+len(nodes), total)
+}
+
+// makeRoomLocked - проверяет, что song помещается в плейлист name,
+// при необходимости удаляя песни по политике вытеснения.
+// Вызывается под блокировкой.
+func (p *playerImpl) makeRoomLocked(ctx context.Context, name string, song Song) error {
+	if p.limits.songs == 0 && p.limits.total == 0 {
+		return nil
+	}
+
+	pl, ok := p.playlistLocked(name)
+	if !ok {
+		return ErrPlaylistNotFound
+	}
+
+	if p.limits.total > 0 && song.Duration > p.limits.total {
+		return ErrPlaylistFull
+	}
+
+	for !p.limits.fit(pl.songs.Len()+1, pl.total+song.Duration) {
+		if p.limits.policy != EvictOldestUnplayed {
+			return ErrPlaylistFull
+		}
+
+		victim := p.oldestUnplayedLocked(pl)
+		if victim == nil {
+			return ErrPlaylistFull
+		}
+
+		p.evictLocked(ctx, name, victim)
+	}
+
+	return nil
+}
+
+// oldestUnplayedLocked - ближайшая к текущей ещё не сыгранная песня плейлиста pl,
+// nil если таких нет.
+// Вызывается под блокировкой.
+func (p *playerImpl) oldestUnplayedLocked(pl *playlist) *playerNode {
+	if pl.current == nil {
+		return pl.first()
+	}
+
+	// во время вставки текущая - сама вставка, а прерванная песня
+	// ещё доиграет, поэтому вытесняется следующая за ней
+	if in := p.interruption; in != nil && pl.current == in.jingle {
+		return in.node.next()
+	}
+
+	return pl.current.next()
+}
+
+// evictLocked - удаляет песню из плейлиста name, чтобы освободить место.
+// Вызывается под блокировкой.
+func (p *playerImpl) evictLocked(ctx context.Context, name string, node *playerNode) {
+	if name == p.active {
+		p.recordEditLocked(p.removeEdit(node))
+		// вытесняется не текущая песня, воспроизведение не перезапускается
+		_ = p.removeLocked(ctx, node)
+	} else {
+		p.playlists[name].unlink(node)
+	}
+
+	p.logger.InfoContext(ctx, "song evicted", songAttr(*node.song), slog.String("playlist", name))
+}
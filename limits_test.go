@@ -0,0 +1,231 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlaylistLimits(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("size", func(t *testing.T) {
+		pl, err := New(WithMaxPlaylistSize(2), WithSongs(minuteSong("a"), minuteSong("b")))
+		td.CmpNoError(t, err)
+
+		_, err = pl.AddSong(ctx, minuteSong("c"))
+		td.CmpTrue(t, errors.Is(err, ErrPlaylistFull))
+		_, err = pl.AddSongAs(ctx, "u", minuteSong("c"))
+		td.CmpTrue(t, errors.Is(err, ErrPlaylistFull))
+		td.Cmp(t, names(pl), []string{"a", "b"})
+
+		td.CmpNoError(t, pl.CreatePlaylist(ctx, "other"))
+		_, err = pl.AddSongTo(ctx, "other", minuteSong("x"))
+		td.CmpNoError(t, err, "ограничение на каждый плейлист отдельно")
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		pl, err := New(WithMaxPlaylistSize(3), WithSongs(minuteSong("a")))
+		td.CmpNoError(t, err)
+
+		errs := pl.AddSongs(ctx, minuteSong("b"), Song{}, minuteSong("c"), minuteSong("d"))
+		td.Cmp(t, errs, td.Len(4))
+		td.CmpNil(t, errs[0])
+		td.CmpTrue(t, errors.Is(errs[1], ErrInvalidSong))
+		td.CmpNil(t, errs[2])
+		td.CmpTrue(t, errors.Is(errs[3], ErrPlaylistFull))
+		td.Cmp(t, names(pl), []string{"a", "b", "c"})
+	})
+
+	t.Run("total duration", func(t *testing.T) {
+		pl, err := New(WithMaxTotalDuration(150*time.Second), WithSongs(minuteSong("a"), minuteSong("b")))
+		td.CmpNoError(t, err)
+
+		_, err = pl.AddSong(ctx, minuteSong("c"))
+		td.CmpTrue(t, errors.Is(err, ErrPlaylistFull))
+		_, err = pl.AddSong(ctx, Song{Name: "short", Duration: 30 * time.Second})
+		td.CmpNoError(t, err)
+		_, err = pl.AddSong(ctx, Song{Name: "radio", URL: "http://radio"})
+		td.CmpNoError(t, err, "поток не добавляет длительности")
+	})
+
+	t.Run("evict oldest unplayed", func(t *testing.T) {
+		pl, err := New(
+			WithMaxPlaylistSize(3),
+			WithEvictionPolicy(EvictOldestUnplayed),
+			WithSongs(minuteSong("a"), minuteSong("b"), minuteSong("c")),
+		)
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, pl.PlayAt(ctx, 1))
+
+		_, err = pl.AddSong(ctx, minuteSong("d"))
+		td.CmpNoError(t, err)
+		td.Cmp(t, names(pl), []string{"a", "b", "d"}, "сыгранная a и текущая b остаются")
+		td.Cmp(t, pl.Status(ctx).Song.Name, "b")
+		td.CmpTrue(t, pl.Status(ctx).Playing)
+
+		_, err = pl.AddSong(ctx, minuteSong("e"))
+		td.CmpNoError(t, err)
+		td.Cmp(t, names(pl), []string{"a", "b", "e"})
+
+		td.CmpNoError(t, pl.Next(ctx))
+		_, err = pl.AddSong(ctx, minuteSong("f"))
+		td.CmpTrue(t, errors.Is(err, ErrPlaylistFull), "несыгранных песен не осталось")
+	})
+
+	t.Run("evict during interruption", func(t *testing.T) {
+		pl := newFakePlayer(t,
+			WithMaxPlaylistSize(3),
+			WithEvictionPolicy(EvictOldestUnplayed),
+			WithSongs(minuteSongs("a", "b", "c")...),
+		)
+		td.CmpNoError(t, pl.PlayAt(ctx, 1))
+		td.CmpNoError(t, pl.InterruptWith(ctx, Song{Name: "jingle", Duration: 30 * time.Second}))
+
+		_, err := pl.AddSong(ctx, minuteSong("d"))
+		td.CmpNoError(t, err)
+		td.Cmp(t, names(pl), []string{"a", "b", "d"}, "прерванная b остаётся")
+
+		played, err := pl.SimulatePlayback(ctx, 150*time.Second)
+		td.CmpNoError(t, err)
+		td.Cmp(t, titles(played), []string{"jingle", "b", "d"})
+	})
+
+	t.Run("every insertion", func(t *testing.T) {
+		pl, err := New(WithMaxPlaylistSize(2), WithSongs(minuteSong("a"), minuteSong("b")))
+		td.CmpNoError(t, err)
+		lib := pl.Library().Len()
+
+		_, err = pl.AddSong(ctx, minuteSong("c"))
+		td.CmpTrue(t, errors.Is(err, ErrPlaylistFull))
+		td.Cmp(t, pl.AddSongs(ctx, minuteSong("c"))[0], ErrPlaylistFull)
+		_, err = pl.Merge(ctx, minuteSongs("c"), MergeAppendMissing)
+		td.Cmp(t, err, ErrPlaylistFull)
+		_, err = pl.Merge(ctx, minuteSongs("a", "c"), MergeInterleave)
+		td.Cmp(t, err, ErrPlaylistFull)
+		td.Cmp(t, pl.WithTransaction(ctx, func(tx PlaylistTx) error {
+			_, err := tx.Add(minuteSong("c"))
+			return err
+		}), ErrPlaylistFull)
+		c := minuteSong("c")
+		td.Cmp(t, pl.ApplySync(ctx, SyncState{Song: &c, At: time.Now()}), ErrPlaylistFull)
+		td.Cmp(t, pl.Library().Len(), lib, "отклонённые песни не попадают в библиотеку")
+
+		id, err := pl.Library().Add(c)
+		td.CmpNoError(t, err)
+		_, err = pl.AddTrack(ctx, id)
+		td.Cmp(t, err, ErrPlaylistFull)
+		td.Cmp(t, names(pl), []string{"a", "b"})
+
+		// замена и удаление в транзакции освобождают место
+		_, err = pl.Merge(ctx, minuteSongs("a", "c"), MergeReplace)
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, pl.WithTransaction(ctx, func(tx PlaylistTx) error {
+			if err := tx.Remove(tx.Songs()[0].ID); err != nil {
+				return err
+			}
+			_, err := tx.Add(minuteSong("d"))
+			return err
+		}))
+		td.Cmp(t, names(pl), []string{"c", "d"})
+	})
+
+	t.Run("running total", func(t *testing.T) {
+		pl, err := New(WithMaxTotalDuration(3*time.Minute), WithSongs(minuteSongs("a", "b", "c")...))
+		td.CmpNoError(t, err)
+
+		_, err = pl.AddSong(ctx, minuteSong("d"))
+		td.Cmp(t, err, ErrPlaylistFull)
+		td.CmpNoError(t, pl.RemoveAt(ctx, 0))
+		_, err = pl.AddSong(ctx, minuteSong("d"))
+		td.CmpNoError(t, err, "удаление уменьшает длительность")
+		td.CmpNoError(t, pl.Undo(ctx))
+		td.CmpNoError(t, pl.Undo(ctx))
+		td.Cmp(t, names(pl), []string{"a", "b", "c"})
+		_, err = pl.AddSong(ctx, minuteSong("d"))
+		td.Cmp(t, err, ErrPlaylistFull, "отмена восстанавливает длительность")
+	})
+
+	t.Run("load playlist", func(t *testing.T) {
+		s := NewMemoryStorage()
+		td.CmpNoError(t, s.SavePlaylist(ctx, "saved", minuteSongs("a", "b", "c")))
+		pl, err := New(WithStorage(s), WithMaxPlaylistSize(2))
+		td.CmpNoError(t, err)
+
+		td.Cmp(t, pl.LoadPlaylist(ctx, "saved"), ErrPlaylistFull)
+		td.Cmp(t, pl.SwitchPlaylist(ctx, "saved"), ErrPlaylistNotFound, "плейлист не создан")
+	})
+
+	t.Run("smart playlist", func(t *testing.T) {
+		pl, err := New(WithMaxPlaylistSize(2), WithSongs(minuteSong("a")))
+		td.CmpNoError(t, err)
+		all := func(SongInfo) bool { return true }
+		_, err = pl.Library().Add(minuteSong("b"))
+		td.CmpNoError(t, err)
+
+		td.CmpNoError(t, pl.CreateSmartPlaylist(ctx, "all", all))
+		_, err = pl.Library().Add(minuteSong("c"))
+		td.CmpNoError(t, err)
+		td.Cmp(t, pl.RefreshSmartPlaylist(ctx, "all"), ErrPlaylistFull)
+		td.CmpNoError(t, pl.SwitchPlaylist(ctx, "all"))
+		td.Cmp(t, names(pl), []string{"a", "b"}, "плейлист не изменился")
+
+		td.Cmp(t, pl.CreateSmartPlaylist(ctx, "more", all), ErrPlaylistFull)
+		td.Cmp(t, pl.SwitchPlaylist(ctx, "more"), ErrPlaylistNotFound, "плейлист не создан")
+	})
+
+	t.Run("restore state", func(t *testing.T) {
+		src, err := New(WithSongs(minuteSongs("a", "b", "c")...))
+		td.CmpNoError(t, err)
+		state, err := src.Snapshot(ctx)
+		td.CmpNoError(t, err)
+
+		pl, err := New(WithMaxPlaylistSize(2), WithSongs(minuteSong("x")))
+		td.CmpNoError(t, err)
+		td.CmpTrue(t, errors.Is(pl.RestoreState(ctx, state), ErrPlaylistFull))
+		td.Cmp(t, names(pl), []string{"x"}, "состояние не изменилось")
+	})
+
+	t.Run("undo and redo", func(t *testing.T) {
+		pl, err := New(WithSongs(minuteSongs("a", "b", "c")...))
+		td.CmpNoError(t, err)
+
+		td.CmpNoError(t, pl.RemoveAt(ctx, 0))
+		td.CmpNoError(t, WithMaxPlaylistSize(2)(pl))
+		td.Cmp(t, pl.Undo(ctx), ErrPlaylistFull, "удалённая песня не помещается")
+		td.Cmp(t, names(pl), []string{"b", "c"})
+		td.CmpNoError(t, WithMaxPlaylistSize(3)(pl))
+		td.CmpNoError(t, pl.Undo(ctx), "изменение осталось в истории")
+		td.Cmp(t, names(pl), []string{"a", "b", "c"})
+
+		_, err = pl.AddSong(ctx, minuteSong("d"))
+		td.CmpTrue(t, errors.Is(err, ErrPlaylistFull))
+		td.CmpNoError(t, WithMaxPlaylistSize(4)(pl))
+		_, err = pl.AddSong(ctx, minuteSong("d"))
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, pl.Undo(ctx))
+		td.CmpNoError(t, WithMaxPlaylistSize(3)(pl))
+		td.Cmp(t, pl.Redo(ctx), ErrPlaylistFull, "добавление не помещается")
+		td.Cmp(t, names(pl), []string{"a", "b", "c"})
+
+		td.CmpNoError(t, pl.ClearPlaylist(ctx))
+		td.CmpNoError(t, WithMaxPlaylistSize(2)(pl))
+		td.Cmp(t, pl.Undo(ctx), ErrPlaylistFull, "очищенный плейлист не помещается")
+		td.Cmp(t, names(pl), td.Empty())
+	})
+
+	t.Run("options", func(t *testing.T) {
+		_, err := New(WithMaxPlaylistSize(0))
+		td.CmpString(t, err, "max playlist size must be positive")
+		_, err = New(WithMaxTotalDuration(-time.Second))
+		td.CmpString(t, err, "max total duration must be positive")
+		_, err = New(WithEvictionPolicy(EvictionPolicy(7)))
+		td.CmpString(t, err, "unknown eviction policy EvictionPolicy(7)")
+
+		_, err = New(WithMaxPlaylistSize(1), WithSongs(minuteSong("a"), minuteSong("b")))
+		td.CmpContains(t, err, ErrPlaylistFull.Error(), "начальные песни тоже ограничены")
+	})
+}
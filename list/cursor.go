@@ -0,0 +1,62 @@
+package list
+
+// Cursor - позиция в списке. Если удалить её элемент через Cursor.Remove,
+// курсор переходит на следующий элемент, а с последнего - на предыдущий.
+type Cursor[T any] struct {
+	list *List[T]
+	elem *Element[T]
+}
+
+// Cursor - создаёт курсор на элементе e списка, nil - вне списка.
+func (l *List[T]) Cursor(e *Element[T]) *Cursor[T] {
+	return &Cursor[T]{list: l, elem: e}
+}
+
+// Element - текущий элемент или nil, если курсор вне списка.
+func (c *Cursor[T]) Element() *Element[T] {
+	return c.elem
+}
+
+// Seek - переставляет курсор на элемент e списка, nil - вне списка.
+func (c *Cursor[T]) Seek(e *Element[T]) {
+	c.elem = e
+}
+
+// Next - переходит на следующий элемент, false - курсор на последнем
+// или вне списка и не сдвинулся.
+func (c *Cursor[T]) Next() bool {
+	if c.elem == nil || c.elem.next == nil {
+		return false
+	}
+
+	c.elem = c.elem.next
+	return true
+}
+
+// Prev - переходит на предыдущий элемент, false - курсор на первом
+// или вне списка и не сдвинулся.
+func (c *Cursor[T]) Prev() bool {
+	if c.elem == nil || c.elem.prev == nil {
+		return false
+	}
+
+	c.elem = c.elem.prev
+	return true
+}
+
+// Remove - удаляет текущий элемент и переходит на следующий, а если его нет -
+// на предыдущий. false - курсор вне списка и ничего не удалено.
+func (c *Cursor[T]) Remove() (T, bool) {
+	e := c.elem
+	if e == nil {
+		var zero T
+		return zero, false
+	}
+
+	c.elem = e.next
+	if c.elem == nil {
+		c.elem = e.prev
+	}
+
+	return c.list.Remove(e), true
+}
@@ -0,0 +1,37 @@
+package list
+
+import (
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestCursor(t *testing.T) {
+	l := New("a", "b", "c")
+	c := l.Cursor(l.Front())
+
+	td.CmpFalse(t, c.Prev(), "на первом")
+	td.CmpTrue(t, c.Next())
+	td.Cmp(t, c.Element().Value, "b")
+
+	v, ok := c.Remove()
+	td.CmpTrue(t, ok)
+	td.Cmp(t, v, "b")
+	td.Cmp(t, c.Element().Value, "c", "после удаления - на следующем")
+
+	v, _ = c.Remove()
+	td.Cmp(t, v, "c")
+	td.Cmp(t, c.Element().Value, "a", "с последнего - на предыдущий")
+	td.CmpFalse(t, c.Next())
+
+	c.Remove()
+	td.CmpNil(t, c.Element(), "список пуст")
+	_, ok = c.Remove()
+	td.CmpFalse(t, ok)
+	td.CmpFalse(t, c.Next())
+	td.CmpFalse(t, c.Prev())
+
+	c.Seek(l.PushBack("d"))
+	td.Cmp(t, c.Element().Value, "d")
+	check(t, l, []string{"d"}, "список")
+}
@@ -0,0 +1,297 @@
+// Package list - обобщённый двусвязный список, на котором построен плейлист плеера.
+//
+// В отличие от container/list, элементы типизированы, а нулевой List
+// готов к работе и его можно копировать по значению, если исходный список
+// дальше не используется: элементы помечены не самим списком, а его меткой,
+// которая переходит к копии. Как и в container/list, методы List
+// ничего не делают с элементами, которые уже удалены или из другого списка.
+//
+//	var l list.List[string]
+//	a := l.PushBack("a")
+//	l.PushBack("b")
+//	l.MoveToBack(a)
+//	l.Values() // [b a]
+//
+// Cursor - позиция в списке, которая переживает удаление своего элемента.
+package list
+
+// Element - элемент списка.
+type Element[T any] struct {
+	// Value - значение элемента
+	Value T
+
+	next *Element[T]
+	prev *Element[T]
+	// owner - метка списка, в котором элемент, nil для удалённого
+	owner *owner
+}
+
+// owner - метка списка в его элементах. Не пустая структура,
+// чтобы указатели на разные метки не совпадали.
+type owner struct{ _ byte }
+
+// Next - следующий элемент или nil, если e последний.
+func (e *Element[T]) Next() *Element[T] {
+	return e.next
+}
+
+// Prev - предыдущий элемент или nil, если e первый.
+func (e *Element[T]) Prev() *Element[T] {
+	return e.prev
+}
+
+// List - двусвязный список. Нулевое значение - пустой список.
+type List[T any] struct {
+	front *Element[T]
+	back  *Element[T]
+	len   int
+	// owner - метка элементов списка, создаётся при первой вставке
+	owner *owner
+}
+
+// New - создаёт список из values.
+func New[T any](values ...T) *List[T] {
+	l := &List[T]{}
+	for _, v := range values {
+		l.PushBack(v)
+	}
+
+	return l
+}
+
+// Len - количество элементов.
+func (l *List[T]) Len() int {
+	return l.len
+}
+
+// Front - первый элемент или nil для пустого списка.
+func (l *List[T]) Front() *Element[T] {
+	return l.front
+}
+
+// Back - последний элемент или nil для пустого списка.
+func (l *List[T]) Back() *Element[T] {
+	return l.back
+}
+
+// PushBack - добавляет значение в конец списка.
+func (l *List[T]) PushBack(v T) *Element[T] {
+	return l.link(&Element[T]{Value: v}, l.back, nil)
+}
+
+// PushFront - добавляет значение в начало списка.
+func (l *List[T]) PushFront(v T) *Element[T] {
+	return l.link(&Element[T]{Value: v}, nil, l.front)
+}
+
+// InsertBefore - вставляет значение перед mark.
+// Если mark не из списка, ничего не вставляет и возвращает nil.
+func (l *List[T]) InsertBefore(v T, mark *Element[T]) *Element[T] {
+	if !l.contains(mark) {
+		return nil
+	}
+
+	return l.link(&Element[T]{Value: v}, mark.prev, mark)
+}
+
+// InsertAfter - вставляет значение после mark.
+// Если mark не из списка, ничего не вставляет и возвращает nil.
+func (l *List[T]) InsertAfter(v T, mark *Element[T]) *Element[T] {
+	if !l.contains(mark) {
+		return nil
+	}
+
+	return l.link(&Element[T]{Value: v}, mark, mark.next)
+}
+
+// Insert - вставляет значение на позицию index, считая с нуля.
+// Если index за пределами списка, значение добавляется в конец.
+func (l *List[T]) Insert(index int, v T) *Element[T] {
+	at := l.At(index)
+	if at == nil {
+		return l.PushBack(v)
+	}
+
+	return l.InsertBefore(v, at)
+}
+
+// Remove - исключает e из списка и возвращает его значение.
+// Уже удалённый элемент и элемент другого списка остаются как есть.
+func (l *List[T]) Remove(e *Element[T]) T {
+	if l.contains(e) {
+		l.unlink(e)
+	}
+
+	return e.Value
+}
+
+// MoveToFront - переставляет e в начало списка.
+func (l *List[T]) MoveToFront(e *Element[T]) {
+	if !l.contains(e) || e == l.front {
+		return
+	}
+
+	l.unlink(e)
+	l.link(e, nil, l.front)
+}
+
+// MoveToBack - переставляет e в конец списка.
+func (l *List[T]) MoveToBack(e *Element[T]) {
+	if !l.contains(e) || e == l.back {
+		return
+	}
+
+	l.unlink(e)
+	l.link(e, l.back, nil)
+}
+
+// MoveBefore - переставляет e перед mark.
+func (l *List[T]) MoveBefore(e, mark *Element[T]) {
+	if !l.contains(e) || !l.contains(mark) || e == mark || e.next == mark {
+		return
+	}
+
+	l.unlink(e)
+	l.link(e, mark.prev, mark)
+}
+
+// MoveAfter - переставляет e после mark.
+func (l *List[T]) MoveAfter(e, mark *Element[T]) {
+	if !l.contains(e) || !l.contains(mark) || e == mark || e.prev == mark {
+		return
+	}
+
+	l.unlink(e)
+	l.link(e, mark, mark.next)
+}
+
+// Move - переставляет e на позицию index, считая с нуля.
+// Если index за пределами списка, e переставляется в конец.
+func (l *List[T]) Move(e *Element[T], index int) {
+	if !l.contains(e) {
+		return
+	}
+
+	l.unlink(e)
+	if at := l.At(index); at != nil {
+		l.link(e, at.prev, at)
+		return
+	}
+
+	l.link(e, l.back, nil)
+}
+
+// At - элемент на позиции index, считая с нуля, или nil, если её нет.
+// Обход идёт с ближайшего к index конца списка.
+func (l *List[T]) At(index int) *Element[T] {
+	if index < 0 || index >= l.len {
+		return nil
+	}
+
+	if index < l.len/2 {
+		e := l.front
+		for i := 0; i < index; i++ {
+			e = e.next
+		}
+		return e
+	}
+
+	e := l.back
+	for i := l.len - 1; i > index; i-- {
+		e = e.prev
+	}
+	return e
+}
+
+// Index - позиция e в списке, считая с нуля, -1 если e не из списка.
+func (l *List[T]) Index(e *Element[T]) int {
+	if !l.contains(e) {
+		return -1
+	}
+
+	i := 0
+	for e = e.prev; e != nil; e = e.prev {
+		i++
+	}
+
+	return i
+}
+
+// Each - вызывает fn для элементов по порядку, пока fn возвращает true.
+// fn может удалить из списка переданный ей элемент.
+func (l *List[T]) Each(fn func(e *Element[T]) bool) {
+	for e := l.front; e != nil; {
+		next := e.next
+		if !fn(e) {
+			return
+		}
+		e = next
+	}
+}
+
+// Values - значения элементов по порядку.
+func (l *List[T]) Values() []T {
+	values := make([]T, 0, l.len)
+	for e := l.front; e != nil; e = e.next {
+		values = append(values, e.Value)
+	}
+
+	return values
+}
+
+// Clear - удаляет все элементы.
+func (l *List[T]) Clear() {
+	for e := l.front; e != nil; {
+		next := e.next
+		e.next, e.prev, e.owner = nil, nil, nil
+		e = next
+	}
+
+	l.front, l.back, l.len = nil, nil, 0
+}
+
+// contains - e входит в список.
+func (l *List[T]) contains(e *Element[T]) bool {
+	return e != nil && e.owner != nil && e.owner == l.owner
+}
+
+// link - вставляет e между prev и next, соседними элементами списка или nil на его концах.
+func (l *List[T]) link(e, prev, next *Element[T]) *Element[T] {
+	if l.owner == nil {
+		l.owner = new(owner)
+	}
+
+	e.prev, e.next, e.owner = prev, next, l.owner
+	if prev != nil {
+		prev.next = e
+	} else {
+		l.front = e
+	}
+
+	if next != nil {
+		next.prev = e
+	} else {
+		l.back = e
+	}
+
+	l.len++
+	return e
+}
+
+// unlink - исключает e из списка.
+func (l *List[T]) unlink(e *Element[T]) {
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		l.front = e.next
+	}
+
+	if e.next != nil {
+		e.next.prev = e.prev
+	} else {
+		l.back = e.prev
+	}
+
+	e.next, e.prev, e.owner = nil, nil, nil
+	l.len--
+}
@@ -0,0 +1,167 @@
+package list
+
+import (
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// backwards - значения списка с конца, для проверки обратных ссылок.
+func backwards[T any](l *List[T]) []T {
+	values := []T{}
+	for e := l.Back(); e != nil; e = e.Prev() {
+		values = append([]T{e.Value}, values...)
+	}
+	return values
+}
+
+// check - сверяет значения списка в обе стороны и длину.
+func check(t *testing.T, l *List[string], want []string, name string) {
+	t.Helper()
+	td.Cmp(t, l.Values(), want, name)
+	td.Cmp(t, backwards(l), want, name+": обратные ссылки согласованы")
+	td.Cmp(t, l.Len(), len(want), name+": длина")
+}
+
+func TestList(t *testing.T) {
+	t.Run("zero", func(t *testing.T) {
+		var l List[string]
+		td.CmpNil(t, l.Front())
+		td.CmpNil(t, l.Back())
+		td.CmpNil(t, l.At(0))
+		check(t, &l, []string{}, "пустой список")
+
+		a := l.PushBack("a")
+		td.Cmp(t, l.Front(), a)
+		td.Cmp(t, l.Back(), a)
+		td.CmpNil(t, a.Next())
+		td.CmpNil(t, a.Prev())
+	})
+
+	t.Run("insert", func(t *testing.T) {
+		l := New("b", "d")
+		l.PushFront("a")
+		l.InsertAfter("c", l.At(1))
+		l.InsertBefore("x", l.Front())
+		check(t, l, []string{"x", "a", "b", "c", "d"}, "push и insert")
+
+		l.Insert(2, "y")
+		l.Insert(100, "z")
+		check(t, l, []string{"x", "a", "y", "b", "c", "d", "z"}, "по индексу, за пределами - в конец")
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		l := New("a", "b", "c")
+		td.Cmp(t, l.Remove(l.At(1)), "b")
+		check(t, l, []string{"a", "c"}, "из середины")
+
+		l.Remove(l.Front())
+		l.Remove(l.Back())
+		check(t, l, []string{}, "с концов")
+		td.CmpNil(t, l.Front())
+	})
+
+	t.Run("double remove", func(t *testing.T) {
+		l := New("a", "b")
+		b := l.Back()
+		td.Cmp(t, l.Remove(b), "b")
+		td.Cmp(t, l.Remove(b), "b", "значение возвращается и для удалённого")
+		check(t, l, []string{"a"}, "повторное удаление ничего не меняет")
+
+		l.MoveToFront(b)
+		l.MoveToBack(b)
+		l.MoveBefore(b, l.Front())
+		l.MoveAfter(l.Front(), b)
+		l.Move(b, 0)
+		td.CmpNil(t, l.InsertAfter("x", b))
+		td.Cmp(t, l.Index(b), -1)
+		check(t, l, []string{"a"}, "удалённый элемент не возвращается")
+	})
+
+	t.Run("foreign element", func(t *testing.T) {
+		l, other := New("a", "b"), New("x", "y")
+		x := other.Front()
+
+		l.Remove(x)
+		l.MoveToFront(other.Back())
+		l.MoveToBack(x)
+		l.MoveBefore(x, l.Front())
+		l.MoveAfter(x, l.Back())
+		l.MoveBefore(l.Front(), x)
+		l.MoveAfter(l.Front(), x)
+		l.Move(x, 0)
+		td.CmpNil(t, l.InsertBefore("z", x))
+		td.Cmp(t, l.Index(x), -1)
+		check(t, l, []string{"a", "b"}, "список не изменился")
+		check(t, other, []string{"x", "y"}, "чужой список тоже")
+	})
+
+	t.Run("move", func(t *testing.T) {
+		l := New("a", "b", "c", "d")
+		a, b, c, d := l.At(0), l.At(1), l.At(2), l.At(3)
+
+		l.MoveToBack(a)
+		check(t, l, []string{"b", "c", "d", "a"}, "MoveToBack")
+		l.MoveToFront(a)
+		check(t, l, []string{"a", "b", "c", "d"}, "MoveToFront")
+		l.MoveAfter(a, c)
+		check(t, l, []string{"b", "c", "a", "d"}, "MoveAfter")
+		l.MoveBefore(d, b)
+		check(t, l, []string{"d", "b", "c", "a"}, "MoveBefore")
+		l.MoveBefore(b, c)
+		l.MoveAfter(c, b)
+		l.MoveAfter(c, c)
+		check(t, l, []string{"d", "b", "c", "a"}, "на своё место - без изменений")
+
+		l.Move(d, 2)
+		check(t, l, []string{"b", "c", "d", "a"}, "Move")
+		l.Move(b, 10)
+		check(t, l, []string{"c", "d", "a", "b"}, "Move за пределы - в конец")
+	})
+
+	t.Run("index", func(t *testing.T) {
+		l := New("a", "b", "c", "d", "e")
+		for i := 0; i < l.Len(); i++ {
+			td.Cmp(t, l.At(i).Value, string(rune('a'+i)))
+			td.Cmp(t, l.Index(l.At(i)), i)
+		}
+		td.CmpNil(t, l.At(-1))
+		td.CmpNil(t, l.At(5))
+	})
+
+	t.Run("each", func(t *testing.T) {
+		l := New("a", "b", "c", "d")
+		var seen []string
+		l.Each(func(e *Element[string]) bool {
+			seen = append(seen, e.Value)
+			if e.Value == "b" {
+				l.Remove(e)
+			}
+			return e.Value != "c"
+		})
+		td.Cmp(t, seen, []string{"a", "b", "c"}, "останавливается по false")
+		check(t, l, []string{"a", "c", "d"}, "удаление при обходе")
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		l := New("a", "b")
+		a := l.Front()
+		l.Clear()
+		check(t, l, []string{}, "пустой")
+		td.CmpNil(t, a.Next(), "элементы отвязаны")
+
+		l.PushBack("c")
+		check(t, l, []string{"c"}, "после очистки")
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		var l List[string]
+		l.PushBack("a")
+		moved := l
+		moved.PushBack("b")
+		check(t, &moved, []string{"a", "b"}, "копия по значению работает")
+
+		moved.Remove(moved.Front())
+		check(t, &moved, []string{"b"}, "элементы принадлежат копии")
+	})
+}
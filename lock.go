@@ -0,0 +1,54 @@
+package player
+
+import "context"
+
+// lock - захватывает блокировку плеера на запись, пока не завершился ctx.
+// Если ctx завершился раньше, блокировка не захвачена и возвращается ctx.Err(),
+// поэтому метод не зависает, пока блокировку держит, например,
+// медленный бэкенд или подписчик с SubscribeBackpressure(Block).
+func (p *playerImpl) lock(ctx context.Context) error {
+	return acquire(ctx, p.mu.TryLock, p.mu.Lock, p.mu.Unlock)
+}
+
+// rlock - как lock, но захватывает блокировку на чтение.
+func (p *playerImpl) rlock(ctx context.Context) error {
+	return acquire(ctx, p.mu.TryRLock, p.mu.RLock, p.mu.RUnlock)
+}
+
+// acquire - захватывает блокировку, пока не завершился ctx. Если ждать приходится,
+// блокировку ждёт отдельная горутина и передаёт её вызвавшему, а если он уже
+// ушёл по ctx - сразу отпускает.
+func acquire(ctx context.Context, try func() bool, lock, unlock func()) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if try() {
+		return nil
+	}
+
+	// ctx не завершится никогда
+	if ctx.Done() == nil {
+		lock()
+		return nil
+	}
+
+	acquired := make(chan struct{})
+	abandoned := make(chan struct{})
+	go func() {
+		lock()
+		select {
+		case acquired <- struct{}{}:
+		case <-abandoned:
+			unlock()
+		}
+	}()
+
+	select {
+	case <-acquired:
+		return nil
+	case <-ctx.Done():
+		close(abandoned)
+		return ctx.Err()
+	}
+}
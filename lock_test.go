@@ -0,0 +1,107 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Deadlines(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("busy", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSongs("a", "b")...))
+		td.CmpNoError(t, pl.Play(ctx))
+
+		// блокировку держит, например, зависший бэкенд
+		pl.mu.Lock()
+		for name, call := range map[string]func(ctx context.Context) error{
+			"AddSong": func(ctx context.Context) error {
+				_, err := pl.AddSong(ctx, minuteSong("c"))
+				return err
+			},
+			"AddSongs": func(ctx context.Context) error { return pl.AddSongs(ctx, minuteSong("c"))[0] },
+			"Next":     pl.Next,
+			"Prev":     pl.Prev,
+			"Pause":    pl.Pause,
+			"Volume":   func(ctx context.Context) error { return pl.SetVolume(ctx, 10) },
+			"Close":    pl.Close,
+			"Undo":     pl.Undo,
+			"Switch":   func(ctx context.Context) error { return pl.SwitchPlaylist(ctx, DefaultPlaylist) },
+			"Enqueue":  func(ctx context.Context) error { return pl.Enqueue(ctx, minuteSong("c")) },
+			"PlayFrom": func(ctx context.Context) error { return pl.PlayFrom(ctx, 1, 0) },
+			"Merge": func(ctx context.Context) error {
+				_, err := pl.Merge(ctx, minuteSongs("c"), MergeAppendMissing)
+				return err
+			},
+			"Search": func(ctx context.Context) error {
+				_, err := pl.Search(ctx, "a")
+				return err
+			},
+			"WaitFor": func(ctx context.Context) error {
+				return pl.WaitFor(ctx, func(Status) bool { return true })
+			},
+			"ClearPlaylist": pl.ClearPlaylist,
+			"Deduplicate": func(ctx context.Context) error {
+				_, err := pl.Deduplicate(ctx)
+				return err
+			},
+			"History": func(ctx context.Context) error {
+				_, err := pl.History(ctx, 0)
+				return err
+			},
+			"ClearHistory":         pl.ClearHistory,
+			"CancelSleepTimer":     pl.CancelSleepTimer,
+			"ClearLoopSection":     pl.ClearLoopSection,
+			"ClearResumePositions": pl.ClearResumePositions,
+		} {
+			tctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+			td.CmpTrue(t, errors.Is(call(tctx), context.DeadlineExceeded), name)
+			cancel()
+		}
+		pl.mu.Unlock()
+
+		st := pl.Status(ctx)
+		td.Cmp(t, st.Song.Name, "a", "ничего не изменилось")
+		td.CmpTrue(t, st.Playing)
+		td.Cmp(t, st.Volume, MaxVolume)
+		td.Cmp(t, names(pl), []string{"a", "b"})
+		td.CmpEmpty(t, pl.QueueRemaining(ctx))
+		td.CmpTrue(t, pl.Healthz(ctx).OK, "плеер не закрыт")
+
+		// брошенные ожидания отпустили блокировку
+		td.CmpNoError(t, pl.Next(ctx))
+		td.Cmp(t, pl.Status(ctx).Song.Name, "b")
+	})
+
+	t.Run("handoff", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSongs("a", "b")...))
+
+		pl.mu.Lock()
+		done := make(chan error, 1)
+		go func() {
+			tctx, cancel := context.WithTimeout(ctx, time.Second)
+			defer cancel()
+			_, err := pl.AddSong(tctx, minuteSong("c"))
+			done <- err
+		}()
+		time.Sleep(10 * time.Millisecond)
+		pl.mu.Unlock()
+
+		td.CmpNoError(t, <-done, "блокировка освободилась до срока")
+		td.Cmp(t, names(pl), []string{"a", "b", "c"})
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSongs("a", "b")...))
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		td.Cmp(t, pl.Play(cctx), context.Canceled)
+		td.CmpFalse(t, pl.Status(ctx).Playing, "завершённый ctx не запускает воспроизведение")
+		td.Cmp(t, pl.AddSongs(cctx, minuteSong("c")), []error{context.Canceled})
+	})
+}
@@ -0,0 +1,35 @@
+package player
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithLogger - задаёт логгер для переходов состояния, ошибок воспроизведения
+// и изменений плейлистов. По умолчанию логи отбрасываются.
+func WithLogger(l *slog.Logger) Option {
+	return func(p *playerImpl) error {
+		if l == nil {
+			l = slog.New(discardHandler{})
+		}
+
+		p.logger = l
+		return nil
+	}
+}
+
+// discardHandler - обработчик, отбрасывающий все записи.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool { return false }
+
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h discardHandler) WithGroup(string) slog.Handler { return h }
+
+// songAttr - атрибут лога с названием песни.
+func songAttr(song Song) slog.Attr {
+	return slog.String("song", song.Name)
+}
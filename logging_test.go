@@ -0,0 +1,65 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// syncBuffer - буфер для логов, в который пишет горутина воспроизведения.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+// failingOutput - бэкенд, который не может остановить песню.
+type failingOutput struct{}
+
+func (failingOutput) Start(context.Context, Song, time.Duration) error { return nil }
+
+func (failingOutput) Stop(context.Context, Song) error { return errors.New("device unplugged") }
+
+func TestWithLogger(t *testing.T) {
+	ctx := context.Background()
+
+	var buf syncBuffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	pl, err := New(WithValidator(ValidationPolicy{}), WithLogger(logger), WithOutput(failingOutput{}), WithSongs(
+		Song{Name: "a", Duration: 20 * time.Millisecond},
+		Song{Name: "b", Duration: 30 * time.Second},
+	))
+	td.CmpNoError(t, err)
+
+	_ = pl.Play(ctx)
+	time.Sleep(30 * time.Millisecond)
+	_ = pl.Pause(ctx)
+
+	logs := buf.String()
+	td.Cmp(t, logs, td.Contains(`msg="song added" song=a playlist=default`))
+	td.Cmp(t, logs, td.Contains(`msg="playback started" song=a`))
+	td.Cmp(t, logs, td.Contains(`msg="song finished" song=a played=20ms completed=true`))
+	td.Cmp(t, logs, td.Contains(`level=ERROR msg="output stop failed" song=a error="device unplugged"`),
+		"ошибка в горутине воспроизведения залогирована")
+	td.Cmp(t, logs, td.Contains(`msg="playback paused" song=b`))
+}
@@ -0,0 +1,63 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// loopSection - участок песни между start и end, который повторяется по кругу.
+type loopSection struct {
+	node  *playerNode
+	start time.Duration
+	end   time.Duration
+}
+
+// SetLoopSection - повторяет участок текущей песни между start и end,
+// пока не будет вызван ClearLoopSection или не сменится песня.
+// Если позиция уже дальше end, воспроизведение сразу переходит к start.
+func (p *playerImpl) SetLoopSection(ctx context.Context, start, end time.Duration) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if p.current == nil {
+		return errors.New("playlist is empty")
+	}
+
+	if start < 0 || start >= end || end > p.current.song.Duration {
+		return fmt.Errorf("invalid loop section [%v, %v) for song of %v", start, end, p.current.song.Duration)
+	}
+
+	p.section = &loopSection{node: p.current, start: start, end: end}
+	if p.elapsedLocked() >= end {
+		p.seekLocked(ctx, start)
+	}
+
+	p.rescheduleLocked()
+	return nil
+}
+
+// ClearLoopSection - отключает повтор участка песни.
+func (p *playerImpl) ClearLoopSection(ctx context.Context) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	p.section = nil
+	p.rescheduleLocked()
+	return nil
+}
+
+// loopActiveLocked - сообщает, повторяется ли участок текущей песни.
+// Вызывается под блокировкой.
+func (p *playerImpl) loopActiveLocked() bool {
+	return p.section != nil && p.section.node == p.current
+}
@@ -0,0 +1,70 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_LoopSection(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("invalid", func(t *testing.T) {
+		pl, _ := NewPlayer()
+		td.CmpError(t, pl.SetLoopSection(ctx, 0, time.Second), "пустой плейлист")
+
+		_, _ = pl.AddSong(ctx, Song{Name: "a", Duration: time.Second})
+		td.CmpError(t, pl.SetLoopSection(ctx, 500*time.Millisecond, 100*time.Millisecond))
+		td.CmpError(t, pl.SetLoopSection(ctx, -time.Millisecond, 100*time.Millisecond))
+		td.CmpError(t, pl.SetLoopSection(ctx, 0, 2*time.Second))
+	})
+
+	t.Run("loops until cleared", func(t *testing.T) {
+		out := &recordingOutput{}
+		pl := newFakePlayer(t, WithOutput(out), WithSongs(
+			Song{Name: "a", Duration: 100 * time.Second},
+			Song{Name: "b", Duration: 30 * time.Minute},
+		))
+
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpNoError(t, pl.SetLoopSection(ctx, 10*time.Second, 40*time.Second))
+		_, err := pl.SimulatePlayback(ctx, 120*time.Second)
+		td.CmpNoError(t, err)
+
+		td.Cmp(t, pl.Status(ctx).Song.Name, "a", "песня не закончилась")
+		td.Cmp(t, pl.Elapsed(ctx), 30*time.Second, "четвёртый круг участка")
+		td.Cmp(t, pl.Metrics(ctx).SongsPlayed, 0)
+		td.Cmp(t, out.Calls(), td.Len(td.Gte(5)), "участок повторился несколько раз")
+
+		td.CmpNoError(t, pl.ClearLoopSection(ctx))
+		_, err = pl.SimulatePlayback(ctx, 100*time.Second)
+		td.CmpNoError(t, err)
+		td.Cmp(t, pl.Status(ctx).Song.Name, "b", "после отключения песня доиграла")
+		td.CmpNoError(t, pl.Pause(ctx))
+	})
+
+	t.Run("jumps back when past end", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSong("a")))
+		td.CmpNoError(t, pl.Play(ctx))
+		_, err := pl.SimulatePlayback(ctx, 30*time.Second)
+		td.CmpNoError(t, err)
+
+		td.CmpNoError(t, pl.SetLoopSection(ctx, 10*time.Second, 20*time.Second))
+		td.Cmp(t, pl.Elapsed(ctx), 10*time.Second)
+	})
+
+	t.Run("cleared on song change", func(t *testing.T) {
+		pl, _ := NewPlayer(
+			Song{Name: "a", Duration: time.Second},
+			Song{Name: "b", Duration: time.Second},
+		)
+		_ = pl.SetLoopSection(ctx, 0, 500*time.Millisecond)
+		_ = pl.Next(ctx)
+		_ = pl.Prev(ctx)
+		_ = pl.Pause(ctx)
+
+		td.CmpNil(t, pl.section)
+	})
+}
@@ -0,0 +1,139 @@
+// Package mediakeys - управление плеером системными медиаклавишами.
+//
+// Bind читает нажатия из Source и вызывает соответствующие методы player.Player.
+// Источник системных клавиш возвращает System; он собирается только с тегом
+// mediakeys, чтобы обычная сборка не обращалась к устройствам ввода:
+//
+//	go build -tags mediakeys ./...
+//
+//	src, err := mediakeys.System()
+//	if err != nil { ... }
+//	err = mediakeys.Bind(ctx, pl, src)
+//
+// В тестах вместо System подставляется свой Source, например Keys.
+package mediakeys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"player"
+)
+
+// ErrUnsupported - системные медиаклавиши недоступны на этой платформе или в этой сборке.
+var ErrUnsupported = errors.New("media keys are not supported")
+
+// Key - медиаклавиша.
+type Key int
+
+const (
+	// KeyPlayPause - переключает воспроизведение и паузу
+	KeyPlayPause Key = iota
+	// KeyPlay - начинает воспроизведение
+	KeyPlay
+	// KeyPause - приостанавливает воспроизведение
+	KeyPause
+	// KeyStop - останавливает воспроизведение, для плеера это пауза
+	KeyStop
+	// KeyNext - следующая песня
+	KeyNext
+	// KeyPrev - предыдущая песня
+	KeyPrev
+)
+
+func (k Key) String() string {
+	switch k {
+	case KeyPlayPause:
+		return "play/pause"
+	case KeyPlay:
+		return "play"
+	case KeyPause:
+		return "pause"
+	case KeyStop:
+		return "stop"
+	case KeyNext:
+		return "next"
+	case KeyPrev:
+		return "prev"
+	default:
+		return fmt.Sprintf("Key(%d)", int(k))
+	}
+}
+
+// Source - источник нажатий медиаклавиш.
+type Source interface {
+	// Listen - начинает слушать клавиши. Канал закрывается, когда отменён ctx
+	// или источник больше не может читать нажатия.
+	Listen(ctx context.Context) (<-chan Key, error)
+}
+
+// Keys - Source, который отдаёт нажатия из канала, удобен для тестов и своих интеграций.
+type Keys <-chan Key
+
+func (k Keys) Listen(ctx context.Context) (<-chan Key, error) {
+	out := make(chan Key)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case key, ok := <-k:
+				if !ok {
+					return
+				}
+
+				select {
+				case out <- key:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// Handle - выполняет действие клавиши key.
+func Handle(ctx context.Context, pl player.Player, key Key) error {
+	switch key {
+	case KeyPlayPause:
+		if pl.Status(ctx).Playing {
+			return pl.Pause(ctx)
+		}
+		return pl.Play(ctx)
+	case KeyPlay:
+		return pl.Play(ctx)
+	case KeyPause, KeyStop:
+		if !pl.Status(ctx).Playing {
+			return nil
+		}
+		return pl.Pause(ctx)
+	case KeyNext:
+		return pl.Next(ctx)
+	case KeyPrev:
+		return pl.Prev(ctx)
+	default:
+		return fmt.Errorf("unknown key %v", key)
+	}
+}
+
+// Bind - выполняет нажатия из src, пока не отменён ctx или src не закроет канал.
+// Ошибки плеера не прерывают работу и пишутся в slog.Default.
+func Bind(ctx context.Context, pl player.Player, src Source) error {
+	keys, err := src.Listen(ctx)
+	if err != nil {
+		return err
+	}
+
+	for key := range keys {
+		if err := Handle(ctx, pl, key); err != nil {
+			slog.Default().WarnContext(ctx, "media key failed", slog.String("key", key.String()), slog.Any("error", err))
+		}
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,76 @@
+package mediakeys
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"player"
+	"player/playertest"
+)
+
+func TestBind(t *testing.T) {
+	ctx := context.Background()
+	songs := []player.Song{{Name: "a", Duration: time.Minute}, {Name: "b", Duration: time.Minute}}
+
+	t.Run("keys", func(t *testing.T) {
+		fake := playertest.NewFake(songs...)
+		keys := make(chan Key, 8)
+		keys <- KeyPlayPause
+		keys <- KeyNext
+		keys <- KeyPrev
+		keys <- KeyPlayPause
+		keys <- KeyStop
+		keys <- KeyPlay
+		keys <- KeyPause
+		close(keys)
+
+		td.CmpNoError(t, Bind(ctx, fake, Keys(keys)))
+		td.Cmp(t, fake.Calls(), td.Smuggle(func(calls []playertest.Call) []string {
+			var methods []string
+			for _, c := range calls {
+				methods = append(methods, c.Method)
+			}
+			return methods
+		}, []string{"Status", "Play", "Next", "Prev", "Status", "Pause", "Status", "Play", "Status", "Pause"}),
+			"повторная пауза без Pause")
+		td.CmpFalse(t, fake.Status(ctx).Playing)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		fake := playertest.NewFake(songs...)
+		fake.FailNext("Next", errors.New("no output"))
+
+		keys := make(chan Key, 2)
+		keys <- KeyNext
+		keys <- KeyNext
+		close(keys)
+
+		td.CmpNoError(t, Bind(ctx, fake, Keys(keys)), "ошибка плеера не прерывает Bind")
+		td.Cmp(t, fake.Called("Next"), 2)
+
+		td.CmpString(t, Handle(ctx, fake, Key(42)), "unknown key Key(42)")
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		done := make(chan error)
+		go func() { done <- Bind(ctx, playertest.NewFake(songs...), Keys(make(chan Key))) }()
+
+		cancel()
+		td.Cmp(t, <-done, context.Canceled)
+	})
+
+	t.Run("source error", func(t *testing.T) {
+		td.Cmp(t, Bind(ctx, playertest.NewFake(), failingSource{}), ErrUnsupported)
+	})
+}
+
+type failingSource struct{}
+
+func (failingSource) Listen(context.Context) (<-chan Key, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,121 @@
+//go:build mediakeys && linux
+
+package mediakeys
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+// Коды клавиш evdev из linux/input-event-codes.h.
+const (
+	evKey = 0x01
+
+	keyNextSong     = 163
+	keyPlayPause    = 164
+	keyPreviousSong = 165
+	keyStopCD       = 166
+	keyPlayCD       = 200
+	keyPauseCD      = 201
+)
+
+// evdevKeys - медиаклавиши по кодам evdev.
+var evdevKeys = map[uint16]Key{
+	keyNextSong:     KeyNext,
+	keyPlayPause:    KeyPlayPause,
+	keyPreviousSong: KeyPrev,
+	keyStopCD:       KeyStop,
+	keyPlayCD:       KeyPlay,
+	keyPauseCD:      KeyPause,
+}
+
+// inputEvent - struct input_event.
+type inputEvent struct {
+	Time  syscall.Timeval
+	Type  uint16
+	Code  uint16
+	Value int32
+}
+
+// Evdev - источник медиаклавиш из устройств ввода Linux.
+// Нужны права на чтение /dev/input, обычно через группу input.
+type Evdev struct {
+	// Paths - устройства, по умолчанию все /dev/input/event*
+	Paths []string
+}
+
+// System - источник медиаклавиш из всех доступных устройств /dev/input/event*.
+func System() (Source, error) {
+	return &Evdev{}, nil
+}
+
+func (e *Evdev) Listen(ctx context.Context) (<-chan Key, error) {
+	paths := e.Paths
+	if len(paths) == 0 {
+		paths, _ = filepath.Glob("/dev/input/event*")
+	}
+
+	var devices []*os.File
+	for _, path := range paths {
+		// устройства без прав на чтение пропускаются
+		if f, err := os.Open(path); err == nil {
+			devices = append(devices, f)
+		}
+	}
+
+	if len(devices) == 0 {
+		return nil, fmt.Errorf("%w: no readable input devices", ErrUnsupported)
+	}
+
+	out := make(chan Key)
+	var wg sync.WaitGroup
+	for _, f := range devices {
+		wg.Add(1)
+		go func(f *os.File) {
+			defer wg.Done()
+			readEvdev(ctx, f, out)
+		}(f)
+	}
+
+	go func() {
+		<-ctx.Done()
+		for _, f := range devices {
+			f.Close()
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out, nil
+}
+
+// readEvdev - читает нажатия медиаклавиш из r до ошибки чтения.
+func readEvdev(ctx context.Context, r io.Reader, out chan<- Key) {
+	for {
+		var ev inputEvent
+		if err := binary.Read(r, binary.NativeEndian, &ev); err != nil {
+			return
+		}
+
+		// 1 - нажатие, 0 - отпускание, 2 - автоповтор
+		key, ok := evdevKeys[ev.Code]
+		if ev.Type != evKey || ev.Value != 1 || !ok {
+			continue
+		}
+
+		select {
+		case out <- key:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,44 @@
+//go:build mediakeys && linux
+
+package mediakeys
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestEvdev_Listen(t *testing.T) {
+	var buf bytes.Buffer
+	for _, ev := range []inputEvent{
+		{Type: evKey, Code: keyPlayPause, Value: 1},
+		{Type: evKey, Code: keyPlayPause, Value: 0},
+		{Type: evKey, Code: 30, Value: 1}, // KEY_A
+		{Type: 0x02, Code: keyNextSong, Value: 1},
+		{Type: evKey, Code: keyNextSong, Value: 2},
+		{Type: evKey, Code: keyPreviousSong, Value: 1},
+	} {
+		td.CmpNoError(t, binary.Write(&buf, binary.NativeEndian, ev))
+	}
+
+	dev := filepath.Join(t.TempDir(), "event0")
+	td.CmpNoError(t, os.WriteFile(dev, buf.Bytes(), 0o600))
+
+	keys, err := (&Evdev{Paths: []string{dev}}).Listen(context.Background())
+	td.CmpNoError(t, err)
+
+	var got []Key
+	for k := range keys {
+		got = append(got, k)
+	}
+	td.Cmp(t, got, []Key{KeyPlayPause, KeyPrev}, "только нажатия медиаклавиш")
+
+	_, err = (&Evdev{Paths: []string{filepath.Join(t.TempDir(), "missing")}}).Listen(context.Background())
+	td.CmpTrue(t, errors.Is(err, ErrUnsupported))
+}
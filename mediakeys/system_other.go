@@ -0,0 +1,9 @@
+//go:build !mediakeys || !(linux || windows)
+
+package mediakeys
+
+// System - источник системных медиаклавиш.
+// В сборке без тега mediakeys и на платформах кроме Linux и Windows возвращает ErrUnsupported.
+func System() (Source, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,118 @@
+//go:build mediakeys && windows
+
+package mediakeys
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	user32   = syscall.NewLazyDLL("user32.dll")
+	kernel32 = syscall.NewLazyDLL("kernel32.dll")
+
+	procRegisterHotKey     = user32.NewProc("RegisterHotKey")
+	procUnregisterHotKey   = user32.NewProc("UnregisterHotKey")
+	procGetMessageW        = user32.NewProc("GetMessageW")
+	procPostThreadMessageW = user32.NewProc("PostThreadMessageW")
+	procGetCurrentThreadId = kernel32.NewProc("GetCurrentThreadId")
+)
+
+const (
+	wmHotkey = 0x0312
+	wmQuit   = 0x0012
+
+	modNoRepeat = 0x4000
+)
+
+// hotkeys - медиаклавиши по виртуальным кодам, ID горячей клавиши - индекс.
+var hotkeys = []struct {
+	vk  uintptr
+	key Key
+}{
+	{0xB0, KeyNext},      // VK_MEDIA_NEXT_TRACK
+	{0xB1, KeyPrev},      // VK_MEDIA_PREV_TRACK
+	{0xB2, KeyStop},      // VK_MEDIA_STOP
+	{0xB3, KeyPlayPause}, // VK_MEDIA_PLAY_PAUSE
+}
+
+// msg - структура MSG.
+type msg struct {
+	hwnd    uintptr
+	message uint32
+	wParam  uintptr
+	lParam  uintptr
+	time    uint32
+	pt      struct{ x, y int32 }
+}
+
+// hotkeySource - источник медиаклавиш через RegisterHotKey.
+type hotkeySource struct{}
+
+// System - источник медиаклавиш через глобальные горячие клавиши Windows.
+// Если медиаклавиши уже заняты другим приложением, Listen возвращает ошибку.
+func System() (Source, error) {
+	return hotkeySource{}, nil
+}
+
+func (hotkeySource) Listen(ctx context.Context) (<-chan Key, error) {
+	out := make(chan Key)
+	started := make(chan error, 1)
+
+	go func() {
+		defer close(out)
+
+		// горячие клавиши и очередь сообщений привязаны к потоку
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		for i, hk := range hotkeys {
+			if ok, _, err := procRegisterHotKey.Call(0, uintptr(i+1), modNoRepeat, hk.vk); ok == 0 {
+				for j := 0; j < i; j++ {
+					procUnregisterHotKey.Call(0, uintptr(j+1))
+				}
+				started <- fmt.Errorf("register %v key: %w", hk.key, err)
+				return
+			}
+		}
+		defer func() {
+			for i := range hotkeys {
+				procUnregisterHotKey.Call(0, uintptr(i+1))
+			}
+		}()
+
+		thread, _, _ := procGetCurrentThreadId.Call()
+		stop := context.AfterFunc(ctx, func() {
+			procPostThreadMessageW.Call(thread, wmQuit, 0, 0)
+		})
+		defer stop()
+		started <- nil
+
+		var m msg
+		for {
+			// 0 - WM_QUIT, -1 - ошибка
+			if r, _, _ := procGetMessageW.Call(uintptr(unsafe.Pointer(&m)), 0, 0, 0); int32(r) <= 0 {
+				return
+			}
+
+			if m.message != wmHotkey || m.wParam == 0 || int(m.wParam) > len(hotkeys) {
+				continue
+			}
+
+			select {
+			case out <- hotkeys[m.wParam-1].key:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	if err := <-started; err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
@@ -0,0 +1,182 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// MergeStrategy - способ слияния активного плейлиста с другим.
+type MergeStrategy int
+
+const (
+	// MergeAppendMissing - добавить в конец песни, которых нет в плейлисте
+	MergeAppendMissing MergeStrategy = iota
+	// MergeReplace - сделать плейлист таким же, как другой, сохранив ID совпадающих песен
+	MergeReplace
+	// MergeInterleave - вставить недостающие песни через одну между песнями плейлиста
+	MergeInterleave
+)
+
+// PlaylistDiff - отличия активного плейлиста от другого списка песен.
+// Песни сравниваются по всем полям, повторы учитываются по количеству.
+type PlaylistDiff struct {
+	// Added - песни другого списка, которых нет в плейлисте, в порядке того списка
+	Added []Song
+	// Removed - песни плейлиста, которых нет в другом списке, в порядке плейлиста
+	Removed []QueuedSong
+}
+
+// Empty - списки совпадают с точностью до порядка.
+func (d PlaylistDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// Diff - сравнивает активный плейлист со списком other.
+func (p *playerImpl) Diff(_ context.Context, other []Song) PlaylistDiff {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	diff, _ := p.diffLocked(other)
+	return diff
+}
+
+// Merge - сливает активный плейлист со списком other по стратегии strategy,
+// не пересоздавая совпадающие песни: их ID сохраняются, а текущая песня
+// продолжает играть, если осталась в плейлисте. Новые песни проверяются, как в AddSongs,
+// и если хоть одна не прошла проверку, плейлист не меняется. Если итоговый плейлист
+// не помещается в ограничения WithMaxPlaylistSize и WithMaxTotalDuration,
+// возвращается ErrPlaylistFull: слияние песни не вытесняет.
+// Слияние отменяется одним Undo. Возвращает применённые отличия.
+func (p *playerImpl) Merge(ctx context.Context, other []Song, strategy MergeStrategy) (PlaylistDiff, error) {
+	if strategy < MergeAppendMissing || strategy > MergeInterleave {
+		return PlaylistDiff{}, errors.New("unknown merge strategy")
+	}
+
+	for i, song := range other {
+		if err := p.validator.Validate(song); err != nil {
+			return PlaylistDiff{}, fmt.Errorf("song %d: %w", i, err)
+		}
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return PlaylistDiff{}, err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return PlaylistDiff{}, ErrClosed
+	}
+
+	diff, matched := p.diffLocked(other)
+	if strategy != MergeReplace {
+		// остальные стратегии ничего не удаляют
+		diff.Removed = nil
+	}
+	if diff.Empty() && (strategy != MergeReplace || p.sameOrderLocked(matched)) {
+		return diff, nil
+	}
+
+	count, total := p.songs.Len(), p.total
+	if strategy == MergeReplace {
+		count, total = 0, 0
+	}
+	for i, n := range matched {
+		switch {
+		case n == nil:
+			count++
+			total += other[i].Duration
+		case strategy == MergeReplace:
+			count++
+			total += n.counted
+		}
+	}
+	if !p.limits.fit(count, total) {
+		return PlaylistDiff{}, ErrPlaylistFull
+	}
+
+	// в библиотеку попадают только принятые песни
+	var added []*playerNode
+	for i, n := range matched {
+		if n == nil {
+			matched[i] = p.newNode(other[i], nil)
+			added = append(added, matched[i])
+		}
+	}
+
+	before := p.nodes()
+	var after []*playerNode
+	switch strategy {
+	case MergeAppendMissing:
+		after = append(before[:len(before):len(before)], added...)
+	case MergeReplace:
+		after = matched
+	case MergeInterleave:
+		after = make([]*playerNode, 0, len(before)+len(added))
+		for i := 0; i < max(len(before), len(added)); i++ {
+			if i < len(before) {
+				after = append(after, before[i])
+			}
+			if i < len(added) {
+				after = append(after, added[i])
+			}
+		}
+	}
+
+	p.recordEditLocked(p.reorderEdit("merge", before, after, p.current))
+	p.auditLocked(ctx, AuditEntry{Op: AuditMerge, Song: p.currentSongLocked(), Detail: p.active})
+	if err := p.relinkLocked(ctx, after, nil); err != nil {
+		return diff, err
+	}
+
+	p.logger.InfoContext(ctx, "playlist merged", slog.String("playlist", p.active),
+		slog.Int("added", len(diff.Added)), slog.Int("removed", len(diff.Removed)))
+	return diff, nil
+}
+
+// diffLocked - сравнивает активный плейлист с other. matched[i] - узел плейлиста,
+// совпавший с other[i], или nil для новой песни.
+// Вызывается под блокировкой.
+func (p *playerImpl) diffLocked(other []Song) (PlaylistDiff, []*playerNode) {
+	pool := make(map[string][]*playerNode)
+	for n := p.first(); n != nil; n = n.next() {
+		key := songKey(*n.song)
+		pool[key] = append(pool[key], n)
+	}
+
+	var diff PlaylistDiff
+	used := make(map[*playerNode]bool)
+	matched := make([]*playerNode, len(other))
+	for i, song := range other {
+		key := songKey(song)
+		if nodes := pool[key]; len(nodes) > 0 {
+			matched[i], pool[key] = nodes[0], nodes[1:]
+			used[nodes[0]] = true
+			continue
+		}
+		diff.Added = append(diff.Added, song)
+	}
+
+	for n := p.first(); n != nil; n = n.next() {
+		if !used[n] {
+			diff.Removed = append(diff.Removed, queuedSong(n))
+		}
+	}
+
+	return diff, matched
+}
+
+// sameOrderLocked - совпадает ли активный плейлист с nodes по порядку.
+// Вызывается под блокировкой.
+func (p *playerImpl) sameOrderLocked(nodes []*playerNode) bool {
+	n := p.first()
+	for _, m := range nodes {
+		if n != m {
+			return false
+		}
+		n = n.next()
+	}
+
+	return n == nil
+}
@@ -0,0 +1,75 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_DiffMerge(t *testing.T) {
+	ctx := context.Background()
+	local := []Song{minuteSong("a"), minuteSong("b"), minuteSong("c")}
+	server := []Song{minuteSong("c"), minuteSong("x"), minuteSong("a"), minuteSong("y")}
+
+	t.Run("diff", func(t *testing.T) {
+		pl, _ := New(WithSongs(local...))
+		diff := pl.Diff(ctx, server)
+		td.Cmp(t, diff.Added, []Song{minuteSong("x"), minuteSong("y")})
+		td.Cmp(t, len(diff.Removed), 1)
+		td.Cmp(t, diff.Removed[0].Song, minuteSong("b"))
+
+		td.CmpTrue(t, pl.Diff(ctx, []Song{minuteSong("c"), minuteSong("b"), minuteSong("a")}).Empty(), "порядок не важен")
+		td.Cmp(t, pl.Diff(ctx, []Song{minuteSong("a"), minuteSong("a")}).Added, []Song{minuteSong("a")}, "повторы считаются")
+	})
+
+	t.Run("append missing", func(t *testing.T) {
+		pl, _ := New(WithSongs(local...))
+		ids := pl.Queue(ctx)
+
+		diff, err := pl.Merge(ctx, server, MergeAppendMissing)
+		td.CmpNoError(t, err)
+		td.Cmp(t, diff.Added, []Song{minuteSong("x"), minuteSong("y")})
+		td.CmpEmpty(t, diff.Removed)
+		td.Cmp(t, names(pl), []string{"a", "b", "c", "x", "y"})
+		td.Cmp(t, pl.Queue(ctx)[:3], ids, "ID сохраняются")
+
+		diff, _ = pl.Merge(ctx, server, MergeAppendMissing)
+		td.CmpTrue(t, diff.Empty(), "повторное слияние ничего не меняет")
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		pl, _ := New(WithSongs(local...))
+		_ = pl.PlayAt(ctx, 2)
+		current := pl.Status(ctx).SongID
+
+		diff, err := pl.Merge(ctx, server, MergeReplace)
+		td.CmpNoError(t, err)
+		td.Cmp(t, len(diff.Removed), 1)
+		td.Cmp(t, names(pl), []string{"c", "x", "a", "y"})
+		td.Cmp(t, pl.Status(ctx).SongID, current, "текущая песня продолжает играть")
+		td.CmpTrue(t, pl.Status(ctx).Playing)
+		_ = pl.Pause(ctx)
+
+		td.CmpNoError(t, pl.Undo(ctx))
+		td.Cmp(t, names(pl), []string{"a", "b", "c"}, "слияние отменяется целиком")
+	})
+
+	t.Run("interleave", func(t *testing.T) {
+		pl, _ := New(WithSongs(local...))
+		_, err := pl.Merge(ctx, server, MergeInterleave)
+		td.CmpNoError(t, err)
+		td.Cmp(t, names(pl), []string{"a", "x", "b", "y", "c"})
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		pl, _ := New(WithSongs(local...))
+		_, err := pl.Merge(ctx, []Song{minuteSong("x"), {Name: "short"}}, MergeAppendMissing)
+		td.CmpTrue(t, errors.Is(err, ErrInvalidSong))
+		td.Cmp(t, names(pl), []string{"a", "b", "c"}, "плейлист не изменился")
+
+		_, err = pl.Merge(ctx, server, MergeStrategy(9))
+		td.CmpError(t, err)
+	})
+}
@@ -0,0 +1,69 @@
+package player
+
+import (
+	"context"
+	"time"
+)
+
+// Metrics - метрики плеера для мониторинга.
+type Metrics struct {
+	// SongsPlayed - сколько песен доиграло до конца
+	SongsPlayed int
+	// Skips - сколько песен было пропущено
+	Skips int
+	// PlaybackTime - общее время воспроизведения, включая текущую песню
+	PlaybackTime time.Duration
+	// Playing - идёт ли воспроизведение
+	Playing bool
+	// PlaylistLength - количество песен в активном плейлисте
+	PlaylistLength int
+	// CacheHits - сколько песен запущено из кэша WithCache
+	CacheHits int
+	// CacheMisses - сколько песен с Source запущено без кэша
+	CacheMisses int
+	// EventsDropped - сколько событий отброшено подписчиками, не успевавшими их читать
+	EventsDropped int
+	// StalledSubscribers - сколько подписчиков сейчас не забирают события: их очередь полна
+	StalledSubscribers int
+}
+
+// counters - накопительные счётчики воспроизведения.
+type counters struct {
+	played   int
+	skips    int
+	listened time.Duration
+}
+
+func (c *counters) record(played time.Duration, completed bool) {
+	if completed {
+		c.played++
+	} else {
+		c.skips++
+	}
+	c.listened += played
+}
+
+// Metrics - возвращает текущие метрики плеера.
+func (p *playerImpl) Metrics(_ context.Context) Metrics {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	m := Metrics{
+		SongsPlayed:    p.counters.played,
+		Skips:          p.counters.skips,
+		PlaybackTime:   p.counters.listened,
+		Playing:        p.isPlaying,
+		PlaylistLength: p.songs.Len(),
+
+		EventsDropped:      p.droppedEventsLocked(),
+		StalledSubscribers: p.stalledSubscribersLocked(),
+	}
+	if p.isPlaying {
+		m.PlaybackTime += p.elapsedLocked()
+	}
+	if p.cache != nil {
+		m.CacheHits, m.CacheMisses = p.cache.hits, p.cache.misses
+	}
+
+	return m
+}
@@ -0,0 +1,61 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Metrics(t *testing.T) {
+	ctx := context.Background()
+
+	pl, _ := New(WithValidator(ValidationPolicy{}), WithSongs(
+		Song{Name: "a", Duration: 20 * time.Millisecond},
+		Song{Name: "b", Duration: 30 * time.Second},
+		Song{Name: "c", Duration: 30 * time.Second},
+	))
+	td.Cmp(t, pl.Metrics(ctx), Metrics{PlaylistLength: 3})
+
+	_ = pl.Play(ctx)
+	time.Sleep(30 * time.Millisecond)
+	_ = pl.Next(ctx)
+	time.Sleep(10 * time.Millisecond)
+
+	td.Cmp(t, pl.Metrics(ctx), td.Struct(Metrics{
+		SongsPlayed:    1,
+		Skips:          1,
+		Playing:        true,
+		PlaylistLength: 3,
+	}, td.StructFields{
+		"PlaybackTime": td.Between(35*time.Millisecond, 60*time.Millisecond),
+	}))
+	_ = pl.Pause(ctx)
+}
+
+func TestPlayerImpl_MetricsBackpressure(t *testing.T) {
+	ctx := context.Background()
+
+	pl, err := New()
+	td.CmpNoError(t, err)
+	s, err := pl.Subscribe(ctx, SubscribeBuffer(1), SubscribeBackpressure(DropNewest))
+	td.CmpNoError(t, err)
+
+	pl.mu.Lock()
+	for i := 0; i < 5; i++ {
+		pl.publishLocked(EventQuota, nil)
+	}
+	pl.mu.Unlock()
+
+	// одно событие может уже ждать отправки в C вне буфера
+	m := pl.Metrics(ctx)
+	td.Cmp(t, m.EventsDropped, td.Between(3, 4))
+	td.Cmp(t, m.StalledSubscribers, 1)
+
+	dropped := s.Dropped()
+	s.Close()
+	m = pl.Metrics(ctx)
+	td.Cmp(t, m.EventsDropped, dropped, "завершённая подписка учитывается")
+	td.Cmp(t, m.StalledSubscribers, 0)
+}
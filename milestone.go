@@ -0,0 +1,223 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Milestone - отметка прогресса песни: доля длительности или позиция от начала.
+type Milestone struct {
+	// Percent - процент длительности песни, от 0 до 100 не включительно; 0 - задан Offset
+	Percent float64
+	// Offset - позиция от начала песни, учитывается, если Percent равен 0
+	Offset time.Duration
+}
+
+// AtPercent - отметка на percent процентах длительности песни.
+func AtPercent(percent float64) Milestone {
+	return Milestone{Percent: percent}
+}
+
+// AtOffset - отметка на позиции d от начала песни.
+func AtOffset(d time.Duration) Milestone {
+	return Milestone{Offset: d}
+}
+
+func (m Milestone) String() string {
+	if m.Percent > 0 {
+		return strconv.FormatFloat(m.Percent, 'g', -1, 64) + "%"
+	}
+
+	return m.Offset.String()
+}
+
+// position - позиция отметки в песне, false если в песне её нет:
+// у потока нет процентов, а отметки после конца песни не достигаются.
+func (m Milestone) position(song Song) (time.Duration, bool) {
+	if m.Percent > 0 {
+		if song.IsStream() {
+			return 0, false
+		}
+		return time.Duration(float64(song.Duration) * m.Percent / 100), true
+	}
+
+	return m.Offset, song.IsStream() || m.Offset < song.Duration
+}
+
+// defaultMilestones - отметки, если WithMilestones не задан.
+var defaultMilestones = []Milestone{AtPercent(25), AtPercent(50), AtPercent(75)}
+
+// MilestoneEvent - текущая песня дошла до отметки прогресса.
+type MilestoneEvent struct {
+	// Song - песня
+	Song Song
+	// SongID - ID песни в плейлисте
+	SongID SongID
+	// Milestone - достигнутая отметка
+	Milestone Milestone
+	// Position - позиция воспроизведения в момент события
+	Position time.Duration
+	// State - состояние плеера в момент события
+	State State
+}
+
+// MilestoneHook - обработчик отметок прогресса.
+type MilestoneHook func(event MilestoneEvent)
+
+// milestones - отметки прогресса текущей песни.
+type milestones struct {
+	// points - отметки, nil - defaultMilestones
+	points []Milestone
+	hooks  []MilestoneHook
+	// node - песня, для которой считается next
+	node *playerNode
+	// next - индекс в milestonePoints(node) следующей отметки
+	next int
+}
+
+// milestonePoint - отметка с её позицией в конкретной песне.
+type milestonePoint struct {
+	at        time.Duration
+	milestone Milestone
+}
+
+// WithMilestones - задаёт отметки прогресса для OnMilestone вместо 25%, 50% и 75%.
+func WithMilestones(ms ...Milestone) Option {
+	return func(p *playerImpl) error {
+		if len(ms) == 0 {
+			return errors.New("no milestones")
+		}
+
+		for _, m := range ms {
+			switch {
+			case m.Percent < 0 || m.Percent >= 100:
+				return fmt.Errorf("milestone %v out of range (0%%, 100%%)", m)
+			case m.Percent == 0 && m.Offset <= 0:
+				return errors.New("milestone offset must be positive")
+			}
+		}
+
+		p.milestones.points = append([]Milestone(nil), ms...)
+		return nil
+	}
+}
+
+// OnMilestone - регистрирует обработчик, который вызывается, когда текущая песня
+// доигрывает до отметки прогресса. Каждая отметка срабатывает один раз за проигрывание песни;
+// отметки, через которые перемотали вперёд, пропускаются.
+// Обработчики вызываются по порядку в отдельной горутине.
+func (p *playerImpl) OnMilestone(ctx context.Context, hook MilestoneHook) error {
+	if hook == nil {
+		return errors.New("hook is nil")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	p.milestones.hooks = append(p.milestones.hooks, hook)
+	p.rescheduleLocked()
+	return nil
+}
+
+// milestonePointsLocked - отметки текущей песни по возрастанию позиции.
+// Вызывается под блокировкой.
+func (p *playerImpl) milestonePointsLocked() []milestonePoint {
+	ms := p.milestones.points
+	if ms == nil {
+		ms = defaultMilestones
+	}
+
+	song := *p.current.song
+	points := make([]milestonePoint, 0, len(ms))
+	for _, m := range ms {
+		if at, ok := m.position(song); ok {
+			points = append(points, milestonePoint{at: at, milestone: m})
+		}
+	}
+
+	sort.SliceStable(points, func(i, j int) bool { return points[i].at < points[j].at })
+	return points
+}
+
+// milestoneDueLocked - сообщает, что за отметками текущей песни нужно следить.
+// Вызывается под блокировкой.
+func (p *playerImpl) milestoneDueLocked() bool {
+	return (len(p.milestones.hooks) > 0 || p.subscribedLocked(EventMilestone)) && !p.inGap
+}
+
+// untilMilestoneLocked - возвращает время до следующей отметки
+// или 0, если текущая песня сменилась и отметки нужно пересчитать.
+// Вызывается под блокировкой.
+func (p *playerImpl) untilMilestoneLocked() time.Duration {
+	if p.milestones.node != p.current {
+		return 0
+	}
+
+	points := p.milestonePointsLocked()
+	if p.milestones.next >= len(points) {
+		return unbounded
+	}
+
+	return points[p.milestones.next].at - p.elapsedLocked()
+}
+
+// skipMilestonesLocked - пропускает отметки текущей песни до позиции pos включительно.
+// Вызывается под блокировкой.
+func (p *playerImpl) skipMilestonesLocked(pos time.Duration) {
+	points := p.milestonePointsLocked()
+	for p.milestones.next < len(points) && points[p.milestones.next].at <= pos {
+		p.milestones.next++
+	}
+}
+
+// milestoneLocked - ставит в очередь обработчики наступивших отметок.
+// Для новой песни отметки до начальной позиции пропускаются.
+// Вызывается под блокировкой.
+func (p *playerImpl) milestoneLocked(ctx context.Context) {
+	pos := p.elapsedLocked()
+	if p.milestones.node != p.current {
+		p.milestones.node, p.milestones.next = p.current, 0
+		p.skipMilestonesLocked(p.playedTime)
+	}
+
+	points := p.milestonePointsLocked()
+	hooks := append([]MilestoneHook(nil), p.milestones.hooks...)
+	for ; p.milestones.next < len(points) && points[p.milestones.next].at <= pos; p.milestones.next++ {
+		event := MilestoneEvent{
+			Song:      *p.current.song,
+			SongID:    p.current.id,
+			Milestone: points[p.milestones.next].milestone,
+			Position:  pos,
+			State:     p.stateLocked(),
+		}
+		p.publishLocked(EventMilestone, event)
+		if len(hooks) > 0 {
+			p.hookQueue.push(func() {
+				for _, h := range hooks {
+					h(event)
+				}
+			})
+		}
+
+		p.logger.DebugContext(ctx, "milestone reached", songAttr(event.Song), slog.String("milestone", event.Milestone.String()))
+	}
+}
+
+// milestoneSeekLocked - пропускает отметки, через которые перемотали вперёд.
+// Вызывается под блокировкой.
+func (p *playerImpl) milestoneSeekLocked(pos time.Duration) {
+	if p.milestones.node == p.current && p.current != nil {
+		p.skipMilestonesLocked(pos)
+	}
+}
@@ -0,0 +1,92 @@
+package player
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_OnMilestone(t *testing.T) {
+	ctx := context.Background()
+
+	// record - регистрирует обработчик и возвращает функцию чтения отметок.
+	record := func(t *testing.T, pl *playerImpl) func() []string {
+		var (
+			mu   sync.Mutex
+			seen []string
+		)
+		td.CmpNoError(t, pl.OnMilestone(ctx, func(e MilestoneEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			td.Cmp(t, e.Position, td.Gte(e.Milestone.Offset))
+			seen = append(seen, e.Song.Name+"@"+e.Milestone.String())
+		}))
+
+		return func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]string(nil), seen...)
+		}
+	}
+
+	t.Run("options", func(t *testing.T) {
+		_, err := New(WithMilestones())
+		td.CmpString(t, err, "no milestones")
+		_, err = New(WithMilestones(AtPercent(100)))
+		td.CmpString(t, err, "milestone 100% out of range (0%, 100%)")
+		_, err = New(WithMilestones(AtOffset(0)))
+		td.CmpString(t, err, "milestone offset must be positive")
+
+		pl, _ := New()
+		td.CmpError(t, pl.OnMilestone(ctx, nil))
+	})
+
+	t.Run("default", func(t *testing.T) {
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithSongs(
+			Song{Name: "a", Duration: 80 * time.Millisecond},
+			Song{Name: "b", Duration: 30 * time.Second},
+		))
+		seen := record(t, pl)
+
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpTrue(t, eventually(func() bool { return pl.Status(ctx).Song.Name == "b" }))
+		td.CmpNoError(t, pl.Pause(ctx))
+		td.CmpNoError(t, pl.hookQueue.wait(ctx))
+
+		td.Cmp(t, seen(), []string{"a@25%", "a@50%", "a@75%"})
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		pl, _ := New(
+			WithValidator(ValidationPolicy{}),
+			WithMilestones(AtOffset(40*time.Millisecond), AtPercent(10), AtOffset(time.Hour)),
+			WithSongs(Song{Name: "a", Duration: 100 * time.Millisecond}, Song{Name: "live", URL: "http://live"}),
+		)
+		seen := record(t, pl)
+
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpTrue(t, eventually(func() bool { return len(seen()) == 3 }))
+		td.CmpNoError(t, pl.Pause(ctx))
+
+		td.Cmp(t, seen(), []string{"a@10%", "a@40ms", "live@40ms"}, "по позиции, у потока только смещения")
+	})
+
+	t.Run("seek", func(t *testing.T) {
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithSongs(Song{
+			Name:     "a",
+			Duration: 200 * time.Millisecond,
+			Chapters: []Chapter{{Title: "1"}, {Title: "2", Start: 120 * time.Millisecond}},
+		}))
+		seen := record(t, pl)
+
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpNoError(t, pl.SeekToChapter(ctx, 1))
+		td.CmpTrue(t, eventually(func() bool { return !pl.Status(ctx).Playing }))
+		td.CmpNoError(t, pl.hookQueue.wait(ctx))
+
+		td.Cmp(t, seen(), []string{"a@75%"}, "пропущенные перемоткой отметки не срабатывают")
+	})
+}
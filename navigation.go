@@ -0,0 +1,87 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// EdgeBehavior - поведение Next на последней песне и Prev на первой.
+type EdgeBehavior int
+
+const (
+	// EdgeRestart - играть крайнюю песню с начала, поведение по умолчанию
+	EdgeRestart EdgeBehavior = iota
+	// EdgeWrap - перейти на другой конец плейлиста,
+	// а когда доиграла последняя песня - играть плейлист сначала
+	EdgeWrap
+	// EdgeStop - остановить воспроизведение, курсор переходит на первую песню
+	EdgeStop
+	// EdgeNoop - ничего не делать
+	EdgeNoop
+)
+
+func (b EdgeBehavior) String() string {
+	switch b {
+	case EdgeRestart:
+		return "restart"
+	case EdgeWrap:
+		return "wrap"
+	case EdgeStop:
+		return "stop"
+	case EdgeNoop:
+		return "noop"
+	default:
+		return fmt.Sprintf("EdgeBehavior(%d)", int(b))
+	}
+}
+
+// MarshalText - кодирует поведение строкой, например "wrap".
+func (b EdgeBehavior) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText - разбирает поведение из строки MarshalText.
+func (b *EdgeBehavior) UnmarshalText(text []byte) (err error) {
+	*b, err = parseEnum(text, EdgeRestart, EdgeNoop, "edge behavior")
+	return err
+}
+
+// WithEdgeBehavior - задаёт поведение Next на последней песне и Prev на первой.
+// С EdgeWrap плейлист повторяется и когда последняя песня доиграла сама.
+func WithEdgeBehavior(b EdgeBehavior) Option {
+	return func(p *playerImpl) error {
+		if b < EdgeRestart || b > EdgeNoop {
+			return errors.New("unknown edge behavior")
+		}
+
+		p.edge = b
+		return nil
+	}
+}
+
+// WithPrevRestart - если песня играет дольше threshold, Prev начинает её сначала
+// вместо перехода на предыдущую. По умолчанию Prev всегда переходит на предыдущую.
+func WithPrevRestart(threshold time.Duration) Option {
+	return func(p *playerImpl) error {
+		if threshold < 0 {
+			return errors.New("prev restart threshold is negative")
+		}
+
+		p.prevRestart = threshold
+		return nil
+	}
+}
+
+// stopAtEdgeLocked - останавливает воспроизведение на краю плейлиста
+// и переводит курсор на node.
+// Вызывается под блокировкой.
+func (p *playerImpl) stopAtEdgeLocked(ctx context.Context, node *playerNode) {
+	p.skipLocked()
+	p.haltLocked(ctx)
+	p.section = nil
+	p.moveToLocked(node)
+	p.logger.InfoContext(ctx, "playback stopped at playlist edge", slog.String("playlist", p.active))
+}
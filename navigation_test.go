@@ -0,0 +1,108 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestWithEdgeBehavior(t *testing.T) {
+	ctx := context.Background()
+	songs := WithSongs(
+		Song{Name: "a", Duration: 30 * time.Second},
+		Song{Name: "b", Duration: 30 * time.Second},
+	)
+
+	_, err := New(WithEdgeBehavior(EdgeNoop + 1))
+	td.CmpError(t, err)
+
+	t.Run("restart", func(t *testing.T) {
+		pl, _ := New(songs)
+		_ = pl.PlayAt(ctx, 1)
+		td.CmpNoError(t, pl.Next(ctx))
+		td.Cmp(t, pl.Status(ctx).Song.Name, "b")
+
+		_ = pl.PlayAt(ctx, 0)
+		td.CmpNoError(t, pl.Prev(ctx))
+		td.Cmp(t, pl.Status(ctx).Song.Name, "a")
+		_ = pl.Pause(ctx)
+	})
+
+	t.Run("wrap", func(t *testing.T) {
+		pl, _ := New(songs, WithEdgeBehavior(EdgeWrap))
+		_ = pl.PlayAt(ctx, 1)
+		td.CmpNoError(t, pl.Next(ctx))
+		td.Cmp(t, pl.Status(ctx).Song.Name, "a", "после последней - первая")
+
+		td.CmpNoError(t, pl.Prev(ctx))
+		td.Cmp(t, pl.Status(ctx).Song.Name, "b", "перед первой - последняя")
+		td.CmpTrue(t, pl.Status(ctx).Playing)
+		_ = pl.Pause(ctx)
+	})
+
+	t.Run("wrap at playlist end", func(t *testing.T) {
+		pl := newFakePlayer(t, WithEdgeBehavior(EdgeWrap), WithSongs(minuteSongs("a", "b", "c")...))
+		td.CmpNoError(t, pl.PlayAt(ctx, 1))
+		td.Cmp(t, titles(pl.PeekNext(ctx, 2)), []string{"c", "a"})
+
+		played, err := pl.SimulatePlayback(ctx, 150*time.Second)
+		td.CmpNoError(t, err)
+		td.Cmp(t, titles(played), []string{"b", "c", "a"}, "после последней песни плейлист играет сначала")
+		td.CmpTrue(t, pl.Status(ctx).Playing)
+	})
+
+	t.Run("wrap with sequencer", func(t *testing.T) {
+		pl := newFakePlayer(t, WithEdgeBehavior(EdgeWrap), WithSequencer(Shuffle()), WithSongs(minuteSongs("a", "b")...))
+		td.CmpNoError(t, pl.Play(ctx))
+
+		played, err := pl.SimulatePlayback(ctx, 210*time.Second)
+		td.CmpNoError(t, err)
+		td.Cmp(t, played, td.Len(4), "после прохода начинается следующий")
+		td.CmpTrue(t, pl.Status(ctx).Playing)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		pl, _ := New(songs, WithEdgeBehavior(EdgeStop))
+		_ = pl.PlayAt(ctx, 1)
+		td.CmpNoError(t, pl.Next(ctx))
+		st := pl.Status(ctx)
+		td.CmpFalse(t, st.Playing, "воспроизведение остановлено")
+		td.Cmp(t, st.Song.Name, "a", "курсор на первой песне")
+	})
+
+	t.Run("noop", func(t *testing.T) {
+		pl, _ := New(songs, WithEdgeBehavior(EdgeNoop))
+		_ = pl.PlayFrom(ctx, 1, 10*time.Second)
+		td.CmpNoError(t, pl.Next(ctx))
+
+		st := pl.Status(ctx)
+		td.Cmp(t, st.Song.Name, "b")
+		td.CmpGte(t, st.Position, 10*time.Second, "песня продолжает играть")
+		td.CmpEmpty(t, history(t, pl, 0), "пропуск не записан")
+		_ = pl.Pause(ctx)
+	})
+}
+
+func TestWithPrevRestart(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := New(WithPrevRestart(-time.Second))
+	td.CmpError(t, err)
+
+	pl, _ := New(WithPrevRestart(3*time.Second), WithSongs(
+		Song{Name: "a", Duration: 30 * time.Second},
+		Song{Name: "b", Duration: 30 * time.Second},
+	))
+
+	_ = pl.PlayFrom(ctx, 1, 10*time.Second)
+	td.CmpNoError(t, pl.Prev(ctx))
+	st := pl.Status(ctx)
+	td.Cmp(t, st.Song.Name, "b", "песня начата сначала")
+	td.Cmp(t, st.Position, td.Lt(time.Second))
+
+	td.CmpNoError(t, pl.Prev(ctx))
+	td.Cmp(t, pl.Status(ctx).Song.Name, "a", "в начале песни - переход на предыдущую")
+	_ = pl.Pause(ctx)
+}
@@ -0,0 +1,131 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Option - настройка плеера.
+type Option func(p *playerImpl) error
+
+// New - конструктор для плеера с настройками.
+func New(opts ...Option) (*playerImpl, error) {
+	pl := &playerImpl{
+		active:         DefaultPlaylist,
+		playlists:      make(map[string]*playlist),
+		smart:          make(map[string]Rule),
+		library:        NewLibrary(),
+		wakeCh:         make(chan struct{}, 1),
+		done:           make(chan struct{}),
+		playbackErrors: make(chan PlaybackError, playbackErrorsBuffer),
+		output:         nopOutput{},
+		volume:         MaxVolume,
+		logger:         slog.New(discardHandler{}),
+		bookmarks:      make(map[string]*bookmark),
+		stats:          make(map[string]*SongStats),
+		storage:        NewMemoryStorage(),
+		validator:      DefaultValidationPolicy,
+		clock:          realClock{},
+		locale:         English,
+		auditSize:      defaultAuditSize,
+		staging:        newStaging(),
+	}
+	pl.setRandSource(defaultRandSource())
+
+	for _, opt := range opts {
+		if err := opt(pl); err != nil {
+			return nil, err
+		}
+	}
+
+	if pl.interstitials != nil {
+		pl.interstitials.last = pl.now()
+	}
+
+	if pl.crossfade > 0 && pl.gap > 0 {
+		return nil, errors.New("crossfade and gap are mutually exclusive")
+	}
+
+	if pl.transition != nil && (pl.crossfade > 0 || pl.gap > 0) {
+		return nil, errors.New("transition is incompatible with crossfade and gap")
+	}
+
+	// песни проверяются и ограничиваются уже настроенным плеером,
+	// в каком бы порядке ни шли опции
+	for i, s := range pl.initial {
+		if _, err := pl.AddSong(context.Background(), s); err != nil {
+			return nil, fmt.Errorf("add songs[%d] song: %v", i, err)
+		}
+	}
+	pl.initial = nil
+
+	if err := pl.loadStorage(context.Background()); err != nil {
+		return nil, fmt.Errorf("load storage: %v", err)
+	}
+
+	// начальные песни отменить нельзя и в журнал аудита они не попадают
+	pl.resetEditsLocked()
+	pl.audit, pl.auditStart = nil, 0
+
+	if pl.autoplay {
+		if err := pl.Play(context.Background()); err != nil {
+			return nil, fmt.Errorf("autoplay: %v", err)
+		}
+	}
+
+	if pl.watchdog > 0 {
+		due, cancel := pl.after(pl.watchdog)
+		go pl.watch(due, cancel)
+	}
+
+	return pl, nil
+}
+
+// WithSongs - добавляет песни в плейлист по умолчанию.
+// Песни добавляются после всех остальных опций, поэтому к ним применяются
+// проверка WithValidator, ограничения плейлиста и остальные настройки.
+func WithSongs(songs ...Song) Option {
+	return func(p *playerImpl) error {
+		p.initial = append(p.initial, songs...)
+		return nil
+	}
+}
+
+// WithOutput - задаёт бэкенд воспроизведения.
+func WithOutput(o Output) Option {
+	return func(p *playerImpl) error {
+		if o == nil {
+			return errors.New("output is nil")
+		}
+
+		p.output = o
+		return nil
+	}
+}
+
+// WithCrossfade - накладывает конец песни на начало следующей на время d.
+func WithCrossfade(d time.Duration) Option {
+	return func(p *playerImpl) error {
+		if d < 0 {
+			return errors.New("crossfade is negative")
+		}
+
+		p.crossfade = d
+		return nil
+	}
+}
+
+// WithGap - вставляет паузу d между песнями.
+func WithGap(d time.Duration) Option {
+	return func(p *playerImpl) error {
+		if d < 0 {
+			return errors.New("gap is negative")
+		}
+
+		p.gap = d
+		return nil
+	}
+}
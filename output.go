@@ -0,0 +1,91 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// Output - бэкенд, который воспроизводит звук.
+// Методы вызываются под блокировкой плеера и не должны обращаться к нему.
+type Output interface {
+	// Start - начинает воспроизведение песни с позиции offset
+	Start(ctx context.Context, song Song, offset time.Duration) error
+	// Stop - останавливает воспроизведение песни
+	Stop(ctx context.Context, song Song) error
+}
+
+// ClosableOutput - бэкенд, который нужно закрыть, когда плеер перестаёт его использовать.
+type ClosableOutput interface {
+	// Close - освобождает устройство
+	Close(ctx context.Context) error
+}
+
+// nopOutput - бэкенд по умолчанию, ничего не воспроизводит.
+type nopOutput struct{}
+
+func (nopOutput) Start(context.Context, Song, time.Duration) error { return nil }
+
+func (nopOutput) Stop(context.Context, Song) error { return nil }
+
+// startOutputLocked - начинает воспроизведение песни в бэкенде.
+// Ошибки уходят в Errors, так как вызывающая горутина не может их вернуть.
+// Вызывается под блокировкой.
+func (p *playerImpl) startOutputLocked(ctx context.Context, song Song, offset time.Duration) {
+	p.applyGainLocked(ctx, song)
+	if err := p.output.Start(ctx, p.cachedLocked(song), offset); err != nil {
+		p.startFailedLocked(ctx, song, err)
+		return
+	}
+	p.failedStarts = 0
+}
+
+// stopOutputLocked - останавливает воспроизведение песни в бэкенде.
+// Ошибки уходят в Errors, так как вызывающая горутина не может их вернуть.
+// Вызывается под блокировкой.
+func (p *playerImpl) stopOutputLocked(ctx context.Context, song Song) {
+	if err := p.output.Stop(ctx, song); err != nil {
+		p.playbackErrorLocked(ctx, song, StageStop, err)
+	}
+}
+
+// SetOutput - переключает бэкенд, сохраняя плейлист и позицию, например чтобы
+// перенести воспроизведение с динамиков на устройство трансляции.
+// Текущая песня останавливается в старом бэкенде без затухания, старый бэкенд
+// закрывается, если реализует ClosableOutput, и песня продолжается в новом
+// с той же позиции. Громкость и эквалайзер передаются новому бэкенду.
+func (p *playerImpl) SetOutput(ctx context.Context, output Output) error {
+	if output == nil {
+		return errors.New("output is nil")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	wasPlaying := p.isPlaying
+	p.pauseLocked(ctx)
+	p.stopFadeLocked(ctx)
+
+	old := p.output
+	p.output = output
+	if c, ok := old.(ClosableOutput); ok {
+		if err := c.Close(ctx); err != nil {
+			p.logger.WarnContext(ctx, "old output close failed", slog.Any("error", err))
+		}
+	}
+
+	err := errors.Join(p.applyVolumeLocked(ctx), p.applyEQLocked(ctx))
+	p.logger.InfoContext(ctx, "output switched", slog.Bool("playing", wasPlaying))
+	if wasPlaying {
+		err = errors.Join(err, p.playLocked(ctx))
+	}
+
+	return err
+}
@@ -0,0 +1,78 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// deviceOutput - бэкенд-устройство, которое запоминает громкость и закрытие.
+type deviceOutput struct {
+	recordingOutput
+	volume int
+}
+
+func (o *deviceOutput) SetVolume(_ context.Context, volume int) error {
+	o.volume = volume
+	return nil
+}
+
+func (o *deviceOutput) Close(context.Context) error {
+	o.record("close")
+	return nil
+}
+
+func TestPlayerImpl_SetOutput(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("во время воспроизведения", func(t *testing.T) {
+		speakers, cast := &deviceOutput{}, &recordingOutput{}
+		pl, err := New(WithOutput(speakers), WithSongs(
+			Song{Name: "a", Duration: time.Minute},
+			Song{Name: "b", Duration: time.Minute},
+		))
+		td.CmpNoError(t, err)
+		defer pl.Close(ctx)
+
+		td.CmpNoError(t, pl.PlayFrom(ctx, 0, 20*time.Second))
+		td.CmpNoError(t, pl.SetOutput(ctx, cast))
+
+		st := pl.Status(ctx)
+		td.Cmp(t, st.Song.Name, "a", "песня та же")
+		td.Cmp(t, st.Position, td.Between(20*time.Second, 21*time.Second), "позиция сохранена")
+		td.Cmp(t, pl.State(ctx), StatePlaying)
+
+		td.Cmp(t, speakers.Calls(), []string{"start a", "stop a", "close"})
+		td.Cmp(t, cast.Calls(), []string{"start a"})
+	})
+
+	t.Run("на паузе", func(t *testing.T) {
+		speakers, cast := &recordingOutput{}, &deviceOutput{}
+		pl, err := New(WithOutput(speakers), WithSongs(Song{Name: "a", Duration: time.Minute}))
+		td.CmpNoError(t, err)
+		defer pl.Close(ctx)
+
+		td.CmpNoError(t, pl.SetVolume(ctx, 30))
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpNoError(t, pl.Pause(ctx))
+		td.CmpNoError(t, pl.SetOutput(ctx, cast))
+
+		td.Cmp(t, pl.State(ctx), StatePaused, "пауза сохранена")
+		td.CmpEmpty(t, cast.Calls(), "новый бэкенд не запускается")
+		td.Cmp(t, cast.volume, 30, "громкость передана новому бэкенду")
+
+		td.CmpNoError(t, pl.Play(ctx))
+		td.Cmp(t, cast.Calls(), []string{"start a"})
+		td.Cmp(t, speakers.Calls(), []string{"start a", "stop a"})
+	})
+
+	t.Run("ошибки", func(t *testing.T) {
+		pl, _ := NewPlayer()
+		td.CmpString(t, pl.SetOutput(ctx, nil), "output is nil")
+
+		td.CmpNoError(t, pl.Close(ctx))
+		td.Cmp(t, pl.SetOutput(ctx, &recordingOutput{}), ErrClosed)
+	})
+}
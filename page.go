@@ -0,0 +1,75 @@
+package player
+
+import (
+	"context"
+	"errors"
+)
+
+// QueuePage - страница очереди активного плейлиста.
+type QueuePage struct {
+	// Songs - песни страницы по порядку
+	Songs []QueuedSong `json:"songs"`
+	// Total - сколько всего песен в плейлисте
+	Total int `json:"total"`
+	// Next - курсор следующей страницы для QueueAfter, 0 для последней страницы
+	Next SongID `json:"next,omitempty"`
+}
+
+// QueuePage - возвращает не больше limit песен очереди, начиная с индекса offset.
+// Для последовательного обхода большого плейлиста дешевле QueueAfter:
+// ему не нужно отсчитывать offset песен от края плейлиста.
+func (p *playerImpl) QueuePage(ctx context.Context, offset, limit int) (QueuePage, error) {
+	if offset < 0 {
+		return QueuePage{}, errors.New("offset is negative")
+	}
+	if limit <= 0 {
+		return QueuePage{}, errors.New("limit must be positive")
+	}
+
+	if err := p.rlock(ctx); err != nil {
+		return QueuePage{}, err
+	}
+	defer p.mu.RUnlock()
+
+	return p.pageLocked(p.nodeAt(offset), limit), nil
+}
+
+// QueueAfter - возвращает не больше limit песен очереди после песни с ID cursor,
+// а для нулевого cursor - с начала плейлиста. Курсор следующей страницы
+// возвращается в QueuePage.Next. Если песню cursor удалили, возвращается ErrSongNotFound.
+func (p *playerImpl) QueueAfter(ctx context.Context, cursor SongID, limit int) (QueuePage, error) {
+	if limit <= 0 {
+		return QueuePage{}, errors.New("limit must be positive")
+	}
+
+	if err := p.rlock(ctx); err != nil {
+		return QueuePage{}, err
+	}
+	defer p.mu.RUnlock()
+
+	if cursor == 0 {
+		return p.pageLocked(p.first(), limit), nil
+	}
+
+	node := p.find(cursor)
+	if node == nil {
+		return QueuePage{}, ErrSongNotFound
+	}
+
+	return p.pageLocked(node.next(), limit), nil
+}
+
+// pageLocked - собирает страницу из не больше limit песен, начиная с from.
+// Вызывается под блокировкой.
+func (p *playerImpl) pageLocked(from *playerNode, limit int) QueuePage {
+	page := QueuePage{Songs: []QueuedSong{}, Total: p.songs.Len()}
+	n := from
+	for ; n != nil && len(page.Songs) < limit; n = n.next() {
+		page.Songs = append(page.Songs, queuedSong(n))
+	}
+	if n != nil {
+		page.Next = page.Songs[len(page.Songs)-1].ID
+	}
+
+	return page
+}
@@ -0,0 +1,73 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_QueuePage(t *testing.T) {
+	ctx := context.Background()
+	songs := make([]Song, 5)
+	for i := range songs {
+		songs[i] = Song{Name: strconv.Itoa(i), Duration: time.Minute}
+	}
+	pl, _ := New(WithSongs(songs...))
+	pageNames := func(page QueuePage) []string {
+		var res []string
+		for _, s := range page.Songs {
+			res = append(res, s.Song.Name)
+		}
+		return res
+	}
+
+	t.Run("offset", func(t *testing.T) {
+		page, err := pl.QueuePage(ctx, 1, 2)
+		td.CmpNoError(t, err)
+		td.Cmp(t, pageNames(page), []string{"1", "2"})
+		td.Cmp(t, page.Total, 5)
+		td.Cmp(t, page.Next, page.Songs[1].ID)
+
+		page, _ = pl.QueuePage(ctx, 3, 10)
+		td.Cmp(t, pageNames(page), []string{"3", "4"})
+		td.Cmp(t, page.Next, SongID(0), "последняя страница")
+
+		page, _ = pl.QueuePage(ctx, 10, 10)
+		td.CmpEmpty(t, page.Songs)
+		td.Cmp(t, page.Total, 5)
+
+		_, err = pl.QueuePage(ctx, -1, 1)
+		td.CmpError(t, err)
+		_, err = pl.QueuePage(ctx, 0, 0)
+		td.CmpError(t, err)
+	})
+
+	t.Run("cursor", func(t *testing.T) {
+		var all []string
+		var cursor SongID
+		for {
+			page, err := pl.QueueAfter(ctx, cursor, 2)
+			td.CmpNoError(t, err)
+			all = append(all, pageNames(page)...)
+			if page.Next == 0 {
+				break
+			}
+			cursor = page.Next
+		}
+		td.Cmp(t, all, []string{"0", "1", "2", "3", "4"})
+
+		page, _ := pl.QueueAfter(ctx, 0, 2)
+		_, _ = pl.AddSong(ctx, Song{Name: "5", Duration: time.Minute})
+		td.CmpNoError(t, pl.RemoveAt(ctx, 0))
+		next, _ := pl.QueueAfter(ctx, page.Next, 2)
+		td.Cmp(t, pageNames(next), []string{"2", "3"}, "курсор не сдвигается при изменении плейлиста")
+
+		td.CmpNoError(t, pl.RemoveSong(ctx, page.Next))
+		_, err := pl.QueueAfter(ctx, page.Next, 2)
+		td.CmpTrue(t, errors.Is(err, ErrSongNotFound))
+	})
+}
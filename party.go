@@ -0,0 +1,171 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// ErrQueueLimit - пользователь достиг ограничения на количество песен в очереди.
+var ErrQueueLimit = errors.New("queue limit reached")
+
+// QueueLimitError - ошибка AddSongAs, когда у пользователя уже слишком много песен в очереди.
+// Соответствует ErrQueueLimit через errors.Is.
+type QueueLimitError struct {
+	// UserID - пользователь, добавлявший песню
+	UserID string
+	// Limit - сколько песен пользователь может держать в очереди
+	Limit int
+}
+
+func (e *QueueLimitError) Error() string {
+	return fmt.Sprintf("user %q has %d pending songs: %v", e.UserID, e.Limit, ErrQueueLimit)
+}
+
+func (e *QueueLimitError) Is(target error) bool {
+	return target == ErrQueueLimit
+}
+
+// QueuedSong - песня активного плейлиста вместе с тем, кто её добавил.
+type QueuedSong struct {
+	// ID - ID песни в плейлисте
+	ID SongID `json:"id"`
+	// Song - песня
+	Song Song `json:"song"`
+	// AddedBy - пользователь, добавивший песню через AddSongAs, пусто для остальных
+	AddedBy string `json:"added_by,omitempty"`
+}
+
+// QueueHook - обработчик добавления песни пользователем.
+type QueueHook func(song QueuedSong)
+
+// WithUserQueueLimit - ограничивает количество ещё не сыгранных песен,
+// которые один пользователь может добавить через AddSongAs.
+// Текущая песня не считается. По умолчанию ограничения нет.
+func WithUserQueueLimit(n int) Option {
+	return func(p *playerImpl) error {
+		if n <= 0 {
+			return errors.New("user queue limit must be positive")
+		}
+
+		p.userQueueLimit = n
+		return nil
+	}
+}
+
+// AddSongAs - добавляет песню в конец активного плейлиста от имени пользователя
+// и возвращает её ID. Если у пользователя уже есть столько ещё не сыгранных
+// песен, сколько разрешает WithUserQueueLimit, возвращается *QueueLimitError.
+func (p *playerImpl) AddSongAs(ctx context.Context, userID string, song Song) (SongID, error) {
+	if userID == "" {
+		return 0, errors.New("user id is empty")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return 0, ErrClosed
+	}
+
+	if p.userQueueLimit > 0 && p.pendingByLocked(userID) >= p.userQueueLimit {
+		return 0, &QueueLimitError{UserID: userID, Limit: p.userQueueLimit}
+	}
+
+	if err := p.checkCooldownLocked(song); err != nil {
+		return 0, err
+	}
+
+	if err := p.admitLocked(ctx, p.active, song); err != nil {
+		return 0, err
+	}
+
+	node := p.newNode(song, nil)
+	node.addedBy = userID
+	p.appendNode(node)
+	p.recordEditLocked(p.addEdit(node))
+	p.rescheduleLocked()
+	p.songQueuedLocked(node)
+	p.auditLocked(ctx, AuditEntry{UserID: userID, Op: AuditAdd, Song: &song, Detail: p.active})
+
+	p.logger.DebugContext(ctx, "song added", songAttr(song), slog.String("user", userID), slog.String("playlist", p.active))
+	return node.id, nil
+}
+
+// Queue - возвращает песни активного плейлиста по порядку вместе с тем, кто их добавил.
+func (p *playerImpl) Queue(_ context.Context) []QueuedSong {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	queue := make([]QueuedSong, 0, p.songs.Len())
+	for n := p.first(); n != nil; n = n.next() {
+		queue = append(queue, queuedSong(n))
+	}
+
+	return queue
+}
+
+// OnSongQueued - регистрирует обработчик, который вызывается,
+// когда пользователь добавляет песню через AddSongAs.
+// Обработчики вызываются по порядку в отдельной горутине.
+func (p *playerImpl) OnSongQueued(ctx context.Context, hook QueueHook) error {
+	if hook == nil {
+		return errors.New("hook is nil")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	p.queueHooks = append(p.queueHooks, hook)
+	return nil
+}
+
+// pendingByLocked - считает ещё не сыгранные песни пользователя в активном плейлисте.
+// Вызывается под блокировкой.
+func (p *playerImpl) pendingByLocked(userID string) int {
+	from := p.first()
+	if in := p.interruption; in != nil {
+		from = in.node.next()
+	} else if p.current != nil {
+		from = p.current.next()
+	}
+
+	count := 0
+	for n := from; n != nil; n = n.next() {
+		if n.addedBy == userID {
+			count++
+		}
+	}
+
+	return count
+}
+
+// songQueuedLocked - ставит в очередь обработчики добавления песни.
+// Вызывается под блокировкой.
+func (p *playerImpl) songQueuedLocked(node *playerNode) {
+	if len(p.queueHooks) == 0 {
+		return
+	}
+
+	hooks := append([]QueueHook(nil), p.queueHooks...)
+	song := queuedSong(node)
+	p.hookQueue.push(func() {
+		for _, h := range hooks {
+			h(song)
+		}
+	})
+}
+
+// queuedSong - описывает узел для Queue и обработчиков.
+func queuedSong(node *playerNode) QueuedSong {
+	return QueuedSong{ID: node.id, Song: *node.song, AddedBy: node.addedBy}
+}
@@ -0,0 +1,84 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_AddSongAs(t *testing.T) {
+	ctx := context.Background()
+	song := func(name string) Song { return Song{Name: name, Duration: 30 * time.Second} }
+
+	t.Run("attribution", func(t *testing.T) {
+		var (
+			mu     sync.Mutex
+			queued []QueuedSong
+		)
+
+		pl, _ := NewPlayer(song("a"))
+		td.CmpError(t, pl.OnSongQueued(ctx, nil))
+		td.CmpNoError(t, pl.OnSongQueued(ctx, func(s QueuedSong) {
+			mu.Lock()
+			defer mu.Unlock()
+			queued = append(queued, s)
+		}))
+
+		_, err := pl.AddSongAs(ctx, "", song("b"))
+		td.CmpString(t, err, "user id is empty")
+
+		id, err := pl.AddSongAs(ctx, "вася", song("b"))
+		td.CmpNoError(t, err)
+
+		td.Cmp(t, pl.Queue(ctx), td.Smuggle(func(q []QueuedSong) []string {
+			var res []string
+			for _, s := range q {
+				res = append(res, s.Song.Name+":"+s.AddedBy)
+			}
+			return res
+		}, []string{"a:", "b:вася"}))
+
+		td.CmpNoError(t, pl.hookQueue.wait(ctx))
+		mu.Lock()
+		defer mu.Unlock()
+		td.Cmp(t, queued, []QueuedSong{{ID: id, Song: song("b"), AddedBy: "вася"}})
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		pl, _ := New(WithUserQueueLimit(2), WithSongs(song("a")))
+
+		_, _ = pl.AddSongAs(ctx, "вася", song("b"))
+		_, _ = pl.AddSongAs(ctx, "вася", song("c"))
+		_, err := pl.AddSongAs(ctx, "вася", song("d"))
+		td.CmpTrue(t, errors.Is(err, ErrQueueLimit))
+
+		var limitErr *QueueLimitError
+		td.CmpTrue(t, errors.As(err, &limitErr))
+		td.Cmp(t, limitErr, &QueueLimitError{UserID: "вася", Limit: 2})
+
+		_, err = pl.AddSongAs(ctx, "петя", song("d"))
+		td.CmpNoError(t, err, "ограничение для каждого пользователя своё")
+
+		// песня стала текущей и больше не ждёт очереди
+		_ = pl.Next(ctx)
+		_ = pl.Pause(ctx)
+		_, err = pl.AddSongAs(ctx, "вася", song("e"))
+		td.CmpNoError(t, err)
+
+		_, err = New(WithUserQueueLimit(0))
+		td.CmpError(t, err)
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		pl, _ := NewPlayer()
+		_ = pl.Close(ctx)
+
+		_, err := pl.AddSongAs(ctx, "вася", song("a"))
+		td.Cmp(t, err, ErrClosed)
+		td.Cmp(t, pl.OnSongQueued(ctx, func(QueuedSong) {}), ErrClosed)
+	})
+}
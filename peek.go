@@ -0,0 +1,135 @@
+package player
+
+import (
+	"context"
+	"maps"
+)
+
+// PeekNext - возвращает до n песен, которые будут играть после текущей,
+// в том порядке, в каком до них дойдёт Next. Первыми идут песни очереди Enqueue,
+// во время вставки за ними - прерванная песня. При EdgeWrap после последней песни идут первые,
+// но каждая песня попадает в результат не больше одного раза.
+// С WithSequencer песни выбираются так же, как их выберет секвенсор. Shuffle
+// и WeightedRandom выбирают случайно, когда песня начинает играть, поэтому
+// для них известна только песня, уже выбранная после текущей.
+func (p *playerImpl) PeekNext(_ context.Context, n int) []Song {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var next []Song
+	if p.sequencer != nil {
+		next = p.peekSequencedLocked(n - p.upNext.Len())
+	} else {
+		next = p.peekLocked(n-p.upNext.Len(), func(node *playerNode) *playerNode { return node.next() }, p.first())
+	}
+	if p.upNext.Len() == 0 || n <= 0 {
+		return next
+	}
+
+	songs := p.upNext.Values()
+	return append(songs[:min(n, len(songs))], next...)
+}
+
+// PeekPrev - возвращает до n песен перед текущей, начиная с ближайшей,
+// в том порядке, в каком до них дойдёт Prev. При EdgeWrap перед первой
+// песней идут последние.
+func (p *playerImpl) PeekPrev(_ context.Context, n int) []Song {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.peekLocked(n, func(node *playerNode) *playerNode { return node.prev() }, p.last())
+}
+
+// peekLocked - обходит плейлист от текущей песни шагами step,
+// переходя на wrap на краю плейлиста при EdgeWrap.
+// Вызывается под блокировкой.
+func (p *playerImpl) peekLocked(n int, step func(*playerNode) *playerNode, wrap *playerNode) []Song {
+	if p.current == nil || n <= 0 {
+		return nil
+	}
+
+	var songs []Song
+	node := p.current
+
+	// вставка с обеих сторон ведёт к прерванной песне
+	if in := p.interruption; in != nil && node == in.jingle {
+		songs = append(songs, *in.node.song)
+		node = in.node
+	}
+
+	start := node
+	for len(songs) < n {
+		node = step(node)
+		if node == nil {
+			if p.edge != EdgeWrap {
+				break
+			}
+			node = wrap
+		}
+		if node == start {
+			break
+		}
+
+		songs = append(songs, *node.song)
+	}
+
+	return songs
+}
+
+// peekSequencedLocked - как peekLocked для песен после текущей, но повторяет
+// выбор секвенсора на копии сыгранных в проходе песен.
+// Вызывается под блокировкой.
+func (p *playerImpl) peekSequencedLocked(n int) []Song {
+	if p.current == nil || n <= 0 {
+		return nil
+	}
+
+	var songs []Song
+	node := p.current
+
+	// вставка ведёт к прерванной песне
+	if in := p.interruption; in != nil && node == in.jingle {
+		songs = append(songs, *in.node.song)
+		node = in.node
+	}
+
+	_, random := p.sequencer.(shuffle)
+	if _, ok := p.sequencer.(*weightedRandom); ok {
+		random = true
+	}
+
+	played := maps.Clone(p.played)
+	seen := map[*playerNode]bool{node: true}
+	for len(songs) < n {
+		var next *playerNode
+		switch {
+		case node == p.sequenced:
+			next = p.afterLocked(node)
+		case random || !p.contains(node):
+			return songs
+		default:
+			if played == nil {
+				played = make(map[*playerNode]struct{})
+			}
+			played[node] = struct{}{}
+			next = p.sequencedLocked(node, played)
+		}
+
+		// проход кончился, при EdgeWrap следующий начнётся с первой песни
+		if next == nil {
+			if p.edge != EdgeWrap {
+				break
+			}
+			next, played = p.first(), nil
+		}
+		if seen[next] {
+			break
+		}
+
+		seen[next] = true
+		songs = append(songs, *next.song)
+		node = next
+	}
+
+	return songs
+}
@@ -0,0 +1,90 @@
+package player
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Peek(t *testing.T) {
+	ctx := context.Background()
+
+	songs := []Song{
+		{Name: "a", Duration: 30 * time.Second},
+		{Name: "b", Duration: 30 * time.Second},
+		{Name: "c", Duration: 30 * time.Second},
+		{Name: "d", Duration: 30 * time.Second},
+	}
+	names := func(songs []Song) []string {
+		res := []string{}
+		for _, s := range songs {
+			res = append(res, s.Name)
+		}
+		return res
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		pl, _ := NewPlayer()
+		td.CmpNil(t, pl.PeekNext(ctx, 3))
+		td.CmpNil(t, pl.PeekPrev(ctx, 3))
+	})
+
+	t.Run("linear", func(t *testing.T) {
+		pl, _ := NewPlayer(songs...)
+		_ = pl.PlayAt(ctx, 1)
+		_ = pl.Pause(ctx)
+
+		td.Cmp(t, names(pl.PeekNext(ctx, 1)), []string{"c"})
+		td.Cmp(t, names(pl.PeekNext(ctx, 10)), []string{"c", "d"}, "конец плейлиста")
+		td.Cmp(t, names(pl.PeekPrev(ctx, 10)), []string{"a"})
+		td.CmpNil(t, pl.PeekNext(ctx, 0))
+	})
+
+	t.Run("wrap", func(t *testing.T) {
+		pl, _ := New(WithEdgeBehavior(EdgeWrap), WithSongs(songs...))
+		_ = pl.PlayAt(ctx, 1)
+		_ = pl.Pause(ctx)
+
+		td.Cmp(t, names(pl.PeekNext(ctx, 10)), []string{"c", "d", "a"}, "каждая песня один раз")
+		td.Cmp(t, names(pl.PeekPrev(ctx, 2)), []string{"a", "d"})
+	})
+
+	t.Run("sequencer", func(t *testing.T) {
+		// следующей играет песня с ближайшим темпом
+		bpm := map[string]float64{"a": 120, "b": 90, "c": 128, "d": 124}
+		seq := BestMatch(func(cur, cand Song) float64 { return -math.Abs(bpm[cur.Name] - bpm[cand.Name]) })
+		pl, _ := New(WithSequencer(seq), WithEdgeBehavior(EdgeWrap), WithSongs(songs...))
+		_ = pl.Play(ctx)
+		defer pl.Pause(ctx)
+
+		td.Cmp(t, names(pl.PeekNext(ctx, 10)), []string{"d", "c", "b"}, "порядок секвенсора")
+		_ = pl.Next(ctx)
+		td.Cmp(t, names(pl.PeekNext(ctx, 10)), []string{"c", "b", "a"}, "после прохода снова первая")
+		_ = pl.Next(ctx)
+		td.Cmp(t, names(pl.PeekNext(ctx, 1)), []string{"b"})
+	})
+
+	t.Run("shuffle", func(t *testing.T) {
+		pl, _ := New(WithSequencer(Shuffle()), WithSongs(songs...))
+		_ = pl.Play(ctx)
+		defer pl.Pause(ctx)
+
+		next := pl.PeekNext(ctx, 3)
+		td.Cmp(t, next, td.Len(1), "известна только выбранная песня")
+		_ = pl.Next(ctx)
+		td.Cmp(t, pl.Status(ctx).Song.Name, next[0].Name)
+	})
+
+	t.Run("interruption", func(t *testing.T) {
+		pl, _ := NewPlayer(songs...)
+		_ = pl.PlayAt(ctx, 1)
+		_ = pl.InterruptWith(ctx, Song{Name: "jingle", Duration: 30 * time.Second})
+		defer pl.Pause(ctx)
+
+		td.Cmp(t, names(pl.PeekNext(ctx, 2)), []string{"b", "c"}, "сначала прерванная песня")
+		td.Cmp(t, names(pl.PeekPrev(ctx, 2)), []string{"b", "a"})
+	})
+}
@@ -0,0 +1,192 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// playbackErrorsBuffer - сколько ошибок воспроизведения хранит канал Errors.
+const playbackErrorsBuffer = 16
+
+// ErrorStage - этап, на котором бэкенд вернул ошибку.
+type ErrorStage string
+
+const (
+	// StageStart - запуск песни: открытие или декодирование
+	StageStart ErrorStage = "start"
+	// StageStop - остановка песни
+	StageStop ErrorStage = "stop"
+	// StagePrepare - подготовка следующей песни
+	StagePrepare ErrorStage = "prepare"
+	// StageRamp - плавное изменение громкости
+	StageRamp ErrorStage = "ramp"
+	// StageGain - выравнивание громкости
+	StageGain ErrorStage = "gain"
+	// StageResolve - определение длительности песни у источника
+	StageResolve ErrorStage = "resolve"
+)
+
+// PlaybackError - ошибка бэкенда во время воспроизведения.
+type PlaybackError struct {
+	// Song - песня, с которой произошла ошибка
+	Song Song
+	// Stage - этап воспроизведения
+	Stage ErrorStage
+	// Err - ошибка бэкенда
+	Err error
+	// State - состояние плеера в момент ошибки
+	State State
+}
+
+func (e PlaybackError) Error() string {
+	return fmt.Sprintf("%s %q: %v", e.Stage, e.Song.Name, e.Err)
+}
+
+func (e PlaybackError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorPolicy - реакция плеера на ошибку запуска песни.
+type ErrorPolicy int
+
+const (
+	// ErrorContinue - только сообщить об ошибке, поведение по умолчанию
+	ErrorContinue ErrorPolicy = iota
+	// ErrorSkip - перейти к следующей песне, как Next
+	ErrorSkip
+	// ErrorPause - приостановить воспроизведение на песне с ошибкой
+	ErrorPause
+	// ErrorStop - остановить воспроизведение и перейти на первую песню
+	ErrorStop
+)
+
+func (e ErrorPolicy) String() string {
+	switch e {
+	case ErrorContinue:
+		return "continue"
+	case ErrorSkip:
+		return "skip"
+	case ErrorPause:
+		return "pause"
+	case ErrorStop:
+		return "stop"
+	default:
+		return fmt.Sprintf("ErrorPolicy(%d)", int(e))
+	}
+}
+
+// MarshalText - кодирует политику строкой, например "skip".
+func (e ErrorPolicy) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+// UnmarshalText - разбирает политику из строки MarshalText.
+func (e *ErrorPolicy) UnmarshalText(text []byte) (err error) {
+	*e, err = parseEnum(text, ErrorContinue, ErrorStop, "error policy")
+	return err
+}
+
+// WithErrorPolicy - задаёт реакцию на ошибку запуска песни бэкендом.
+// Если при ErrorSkip подряд не запускается ни одна песня плейлиста,
+// воспроизведение останавливается, как при ErrorStop.
+func WithErrorPolicy(policy ErrorPolicy) Option {
+	return func(p *playerImpl) error {
+		if policy < ErrorContinue || policy > ErrorStop {
+			return errors.New("unknown error policy")
+		}
+
+		p.errorPolicy = policy
+		return nil
+	}
+}
+
+// Errors - возвращает канал ошибок бэкенда во время воспроизведения.
+// Если канал не читают, новые ошибки отбрасываются, когда в нём накопится
+// playbackErrorsBuffer ошибок. Канал закрывается при закрытии плеера.
+func (p *playerImpl) Errors() <-chan PlaybackError {
+	return p.playbackErrors
+}
+
+// playbackErrorLocked - логирует ошибку бэкенда и отправляет её в канал Errors.
+// Вызывается под блокировкой.
+func (p *playerImpl) playbackErrorLocked(ctx context.Context, song Song, stage ErrorStage, err error) {
+	p.logger.ErrorContext(ctx, "output "+string(stage)+" failed", songAttr(song), slog.Any("error", err))
+	if p.closed {
+		return
+	}
+
+	event := PlaybackError{Song: song, Stage: stage, Err: err, State: p.stateLocked()}
+	p.publishLocked(EventError, event)
+
+	select {
+	case p.playbackErrors <- event:
+	default:
+		p.logger.WarnContext(ctx, "playback error dropped", songAttr(song), slog.String("stage", string(stage)))
+	}
+}
+
+// startFailedLocked - сообщает об ошибке запуска текущей песни
+// и реагирует на неё по политике.
+// Вызывается под блокировкой.
+func (p *playerImpl) startFailedLocked(ctx context.Context, song Song, err error) {
+	p.playbackErrorLocked(ctx, song, StageStart, err)
+	p.failurePolicyLocked(ctx, song)
+}
+
+// failurePolicyLocked - реагирует на ошибку текущей песни по политике.
+// Реакция выполняется в отдельной горутине, так как ошибка может произойти
+// посреди шага воспроизведения.
+// Вызывается под блокировкой.
+func (p *playerImpl) failurePolicyLocked(ctx context.Context, song Song) {
+	if p.errorPolicy == ErrorContinue {
+		return
+	}
+
+	node := p.current
+	p.failedStarts++
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		// песню уже сменили
+		if p.closed || p.current != node {
+			return
+		}
+
+		policy := p.errorPolicy
+		if policy == ErrorSkip && p.failedStarts >= p.songs.Len() {
+			policy = ErrorStop
+		}
+
+		switch policy {
+		case ErrorSkip:
+			p.skipFailedLocked(ctx)
+		case ErrorPause:
+			p.pauseLocked(ctx)
+		case ErrorStop:
+			p.failedStarts = 0
+			p.stopAtEdgeLocked(ctx, p.first())
+		}
+	}()
+}
+
+// skipFailedLocked - переходит к следующей песне, пропуская песни,
+// которые тоже не запускаются. Если не запустилась ни одна песня плейлиста,
+// останавливает воспроизведение.
+// Вызывается под блокировкой.
+func (p *playerImpl) skipFailedLocked(ctx context.Context) {
+	for p.failedStarts < p.songs.Len() {
+		p.logger.InfoContext(ctx, "skipping failed song", songAttr(*p.current.song))
+		err := p.nextLocked(ctx)
+		if err == nil || p.isPlaying || errors.Is(err, ErrClosed) {
+			return
+		}
+		p.failedStarts++
+	}
+
+	p.failedStarts = 0
+	p.stopAtEdgeLocked(ctx, p.first())
+}
@@ -0,0 +1,180 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// brokenOutput - бэкенд, который не может запустить часть песен.
+type brokenOutput struct {
+	recordingOutput
+
+	mu     sync.Mutex
+	broken map[string]bool
+}
+
+func (o *brokenOutput) Start(ctx context.Context, song Song, offset time.Duration) error {
+	o.mu.Lock()
+	broken := o.broken[song.Name]
+	o.mu.Unlock()
+
+	if broken {
+		return errors.New("cannot decode")
+	}
+	return o.recordingOutput.Start(ctx, song, offset)
+}
+
+func nextPlaybackError(t *testing.T, pl *playerImpl) PlaybackError {
+	t.Helper()
+
+	select {
+	case perr := <-pl.Errors():
+		return perr
+	case <-time.After(time.Second):
+		t.Fatal("нет ошибки воспроизведения")
+		return PlaybackError{}
+	}
+}
+
+// eventually - ждёт, пока реакция на ошибку выполнится в отдельной горутине.
+func eventually(cond func() bool) bool {
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+		if cond() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return cond()
+}
+
+func TestPlaybackErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ошибка запуска попадает в канал", func(t *testing.T) {
+		output := &brokenOutput{broken: map[string]bool{"a": true}}
+		pl, err := New(WithValidator(ValidationPolicy{}), WithOutput(output), WithSongs(
+			Song{Name: "a", Duration: time.Minute},
+			Song{Name: "b", Duration: time.Minute},
+		))
+		td.CmpNoError(t, err)
+
+		td.CmpError(t, pl.Play(ctx))
+
+		perr := nextPlaybackError(t, pl)
+		td.Cmp(t, perr.Song.Name, "a", "песня с ошибкой")
+		td.Cmp(t, perr.Stage, StageStart, "этап")
+		td.Cmp(t, perr.Error(), `start "a": cannot decode`, "текст ошибки")
+		td.CmpTrue(t, errors.Is(perr, perr.Err), "Unwrap возвращает ошибку бэкенда")
+
+		td.CmpNoError(t, pl.Close(ctx))
+		_, ok := <-pl.Errors()
+		td.CmpFalse(t, ok, "канал закрыт вместе с плеером")
+	})
+
+	t.Run("по умолчанию воспроизведение продолжается", func(t *testing.T) {
+		output := &brokenOutput{broken: map[string]bool{"b": true}}
+		pl, err := New(WithValidator(ValidationPolicy{}), WithOutput(output), WithSongs(
+			Song{Name: "a", Duration: 20 * time.Millisecond},
+			Song{Name: "b", Duration: time.Minute},
+		))
+		td.CmpNoError(t, err)
+		defer pl.Close(ctx)
+
+		td.CmpNoError(t, pl.Play(ctx))
+		perr := nextPlaybackError(t, pl)
+		td.Cmp(t, perr.Song.Name, "b", "песня с ошибкой")
+
+		status := pl.Status(ctx)
+		td.Cmp(t, status.Song.Name, "b", "плеер остался на песне")
+		td.CmpTrue(t, status.Playing, "и продолжает воспроизведение")
+	})
+
+	t.Run("канал не блокирует плеер", func(t *testing.T) {
+		output := &brokenOutput{broken: map[string]bool{"a": true}}
+		pl, err := New(WithValidator(ValidationPolicy{}), WithOutput(output), WithSongs(Song{Name: "a", Duration: time.Minute}))
+		td.CmpNoError(t, err)
+		defer pl.Close(ctx)
+
+		for i := 0; i < playbackErrorsBuffer+5; i++ {
+			td.CmpError(t, pl.Play(ctx))
+		}
+		td.Cmp(t, len(pl.Errors()), playbackErrorsBuffer, "лишние ошибки отброшены")
+	})
+}
+
+func TestWithErrorPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := New(WithErrorPolicy(ErrorPolicy(42)))
+	td.CmpString(t, err, "unknown error policy")
+
+	songs := []Song{
+		{Name: "a", Duration: 20 * time.Millisecond},
+		{Name: "b", Duration: time.Minute},
+		{Name: "c", Duration: time.Minute},
+	}
+
+	t.Run("пропуск", func(t *testing.T) {
+		output := &brokenOutput{broken: map[string]bool{"b": true}}
+		pl, err := New(WithValidator(ValidationPolicy{}), WithOutput(output), WithErrorPolicy(ErrorSkip), WithSongs(songs...))
+		td.CmpNoError(t, err)
+		defer pl.Close(ctx)
+
+		td.CmpNoError(t, pl.Play(ctx))
+		nextPlaybackError(t, pl)
+
+		td.CmpTrue(t, eventually(func() bool { return pl.Status(ctx).Song.Name == "c" }), "перешли к следующей песне")
+		td.CmpTrue(t, pl.Status(ctx).Playing, "воспроизведение продолжается")
+		td.Cmp(t, output.Calls(), []string{"start a", "stop a", "stop b", "start c"})
+	})
+
+	t.Run("пропуск останавливается, если не запускается ни одна песня", func(t *testing.T) {
+		output := &brokenOutput{broken: map[string]bool{"b": true, "c": true}}
+		pl, err := New(WithValidator(ValidationPolicy{}), WithOutput(output), WithErrorPolicy(ErrorSkip), WithEdgeBehavior(EdgeWrap), WithSongs(songs...))
+		td.CmpNoError(t, err)
+		defer pl.Close(ctx)
+
+		td.CmpNoError(t, pl.Play(ctx))
+		output.mu.Lock()
+		output.broken["a"] = true
+		output.mu.Unlock()
+
+		td.CmpTrue(t, eventually(func() bool {
+			status := pl.Status(ctx)
+			return !status.Playing && status.Song.Name == "a"
+		}), "остановились на первой песне")
+	})
+
+	t.Run("пауза", func(t *testing.T) {
+		output := &brokenOutput{broken: map[string]bool{"b": true}}
+		pl, err := New(WithValidator(ValidationPolicy{}), WithOutput(output), WithErrorPolicy(ErrorPause), WithSongs(songs...))
+		td.CmpNoError(t, err)
+		defer pl.Close(ctx)
+
+		td.CmpNoError(t, pl.Play(ctx))
+		nextPlaybackError(t, pl)
+
+		td.CmpTrue(t, eventually(func() bool { return !pl.Status(ctx).Playing }), "воспроизведение приостановлено")
+		td.Cmp(t, pl.Status(ctx).Song.Name, "b", "на песне с ошибкой")
+	})
+
+	t.Run("остановка", func(t *testing.T) {
+		output := &brokenOutput{broken: map[string]bool{"b": true}}
+		pl, err := New(WithValidator(ValidationPolicy{}), WithOutput(output), WithErrorPolicy(ErrorStop), WithSongs(songs...))
+		td.CmpNoError(t, err)
+		defer pl.Close(ctx)
+
+		td.CmpNoError(t, pl.Play(ctx))
+		nextPlaybackError(t, pl)
+
+		td.CmpTrue(t, eventually(func() bool {
+			status := pl.Status(ctx)
+			return !status.Playing && status.Song.Name == "a"
+		}), "остановились на первой песне")
+	})
+}
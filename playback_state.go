@@ -0,0 +1,103 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidState - операция недопустима в текущем состоянии плеера.
+// Ошибки StateError совпадают с ней через errors.Is.
+var ErrInvalidState = errors.New("invalid player state")
+
+// State - состояние воспроизведения.
+type State int
+
+const (
+	// StateStopped - воспроизведение не начато или остановлено
+	StateStopped State = iota
+	// StatePlaying - песня воспроизводится
+	StatePlaying
+	// StatePaused - воспроизведение приостановлено, позиция сохранена
+	StatePaused
+	// StateTransitioning - идёт наложение или пауза между песнями
+	StateTransitioning
+	// StateClosed - плеер закрыт
+	StateClosed
+)
+
+func (s State) String() string {
+	switch s {
+	case StateStopped:
+		return "stopped"
+	case StatePlaying:
+		return "playing"
+	case StatePaused:
+		return "paused"
+	case StateTransitioning:
+		return "transitioning"
+	case StateClosed:
+		return "closed"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// MarshalText - кодирует состояние строкой, например "playing".
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// StateError - операция Op недопустима в состоянии State.
+type StateError struct {
+	// Op - операция, например "seek"
+	Op string
+	// State - состояние плеера в момент вызова
+	State State
+}
+
+func (e StateError) Error() string {
+	return fmt.Sprintf("%s while %s", e.Op, e.State)
+}
+
+func (e StateError) Is(target error) bool {
+	return target == ErrInvalidState
+}
+
+// State - возвращает состояние воспроизведения.
+func (p *playerImpl) State(_ context.Context) State {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.stateLocked()
+}
+
+// stateLocked - возвращает состояние воспроизведения.
+// Вызывается под блокировкой.
+func (p *playerImpl) stateLocked() State {
+	switch {
+	case p.closed:
+		return StateClosed
+	case p.isPlaying && (p.inGap || p.fading != nil):
+		return StateTransitioning
+	case p.isPlaying:
+		return StatePlaying
+	case p.paused:
+		return StatePaused
+	default:
+		return StateStopped
+	}
+}
+
+// requireStateLocked - возвращает StateError, если плеер не в одном из состояний allowed.
+// Вызывается под блокировкой.
+func (p *playerImpl) requireStateLocked(op string, allowed ...State) error {
+	state := p.stateLocked()
+	for _, s := range allowed {
+		if s == state {
+			return nil
+		}
+	}
+
+	return StateError{Op: op, State: state}
+}
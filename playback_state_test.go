@@ -0,0 +1,57 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_State(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("переходы", func(t *testing.T) {
+		pl, err := NewPlayer(Song{Name: "a", Duration: time.Minute}, Song{Name: "b", Duration: time.Minute})
+		td.CmpNoError(t, err)
+		td.Cmp(t, pl.State(ctx), StateStopped)
+
+		err = pl.Pause(ctx)
+		td.Cmp(t, err, StateError{Op: "pause", State: StateStopped})
+		td.CmpTrue(t, errors.Is(err, ErrInvalidState))
+		td.CmpString(t, err, "pause while stopped")
+
+		td.CmpNoError(t, pl.Play(ctx))
+		td.Cmp(t, pl.State(ctx), StatePlaying)
+
+		td.CmpNoError(t, pl.Pause(ctx))
+		td.Cmp(t, pl.State(ctx), StatePaused)
+		td.CmpNoError(t, pl.Pause(ctx), "повторная пауза допустима")
+
+		td.CmpNoError(t, pl.RemoveAt(ctx, 0))
+		td.Cmp(t, pl.State(ctx), StateStopped, "песня сменилась - позиция потеряна")
+
+		td.CmpNoError(t, pl.Close(ctx))
+		td.Cmp(t, pl.State(ctx), StateClosed)
+	})
+
+	t.Run("смена песни", func(t *testing.T) {
+		pl, err := New(WithValidator(ValidationPolicy{}), WithCrossfade(50*time.Millisecond), WithSongs(
+			Song{Name: "a", Duration: 60 * time.Millisecond},
+			Song{Name: "b", Duration: time.Minute},
+		))
+		td.CmpNoError(t, err)
+		defer pl.Close(ctx)
+
+		td.CmpNoError(t, pl.Play(ctx))
+		waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		td.CmpNoError(t, pl.WaitFor(waitCtx, func(st Status) bool { return st.Song.Name == "b" }))
+		td.Cmp(t, pl.State(ctx), StateTransitioning, "предыдущая песня ещё затухает")
+
+		td.CmpNoError(t, pl.WaitFor(waitCtx, func(Status) bool { return pl.State(ctx) == StatePlaying }))
+	})
+
+	td.Cmp(t, State(42).String(), "State(42)")
+}
@@ -2,10 +2,14 @@ package player
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"log/slog"
+	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"player/list"
 )
 
 /*
@@ -20,45 +24,333 @@ import (
 - Prev воспроизвести предыдущую песню
 */
 
+// Player - музыкальный плеер. Методы, возвращающие ошибку, не ждут плеер
+// дольше, чем живёт ctx: если ctx завершится раньше, чем освободится плеер,
+// метод ничего не меняет и возвращает ctx.Err().
 type Player interface {
 	// Play - начинает воспроизведение
 	Play(ctx context.Context) error
 	// Pause - приостанавливает воспроизведение
 	Pause(ctx context.Context) error
-	// AddSong - добавляет в конец плейлиста песню
-	AddSong(ctx context.Context, song Song) error
+	// AddSong - добавляет в конец плейлиста песню и возвращает её ID
+	AddSong(ctx context.Context, song Song) (SongID, error)
 	// Next воспроизвести след песню
 	Next(ctx context.Context) error
 	// Prev воспроизвести предыдущую песню
 	Prev(ctx context.Context) error
+	// SetVolume - устанавливает громкость от 0 до 100
+	SetVolume(ctx context.Context, volume int) error
+	// Volume - возвращает громкость
+	Volume(ctx context.Context) int
+	// Mute - выключает звук, сохраняя громкость
+	Mute(ctx context.Context) error
+	// Unmute - включает звук
+	Unmute(ctx context.Context) error
+	// Status - возвращает состояние воспроизведения
+	Status(ctx context.Context) Status
 }
 
 type Song struct {
 	// Name - название песни
-	Name string
-	// Duration - длительность песни
-	Duration time.Duration
+	Name string `json:"name"`
+	// Duration - длительность песни, 0 для потока
+	Duration time.Duration `json:"duration"`
+	// Artist - исполнитель
+	Artist string `json:"artist,omitempty"`
+	// Album - альбом
+	Album string `json:"album,omitempty"`
+	// URL - адрес потока или файла
+	URL string `json:"url,omitempty"`
+	// LoudnessLUFS - измеренная громкость песни, 0 - неизвестна
+	LoudnessLUFS float64 `json:"loudness_lufs,omitempty"`
+	// Chapters - главы песни по возрастанию начала, например для аудиокниг
+	Chapters []Chapter `json:"chapters,omitempty"`
+	// Cues - дорожки CUE по возрастанию начала, если песня - альбом одним файлом.
+	// Next и Prev переходят между ними, не меняя песню
+	Cues []CueTrack `json:"cues,omitempty"`
+	// Source - источник звука, у которого плеер узнаёт длительность,
+	// если она не задана
+	Source Source `json:"-"`
+	// LeadIn - тишина в начале песни, которая пропускается при запуске с начала
+	LeadIn time.Duration `json:"lead_in,omitempty"`
+	// LeadOut - тишина в конце песни, перед которой плеер переходит к следующей
+	LeadOut time.Duration `json:"lead_out,omitempty"`
+}
+
+// IsStream - песня является потоком неизвестной длительности, например интернет-радио.
+// Поток играет, пока его не пропустят или не остановят.
+func (s Song) IsStream() bool {
+	return s.Duration == 0
+}
+
+// End - позиция, на которой песня заканчивается с учётом LeadOut.
+func (s Song) End() time.Duration {
+	return s.Duration - s.LeadOut
+}
+
+// playLength - сколько песня играет от LeadIn до End.
+func (s Song) playLength() time.Duration {
+	return s.End() - s.LeadIn
 }
 
 type playerNode struct {
+	id   SongID
 	song *Song
+	// hook - вызывается, когда песня доиграла или была пропущена
+	hook SongHook
+	// track - трек библиотеки, на который ссылается узел
+	track TrackID
+	// addedBy - пользователь, добавивший песню через AddSongAs
+	addedBy string
+	// resolved - длительность песни получена от Source
+	resolved bool
+	// resolveErr - ошибка, с которой не удалось узнать длительность, до следующего Play
+	resolveErr error
+	// counted - длительность песни, учтённая в total плейлиста
+	counted time.Duration
+
+	// elem - элемент плейлиста, nil для узла вставки и удалённой песни
+	elem *list.Element[*playerNode]
+	// around - для узла вставки вне плейлиста: песня, к которой ведут next и prev
+	around *playerNode
+}
 
-	next *playerNode
-	prev *playerNode
+// next - следующая песня плейлиста, nil для последней.
+func (n *playerNode) next() *playerNode {
+	if n.elem == nil {
+		return n.around
+	}
+
+	return nodeOf(n.elem.Next())
 }
 
-type playerImpl struct {
-	mu   sync.RWMutex
-	head *playerNode
-	tail *playerNode
+// prev - предыдущая песня плейлиста, nil для первой.
+func (n *playerNode) prev() *playerNode {
+	if n.elem == nil {
+		return n.around
+	}
 
-	current *playerNode
+	return nodeOf(n.elem.Prev())
+}
 
-	pauseCh chan struct{}
-	stopCh  chan struct{}
+// nodeOf - узел элемента плейлиста, nil для nil.
+func nodeOf(e *list.Element[*playerNode]) *playerNode {
+	if e == nil {
+		return nil
+	}
 
-	isPlaying  bool
-	playedTime time.Duration
+	return e.Value
+}
+
+type playerImpl struct {
+	mu sync.RWMutex
+
+	// playlist - активный плейлист
+	playlist
+	// active - название активного плейлиста
+	active string
+	// playlists - неактивные плейлисты по названию
+	playlists map[string]*playlist
+	// smart - правила умных плейлистов по названию
+	smart map[string]Rule
+	// library - библиотека, на треки которой ссылаются плейлисты
+	library *Library
+	// initial - песни WithSongs, New добавляет их после остальных настроек
+	initial []Song
+
+	// output - бэкенд воспроизведения
+	output Output
+	// crossfade - наложение конца песни на начало следующей
+	crossfade time.Duration
+	// gap - пауза между песнями
+	gap time.Duration
+	// preparer - подготавливает следующую песню заранее
+	preparer Preparer
+	// prepareLead - за сколько до перехода подготавливать следующую песню
+	prepareLead time.Duration
+	// replayGain - выравнивать громкость песен до targetLUFS
+	replayGain bool
+	targetLUFS float64
+	// volume - громкость от 0 до 100
+	volume int
+	// muted - звук выключен
+	muted bool
+	// ducked - звук приглушён до доли duckLevel громкости
+	ducked    bool
+	duckLevel float64
+	// eq - усиление полос эквалайзера, nil если не задавался
+	eq []float64
+	// validator - проверка каждой добавляемой песни
+	validator SongValidator
+	// storage - хранилище плейлистов, истории и состояния
+	storage Storage
+	// scrobbler - сервис учёта прослушиваний
+	scrobbler Scrobbler
+	// scrobbled - песня, прослушивание которой уже отправлено
+	scrobbled *playerNode
+	// tagReader - чтение тегов для LoadDirectory
+	tagReader TagReader
+	// autoplay - начать воспроизведение сразу после создания
+	autoplay bool
+	// edge - поведение Next в конце и Prev в начале плейлиста
+	edge EdgeBehavior
+	// prevRestart - после скольких секунд Prev начинает текущую песню сначала
+	prevRestart time.Duration
+	// playbackErrors - канал Errors
+	playbackErrors chan PlaybackError
+	// errorPolicy - реакция на ошибку запуска песни
+	errorPolicy ErrorPolicy
+	// failedStarts - сколько песен подряд не запустилось
+	failedStarts int
+
+	// transitionHooks - обработчики смены текущей песни
+	transitionHooks []TransitionHook
+	// autoTransitionAt - расчётный момент автоматического перехода, который выполняет moveToLocked
+	autoTransitionAt time.Time
+
+	// chapterHooks - обработчики смены главы
+	chapterHooks []ChapterHook
+	// chapterNode, chapterIndex - песня и глава, о которой сообщили обработчикам
+	chapterNode  *playerNode
+	chapterIndex int
+	// milestones - отметки прогресса текущей песни
+	milestones milestones
+
+	// sequencer - выбор следующей песни, nil - порядок плейлиста
+	sequencer Sequencer
+	// sequenced - песня, для которой секвенсор уже выбрал следующую
+	sequenced *playerNode
+	// chosen - песня, которую секвенсор выбрал после sequenced, nil - после неё проход кончается
+	chosen *playerNode
+	// played - песни, сыгранные в текущем проходе секвенсора
+	played map[*playerNode]struct{}
+	// rand - генератор для Shuffle и WeightedRandom
+	rand *rand.Rand
+	// replayable - источник rand, если его состояние можно сохранить
+	replayable *ReplayableRand
+	// interstitials - вставки между песнями, nil если не заданы
+	interstitials *interstitials
+	// userQueueLimit - сколько несыгранных песен может добавить один пользователь, 0 - без ограничения
+	userQueueLimit int
+
+	// logger - логгер переходов состояния и ошибок
+	logger *slog.Logger
+
+	// stopCh закрывается для остановки горутины воспроизведения
+	stopCh chan struct{}
+	// loopCtx - контекст горутины воспроизведения, для её перезапуска
+	loopCtx context.Context
+	// loopDeadline - когда горутина воспроизведения должна проснуться, UnixNano по часам плеера
+	loopDeadline atomic.Int64
+	// watchdog - период сторожевого таймера, 0 - отключён
+	watchdog time.Duration
+	// wakeCh будит горутину воспроизведения для пересчёта таймера
+	wakeCh chan struct{}
+	// changed закрывается при смене песни, начале и остановке воспроизведения
+	changed chan struct{}
+	// done - закрывается при закрытии плеера
+	done chan struct{}
+
+	isPlaying bool
+	// paused - воспроизведение приостановлено через Pause, а не остановлено
+	paused    bool
+	startedAt time.Time
+	// inGap - текущая песня ожидает окончания паузы между песнями
+	inGap bool
+	// fading - предыдущая песня, которая затухает при наложении
+	fading *fadeOut
+	// envelope - нарастание и затухание звука
+	envelope envelope
+	// rampedOut - песня, затухание которой в конце уже началось
+	rampedOut *playerNode
+	// transition - переход между песнями, nil - наложение crossfade или встык
+	transition Transition
+	// begun - песня, для которой уже вызван Transition.Begin
+	begun *playerNode
+	// prepared - песня, для которой уже вызвана подготовка
+	prepared *playerNode
+	// section - повторяемый участок текущей песни
+	section *loopSection
+
+	// interruption - песня, прерванная вставкой
+	interruption *interruption
+
+	// edits - изменения активного плейлиста для Undo, от старых к новым
+	edits []edit
+	// undone - отменённые изменения для Redo
+	undone []edit
+
+	// closed - плеер закрыт методом Close
+	closed bool
+
+	// resume - сохранённые позиции песен, nil если режим отключён
+	resume map[string]time.Duration
+
+	// bookmarks - закладки по названию
+	bookmarks map[string]*bookmark
+
+	// history - история прослушанных и пропущенных песен
+	history []HistoryEntry
+	// audit - журнал аудита, кольцевой буфер с началом в auditStart
+	audit      []AuditEntry
+	auditStart int
+	// auditSize - размер журнала аудита, 0 - журнал отключён
+	auditSize int
+	// stats - статистика воспроизведения по песням
+	stats map[string]*SongStats
+	// sleep - активный таймер сна
+	sleep *sleepTimer
+	// afterCurrent - отложенная остановка в конце текущей песни, nil если не задана
+	afterCurrent *afterCurrent
+	// limits - ограничения размера плейлистов
+	limits limits
+	// cache - кэш скачанных песен, nil если не задан
+	cache *songCache
+	// resolving - узел, длительность которого узнаётся в отдельной горутине, см. resolveAheadLocked
+	resolving *playerNode
+	// quota - дневные лимиты времени прослушивания
+	quota quota
+	// cooldown - защита от повторов недавно сыгранных песен
+	cooldown cooldown
+	// schedules - запланированные запуски по ID
+	schedules      map[ScheduleID]*schedule
+	lastScheduleID ScheduleID
+
+	// counters - счётчики для метрик
+	counters counters
+
+	// hooks - обработчики окончания любой песни
+	hooks []SongHook
+	// ratingHooks - обработчики изменения оценок и избранного
+	ratingHooks []RatingHook
+	// queueHooks - обработчики добавления песен пользователями
+	queueHooks []QueueHook
+
+	// skipVotes - сколько голосов нужно для пропуска песни, 0 - не задано
+	skipVotes int
+	// skipFraction - какая доля слушателей должна проголосовать за пропуск, 0 - не задано
+	skipFraction float64
+	// listeners - зарегистрированные слушатели
+	listeners map[string]bool
+	// votes - голоса за пропуск песни votesFor
+	votes    map[string]bool
+	votesFor *playerNode
+	// voteHooks - обработчики голосов за пропуск
+	voteHooks []VoteHook
+	// upNext - очередь Enqueue
+	upNext list.List[Song]
+	// clock - источник времени воспроизведения
+	clock Clock
+	// locale - язык длительностей словами в NowPlayingString
+	locale Locale
+	// subscribers - подписки Subscribe
+	subscribers []*Subscription
+	// droppedEvents - события, отброшенные завершёнными подписками
+	droppedEvents int
+	// staging - промежуточная очередь AddSong
+	staging *staging
+	// hookQueue - очередь вызова обработчиков вне блокировки
+	hookQueue hookQueue
 }
 
 // Проверяем, что реализация удовлетворяет интерфейсу
@@ -66,142 +358,410 @@ var _ Player = &playerImpl{}
 
 // NewPlayer - конструктор для плеера.
 func NewPlayer(songs ...Song) (*playerImpl, error) {
-	pl := &playerImpl{
-		pauseCh: make(chan struct{}),
-		stopCh:  make(chan struct{}),
+	return New(WithSongs(songs...))
+}
+
+// NewSong - конструктор для Song.
+func NewSong(name string, d time.Duration) (Song, error) {
+	song := Song{Name: name, Duration: d}
+	if err := validateSong(song); err != nil {
+		return Song{}, err
 	}
 
-	for i, s := range songs {
-		if err := pl.AddSong(context.Background(), s); err != nil {
-			return nil, fmt.Errorf("add songs[%d] song: %v", i, err)
-		}
+	return song, nil
+}
+
+// NewStream - конструктор для потока с адресом url.
+func NewStream(name, url string) (Song, error) {
+	song := Song{Name: name, URL: url}
+	if err := validateSong(song); err != nil {
+		return Song{}, err
 	}
 
-	return pl, nil
+	return song, nil
 }
 
-// NewSong - конструктор для Song.
-func NewSong(name string, d time.Duration) (Song, error) {
-	if name == "" {
-		return Song{}, errors.New("song name is empty")
+func (p *playerImpl) Play(ctx context.Context) error {
+	if err := p.lock(ctx); err != nil {
+		return err
 	}
+	defer p.mu.Unlock()
 
-	if d < time.Second {
-		return Song{}, errors.New("song duration is less than 1 sec")
+	if p.closed {
+		return ErrClosed
 	}
 
-	return Song{Name: name, Duration: d}, nil
+	p.auditLocked(ctx, AuditEntry{Op: AuditPlay, Song: p.currentSongLocked()})
+	return p.playLocked(ctx)
 }
 
-func (p *playerImpl) Play(ctx context.Context) error {
+func (p *playerImpl) playLocked(ctx context.Context) error {
 	// плейлист пустой, нечего играть
 	// уже воспроизводится песня
 	if p.current == nil || p.isPlaying {
 		return nil
 	}
 
-	if p.playedTime > p.current.song.Duration {
-		return p.Next(ctx)
+	if p.quotaDueLocked() && p.untilQuotaLocked() <= 0 {
+		return ErrQuotaExceeded
 	}
 
+	// длительность узнаётся заново, пока песня играет как поток
+	p.current.resolveErr = nil
+
+	if !p.current.song.IsStream() && p.playedTime > p.current.song.End() {
+		return p.nextLocked(ctx)
+	}
+
+	// песня запускается с начала - пропускаем тишину
+	if p.playedTime == 0 {
+		p.playedTime = p.current.song.LeadIn
+	}
+
+	// та же песня ещё затухает после паузы
+	if p.fading != nil && songKey(p.fading.song) == songKey(*p.current.song) {
+		p.stopFadeLocked(ctx)
+	}
+
+	p.sequenceLocked()
+	p.applyGainLocked(ctx, *p.current.song)
+	if err := p.output.Start(ctx, p.cachedLocked(*p.current.song), p.playedTime); err != nil {
+		p.playbackErrorLocked(ctx, *p.current.song, StageStart, err)
+		return fmt.Errorf("start song: %v", err)
+	}
+	p.failedStarts = 0
+	p.rampedOut = nil
+	p.fadeInLocked(ctx, *p.current.song)
+	p.nowPlayingLocked(ctx)
+
+	stop := make(chan struct{})
+	p.stopCh = stop
 	p.isPlaying = true
-	startPlaying := time.Now()
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				p.isPlaying = false
-				p.playedTime = 0
-				return
-			case <-p.stopCh:
-				p.isPlaying = false
+	p.paused = false
+	p.quotaStartLocked()
+	p.startedAt = p.now()
+	p.notifyLocked()
+
+	p.logger.InfoContext(ctx, "playback started", songAttr(*p.current.song), slog.Duration("offset", p.playedTime))
+
+	p.loopCtx = ctx
+	p.beat(p.untilStepLocked())
+	go p.loop(ctx, stop)
+	return nil
+}
+
+// loop - горутина воспроизведения, работает до закрытия stop.
+func (p *playerImpl) loop(ctx context.Context, stop chan struct{}) {
+	for {
+		p.mu.RLock()
+		// воспроизведение остановили, а текущая песня могла быть удалена
+		if p.stopCh != stop {
+			p.mu.RUnlock()
+			return
+		}
+		wait := p.untilStepLocked()
+		p.beat(wait)
+		p.mu.RUnlock()
+
+		due, cancel := p.after(wait)
+		select {
+		case <-stop:
+			cancel()
+			return
+
+		case <-p.wakeCh:
+			cancel()
+
+		case <-ctx.Done():
+			cancel()
+
+			p.mu.Lock()
+			if p.stopCh == stop {
+				p.haltLocked(context.Background())
 				p.playedTime = 0
+				p.logger.Info("playback stopped", songAttr(*p.current.song), slog.Any("reason", ctx.Err()))
+			}
+			p.mu.Unlock()
+			return
+
+		case <-due:
+			p.mu.Lock()
+			// воспроизведение остановили, пока ждали блокировку
+			if p.stopCh != stop {
+				p.mu.Unlock()
 				return
+			}
+			// шаг уже выполнил SimulatePlayback
+			if _, fake := p.clock.(*FakeClock); fake && p.untilStepLocked() > 0 {
+				p.mu.Unlock()
+				continue
+			}
 
-			case <-p.pauseCh:
-				p.isPlaying = false
-				p.playedTime += time.Since(startPlaying)
+			playing := p.stepLocked(ctx)
+			p.mu.Unlock()
+
+			if !playing {
 				return
+			}
+		}
+	}
+}
 
-			case <-time.After(p.current.song.Duration - p.playedTime):
-				p.playedTime = 0
+// rescheduleLocked - будит горутину воспроизведения,
+// чтобы она пересчитала время до следующего шага.
+// Вызывается под блокировкой.
+func (p *playerImpl) rescheduleLocked() {
+	select {
+	case p.wakeCh <- struct{}{}:
+	default:
+	}
+}
 
-				// когда достигли конца списка
-				// делаем текущую песню первой
-				// и останавливаем воспроизведение
-				if p.current.next == nil {
-					p.isPlaying = false
-					p.current = p.head
-					return
-				}
+// haltLocked - останавливает горутину воспроизведения и бэкенд.
+// Вызывается под блокировкой.
+func (p *playerImpl) haltLocked(ctx context.Context) {
+	if p.stopCh != nil {
+		close(p.stopCh)
+		p.stopCh = nil
+	}
 
-				p.current = p.current.next
-			}
+	p.stopFadeLocked(ctx)
+	if p.isPlaying && !p.inGap {
+		song := *p.current.song
+		if p.canFadeOutLocked() && p.rampedOut != p.current {
+			// песня затухает и останавливается позже
+			p.rampLocked(ctx, song, 1, 0, p.envelope.out)
+			p.fadeOutLocked(ctx, song, p.envelope.out)
+		} else {
+			p.stopOutputLocked(ctx, song)
 		}
-	}()
-	return nil
+	}
+
+	p.quotaStopLocked()
+	p.inGap = false
+	p.isPlaying = false
+	p.notifyLocked()
 }
 
-func (p *playerImpl) Pause(_ context.Context) error {
-	p.mu.Lock()
-	defer p.mu.Unlock()
+// pauseLocked - приостанавливает воспроизведение, сохраняя позицию.
+// Вызывается под блокировкой.
+func (p *playerImpl) pauseLocked(ctx context.Context) {
 	if !p.isPlaying {
-		return nil
+		return
 	}
 
-	p.pauseCh <- struct{}{}
-	return nil
+	p.playedTime = p.elapsedLocked()
+	p.haltLocked(ctx)
+	p.paused = true
+	p.saveResumePositionLocked(*p.current.song, p.playedTime)
+	p.logger.InfoContext(ctx, "playback paused", songAttr(*p.current.song), slog.Duration("position", p.playedTime))
+}
+
+// moveToLocked - делает песню текущей и выставляет позицию,
+// с которой она начнёт играть.
+// Вызывается под блокировкой.
+func (p *playerImpl) moveToLocked(node *playerNode) {
+	p.transitionedLocked(p.current, node)
+	p.current = node
+	p.paused = false
+	p.rampedOut = nil
+	p.begun = nil
+	p.scrobbled = nil
+	p.milestones.node = nil
+	p.notifyLocked()
+	if node == nil {
+		p.playedTime = 0
+		return
+	}
+	p.playedTime = max(p.resumePositionLocked(*node.song), node.song.LeadIn)
+
+	// возвращаемся к прерванной песне
+	if in := p.interruption; in != nil && node == in.node {
+		p.playedTime = in.position
+		p.interruption = nil
+	}
 }
 
-func (p *playerImpl) AddSong(_ context.Context, song Song) error {
-	node := &playerNode{song: &song}
+// elapsedLocked - возвращает позицию воспроизведения текущей песни.
+// Вызывается под блокировкой.
+func (p *playerImpl) elapsedLocked() time.Duration {
+	if !p.isPlaying {
+		return p.playedTime
+	}
+
+	// во время паузы между песнями startedAt находится в будущем
+	if d := p.since(p.startedAt); d > 0 {
+		return p.playedTime + d
+	}
+
+	return p.playedTime
+}
+
+// skipLocked - фиксирует пропуск текущей песни, если она начинала играть.
+// Вызывается под блокировкой.
+func (p *playerImpl) skipLocked() {
+	if !p.isPlaying && p.playedTime == 0 {
+		return
+	}
 
-	p.mu.Lock()
+	p.playedTime = p.elapsedLocked()
+	p.finishLocked(false)
+}
+
+// finishLocked - вызывается, когда текущая песня доиграла или была пропущена.
+// Вызывается под блокировкой.
+func (p *playerImpl) finishLocked(completed bool) {
+	now := p.now()
+	p.logger.Info("song finished", songAttr(*p.current.song),
+		slog.Duration("played", p.playedTime), slog.Bool("completed", completed))
+
+	entry := HistoryEntry{
+		Song:       *p.current.song,
+		FinishedAt: now,
+		Played:     p.playedTime,
+		Completed:  completed,
+	}
+	p.recordHistoryLocked(entry)
+	p.persistHistoryLocked(entry)
+	p.publishLocked(EventSongFinished, entry)
+	p.recordStatsLocked(*p.current.song, p.playedTime, completed, now)
+	p.cooldownFinishLocked(*p.current.song, now)
+	p.counters.record(p.playedTime, completed)
+
+	if completed {
+		p.saveResumePositionLocked(*p.current.song, 0)
+	} else {
+		p.saveResumePositionLocked(*p.current.song, p.playedTime)
+	}
+	p.runHooksLocked(p.current, completed)
+}
+
+func (p *playerImpl) Pause(ctx context.Context) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
 	defer p.mu.Unlock()
 
-	if p.head == nil {
-		p.head, p.tail, p.current = node, node, node
-		return nil
+	if p.closed {
+		return ErrClosed
 	}
 
-	tail := p.tail
-	tail.next = node
-	node.prev = tail
+	if err := p.requireStateLocked("pause", StatePlaying, StateTransitioning, StatePaused); err != nil {
+		return err
+	}
 
-	p.tail = node
+	p.auditLocked(ctx, AuditEntry{Op: AuditPause, Song: p.currentSongLocked()})
+	p.pauseLocked(ctx)
 	return nil
 }
 
+func (p *playerImpl) AddSong(ctx context.Context, song Song) (SongID, error) {
+	// одновременные добавления проходят через промежуточную очередь
+	// и применяются пачкой за один захват блокировки
+	req, err := p.stageAdd(ctx, song)
+	if err != nil {
+		return 0, err
+	}
+
+	p.logger.DebugContext(ctx, "song added", songAttr(song), slog.String("playlist", req.playlist))
+	return req.id, nil
+}
+
 func (p *playerImpl) Next(ctx context.Context) error {
-	p.mu.Lock()
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
 	defer p.mu.Unlock()
 
-	p.isPlaying = false
-	p.playedTime = 0
+	if p.closed {
+		return ErrClosed
+	}
+
+	p.auditLocked(ctx, AuditEntry{Op: AuditNext, Song: p.currentSongLocked()})
+	if p.current != nil && p.nextCueLocked(ctx) {
+		return p.playLocked(ctx)
+	}
 
-	p.stopCh <- struct{}{}
-	p.current = p.current.next
+	return p.nextLocked(ctx)
+}
+
+func (p *playerImpl) nextLocked(ctx context.Context) error {
 	if p.current == nil {
-		p.current = p.tail
+		return nil
+	}
+
+	next := p.afterLocked(p.current)
+	if queued := p.dequeueLocked(next); queued != nil {
+		next = queued
+	} else if next == nil {
+		switch p.edge {
+		case EdgeNoop:
+			return nil
+		case EdgeStop:
+			// как при окончании плейлиста
+			p.stopAtEdgeLocked(ctx, p.first())
+			return nil
+		case EdgeWrap:
+			next = p.first()
+		default:
+			next = p.last()
+		}
 	}
 
-	return p.Play(ctx)
+	p.skipLocked()
+	p.haltLocked(ctx)
+	p.section = nil
+	p.moveToLocked(next)
+
+	return p.playLocked(ctx)
 }
 
 func (p *playerImpl) Prev(ctx context.Context) error {
-	p.mu.Lock()
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
 	defer p.mu.Unlock()
 
-	p.isPlaying = false
-	p.playedTime = 0
+	if p.closed {
+		return ErrClosed
+	}
 
-	p.stopCh <- struct{}{}
-	p.current = p.current.prev
-	// если нет предыдущего элемента
-	// начинаем воспроизведение с начала.
+	p.auditLocked(ctx, AuditEntry{Op: AuditPrev, Song: p.currentSongLocked()})
 	if p.current == nil {
-		p.current = p.head
+		return nil
 	}
 
-	return p.Play(ctx)
+	if p.prevCueLocked(ctx) {
+		return p.playLocked(ctx)
+	}
+
+	// песня играет достаточно долго - начинаем её сначала
+	if p.prevRestart > 0 && p.elapsedLocked()-p.current.song.LeadIn > p.prevRestart {
+		p.seekLocked(ctx, p.current.song.LeadIn)
+		return p.playLocked(ctx)
+	}
+
+	prev := p.current.prev()
+	if prev == nil {
+		switch p.edge {
+		case EdgeNoop:
+			return nil
+		case EdgeStop:
+			p.stopAtEdgeLocked(ctx, p.first())
+			return nil
+		case EdgeWrap:
+			prev = p.last()
+		default:
+			// если нет предыдущего элемента
+			// начинаем воспроизведение с начала.
+			prev = p.first()
+		}
+	}
+
+	p.skipLocked()
+	p.haltLocked(ctx)
+	p.section = nil
+	p.moveToLocked(prev)
+
+	return p.playLocked(ctx)
 }
@@ -3,7 +3,9 @@ package player
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -14,8 +16,8 @@ func TestNewPlayer(t *testing.T) {
 	t.Run("empty playlist", func(t *testing.T) {
 		pl, _ := NewPlayer()
 
-		td.CmpNil(t, pl.head, "head is empty")
-		td.CmpNil(t, pl.tail, "tail is empty")
+		td.CmpNil(t, pl.first(), "head is empty")
+		td.CmpNil(t, pl.last(), "tail is empty")
 		td.CmpNil(t, pl.current, "no current song")
 	})
 
@@ -26,21 +28,48 @@ func TestNewPlayer(t *testing.T) {
 
 		pl, _ := NewPlayer(sg, ap, shuff)
 
-		td.Cmp(t, *pl.head.song, sg, "сектор газа - первая песня")
-		td.Cmp(t, *pl.tail.song, shuff, "шуфутинский - в конце")
+		td.Cmp(t, *pl.first().song, sg, "сектор газа - первая песня")
+		td.Cmp(t, *pl.last().song, shuff, "шуфутинский - в конце")
 
 		curr := pl.current
 		td.Cmp(t, *curr.song, sg, "сектор газа - первый")
-		td.CmpNil(t, curr.prev, "у первого элемента нет ссылки на пред элемент")
+		td.CmpNil(t, curr.prev(), "у первого элемента нет ссылки на пред элемент")
 
-		curr = curr.next
+		curr = curr.next()
 		td.Cmp(t, *curr.song, ap, "пушной - второй")
-		td.Cmp(t, *curr.prev.song, sg, "предыдущий(0) - сектор газа")
+		td.Cmp(t, *curr.prev().song, sg, "предыдущий(0) - сектор газа")
 
-		curr = curr.next
+		curr = curr.next()
 		td.Cmp(t, *curr.song, shuff, "шуф - третий")
-		td.Cmp(t, *curr.prev.song, ap, "предыдущий - пушной")
-		td.CmpNil(t, curr.next, "3(последний) элемент не имеет ссылки на следующий")
+		td.Cmp(t, *curr.prev().song, ap, "предыдущий - пушной")
+		td.CmpNil(t, curr.next(), "3(последний) элемент не имеет ссылки на следующий")
+	})
+}
+
+func TestNew_OptionOrder(t *testing.T) {
+	strict := WithValidator(ValidationPolicy{MaxDuration: 2 * time.Minute})
+	long := Song{Name: "long", Duration: time.Hour}
+
+	t.Run("validator", func(t *testing.T) {
+		_, err := New(strict, WithSongs(long))
+		td.CmpError(t, err, "проверка перед песнями")
+		_, err = New(WithSongs(long), strict)
+		td.CmpError(t, err, "проверка после песен")
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		_, err := New(WithMaxPlaylistSize(2), WithSongs(minuteSongs("a", "b", "c")...))
+		td.CmpContains(t, err, ErrPlaylistFull.Error(), "ограничение перед песнями")
+		_, err = New(WithSongs(minuteSongs("a", "b", "c")...), WithMaxPlaylistSize(2))
+		td.CmpContains(t, err, ErrPlaylistFull.Error(), "ограничение после песен")
+	})
+
+	t.Run("library", func(t *testing.T) {
+		lib := NewLibrary()
+		pl, err := New(WithSongs(minuteSong("a")), WithLibrary(lib))
+		td.CmpNoError(t, err)
+		td.Cmp(t, pl.Library(), td.Shallow(lib))
+		td.Cmp(t, lib.Len(), 1, "песни попадают в заданную библиотеку")
 	})
 }
 
@@ -50,16 +79,16 @@ func TestPlayerImpl_AddSong(t *testing.T) {
 		pl, _ := NewPlayer(song)
 
 		anotherSong, _ := NewSong("another song", time.Second)
-		_ = pl.AddSong(context.Background(), anotherSong)
-		td.Cmp(t, *pl.tail.song, anotherSong, "новая песня должна быть добавлена в конец")
-		td.Cmp(t, *pl.tail.prev.song, song, "предыдущая песня должна быть 'some song'")
-		td.Cmp(t, *pl.head.song, song, "head должен указывать на первую песню")
+		_, _ = pl.AddSong(context.Background(), anotherSong)
+		td.Cmp(t, *pl.last().song, anotherSong, "новая песня должна быть добавлена в конец")
+		td.Cmp(t, *pl.last().prev().song, song, "предыдущая песня должна быть 'some song'")
+		td.Cmp(t, *pl.first().song, song, "head должен указывать на первую песню")
 
 		someSong, _ := NewSong("some another song", time.Second)
-		_ = pl.AddSong(context.Background(), someSong)
-		td.Cmp(t, *pl.tail.song, someSong, "новая песня должна быть добавлена в конец")
-		td.Cmp(t, *pl.tail.prev.song, anotherSong, "предыдущая песня должна быть 'another song'")
-		td.Cmp(t, *pl.head.song, song, "head должен указывать на первую песню")
+		_, _ = pl.AddSong(context.Background(), someSong)
+		td.Cmp(t, *pl.last().song, someSong, "новая песня должна быть добавлена в конец")
+		td.Cmp(t, *pl.last().prev().song, anotherSong, "предыдущая песня должна быть 'another song'")
+		td.Cmp(t, *pl.first().song, song, "head должен указывать на первую песню")
 	})
 
 	t.Run("concurrent", func(t *testing.T) {
@@ -70,7 +99,7 @@ func TestPlayerImpl_AddSong(t *testing.T) {
 		for i := 0; i < 100_000; i++ {
 			go func(el int) {
 				s, _ := NewSong(fmt.Sprintf("%d", el), time.Second)
-				_ = pl.AddSong(context.Background(), s)
+				_, _ = pl.AddSong(context.Background(), s)
 
 				wg.Done()
 			}(i)
@@ -79,9 +108,9 @@ func TestPlayerImpl_AddSong(t *testing.T) {
 
 		count := 1
 		curr := pl.current
-		for curr.next != nil {
+		for curr.next() != nil {
 			count++
-			curr = curr.next
+			curr = curr.next()
 		}
 
 		td.Cmp(t, count, 100_000)
@@ -97,7 +126,7 @@ func TestPlaying(t *testing.T) {
 
 	t.Run("should stop player", func(t *testing.T) {
 		for i := 0; i < 100; i++ {
-			pl.current = pl.head
+			pl.current = pl.first()
 			ctx, cancel := context.WithCancel(context.Background())
 
 			_ = pl.Play(ctx)
@@ -113,7 +142,7 @@ func TestPlaying(t *testing.T) {
 
 	t.Run("should pause player", func(t *testing.T) {
 		ctx := context.Background()
-		pl.current = pl.head
+		pl.current = pl.first()
 
 		_ = pl.Play(ctx)
 
@@ -149,7 +178,7 @@ func TestPlaying(t *testing.T) {
 		ap := Song{Name: "Александр Пушной - Почему я идиот?", Duration: 30 * time.Second}
 
 		ctx := context.Background()
-		nextPl, _ := NewPlayer(sg, ap)
+		nextPl, _ := New(WithValidator(ValidationPolicy{}), WithSongs(sg, ap))
 		_ = nextPl.Play(ctx)
 		time.Sleep(150 * time.Millisecond)
 		_ = nextPl.Pause(ctx)
@@ -164,9 +193,9 @@ func TestPlaying(t *testing.T) {
 		ap := Song{Name: "Александр Пушной - Почему я идиот?", Duration: 100 * time.Millisecond}
 
 		ctx := context.Background()
-		nextPl, _ := NewPlayer(sg, ap)
+		nextPl, _ := New(WithValidator(ValidationPolicy{}), WithSongs(sg, ap))
 
-		nextPl.current = nextPl.tail
+		nextPl.current = nextPl.last()
 		_ = nextPl.Play(ctx)
 		time.Sleep(150 * time.Millisecond)
 
@@ -174,3 +203,40 @@ func TestPlaying(t *testing.T) {
 		td.Cmp(t, nextPl.current.song, &sg, "текущая песня должна быть 'Сектор Газа - 30 лет'")
 	})
 }
+
+func BenchmarkPlayerImpl_AddSong(b *testing.B) {
+	ctx := context.Background()
+	pl, _ := NewPlayer()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = pl.AddSong(ctx, Song{Name: strconv.Itoa(i), Duration: time.Second})
+	}
+}
+
+func BenchmarkPlayerImpl_AddSongParallel(b *testing.B) {
+	ctx := context.Background()
+	pl, _ := NewPlayer()
+
+	var n atomic.Int64
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = pl.AddSong(ctx, Song{Name: strconv.FormatInt(n.Add(1), 10), Duration: time.Second})
+		}
+	})
+}
+
+func BenchmarkPlayerImpl_AddSongs(b *testing.B) {
+	ctx := context.Background()
+	pl, _ := NewPlayer()
+
+	songs := make([]Song, 100)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for j := range songs {
+			songs[j] = Song{Name: strconv.Itoa(i*len(songs) + j), Duration: time.Second}
+		}
+		_ = pl.AddSongs(ctx, songs...)
+	}
+}
@@ -0,0 +1,410 @@
+// Package playertest - управляемая подделка плеера для тестов приложений,
+// которые встраивают player.Player.
+//
+// Fake не запускает таймеров и горутин: время идёт только при вызове Advance,
+// поэтому тесты управляющей логики обходятся без time.Sleep.
+//
+//	fake := playertest.NewFake(song1, song2)
+//	app := NewApp(fake)
+//	app.HandleKey(ctx, "n")
+//	fake.Advance(30 * time.Second)
+//	if fake.Called("Next") != 1 { ... }
+package playertest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"player"
+)
+
+var _ player.Player = (*Fake)(nil)
+
+// Call - записанный вызов метода плеера.
+type Call struct {
+	// Method - имя метода, например "SetVolume"
+	Method string
+	// Args - аргументы вызова без ctx
+	Args []any
+}
+
+// fakeSong - песня в очереди подделки.
+type fakeSong struct {
+	id   player.SongID
+	song player.Song
+}
+
+// Fake - подделка player.Player с простой моделью воспроизведения.
+// Повторяет поведение плеера по умолчанию: Next и Prev запускают
+// воспроизведение, в конце плейлиста воспроизведение останавливается
+// и курсор возвращается на первую песню.
+type Fake struct {
+	mu sync.Mutex
+
+	songs    []fakeSong
+	current  int
+	position time.Duration
+	playing  bool
+	paused   bool
+	volume   int
+	muted    bool
+	lastID   player.SongID
+
+	// now - время подделки, идёт только в Advance
+	now time.Time
+
+	calls    []Call
+	errs     map[string][]error
+	statuses []player.Status
+}
+
+// NewFake - конструктор для Fake с песнями в очереди.
+// Песни не проверяются, чтобы в тестах можно было использовать любые.
+func NewFake(songs ...player.Song) *Fake {
+	f := &Fake{
+		volume: player.MaxVolume,
+		now:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		errs:   make(map[string][]error),
+	}
+	for _, song := range songs {
+		f.appendLocked(song)
+	}
+	f.rewindLocked()
+
+	return f
+}
+
+func (f *Fake) Play(_ context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.recordLocked("Play"); err != nil {
+		return err
+	}
+
+	f.playing = len(f.songs) > 0
+	f.paused = false
+	return nil
+}
+
+func (f *Fake) Pause(_ context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.recordLocked("Pause"); err != nil {
+		return err
+	}
+
+	if !f.playing && !f.paused {
+		return player.StateError{Op: "pause", State: player.StateStopped}
+	}
+
+	f.playing, f.paused = false, true
+	return nil
+}
+
+func (f *Fake) AddSong(_ context.Context, song player.Song) (player.SongID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.recordLocked("AddSong", song); err != nil {
+		return 0, err
+	}
+
+	if err := player.DefaultValidationPolicy.Validate(song); err != nil {
+		return 0, err
+	}
+
+	id := f.appendLocked(song)
+	if len(f.songs) == 1 {
+		f.rewindLocked()
+	}
+
+	return id, nil
+}
+
+func (f *Fake) Next(_ context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.recordLocked("Next"); err != nil {
+		return err
+	}
+
+	if len(f.songs) == 0 {
+		return nil
+	}
+
+	// на последней песне плеер начинает её сначала
+	f.moveLocked(min(f.current+1, len(f.songs)-1))
+	f.playing = true
+	return nil
+}
+
+func (f *Fake) Prev(_ context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.recordLocked("Prev"); err != nil {
+		return err
+	}
+
+	if len(f.songs) == 0 {
+		return nil
+	}
+
+	f.moveLocked(max(f.current-1, 0))
+	f.playing = true
+	return nil
+}
+
+func (f *Fake) SetVolume(_ context.Context, volume int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.recordLocked("SetVolume", volume); err != nil {
+		return err
+	}
+
+	if volume < 0 || volume > player.MaxVolume {
+		return fmt.Errorf("volume %d out of range [0, %d]", volume, player.MaxVolume)
+	}
+
+	f.volume = volume
+	return nil
+}
+
+func (f *Fake) Volume(_ context.Context) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, Call{Method: "Volume"})
+	return f.volume
+}
+
+func (f *Fake) Mute(_ context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.recordLocked("Mute"); err != nil {
+		return err
+	}
+
+	f.muted = true
+	return nil
+}
+
+func (f *Fake) Unmute(_ context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.recordLocked("Unmute"); err != nil {
+		return err
+	}
+
+	f.muted = false
+	return nil
+}
+
+// Status - возвращает следующее состояние из ScriptStatus,
+// а когда они закончатся - состояние модели.
+func (f *Fake) Status(_ context.Context) player.Status {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, Call{Method: "Status"})
+	if len(f.statuses) > 0 {
+		st := f.statuses[0]
+		f.statuses = f.statuses[1:]
+		return st
+	}
+
+	return f.statusLocked()
+}
+
+// State - возвращает состояние модели: Stopped, Playing или Paused.
+func (f *Fake) State(_ context.Context) player.State {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, Call{Method: "State"})
+	switch {
+	case f.playing:
+		return player.StatePlaying
+	case f.paused:
+		return player.StatePaused
+	default:
+		return player.StateStopped
+	}
+}
+
+// ScriptStatus - задаёт состояния, которые вернут следующие вызовы Status.
+// Состояния модели они не меняют.
+func (f *Fake) ScriptStatus(statuses ...player.Status) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.statuses = append(f.statuses, statuses...)
+}
+
+// FailNext - следующий вызов метода method вернёт err, не изменив состояние.
+// Повторные вызовы FailNext задают ошибки для последующих вызовов по порядку.
+func (f *Fake) FailNext(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.errs[method] = append(f.errs[method], err)
+}
+
+// Advance - сдвигает время подделки на d. Если воспроизведение идёт,
+// песни переключаются так же, как в плеере: по окончании песни начинается
+// следующая, после последней воспроизведение останавливается.
+func (f *Fake) Advance(d time.Duration) {
+	if d < 0 {
+		panic("playertest: negative advance")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+	for f.playing && d > 0 {
+		song := f.songs[f.current].song
+		if song.IsStream() {
+			f.position += d
+			return
+		}
+
+		left := song.End() - f.position
+		if d < left {
+			f.position += d
+			return
+		}
+
+		d -= left
+		if f.current == len(f.songs)-1 {
+			f.playing = false
+			f.rewindLocked()
+			return
+		}
+		f.moveLocked(f.current + 1)
+	}
+}
+
+// Now - возвращает время подделки.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+// Songs - возвращает песни в очереди.
+func (f *Fake) Songs() []player.Song {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	songs := make([]player.Song, len(f.songs))
+	for i, s := range f.songs {
+		songs[i] = s.song
+	}
+	return songs
+}
+
+// Calls - возвращает все записанные вызовы по порядку.
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Call(nil), f.calls...)
+}
+
+// Called - возвращает, сколько раз вызывался метод method.
+func (f *Fake) Called(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := 0
+	for _, c := range f.calls {
+		if c.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+// ResetCalls - забывает записанные вызовы.
+func (f *Fake) ResetCalls() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = nil
+}
+
+// recordLocked - записывает вызов и возвращает ошибку из FailNext, если она задана.
+// Вызывается под блокировкой.
+func (f *Fake) recordLocked(method string, args ...any) error {
+	f.calls = append(f.calls, Call{Method: method, Args: args})
+
+	errs := f.errs[method]
+	if len(errs) == 0 {
+		return nil
+	}
+
+	if len(errs) == 1 {
+		delete(f.errs, method)
+	} else {
+		f.errs[method] = errs[1:]
+	}
+	return errs[0]
+}
+
+// appendLocked - добавляет песню в конец очереди.
+// Вызывается под блокировкой.
+func (f *Fake) appendLocked(song player.Song) player.SongID {
+	f.lastID++
+	f.songs = append(f.songs, fakeSong{id: f.lastID, song: song})
+	return f.lastID
+}
+
+// moveLocked - делает текущей песню i и начинает её сначала.
+// Вызывается под блокировкой.
+func (f *Fake) moveLocked(i int) {
+	f.current = i
+	f.position = f.songs[i].song.LeadIn
+	f.paused = false
+}
+
+// rewindLocked - возвращает курсор на первую песню.
+// Вызывается под блокировкой.
+func (f *Fake) rewindLocked() {
+	f.current = 0
+	f.position = 0
+	f.paused = false
+	if len(f.songs) > 0 {
+		f.position = f.songs[0].song.LeadIn
+	}
+}
+
+// statusLocked - возвращает состояние модели.
+// Вызывается под блокировкой.
+func (f *Fake) statusLocked() player.Status {
+	st := player.Status{
+		Playlist: player.DefaultPlaylist,
+		Playing:  f.playing,
+		Volume:   f.volume,
+		Muted:    f.muted,
+	}
+
+	if len(f.songs) > 0 {
+		cur := f.songs[f.current]
+		song := cur.song
+		st.Song = &song
+		st.SongID = cur.id
+		st.Position = f.position
+	}
+
+	return st
+}
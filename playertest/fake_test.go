@@ -0,0 +1,117 @@
+package playertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+
+	"player"
+)
+
+func TestFake(t *testing.T) {
+	ctx := context.Background()
+
+	songs := []player.Song{
+		{Name: "a", Duration: time.Minute, LeadIn: time.Second},
+		{Name: "b", Duration: 2 * time.Minute},
+	}
+
+	t.Run("модель воспроизведения", func(t *testing.T) {
+		f := NewFake(songs...)
+
+		st := f.Status(ctx)
+		td.Cmp(t, st.Song.Name, "a", "текущая песня")
+		td.Cmp(t, st.Position, time.Second, "начало после тишины")
+		td.CmpFalse(t, st.Playing, "не воспроизводится")
+
+		td.CmpNoError(t, f.Play(ctx))
+		f.Advance(30 * time.Second)
+		td.Cmp(t, f.Status(ctx).Position, 31*time.Second, "время идёт при воспроизведении")
+
+		f.Advance(time.Minute)
+		st = f.Status(ctx)
+		td.Cmp(t, st.Song.Name, "b", "следующая песня по окончании")
+		td.Cmp(t, st.Position, 31*time.Second, "остаток времени перенесён")
+
+		td.CmpNoError(t, f.Pause(ctx))
+		td.Cmp(t, f.State(ctx), player.StatePaused)
+		f.Advance(time.Hour)
+		td.Cmp(t, f.Status(ctx).Position, 31*time.Second, "на паузе время стоит")
+
+		td.CmpNoError(t, f.Play(ctx))
+		f.Advance(time.Hour)
+		st = f.Status(ctx)
+		td.CmpFalse(t, st.Playing, "после последней песни воспроизведение остановлено")
+		td.Cmp(t, f.State(ctx), player.StateStopped)
+		td.CmpTrue(t, errors.Is(f.Pause(ctx), player.ErrInvalidState), "пауза без воспроизведения")
+		td.Cmp(t, st.Song.Name, "a", "курсор на первой песне")
+
+		td.Cmp(t, f.Now().Sub(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)), 2*time.Hour+90*time.Second, "время подделки")
+	})
+
+	t.Run("навигация и громкость", func(t *testing.T) {
+		f := NewFake(songs...)
+
+		td.CmpNoError(t, f.Next(ctx))
+		td.CmpNoError(t, f.Next(ctx))
+		st := f.Status(ctx)
+		td.Cmp(t, st.Song.Name, "b", "на последней песне Next начинает её сначала")
+		td.CmpTrue(t, st.Playing, "Next запускает воспроизведение")
+
+		td.CmpNoError(t, f.Prev(ctx))
+		td.CmpNoError(t, f.Prev(ctx))
+		td.Cmp(t, f.Status(ctx).Song.Name, "a")
+
+		td.CmpString(t, f.SetVolume(ctx, 101), "volume 101 out of range [0, 100]")
+		td.CmpNoError(t, f.SetVolume(ctx, 40))
+		td.CmpNoError(t, f.Mute(ctx))
+		td.Cmp(t, f.Volume(ctx), 40)
+		td.CmpTrue(t, f.Status(ctx).Muted)
+		td.CmpNoError(t, f.Unmute(ctx))
+		td.CmpFalse(t, f.Status(ctx).Muted)
+
+		id, err := f.AddSong(ctx, player.Song{Name: "c", Duration: time.Minute})
+		td.CmpNoError(t, err)
+		td.Cmp(t, id, player.SongID(3), "ID по порядку")
+		_, err = f.AddSong(ctx, player.Song{Name: "short", Duration: time.Millisecond})
+		td.CmpTrue(t, errors.Is(err, player.ErrInvalidSong), "песня проверяется как в плеере")
+		td.Cmp(t, len(f.Songs()), 3)
+	})
+
+	t.Run("запись вызовов и ошибки", func(t *testing.T) {
+		f := NewFake(songs...)
+		boom := errors.New("boom")
+
+		f.FailNext("Play", boom)
+		td.Cmp(t, f.Play(ctx), boom, "заданная ошибка")
+		td.CmpFalse(t, f.Status(ctx).Playing, "состояние не изменилось")
+		td.CmpNoError(t, f.Play(ctx), "ошибка только один раз")
+
+		td.CmpNoError(t, f.SetVolume(ctx, 10))
+		td.Cmp(t, f.Called("Play"), 2)
+		td.Cmp(t, f.Calls(), []Call{
+			{Method: "Play", Args: nil},
+			{Method: "Status"},
+			{Method: "Play"},
+			{Method: "SetVolume", Args: []any{10}},
+		})
+
+		f.ResetCalls()
+		td.CmpEmpty(t, f.Calls())
+	})
+
+	t.Run("заданные состояния", func(t *testing.T) {
+		f := NewFake(songs...)
+		f.ScriptStatus(
+			player.Status{Playing: true, Position: time.Second},
+			player.Status{Playing: false, Position: 2 * time.Second},
+		)
+
+		td.Cmp(t, f.Status(ctx).Position, time.Second)
+		td.Cmp(t, f.Status(ctx).Position, 2*time.Second)
+		td.Cmp(t, f.Status(ctx).Song.Name, "a", "затем состояние модели")
+	})
+}
@@ -0,0 +1,231 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"player/list"
+)
+
+// DefaultPlaylist - название плейлиста, который создаётся вместе с плеером.
+const DefaultPlaylist = "default"
+
+var (
+	// ErrPlaylistNotFound - плейлист с таким названием не существует.
+	ErrPlaylistNotFound = errors.New("playlist not found")
+	// ErrPlaylistExists - плейлист с таким названием уже существует.
+	ErrPlaylistExists = errors.New("playlist already exists")
+	// ErrPlaylistActive - операция недоступна для активного плейлиста.
+	ErrPlaylistActive = errors.New("playlist is active")
+)
+
+// playlist - двусвязный список песен с курсором на текущую песню.
+type playlist struct {
+	// songs - узлы песен по порядку
+	songs list.List[*playerNode]
+
+	current *playerNode
+
+	playedTime time.Duration
+
+	// byID - узлы списка по ID песни
+	byID map[SongID]*playerNode
+	// total - суммарная длительность песен для WithMaxTotalDuration
+	total time.Duration
+}
+
+// newTrackNode - создаёт узел для трека библиотеки под новым ID.
+func newTrackNode(t *track, hook SongHook) *playerNode {
+	return &playerNode{id: newSongID(), song: t.song, track: t.id, hook: hook}
+}
+
+// appendTrack - добавляет трек библиотеки в конец списка под новым ID.
+func (pl *playlist) appendTrack(t *track, hook SongHook) *playerNode {
+	node := newTrackNode(t, hook)
+	pl.appendNode(node)
+	return node
+}
+
+// appendNode - добавляет узел в конец списка.
+func (pl *playlist) appendNode(node *playerNode) {
+	pl.index(node)
+	node.elem = pl.songs.PushBack(node)
+	if pl.current == nil {
+		pl.current = node
+	}
+}
+
+// index - учитывает узел в индексе списка.
+func (pl *playlist) index(node *playerNode) {
+	if pl.byID == nil {
+		pl.byID = make(map[SongID]*playerNode)
+	}
+
+	pl.byID[node.id] = node
+	node.counted = node.song.Duration
+	pl.total += node.counted
+}
+
+// first - первая песня списка, nil для пустого.
+func (pl *playlist) first() *playerNode {
+	return nodeOf(pl.songs.Front())
+}
+
+// last - последняя песня списка, nil для пустого.
+func (pl *playlist) last() *playerNode {
+	return nodeOf(pl.songs.Back())
+}
+
+// CreatePlaylist - создаёт новый пустой плейлист.
+func (p *playerImpl) CreatePlaylist(ctx context.Context, name string) error {
+	if name == "" {
+		return errors.New("playlist name is empty")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if p.hasPlaylistLocked(name) {
+		return ErrPlaylistExists
+	}
+
+	p.playlists[name] = &playlist{}
+	p.logger.InfoContext(ctx, "playlist created", slog.String("playlist", name))
+	return nil
+}
+
+// SwitchPlaylist - делает плейлист активным.
+// Воспроизведение приостанавливается, позиция в каждом плейлисте сохраняется.
+func (p *playerImpl) SwitchPlaylist(ctx context.Context, name string) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if err := p.switchPlaylistLocked(ctx, name); err != nil {
+		return err
+	}
+
+	p.auditLocked(ctx, AuditEntry{Op: AuditSwitchPlaylist, Song: p.currentSongLocked(), Detail: name})
+	return nil
+}
+
+// switchPlaylistLocked - делает плейлист активным.
+// Вызывается под блокировкой.
+func (p *playerImpl) switchPlaylistLocked(ctx context.Context, name string) error {
+	if name == p.active {
+		return nil
+	}
+
+	next, ok := p.playlists[name]
+	if !ok {
+		return ErrPlaylistNotFound
+	}
+
+	p.pauseLocked(ctx)
+	p.cancelInterruptionLocked()
+
+	prev := p.playlist
+	p.playlists[p.active] = &prev
+	delete(p.playlists, name)
+
+	p.playlist = *next
+	p.setActiveLocked(name)
+	p.section = nil
+	p.resetEditsLocked()
+	p.logger.InfoContext(ctx, "playlist switched", slog.String("playlist", name))
+	return nil
+}
+
+// DeletePlaylist - удаляет неактивный плейлист.
+func (p *playerImpl) DeletePlaylist(ctx context.Context, name string) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if name == p.active {
+		return ErrPlaylistActive
+	}
+
+	if _, ok := p.playlists[name]; !ok {
+		return ErrPlaylistNotFound
+	}
+
+	delete(p.playlists, name)
+	delete(p.smart, name)
+	p.logger.InfoContext(ctx, "playlist deleted", slog.String("playlist", name))
+	return nil
+}
+
+// AddSongTo - добавляет песню в конец указанного плейлиста и возвращает её ID.
+func (p *playerImpl) AddSongTo(ctx context.Context, name string, song Song) (SongID, error) {
+	if err := p.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return 0, ErrClosed
+	}
+
+	pl := &p.playlist
+	if name != p.active {
+		var ok bool
+		if pl, ok = p.playlists[name]; !ok {
+			return 0, ErrPlaylistNotFound
+		}
+	}
+
+	if err := p.admitLocked(ctx, name, song); err != nil {
+		return 0, err
+	}
+
+	node := p.newNode(song, nil)
+	pl.appendNode(node)
+	if name == p.active {
+		p.recordEditLocked(p.addEdit(node))
+		p.rescheduleLocked()
+	}
+	p.auditLocked(ctx, AuditEntry{Op: AuditAdd, Song: &song, Detail: name})
+	p.logger.DebugContext(ctx, "song added", songAttr(song), slog.String("playlist", name))
+	return node.id, nil
+}
+
+// contains - проверяет, что узел принадлежит списку.
+func (pl *playlist) contains(node *playerNode) bool {
+	return node != nil && pl.byID[node.id] == node
+}
+
+// newNode - добавляет песню в библиотеку и создаёт для неё узел под новым ID.
+// Блокировка плеера не нужна.
+func (p *playerImpl) newNode(song Song, hook SongHook) *playerNode {
+	return newTrackNode(p.library.add(song, p.now()), hook)
+}
+
+// hasPlaylistLocked - проверяет существование плейлиста.
+// Вызывается под блокировкой.
+func (p *playerImpl) hasPlaylistLocked(name string) bool {
+	if name == p.active {
+		return true
+	}
+
+	_, ok := p.playlists[name]
+	return ok
+}
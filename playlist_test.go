@@ -0,0 +1,65 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Playlists(t *testing.T) {
+	ctx := context.Background()
+	sg := Song{Name: "Сектор Газа - 30 лет", Duration: 30 * time.Second}
+	ap := Song{Name: "Александр Пушной - Почему я идиот?", Duration: 30 * time.Second}
+	shuff := Song{Name: "Михаил Шуфутинский - 3 сентября", Duration: 30 * time.Second}
+
+	t.Run("create and delete", func(t *testing.T) {
+		pl, _ := NewPlayer()
+
+		td.CmpNoError(t, pl.CreatePlaylist(ctx, "rock"))
+		td.Cmp(t, pl.CreatePlaylist(ctx, "rock"), ErrPlaylistExists)
+		td.Cmp(t, pl.CreatePlaylist(ctx, DefaultPlaylist), ErrPlaylistExists)
+		td.CmpError(t, pl.CreatePlaylist(ctx, ""), "пустое название")
+
+		td.Cmp(t, pl.DeletePlaylist(ctx, DefaultPlaylist), ErrPlaylistActive)
+		td.CmpNoError(t, pl.DeletePlaylist(ctx, "rock"))
+		td.Cmp(t, pl.DeletePlaylist(ctx, "rock"), ErrPlaylistNotFound)
+	})
+
+	t.Run("add song to playlist", func(t *testing.T) {
+		pl, _ := NewPlayer(sg)
+		_ = pl.CreatePlaylist(ctx, "chanson")
+
+		_, err := pl.AddSongTo(ctx, "chanson", shuff)
+		td.CmpNoError(t, err)
+		id, err := pl.AddSongTo(ctx, DefaultPlaylist, ap)
+		td.CmpNoError(t, err)
+		td.Cmp(t, pl.last().id, id)
+		_, err = pl.AddSongTo(ctx, "unknown", ap)
+		td.Cmp(t, err, ErrPlaylistNotFound)
+
+		td.Cmp(t, *pl.last().song, ap, "в активный плейлист добавлен пушной")
+		td.Cmp(t, *pl.playlists["chanson"].first().song, shuff, "в chanson добавлен шуфутинский")
+	})
+
+	t.Run("switch keeps cursors", func(t *testing.T) {
+		pl, _ := NewPlayer(sg, ap)
+		_ = pl.CreatePlaylist(ctx, "chanson")
+		_, _ = pl.AddSongTo(ctx, "chanson", shuff)
+
+		pl.current = pl.last()
+		_ = pl.Play(ctx)
+		time.Sleep(10 * time.Millisecond)
+
+		td.Cmp(t, pl.SwitchPlaylist(ctx, "unknown"), ErrPlaylistNotFound)
+		td.CmpNoError(t, pl.SwitchPlaylist(ctx, "chanson"))
+		td.CmpFalse(t, pl.isPlaying, "воспроизведение приостановлено")
+		td.Cmp(t, pl.active, "chanson")
+		td.Cmp(t, *pl.current.song, shuff, "текущая песня из нового плейлиста")
+
+		td.CmpNoError(t, pl.SwitchPlaylist(ctx, DefaultPlaylist))
+		td.Cmp(t, *pl.current.song, ap, "курсор старого плейлиста сохранён")
+		td.CmpGte(t, pl.playedTime, 10*time.Millisecond, "позиция сохранена")
+	})
+}
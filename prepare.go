@@ -0,0 +1,52 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Preparer - бэкенд, которому нужно заранее подготовить песню
+// (буферизовать, декодировать), чтобы переход между песнями был бесшовным.
+type Preparer interface {
+	// Prepare - подготавливает песню, которая зазвучит следующей
+	Prepare(ctx context.Context, song Song) error
+}
+
+// WithPreparer - подготавливает следующую песню за lead до перехода к ней.
+// Подготовка выполняется в отдельной горутине, ошибки логируются.
+func WithPreparer(prep Preparer, lead time.Duration) Option {
+	return func(p *playerImpl) error {
+		if prep == nil {
+			return errors.New("preparer is nil")
+		}
+		if lead <= 0 {
+			return errors.New("prepare lead must be positive")
+		}
+
+		p.preparer = prep
+		p.prepareLead = lead
+		return nil
+	}
+}
+
+// prepareDueLocked - сообщает, нужно ли ещё подготовить следующую песню.
+// Вызывается под блокировкой.
+func (p *playerImpl) prepareDueLocked() bool {
+	return p.preparer != nil && !p.inGap && p.afterLocked(p.current) != nil && p.prepared != p.afterLocked(p.current)
+}
+
+// prepareNextLocked - запускает подготовку следующей песни.
+// Вызывается под блокировкой.
+func (p *playerImpl) prepareNextLocked(ctx context.Context) {
+	p.prepared = p.afterLocked(p.current)
+	song := *p.prepared.song
+
+	go func() {
+		if err := p.preparer.Prepare(ctx, song); err != nil {
+			p.mu.Lock()
+			p.playbackErrorLocked(ctx, song, StagePrepare, err)
+			p.mu.Unlock()
+		}
+	}()
+}
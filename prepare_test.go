@@ -0,0 +1,59 @@
+package player
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// recordingPreparer - запоминает подготовленные песни и время подготовки.
+type recordingPreparer struct {
+	mu       sync.Mutex
+	start    time.Time
+	prepared []string
+	at       []time.Duration
+}
+
+func (r *recordingPreparer) Prepare(_ context.Context, song Song) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.prepared = append(r.prepared, song.Name)
+	r.at = append(r.at, time.Since(r.start))
+	return nil
+}
+
+func TestWithPreparer(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := New(WithPreparer(nil, time.Second))
+	td.CmpError(t, err)
+	_, err = New(WithPreparer(&recordingPreparer{}, 0))
+	td.CmpError(t, err)
+
+	prep := &recordingPreparer{start: time.Now()}
+	pl, _ := New(WithValidator(ValidationPolicy{}), WithPreparer(prep, 30*time.Millisecond), WithSongs(
+		Song{Name: "a", Duration: 50 * time.Millisecond},
+		Song{Name: "b", Duration: 20 * time.Millisecond},
+		Song{Name: "c", Duration: 30 * time.Second},
+	))
+
+	_ = pl.Play(ctx)
+	time.Sleep(30 * time.Millisecond)
+
+	prep.mu.Lock()
+	td.Cmp(t, prep.prepared, []string{"b"}, "b подготовлена, пока играет a")
+	td.Cmp(t, prep.at[0], td.Between(15*time.Millisecond, 30*time.Millisecond))
+	prep.mu.Unlock()
+
+	time.Sleep(50 * time.Millisecond)
+	_ = pl.Pause(ctx)
+
+	prep.mu.Lock()
+	defer prep.mu.Unlock()
+	td.Cmp(t, prep.prepared, []string{"b", "c"}, "песня короче lead подготавливает следующую сразу")
+	td.Cmp(t, pl.current.song.Name, "c")
+}
@@ -0,0 +1,37 @@
+package player
+
+import (
+	"context"
+	"time"
+)
+
+// Elapsed - возвращает позицию воспроизведения текущей песни,
+// во время воспроизведения она вычисляется от момента старта.
+// Для потока - сколько он играет.
+func (p *playerImpl) Elapsed(_ context.Context) time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.current == nil {
+		return 0
+	}
+
+	if p.current.song.IsStream() {
+		return p.elapsedLocked()
+	}
+
+	return min(p.elapsedLocked(), p.current.song.Duration)
+}
+
+// Remaining - возвращает, сколько осталось играть текущей песне до End.
+// Для потока оставшееся время неизвестно и равно 0.
+func (p *playerImpl) Remaining(_ context.Context) time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.current == nil {
+		return 0
+	}
+
+	return max(p.current.song.End()-p.elapsedLocked(), 0)
+}
@@ -0,0 +1,29 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Progress(t *testing.T) {
+	ctx := context.Background()
+
+	empty, _ := NewPlayer()
+	td.Cmp(t, empty.Elapsed(ctx), time.Duration(0))
+	td.Cmp(t, empty.Remaining(ctx), time.Duration(0))
+
+	pl, _ := NewPlayer(Song{Name: "a", Duration: 30 * time.Second})
+	td.CmpNoError(t, pl.PlayFrom(ctx, 0, 10*time.Second))
+	time.Sleep(50 * time.Millisecond)
+
+	td.Cmp(t, pl.Elapsed(ctx), td.Between(10*time.Second+50*time.Millisecond, 10*time.Second+100*time.Millisecond),
+		"позиция растёт во время воспроизведения")
+	td.Cmp(t, pl.Remaining(ctx), td.Between(20*time.Second-100*time.Millisecond, 20*time.Second-50*time.Millisecond))
+
+	_ = pl.Pause(ctx)
+	td.Cmp(t, pl.Elapsed(ctx), pl.playedTime, "на паузе позиция не меняется")
+	td.Cmp(t, pl.Elapsed(ctx)+pl.Remaining(ctx), 30*time.Second)
+}
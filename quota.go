@@ -0,0 +1,307 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// ErrQuotaExceeded - лимит времени прослушивания на сегодня исчерпан.
+var ErrQuotaExceeded = errors.New("listening quota exceeded")
+
+// quotaSaveInterval - как часто, пока действуют лимиты, время прослушивания
+// сохраняется в хранилище: после сбоя теряется не больше этого времени.
+const quotaSaveInterval = time.Minute
+
+// QuotaUsage - время прослушивания за день.
+type QuotaUsage struct {
+	// Day - день в формате 2006-01-02 по местному времени
+	Day string `json:"day"`
+	// Total - сколько слушали за день во всех плейлистах
+	Total time.Duration `json:"total"`
+	// Playlists - сколько слушали за день каждый плейлист
+	Playlists map[string]time.Duration `json:"playlists,omitempty"`
+}
+
+// QuotaExceeded - событие исчерпания лимита прослушивания.
+type QuotaExceeded struct {
+	// Playlist - плейлист с исчерпанным лимитом, пусто для общего лимита
+	Playlist string
+	// Limit - дневной лимит
+	Limit time.Duration
+	// Used - сколько слушали за день
+	Used time.Duration
+	// State - состояние плеера после остановки
+	State State
+}
+
+// QuotaHook - обработчик исчерпания лимита прослушивания.
+type QuotaHook func(event QuotaExceeded)
+
+// quota - дневные лимиты времени прослушивания.
+type quota struct {
+	// daily - общий лимит, 0 - без ограничения
+	daily time.Duration
+	// playlists - лимиты плейлистов
+	playlists map[string]time.Duration
+	// usage - учтённое время прослушивания
+	usage QuotaUsage
+	// since - начало ещё не учтённого воспроизведения, нулевое если не играет
+	since time.Time
+	// saved - когда время прослушивания последний раз сохранялось в хранилище
+	saved time.Time
+	hooks []QuotaHook
+}
+
+// SetDailyLimit - ограничивает общее время прослушивания за день, например
+// для детского режима. Когда время исчерпано, воспроизведение приостанавливается,
+// вызываются обработчики OnQuotaExceeded, а Play до конца дня возвращает
+// ErrQuotaExceeded. 0 снимает ограничение.
+// Время прослушивания сохраняется в состоянии плеера и переживает перезапуск
+// вместе с хранилищем из WithStorage, а пока действуют лимиты, состояние
+// сохраняется и по ходу воспроизведения, поэтому переживает и сбой.
+func (p *playerImpl) SetDailyLimit(ctx context.Context, d time.Duration) error {
+	if d < 0 {
+		return errors.New("limit is negative")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	p.quota.daily = d
+	p.rescheduleLocked()
+	return nil
+}
+
+// SetPlaylistLimit - ограничивает время прослушивания плейлиста name за день,
+// как SetDailyLimit. 0 снимает ограничение.
+func (p *playerImpl) SetPlaylistLimit(ctx context.Context, name string, d time.Duration) error {
+	if d < 0 {
+		return errors.New("limit is negative")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if !p.hasPlaylistLocked(name) {
+		return ErrPlaylistNotFound
+	}
+
+	if d == 0 {
+		delete(p.quota.playlists, name)
+	} else {
+		if p.quota.playlists == nil {
+			p.quota.playlists = make(map[string]time.Duration)
+		}
+		p.quota.playlists[name] = d
+	}
+
+	p.rescheduleLocked()
+	return nil
+}
+
+// ListenTime - возвращает время прослушивания за сегодня.
+func (p *playerImpl) ListenTime(_ context.Context) QuotaUsage {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.usageLocked(p.now())
+}
+
+// OnQuotaExceeded - регистрирует обработчик, который вызывается,
+// когда воспроизведение остановлено из-за исчерпанного лимита.
+// Обработчики вызываются по порядку в отдельной горутине.
+func (p *playerImpl) OnQuotaExceeded(ctx context.Context, hook QuotaHook) error {
+	if hook == nil {
+		return errors.New("hook is nil")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	p.quota.hooks = append(p.quota.hooks, hook)
+	return nil
+}
+
+// quotaDay - день для учёта времени прослушивания.
+func quotaDay(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+// usageLocked - возвращает время прослушивания за день now,
+// включая ещё не учтённое воспроизведение.
+// Вызывается под блокировкой.
+func (p *playerImpl) usageLocked(now time.Time) QuotaUsage {
+	day := quotaDay(now)
+	usage := QuotaUsage{Day: day, Playlists: make(map[string]time.Duration)}
+	if p.quota.usage.Day == day {
+		usage.Total = p.quota.usage.Total
+		for name, d := range p.quota.usage.Playlists {
+			usage.Playlists[name] = d
+		}
+	}
+
+	if since := p.quota.since; !since.IsZero() {
+		// вчерашнее воспроизведение в сегодняшний лимит не входит
+		y, m, d := now.Date()
+		if midnight := time.Date(y, m, d, 0, 0, 0, 0, now.Location()); since.Before(midnight) {
+			since = midnight
+		}
+
+		played := now.Sub(since)
+		usage.Total += played
+		usage.Playlists[p.active] += played
+	}
+
+	return usage
+}
+
+// quotaStartLocked - начинает учёт воспроизведения.
+// Вызывается под блокировкой.
+func (p *playerImpl) quotaStartLocked() {
+	p.quota.since = p.now()
+}
+
+// quotaStopLocked - учитывает воспроизведение до текущего момента.
+// Вызывается под блокировкой.
+func (p *playerImpl) quotaStopLocked() {
+	if p.quota.since.IsZero() {
+		return
+	}
+
+	p.quota.usage = p.usageLocked(p.now())
+	p.quota.since = time.Time{}
+}
+
+// setActiveLocked - делает плейлист name активным. Время прослушивания
+// до переключения учитывается прежнему плейлисту.
+// Вызывается под блокировкой.
+func (p *playerImpl) setActiveLocked(name string) {
+	playing := !p.quota.since.IsZero()
+	p.quotaStopLocked()
+	p.active = name
+	if playing {
+		p.quotaStartLocked()
+	}
+}
+
+// quotaLeftLocked - возвращает, сколько ещё можно слушать активный плейлист,
+// и плейлист, лимит которого исчерпается первым, пустой для общего лимита.
+// Вызывается под блокировкой.
+func (p *playerImpl) quotaLeftLocked() (time.Duration, string) {
+	usage := p.usageLocked(p.now())
+
+	left, playlist := unbounded, ""
+	if p.quota.daily > 0 {
+		left = p.quota.daily - usage.Total
+	}
+
+	if limit, ok := p.quota.playlists[p.active]; ok {
+		if d := limit - usage.Playlists[p.active]; d < left {
+			left, playlist = d, p.active
+		}
+	}
+
+	return left, playlist
+}
+
+// quotaDueLocked - сообщает, что время прослушивания активного плейлиста ограничено.
+// Вызывается под блокировкой.
+func (p *playerImpl) quotaDueLocked() bool {
+	return p.quota.daily > 0 || p.quota.playlists[p.active] > 0
+}
+
+// untilQuotaLocked - возвращает время до исчерпания лимита.
+// Вызывается под блокировкой.
+func (p *playerImpl) untilQuotaLocked() time.Duration {
+	left, _ := p.quotaLeftLocked()
+	return left
+}
+
+// quotaSaveDueLocked - сообщает, что время прослушивания ограничено и его
+// нужно сохранять по ходу воспроизведения.
+// Вызывается под блокировкой.
+func (p *playerImpl) quotaSaveDueLocked() bool {
+	return p.quotaDueLocked() && !p.quota.since.IsZero()
+}
+
+// untilQuotaSaveLocked - возвращает время до следующего сохранения времени прослушивания.
+// Вызывается под блокировкой.
+func (p *playerImpl) untilQuotaSaveLocked() time.Duration {
+	return p.quota.saved.Add(quotaSaveInterval).Sub(p.now())
+}
+
+// saveQuotaLocked - сохраняет состояние плеера вместе со временем прослушивания
+// в отдельной горутине. Состояние снимается, когда до сохранения дошла очередь,
+// поэтому оно не перезапишет более новое.
+// Вызывается под блокировкой.
+func (p *playerImpl) saveQuotaLocked() {
+	p.quota.saved = p.now()
+	p.hookQueue.push(func() {
+		if err := p.Persist(context.Background()); err != nil {
+			p.logger.Error("save listening time failed", slog.Any("error", err))
+		}
+	})
+}
+
+// quotaExceededLocked - приостанавливает воспроизведение по исчерпании лимита
+// и вызывает обработчики.
+// Вызывается под блокировкой.
+func (p *playerImpl) quotaExceededLocked(ctx context.Context) {
+	_, playlist := p.quotaLeftLocked()
+	p.pauseLocked(ctx)
+	p.saveQuotaLocked()
+
+	usage := p.quota.usage
+	event := QuotaExceeded{Playlist: playlist, Limit: p.quota.daily, Used: usage.Total, State: p.stateLocked()}
+	if playlist != "" {
+		event.Limit, event.Used = p.quota.playlists[playlist], usage.Playlists[playlist]
+	}
+
+	p.logger.InfoContext(ctx, "listening quota exceeded", slog.String("playlist", playlist),
+		slog.Duration("limit", event.Limit), slog.Duration("used", event.Used))
+	p.publishLocked(EventQuota, event)
+
+	if len(p.quota.hooks) == 0 {
+		return
+	}
+
+	hooks := append([]QuotaHook(nil), p.quota.hooks...)
+	p.hookQueue.push(func() {
+		for _, h := range hooks {
+			h(event)
+		}
+	})
+}
+
+// restoreQuotaLocked - восстанавливает сегодняшнее время прослушивания из состояния.
+// Вызывается под блокировкой.
+func (p *playerImpl) restoreQuotaLocked(usage *QuotaUsage) {
+	if usage == nil || usage.Day != quotaDay(p.now()) {
+		return
+	}
+
+	p.quota.usage = QuotaUsage{Day: usage.Day, Total: usage.Total, Playlists: make(map[string]time.Duration)}
+	for name, d := range usage.Playlists {
+		p.quota.usage.Playlists[name] = d
+	}
+}
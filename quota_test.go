@@ -0,0 +1,151 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Quota(t *testing.T) {
+	ctx := context.Background()
+	song := Song{Name: "Винни-Пух", Duration: time.Minute}
+
+	t.Run("дневной лимит", func(t *testing.T) {
+		pl, err := NewPlayer(song)
+		td.CmpNoError(t, err)
+		defer pl.Close(ctx)
+
+		var (
+			mu     sync.Mutex
+			events []QuotaExceeded
+		)
+		td.CmpNoError(t, pl.OnQuotaExceeded(ctx, func(e QuotaExceeded) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, e)
+		}))
+
+		td.CmpNoError(t, pl.SetDailyLimit(ctx, 50*time.Millisecond))
+		td.CmpNoError(t, pl.Play(ctx))
+
+		waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		td.CmpNoError(t, pl.WaitFor(waitCtx, func(st Status) bool { return !st.Playing }))
+		td.Cmp(t, pl.State(ctx), StatePaused, "воспроизведение приостановлено")
+		td.Cmp(t, pl.Play(ctx), ErrQuotaExceeded, "до конца дня не запускается")
+		td.Cmp(t, pl.Next(ctx), ErrQuotaExceeded)
+
+		usage := pl.ListenTime(ctx)
+		td.Cmp(t, usage.Day, time.Now().Format(time.DateOnly))
+		td.Cmp(t, usage.Total, td.Between(50*time.Millisecond, 70*time.Millisecond))
+		td.Cmp(t, usage.Playlists[DefaultPlaylist], usage.Total)
+
+		td.CmpNoError(t, pl.hookQueue.wait(ctx))
+		mu.Lock()
+		td.Cmp(t, events, []QuotaExceeded{{Limit: 50 * time.Millisecond, Used: usage.Total, State: StatePaused}})
+		mu.Unlock()
+
+		td.CmpNoError(t, pl.SetDailyLimit(ctx, 0))
+		td.CmpNoError(t, pl.Play(ctx), "ограничение снято")
+	})
+
+	t.Run("лимит плейлиста", func(t *testing.T) {
+		pl, err := NewPlayer(song)
+		td.CmpNoError(t, err)
+		defer pl.Close(ctx)
+
+		td.CmpNoError(t, pl.CreatePlaylist(ctx, "мультики"))
+		_, err = pl.AddSongTo(ctx, "мультики", song)
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, pl.SetPlaylistLimit(ctx, "мультики", 30*time.Millisecond))
+
+		var got QuotaExceeded
+		td.CmpNoError(t, pl.OnQuotaExceeded(ctx, func(e QuotaExceeded) { got = e }))
+
+		td.CmpNoError(t, pl.SwitchPlaylist(ctx, "мультики"))
+		td.CmpNoError(t, pl.Play(ctx))
+		time.Sleep(50 * time.Millisecond)
+		td.CmpFalse(t, pl.Status(ctx).Playing)
+		td.CmpNoError(t, pl.hookQueue.wait(ctx))
+		td.Cmp(t, got.Playlist, "мультики")
+		td.Cmp(t, got.Limit, 30*time.Millisecond)
+
+		td.CmpNoError(t, pl.SwitchPlaylist(ctx, DefaultPlaylist))
+		td.CmpNoError(t, pl.Play(ctx), "другие плейлисты не ограничены")
+	})
+
+	t.Run("переживает перезапуск", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := NewFileStorage(dir)
+		td.CmpNoError(t, err)
+
+		pl, err := New(WithStorage(s), WithSongs(song))
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, pl.Play(ctx))
+		time.Sleep(30 * time.Millisecond)
+		td.CmpNoError(t, pl.Close(ctx))
+
+		s, err = NewFileStorage(dir)
+		td.CmpNoError(t, err)
+		pl, err = New(WithStorage(s))
+		td.CmpNoError(t, err, "исчерпанный лимит не мешает восстановлению")
+		defer pl.Close(ctx)
+
+		td.Cmp(t, pl.ListenTime(ctx).Total, td.Gte(30*time.Millisecond))
+		td.CmpNoError(t, pl.SetDailyLimit(ctx, 20*time.Millisecond))
+		td.Cmp(t, pl.Play(ctx), ErrQuotaExceeded)
+	})
+
+	t.Run("переживает сбой", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := NewFileStorage(dir)
+		td.CmpNoError(t, err)
+
+		long := Song{Name: "Винни-Пух", Duration: 10 * time.Minute}
+		pl := newFakePlayer(t, WithStorage(s), WithSongs(long))
+		td.CmpNoError(t, pl.SetDailyLimit(ctx, time.Hour))
+		td.CmpNoError(t, pl.Play(ctx))
+		_, err = pl.SimulatePlayback(ctx, 150*time.Second)
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, pl.hookQueue.wait(ctx))
+
+		// плеер не закрыт, как при сбое
+		s, err = NewFileStorage(dir)
+		td.CmpNoError(t, err)
+		restored, err := New(WithClock(NewFakeClock(testStart.Add(time.Hour))), WithStorage(s))
+		td.CmpNoError(t, err)
+		defer restored.Close(ctx)
+		td.Cmp(t, restored.ListenTime(ctx).Total, 150*time.Second, "сохранено по ходу воспроизведения")
+	})
+
+	t.Run("по плейлистам", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSong("a")))
+		td.CmpNoError(t, pl.CreatePlaylist(ctx, "мультики"))
+		_, err := pl.AddSongTo(ctx, "мультики", minuteSong("b"))
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, pl.SetDailyLimit(ctx, time.Hour))
+
+		td.CmpNoError(t, pl.Play(ctx))
+		_, err = pl.SimulatePlayback(ctx, 30*time.Second)
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, pl.SwitchPlaylist(ctx, "мультики"))
+		td.CmpNoError(t, pl.Play(ctx))
+		_, err = pl.SimulatePlayback(ctx, 20*time.Second)
+		td.CmpNoError(t, err)
+
+		usage := pl.ListenTime(ctx)
+		td.Cmp(t, usage.Total, 50*time.Second)
+		td.Cmp(t, usage.Playlists, map[string]time.Duration{DefaultPlaylist: 30 * time.Second, "мультики": 20 * time.Second})
+	})
+
+	t.Run("ошибки", func(t *testing.T) {
+		pl, _ := NewPlayer()
+		td.CmpString(t, pl.SetDailyLimit(ctx, -time.Second), "limit is negative")
+		td.CmpTrue(t, errors.Is(pl.SetPlaylistLimit(ctx, "нет такого", time.Hour), ErrPlaylistNotFound))
+		td.CmpString(t, pl.OnQuotaExceeded(ctx, nil), "hook is nil")
+	})
+}
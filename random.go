@@ -0,0 +1,103 @@
+package player
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// ReplayableRand - источник случайных чисел, состояние которого описывается
+// начальным значением и числом выданных значений. По RandState из Snapshot
+// генератор восстанавливается в ту же точку, поэтому порядок Shuffle
+// и WeightedRandom можно воспроизвести в тесте или по отчёту об ошибке.
+// Как и rand.NewSource, не безопасен для конкурентного использования.
+type ReplayableRand struct {
+	src   rand.Source
+	seed  int64
+	draws uint64
+}
+
+// NewReplayableRand - создаёт источник с начальным значением seed.
+func NewReplayableRand(seed int64) *ReplayableRand {
+	return &ReplayableRand{src: rand.NewSource(seed), seed: seed}
+}
+
+func (r *ReplayableRand) Int63() int64 {
+	r.draws++
+	return r.src.Int63()
+}
+
+// Seed - начинает последовательность заново с начальным значением seed.
+func (r *ReplayableRand) Seed(seed int64) {
+	r.src.Seed(seed)
+	r.seed, r.draws = seed, 0
+}
+
+// State - начальное значение и число выданных значений.
+func (r *ReplayableRand) State() RandState {
+	return RandState{Seed: r.seed, Draws: r.draws}
+}
+
+// restore - переводит источник в состояние state.
+func (r *ReplayableRand) restore(state RandState) {
+	r.Seed(state.Seed)
+	for r.draws < state.Draws {
+		r.Int63()
+	}
+}
+
+// RandState - сериализуемое состояние ReplayableRand.
+type RandState struct {
+	// Seed - начальное значение
+	Seed int64 `json:"seed"`
+	// Draws - сколько значений выдано с начала последовательности
+	Draws uint64 `json:"draws"`
+}
+
+// WithRandSource - задаёт источник случайных чисел для Shuffle и WeightedRandom.
+// С ReplayableRand его состояние попадает в Snapshot и восстанавливается
+// RestoreState. Выбор WeightedRandom зависит ещё и от статистики песен,
+// которая в состояние не входит. По умолчанию - ReplayableRand от текущего времени.
+func WithRandSource(src rand.Source) Option {
+	return func(p *playerImpl) error {
+		if src == nil {
+			return errors.New("rand source is nil")
+		}
+
+		p.setRandSource(src)
+		return nil
+	}
+}
+
+// defaultRandSource - источник по умолчанию.
+func defaultRandSource() rand.Source {
+	return NewReplayableRand(time.Now().UnixNano())
+}
+
+// setRandSource - переключает генератор плеера на src.
+func (p *playerImpl) setRandSource(src rand.Source) {
+	p.rand = rand.New(src)
+	p.replayable, _ = src.(*ReplayableRand)
+}
+
+// randStateLocked - состояние генератора, nil если его нельзя сохранить.
+// Вызывается под блокировкой.
+func (p *playerImpl) randStateLocked() *RandState {
+	if p.replayable == nil {
+		return nil
+	}
+
+	state := p.replayable.State()
+	return &state
+}
+
+// restoreRandLocked - восстанавливает состояние генератора, если оно сохранено
+// и источник плеера - ReplayableRand.
+// Вызывается под блокировкой.
+func (p *playerImpl) restoreRandLocked(state *RandState) {
+	if state == nil || p.replayable == nil {
+		return
+	}
+
+	p.replayable.restore(*state)
+}
@@ -0,0 +1,94 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestWithRandSource(t *testing.T) {
+	ctx := context.Background()
+
+	songs := make([]Song, 10)
+	for i := range songs {
+		songs[i] = Song{Name: fmt.Sprintf("s%d", i), Duration: time.Minute}
+	}
+
+	newPlayer := func(t *testing.T, seq Sequencer, src rand.Source) *playerImpl {
+		pl := newFakePlayer(t, WithSequencer(seq), WithRandSource(src), WithSongs(songs...))
+		return pl
+	}
+
+	// play - проигрывает d и возвращает названия сыгранных песен.
+	play := func(t *testing.T, pl *playerImpl, d time.Duration) []string {
+		played, err := pl.SimulatePlayback(ctx, d)
+		td.CmpNoError(t, err)
+		return titles(played)
+	}
+
+	// вес не зависит от статистики, которая не входит в состояние
+	flat := WeightedRandom(func(SongStats, time.Time) float64 { return 1 })
+
+	for name, seq := range map[string]Sequencer{"shuffle": Shuffle(), "weighted": flat} {
+		t.Run(name, func(t *testing.T) {
+			a := newPlayer(t, seq, NewReplayableRand(42))
+			b := newPlayer(t, seq, NewReplayableRand(42))
+			td.CmpNoError(t, a.Play(ctx))
+			td.CmpNoError(t, b.Play(ctx))
+
+			order := play(t, a, 5*time.Minute+30*time.Second)
+			td.Cmp(t, order, td.Len(6))
+			td.Cmp(t, play(t, b, 5*time.Minute+30*time.Second), order, "одинаковый seed - одинаковый порядок")
+
+			state, err := a.Snapshot(ctx)
+			td.CmpNoError(t, err)
+			td.Cmp(t, state.Rand, td.Struct(&RandState{Seed: 42}, td.StructFields{"Draws": td.Gt(uint64(0))}))
+
+			// восстановленный плеер продолжает тот же порядок
+			c := newPlayer(t, seq, NewReplayableRand(7))
+			td.CmpNoError(t, c.RestoreState(ctx, state))
+			td.Cmp(t, play(t, c, 3*time.Minute), play(t, a, 3*time.Minute))
+		})
+	}
+
+	t.Run("not replayable", func(t *testing.T) {
+		pl := newPlayer(t, Shuffle(), rand.NewSource(1))
+		state, err := pl.Snapshot(ctx)
+		td.CmpNoError(t, err)
+		td.CmpNil(t, state.Rand)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		pl, err := New()
+		td.CmpNoError(t, err)
+		state, err := pl.Snapshot(ctx)
+		td.CmpNoError(t, err)
+		td.CmpNotNil(t, state.Rand, "seed по умолчанию тоже сохраняется")
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		_, err := New(WithRandSource(nil))
+		td.CmpString(t, err, "rand source is nil")
+	})
+}
+
+func TestReplayableRand(t *testing.T) {
+	r := NewReplayableRand(5)
+	for i := 0; i < 3; i++ {
+		r.Int63()
+	}
+	state := r.State()
+	td.Cmp(t, state, RandState{Seed: 5, Draws: 3})
+	want := r.Int63()
+
+	other := NewReplayableRand(9)
+	other.restore(state)
+	td.Cmp(t, other.Int63(), want, "продолжает с той же точки")
+
+	r.Seed(6)
+	td.Cmp(t, r.State(), RandState{Seed: 6})
+}
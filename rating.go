@@ -0,0 +1,140 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+)
+
+// MaxRating - наибольшая оценка песни.
+const MaxRating = 5
+
+// ErrInvalidRating - оценка вне диапазона от 0 до MaxRating.
+var ErrInvalidRating = errors.New("rating must be between 0 and 5")
+
+// RatingHook - обработчик изменения оценки или избранного.
+type RatingHook func(song Song, rating int, favorite bool)
+
+// RateSong - ставит песне активного плейлиста оценку от 1 до 5 звёзд, 0 снимает оценку.
+// Оценка относится к песне, а не к её месту в плейлисте,
+// и хранится вместе со статистикой.
+func (p *playerImpl) RateSong(ctx context.Context, id SongID, stars int) error {
+	if stars < 0 || stars > MaxRating {
+		return ErrInvalidRating
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	node := p.find(id)
+	if node == nil {
+		return ErrSongNotFound
+	}
+
+	s := p.songStatsLocked(*node.song)
+	if s.Rating == stars {
+		return nil
+	}
+
+	s.Rating = stars
+	p.ratingChangedLocked(s)
+	p.logger.DebugContext(ctx, "song rated", songAttr(*node.song), slog.Int("rating", stars))
+	return nil
+}
+
+// ToggleFavorite - добавляет песню активного плейлиста в избранное или убирает из него.
+// Возвращает, находится ли песня в избранном теперь.
+func (p *playerImpl) ToggleFavorite(ctx context.Context, id SongID) (bool, error) {
+	if err := p.lock(ctx); err != nil {
+		return false, err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return false, ErrClosed
+	}
+
+	node := p.find(id)
+	if node == nil {
+		return false, ErrSongNotFound
+	}
+
+	s := p.songStatsLocked(*node.song)
+	s.Favorite = !s.Favorite
+	p.ratingChangedLocked(s)
+	p.logger.DebugContext(ctx, "song favorite toggled", songAttr(*node.song), slog.Bool("favorite", s.Favorite))
+	return s.Favorite, nil
+}
+
+// Favorites - возвращает избранные песни по названию.
+func (p *playerImpl) Favorites(_ context.Context) []Song {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var songs []Song
+	for _, s := range p.stats {
+		if s.Favorite {
+			songs = append(songs, s.Song)
+		}
+	}
+
+	sort.Slice(songs, func(i, j int) bool {
+		return songs[i].Name < songs[j].Name
+	})
+
+	return songs
+}
+
+// OnRatingChanged - регистрирует обработчик, который вызывается,
+// когда меняется оценка песни или её наличие в избранном.
+// Обработчики вызываются по порядку в отдельной горутине.
+func (p *playerImpl) OnRatingChanged(ctx context.Context, hook RatingHook) error {
+	if hook == nil {
+		return errors.New("hook is nil")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	p.ratingHooks = append(p.ratingHooks, hook)
+	return nil
+}
+
+// ratingChangedLocked - ставит в очередь обработчики изменения оценки.
+// Вызывается под блокировкой.
+func (p *playerImpl) ratingChangedLocked(s *SongStats) {
+	if len(p.ratingHooks) == 0 {
+		return
+	}
+
+	hooks := append([]RatingHook(nil), p.ratingHooks...)
+	song, rating, favorite := s.Song, s.Rating, s.Favorite
+	p.hookQueue.push(func() {
+		for _, h := range hooks {
+			h(song, rating, favorite)
+		}
+	})
+}
+
+// RatingAtLeast - песни с оценкой не ниже stars.
+func RatingAtLeast(stars int) Rule {
+	return func(info SongInfo) bool { return info.Stats.Rating >= stars }
+}
+
+// IsFavorite - избранные песни.
+func IsFavorite() Rule {
+	return func(info SongInfo) bool { return info.Stats.Favorite }
+}
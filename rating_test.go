@@ -0,0 +1,91 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Rating(t *testing.T) {
+	ctx := context.Background()
+	sg := Song{Name: "30 лет", Artist: "Сектор Газа", Duration: 30 * time.Second}
+	ap := Song{Name: "Почему я идиот?", Artist: "Александр Пушной", Duration: 30 * time.Second}
+
+	t.Run("rate and favorite", func(t *testing.T) {
+		var (
+			mu    sync.Mutex
+			calls []string
+		)
+
+		pl, _ := NewPlayer(sg, ap)
+		td.CmpError(t, pl.OnRatingChanged(ctx, nil))
+		td.CmpNoError(t, pl.OnRatingChanged(ctx, func(song Song, rating int, favorite bool) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			calls = append(calls, fmt.Sprintf("%s %d %t", song.Name, rating, favorite))
+		}))
+
+		_, sgID, _ := pl.SongAt(ctx, 0)
+		_, apID, _ := pl.SongAt(ctx, 1)
+
+		td.Cmp(t, pl.RateSong(ctx, sgID, 6), ErrInvalidRating)
+		td.Cmp(t, pl.RateSong(ctx, sgID, -1), ErrInvalidRating)
+		td.Cmp(t, pl.RateSong(ctx, 0, 3), ErrSongNotFound)
+
+		td.CmpNoError(t, pl.RateSong(ctx, sgID, 4))
+		td.CmpNoError(t, pl.RateSong(ctx, sgID, 4), "повторная оценка не вызывает обработчик")
+
+		fav, err := pl.ToggleFavorite(ctx, apID)
+		td.CmpNoError(t, err)
+		td.CmpTrue(t, fav)
+		td.Cmp(t, pl.Favorites(ctx), []Song{ap})
+
+		fav, _ = pl.ToggleFavorite(ctx, apID)
+		td.CmpFalse(t, fav)
+		td.CmpEmpty(t, pl.Favorites(ctx))
+
+		td.Cmp(t, pl.Stats(ctx), td.Contains(td.Struct(SongStats{Song: sg, Rating: 4}, nil)),
+			"оценка хранится вместе со статистикой")
+
+		td.CmpNoError(t, pl.hookQueue.wait(ctx))
+		mu.Lock()
+		defer mu.Unlock()
+		td.Cmp(t, calls, []string{
+			"30 лет 4 false",
+			"Почему я идиот? 0 true",
+			"Почему я идиот? 0 false",
+		})
+	})
+
+	t.Run("smart playlist", func(t *testing.T) {
+		pl, _ := NewPlayer(sg, ap)
+		_, sgID, _ := pl.SongAt(ctx, 0)
+		_, apID, _ := pl.SongAt(ctx, 1)
+		_ = pl.RateSong(ctx, sgID, 5)
+		_, _ = pl.ToggleFavorite(ctx, apID)
+
+		_ = pl.CreateSmartPlaylist(ctx, "лучшее", AnyOf(RatingAtLeast(5), IsFavorite()))
+		_ = pl.CreateSmartPlaylist(ctx, "избранное", IsFavorite())
+
+		_ = pl.SwitchPlaylist(ctx, "лучшее")
+		td.Cmp(t, names(pl), []string{sg.Name, ap.Name})
+		_ = pl.SwitchPlaylist(ctx, "избранное")
+		td.Cmp(t, names(pl), []string{ap.Name})
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		pl, _ := NewPlayer(sg)
+		_, id, _ := pl.SongAt(ctx, 0)
+		_ = pl.Close(ctx)
+
+		td.Cmp(t, pl.RateSong(ctx, id, 1), ErrClosed)
+		_, err := pl.ToggleFavorite(ctx, id)
+		td.Cmp(t, err, ErrClosed)
+		td.Cmp(t, pl.OnRatingChanged(ctx, func(Song, int, bool) {}), ErrClosed)
+	})
+}
@@ -0,0 +1,171 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrRendererNotFound - устройство с таким названием не найдено.
+var ErrRendererNotFound = errors.New("renderer not found")
+
+// Renderer - удалённое устройство воспроизведения, например DLNA-рендерер
+// или Chromecast. Устройство само загружает песню по адресу и управляется командами.
+// Для DLNA есть DLNARenderer, клиент Chromecast подключается реализацией этого интерфейса.
+type Renderer interface {
+	// Name - название устройства
+	Name() string
+	// Load - загружает песню по адресу song.URL, не начиная воспроизведение
+	Load(ctx context.Context, song Song) error
+	// Play - начинает или продолжает воспроизведение загруженной песни
+	Play(ctx context.Context) error
+	// Pause - приостанавливает воспроизведение
+	Pause(ctx context.Context) error
+	// Seek - переходит к позиции pos загруженной песни
+	Seek(ctx context.Context, pos time.Duration) error
+	// Stop - останавливает воспроизведение и выгружает песню
+	Stop(ctx context.Context) error
+}
+
+// VolumeRenderer - устройство, поддерживающее управление громкостью.
+type VolumeRenderer interface {
+	// SetVolume - устанавливает громкость от 0 до 100
+	SetVolume(ctx context.Context, volume int) error
+}
+
+// Discovery - поиск устройств в сети, например SSDPDiscovery для DLNA.
+type Discovery interface {
+	// Discover - возвращает найденные устройства
+	Discover(ctx context.Context) ([]Renderer, error)
+}
+
+// DiscoveryFunc - функция, удовлетворяющая интерфейсу Discovery.
+type DiscoveryFunc func(ctx context.Context) ([]Renderer, error)
+
+func (f DiscoveryFunc) Discover(ctx context.Context) ([]Renderer, error) {
+	return f(ctx)
+}
+
+// FindRenderer - ищет через d устройство с названием name.
+func FindRenderer(ctx context.Context, d Discovery, name string) (Renderer, error) {
+	if d == nil {
+		return nil, errors.New("discovery is nil")
+	}
+
+	renderers, err := d.Discover(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("discover renderers: %w", err)
+	}
+
+	for _, r := range renderers {
+		if r.Name() == name {
+			return r, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrRendererNotFound, name)
+}
+
+// RendererOutput - Output, который воспроизводит песни на удалённом устройстве.
+// Пауза плеера становится паузой устройства: песня остаётся загруженной,
+// и продолжение той же песни переходит к позиции без повторной загрузки.
+// Песни без адреса воспроизвести нельзя. Вместе с SetOutput позволяет
+// перенести воспроизведение на колонки в сети.
+type RendererOutput struct {
+	renderer Renderer
+
+	mu sync.Mutex
+	// loaded - ключ загруженной в устройство песни
+	loaded string
+}
+
+// NewRendererOutput - конструктор для RendererOutput.
+func NewRendererOutput(r Renderer) (*RendererOutput, error) {
+	if r == nil {
+		return nil, errors.New("renderer is nil")
+	}
+
+	return &RendererOutput{renderer: r}, nil
+}
+
+// Renderer - возвращает устройство бэкенда.
+func (o *RendererOutput) Renderer() Renderer {
+	return o.renderer
+}
+
+func (o *RendererOutput) Start(ctx context.Context, song Song, offset time.Duration) error {
+	if song.URL == "" {
+		return fmt.Errorf("song %q has no url", song.Name)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	key := songKey(song)
+	seek := offset > 0
+	if o.loaded != key {
+		o.loaded = ""
+		if err := o.renderer.Load(ctx, song); err != nil {
+			return fmt.Errorf("load on %s: %w", o.renderer.Name(), err)
+		}
+		o.loaded = key
+	} else {
+		// песня на паузе, продолжаем с позиции плеера
+		seek = true
+	}
+
+	// поток начинается с текущего момента, перематывать его некуда
+	if seek && !song.IsStream() {
+		if err := o.renderer.Seek(ctx, offset); err != nil {
+			return fmt.Errorf("seek on %s: %w", o.renderer.Name(), err)
+		}
+	}
+
+	if err := o.renderer.Play(ctx); err != nil {
+		return fmt.Errorf("play on %s: %w", o.renderer.Name(), err)
+	}
+
+	return nil
+}
+
+// Stop - приостанавливает песню на устройстве. Если в устройство уже загружена
+// другая песня, например при наложении, ничего не делает.
+func (o *RendererOutput) Stop(ctx context.Context, song Song) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.loaded != songKey(song) {
+		return nil
+	}
+
+	if err := o.renderer.Pause(ctx); err != nil {
+		return fmt.Errorf("pause on %s: %w", o.renderer.Name(), err)
+	}
+
+	return nil
+}
+
+// SetVolume - передаёт громкость устройству, если оно её поддерживает.
+func (o *RendererOutput) SetVolume(ctx context.Context, volume int) error {
+	vr, ok := o.renderer.(VolumeRenderer)
+	if !ok {
+		return nil
+	}
+
+	return vr.SetVolume(ctx, volume)
+}
+
+// Close - останавливает устройство и выгружает песню.
+func (o *RendererOutput) Close(ctx context.Context) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.loaded = ""
+	if err := o.renderer.Stop(ctx); err != nil {
+		return fmt.Errorf("stop on %s: %w", o.renderer.Name(), err)
+	}
+
+	return nil
+}
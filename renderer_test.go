@@ -0,0 +1,125 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// fakeRenderer - устройство, которое записывает команды.
+type fakeRenderer struct {
+	name string
+
+	mu       sync.Mutex
+	commands []string
+	volume   int
+}
+
+func (r *fakeRenderer) Name() string { return r.name }
+
+func (r *fakeRenderer) Load(_ context.Context, song Song) error {
+	return r.record("load " + song.URL)
+}
+
+func (r *fakeRenderer) Play(context.Context) error { return r.record("play") }
+
+func (r *fakeRenderer) Pause(context.Context) error { return r.record("pause") }
+
+func (r *fakeRenderer) Seek(_ context.Context, pos time.Duration) error {
+	return r.record(fmt.Sprintf("seek %v", pos.Round(time.Second)))
+}
+
+func (r *fakeRenderer) Stop(context.Context) error { return r.record("stop") }
+
+func (r *fakeRenderer) SetVolume(_ context.Context, volume int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.volume = volume
+	return nil
+}
+
+func (r *fakeRenderer) record(cmd string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.commands = append(r.commands, cmd)
+	return nil
+}
+
+func (r *fakeRenderer) Commands() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.commands...)
+}
+
+func TestRendererOutput(t *testing.T) {
+	ctx := context.Background()
+
+	a := Song{Name: "a", Duration: time.Minute, URL: "http://nas/a.mp3"}
+	b := Song{Name: "b", Duration: time.Minute, URL: "http://nas/b.mp3"}
+
+	t.Run("команды устройству", func(t *testing.T) {
+		device := &fakeRenderer{name: "гостиная"}
+		out, err := NewRendererOutput(device)
+		td.CmpNoError(t, err)
+
+		pl, err := New(WithSongs(a, b))
+		td.CmpNoError(t, err)
+		defer pl.Close(ctx)
+
+		td.CmpNoError(t, pl.PlayFrom(ctx, 0, 10*time.Second))
+		td.CmpNoError(t, pl.SetOutput(ctx, out), "воспроизведение переносится на устройство")
+		td.CmpNoError(t, pl.Pause(ctx))
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpNoError(t, pl.SetVolume(ctx, 40))
+		td.CmpNoError(t, pl.Next(ctx))
+
+		td.Cmp(t, device.Commands(), []string{
+			"load http://nas/a.mp3", "seek 10s", "play",
+			"pause", "seek 10s", "play",
+			"pause", "load http://nas/b.mp3", "play",
+		})
+		td.Cmp(t, device.volume, 40)
+
+		td.CmpNoError(t, pl.SetOutput(ctx, &recordingOutput{}))
+		td.Cmp(t, device.Commands()[len(device.Commands())-2:], []string{"pause", "stop"}, "устройство остановлено")
+	})
+
+	t.Run("песня без адреса", func(t *testing.T) {
+		out, _ := NewRendererOutput(&fakeRenderer{})
+		td.CmpString(t, out.Start(ctx, Song{Name: "a", Duration: time.Minute}, 0), `song "a" has no url`)
+	})
+
+	_, err := NewRendererOutput(nil)
+	td.CmpString(t, err, "renderer is nil")
+}
+
+func TestFindRenderer(t *testing.T) {
+	ctx := context.Background()
+
+	kitchen := &fakeRenderer{name: "кухня"}
+	d := DiscoveryFunc(func(context.Context) ([]Renderer, error) {
+		return []Renderer{&fakeRenderer{name: "гостиная"}, kitchen}, nil
+	})
+
+	r, err := FindRenderer(ctx, d, "кухня")
+	td.CmpNoError(t, err)
+	td.Cmp(t, r, kitchen)
+
+	_, err = FindRenderer(ctx, d, "спальня")
+	td.CmpTrue(t, errors.Is(err, ErrRendererNotFound))
+
+	boom := errors.New("network is down")
+	_, err = FindRenderer(ctx, DiscoveryFunc(func(context.Context) ([]Renderer, error) { return nil, boom }), "кухня")
+	td.CmpTrue(t, errors.Is(err, boom))
+
+	_, err = FindRenderer(ctx, nil, "кухня")
+	td.CmpString(t, err, "discovery is nil")
+}
@@ -0,0 +1,50 @@
+package player
+
+import (
+	"context"
+	"errors"
+)
+
+// GainOutput - бэкенд, поддерживающий нормализацию громкости песен.
+type GainOutput interface {
+	// SetGain - задаёт усиление песни в децибелах, вызывается перед каждым Start
+	SetGain(ctx context.Context, song Song, db float64) error
+}
+
+// WithReplayGain - выравнивает громкость песен до targetLUFS, например -14 или -23.
+// Перед началом каждой песни бэкенду, реализующему GainOutput, передаётся усиление,
+// вычисленное по Song.LoudnessLUFS. Песни без измеренной громкости играют без усиления.
+func WithReplayGain(targetLUFS float64) Option {
+	return func(p *playerImpl) error {
+		if targetLUFS >= 0 || targetLUFS < -70 {
+			return errors.New("target loudness must be between -70 and 0 LUFS")
+		}
+
+		p.replayGain, p.targetLUFS = true, targetLUFS
+		return nil
+	}
+}
+
+// gainLocked - возвращает усиление песни в децибелах.
+// Вызывается под блокировкой.
+func (p *playerImpl) gainLocked(song Song) float64 {
+	if !p.replayGain || song.LoudnessLUFS == 0 {
+		return 0
+	}
+
+	return p.targetLUFS - song.LoudnessLUFS
+}
+
+// applyGainLocked - передаёт бэкенду усиление песни перед её началом.
+// Ошибки логируются, так как вызывающая горутина не может их вернуть.
+// Вызывается под блокировкой.
+func (p *playerImpl) applyGainLocked(ctx context.Context, song Song) {
+	out, ok := p.output.(GainOutput)
+	if !ok || !p.replayGain {
+		return
+	}
+
+	if err := out.SetGain(ctx, song, p.gainLocked(song)); err != nil {
+		p.playbackErrorLocked(ctx, song, StageGain, err)
+	}
+}
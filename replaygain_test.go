@@ -0,0 +1,61 @@
+package player
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+type gainOutput struct {
+	recordingOutput
+}
+
+func (o *gainOutput) SetGain(_ context.Context, song Song, db float64) error {
+	o.record(fmt.Sprintf("gain %s %+g", song.Name, db))
+	return nil
+}
+
+func TestWithReplayGain(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := New(WithReplayGain(3))
+	td.CmpError(t, err)
+
+	loud := Song{Name: "a", Duration: 20 * time.Millisecond, LoudnessLUFS: -8}
+	quiet := Song{Name: "b", Duration: 20 * time.Millisecond, LoudnessLUFS: -20}
+	unknown := Song{Name: "c", Duration: 30 * time.Second}
+
+	t.Run("gain before start", func(t *testing.T) {
+		out := &gainOutput{}
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithOutput(out), WithReplayGain(-14), WithSongs(loud, quiet, unknown))
+		_ = pl.Play(ctx)
+		time.Sleep(50 * time.Millisecond)
+		_ = pl.Pause(ctx)
+
+		td.Cmp(t, out.Calls(), []string{
+			"gain a -6", "start a",
+			"stop a", "gain b +6", "start b",
+			"stop b", "gain c +0", "start c",
+			"stop c",
+		})
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		out := &gainOutput{}
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithOutput(out), WithSongs(loud))
+		_ = pl.Play(ctx)
+		_ = pl.Pause(ctx)
+
+		td.Cmp(t, out.Calls(), []string{"start a", "stop a"})
+	})
+
+	t.Run("exported", func(t *testing.T) {
+		data, err := json.Marshal(loud)
+		td.CmpNoError(t, err)
+		td.CmpJSON(t, json.RawMessage(data), `{"name": "a", "duration": 20000000, "loudness_lufs": -8}`, nil)
+	})
+}
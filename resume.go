@@ -0,0 +1,56 @@
+package player
+
+import (
+	"context"
+	"time"
+)
+
+// WithResumePositions - запоминает позицию песни при паузе или пропуске
+// и продолжает с неё, когда песня снова начинает играть.
+// Полезно для подкастов и аудиокниг.
+func WithResumePositions() Option {
+	return func(p *playerImpl) error {
+		p.resume = make(map[string]time.Duration)
+		return nil
+	}
+}
+
+// ClearResumePositions - забывает сохранённые позиции всех песен.
+func (p *playerImpl) ClearResumePositions(ctx context.Context) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.resume != nil {
+		p.resume = make(map[string]time.Duration)
+	}
+	return nil
+}
+
+// saveResumePositionLocked - запоминает позицию песни, нулевая позиция удаляет запись.
+// Вызывается под блокировкой.
+func (p *playerImpl) saveResumePositionLocked(song Song, pos time.Duration) {
+	if p.resume == nil {
+		return
+	}
+
+	if pos <= song.LeadIn || pos >= song.End() {
+		delete(p.resume, songKey(song))
+		return
+	}
+
+	p.resume[songKey(song)] = pos
+}
+
+// resumePositionLocked - возвращает позицию, с которой должна начать играть песня.
+// Вызывается под блокировкой.
+func (p *playerImpl) resumePositionLocked(song Song) time.Duration {
+	return p.resume[songKey(song)]
+}
+
+// songKey - ключ, по которому песня узнаётся повторно.
+// Песни с одинаковым названием, но разными метаданными различаются.
+func songKey(song Song) string {
+	return libraryKey(song)
+}
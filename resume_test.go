@@ -0,0 +1,65 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestWithResumePositions(t *testing.T) {
+	ctx := context.Background()
+	podcast := Song{Name: "podcast", Duration: 30 * time.Second}
+	book := Song{Name: "book", Duration: 30 * time.Second}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		pl, _ := NewPlayer(podcast, book)
+		pl.playedTime = 5 * time.Second
+		_ = pl.Next(ctx)
+		_ = pl.Prev(ctx)
+		_ = pl.Pause(ctx)
+
+		td.Cmp(t, pl.playedTime, td.Lt(time.Second))
+	})
+
+	t.Run("resume after skip", func(t *testing.T) {
+		pl, _ := New(WithResumePositions(), WithSongs(podcast, book))
+		pl.playedTime = 5 * time.Second
+
+		_ = pl.Next(ctx)
+		td.Cmp(t, pl.playedTime, time.Duration(0), "новая песня с начала")
+		time.Sleep(20 * time.Millisecond)
+		_ = pl.Pause(ctx)
+
+		_ = pl.Prev(ctx)
+		_ = pl.Pause(ctx)
+		td.Cmp(t, pl.playedTime, td.Between(5*time.Second, 5*time.Second+10*time.Millisecond), "podcast с сохранённой позиции")
+
+		_ = pl.Next(ctx)
+		_ = pl.Pause(ctx)
+		td.Cmp(t, pl.playedTime, td.Gte(20*time.Millisecond), "book с сохранённой позиции")
+
+		td.CmpNoError(t, pl.ClearResumePositions(ctx))
+		_ = pl.Prev(ctx)
+		_ = pl.Pause(ctx)
+		td.Cmp(t, pl.playedTime, td.Lt(10*time.Millisecond), "позиции очищены")
+	})
+
+	t.Run("completed song starts over", func(t *testing.T) {
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithResumePositions(), WithSongs(
+			Song{Name: "short", Duration: 20 * time.Millisecond},
+			book,
+		))
+		short := *pl.first().song
+		pl.resume[songKey(short)] = 10 * time.Millisecond
+		pl.playedTime = 10 * time.Millisecond
+
+		_ = pl.Play(ctx)
+		time.Sleep(20 * time.Millisecond)
+		_ = pl.Pause(ctx)
+
+		td.Cmp(t, pl.current.song.Name, "book")
+		td.Cmp(t, pl.resume, td.Not(td.ContainsKey(songKey(short))))
+	})
+}
@@ -0,0 +1,202 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// ErrScheduleNotFound - расписания с таким ID нет.
+var ErrScheduleNotFound = errors.New("schedule not found")
+
+// ScheduleID - ID запланированного запуска.
+type ScheduleID uint64
+
+// schedule - запланированный запуск воспроизведения.
+type schedule struct {
+	id ScheduleID
+	// cron - расписание повторяющегося запуска, nil для однократного
+	cron *cronSchedule
+	// playlist - плейлист, который становится активным перед запуском, пусто - текущий
+	playlist string
+	// stopAfter - через сколько после запуска остановить воспроизведение, 0 - не останавливать
+	stopAfter time.Duration
+
+	// timer - отменяет таймер запуска
+	timer func()
+	// stop - отменяет таймер остановки после запуска
+	stop func()
+	// canceled - запуск отменён, сработавшие таймеры ничего не делают
+	canceled bool
+}
+
+// SchedulePlay - начинает воспроизведение в момент at.
+func (p *playerImpl) SchedulePlay(ctx context.Context, at time.Time) (ScheduleID, error) {
+	if err := p.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return 0, ErrClosed
+	}
+
+	s := p.newScheduleLocked()
+	s.timer = p.afterFunc(at.Sub(p.now()), func() { p.runSchedule(s) })
+
+	p.logger.InfoContext(ctx, "playback scheduled", slog.Time("at", at))
+	return s.id, nil
+}
+
+// SchedulePlaylist - по расписанию cron делает плейлист playlist активным
+// и начинает воспроизведение, а если stopAfter больше нуля - останавливает его
+// через stopAfter. Расписание задаётся пятью полями: минуты, часы, дни месяца,
+// месяцы и дни недели, например "0 9 * * 1-5" - в 9 утра по будням.
+// Время считается в местном часовом поясе.
+func (p *playerImpl) SchedulePlaylist(ctx context.Context, cronExpr, playlist string, stopAfter time.Duration) (ScheduleID, error) {
+	if stopAfter < 0 {
+		return 0, errors.New("stop duration is negative")
+	}
+
+	c, err := parseCron(cronExpr)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return 0, err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return 0, ErrClosed
+	}
+
+	if !p.hasPlaylistLocked(playlist) {
+		return 0, ErrPlaylistNotFound
+	}
+
+	s := p.newScheduleLocked()
+	s.cron, s.playlist, s.stopAfter = c, playlist, stopAfter
+	if !p.armScheduleLocked(s) {
+		delete(p.schedules, s.id)
+		return 0, errors.New("cron expression never fires")
+	}
+
+	p.logger.InfoContext(ctx, "playlist scheduled", slog.String("playlist", playlist), slog.String("cron", cronExpr))
+	return s.id, nil
+}
+
+// CancelSchedule - отменяет запланированный запуск и остановку после него.
+func (p *playerImpl) CancelSchedule(ctx context.Context, id ScheduleID) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	s, ok := p.schedules[id]
+	if !ok {
+		return ErrScheduleNotFound
+	}
+
+	p.cancelScheduleLocked(s)
+	return nil
+}
+
+// newScheduleLocked - регистрирует новый запуск.
+// Вызывается под блокировкой.
+func (p *playerImpl) newScheduleLocked() *schedule {
+	if p.schedules == nil {
+		p.schedules = make(map[ScheduleID]*schedule)
+	}
+
+	p.lastScheduleID++
+	s := &schedule{id: p.lastScheduleID}
+	p.schedules[s.id] = s
+
+	return s
+}
+
+// armScheduleLocked - заводит таймер до следующего срабатывания cron.
+// Вызывается под блокировкой.
+func (p *playerImpl) armScheduleLocked(s *schedule) bool {
+	at, ok := s.cron.next(p.now())
+	if !ok {
+		return false
+	}
+
+	s.timer = p.afterFunc(at.Sub(p.now()), func() { p.runSchedule(s) })
+	return true
+}
+
+// runSchedule - запускает воспроизведение по сработавшему таймеру.
+func (p *playerImpl) runSchedule(s *schedule) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if s.canceled || p.closed {
+		return
+	}
+
+	ctx := context.Background()
+	last := s.cron == nil || !p.armScheduleLocked(s)
+	started := p.startScheduleLocked(ctx, s)
+	// последний запуск забывается, когда не осталось таймеров, которые отменяет Close
+	if last && (!started || s.stopAfter == 0) {
+		delete(p.schedules, s.id)
+	}
+
+	if started && s.stopAfter > 0 {
+		if s.stop != nil {
+			s.stop()
+		}
+		s.stop = p.afterFunc(s.stopAfter, func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+
+			if s.canceled || p.closed {
+				return
+			}
+
+			if last {
+				delete(p.schedules, s.id)
+			}
+			p.pauseLocked(ctx)
+			p.logger.InfoContext(ctx, "scheduled playback stopped")
+		})
+	}
+}
+
+// startScheduleLocked - делает активным плейлист запуска s и начинает воспроизведение,
+// false если не удалось.
+// Вызывается под блокировкой.
+func (p *playerImpl) startScheduleLocked(ctx context.Context, s *schedule) bool {
+	if s.playlist != "" {
+		if err := p.switchPlaylistLocked(ctx, s.playlist); err != nil {
+			p.logger.ErrorContext(ctx, "scheduled playback failed", slog.String("playlist", s.playlist), slog.Any("error", err))
+			return false
+		}
+	}
+
+	if err := p.playLocked(ctx); err != nil {
+		p.logger.ErrorContext(ctx, "scheduled playback failed", slog.Any("error", err))
+		return false
+	}
+
+	p.logger.InfoContext(ctx, "scheduled playback started")
+	return true
+}
+
+// cancelScheduleLocked - отменяет таймеры запуска.
+// Вызывается под блокировкой.
+func (p *playerImpl) cancelScheduleLocked(s *schedule) {
+	s.canceled = true
+	if s.timer != nil {
+		s.timer()
+	}
+	if s.stop != nil {
+		s.stop()
+	}
+	delete(p.schedules, s.id)
+}
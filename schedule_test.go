@@ -0,0 +1,120 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Schedule(t *testing.T) {
+	ctx := context.Background()
+	song := Song{Name: "a", Duration: 30 * time.Second}
+
+	t.Run("play at", func(t *testing.T) {
+		pl, _ := NewPlayer(song)
+		_, err := pl.SchedulePlay(ctx, time.Now().Add(20*time.Millisecond))
+		td.CmpNoError(t, err)
+		td.CmpFalse(t, pl.Status(ctx).Playing)
+
+		waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		td.CmpNoError(t, pl.WaitFor(waitCtx, func(st Status) bool { return st.Playing }))
+		_ = pl.Pause(ctx)
+		td.CmpEmpty(t, pl.schedules, "однократный запуск забыт")
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		pl, _ := NewPlayer(song)
+		id, _ := pl.SchedulePlay(ctx, time.Now().Add(10*time.Millisecond))
+		td.CmpNoError(t, pl.CancelSchedule(ctx, id))
+		td.Cmp(t, pl.CancelSchedule(ctx, id), ErrScheduleNotFound)
+
+		time.Sleep(20 * time.Millisecond)
+		td.CmpFalse(t, pl.Status(ctx).Playing)
+	})
+
+	t.Run("playlist with stop", func(t *testing.T) {
+		pl, _ := NewPlayer(song)
+		_ = pl.CreatePlaylist(ctx, "утро")
+		_, _ = pl.AddSongTo(ctx, "утро", Song{Name: "b", Duration: 30 * time.Second})
+
+		_, err := pl.SchedulePlaylist(ctx, "0 7 * * *", "нет такого", 0)
+		td.Cmp(t, err, ErrPlaylistNotFound)
+		_, err = pl.SchedulePlaylist(ctx, "0 7 * *", "утро", 0)
+		td.CmpError(t, err)
+		_, err = pl.SchedulePlaylist(ctx, "0 0 30 2 *", "утро", 0)
+		td.CmpError(t, err)
+
+		id, err := pl.SchedulePlaylist(ctx, "0 7 * * *", "утро", 20*time.Millisecond)
+		td.CmpNoError(t, err)
+		defer pl.CancelSchedule(ctx, id)
+
+		// срабатывание таймера
+		pl.runSchedule(pl.schedules[id])
+		st := pl.Status(ctx)
+		td.Cmp(t, st.Playlist, "утро")
+		td.CmpTrue(t, st.Playing)
+		td.Cmp(t, pl.schedules[id].timer, td.NotNil(), "следующий запуск заведён")
+
+		time.Sleep(40 * time.Millisecond)
+		td.CmpFalse(t, pl.Status(ctx).Playing, "остановлено через stopAfter")
+	})
+
+	t.Run("fake clock", func(t *testing.T) {
+		clock := NewFakeClock(time.Date(2024, 5, 1, 6, 59, 0, 0, time.Local))
+		pl, _ := New(WithClock(clock), WithSongs(song))
+		_ = pl.CreatePlaylist(ctx, "утро")
+		_, _ = pl.AddSongTo(ctx, "утро", Song{Name: "b", Duration: time.Hour})
+
+		_, err := pl.SchedulePlaylist(ctx, "0 7 * * *", "утро", 30*time.Minute)
+		td.CmpNoError(t, err)
+		_, err = pl.SchedulePlay(ctx, clock.Now().Add(time.Hour))
+		td.CmpNoError(t, err)
+
+		// wait - ждёт, пока таймер расписания сработает по часам плеера.
+		wait := func(playing bool) {
+			t.Helper()
+			waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+			defer cancel()
+			td.CmpNoError(t, pl.WaitFor(waitCtx, func(st Status) bool { return st.Playing == playing }))
+		}
+
+		clock.Advance(30 * time.Second)
+		time.Sleep(10 * time.Millisecond)
+		td.CmpFalse(t, pl.Status(ctx).Playing, "системное время не учитывается")
+
+		clock.Advance(30 * time.Second)
+		wait(true)
+		td.Cmp(t, pl.Status(ctx).Playlist, "утро")
+
+		_, err = pl.SimulatePlayback(ctx, 30*time.Minute)
+		td.CmpNoError(t, err)
+		wait(false)
+
+		td.CmpNoError(t, pl.Close(ctx))
+		td.CmpEmpty(t, pl.schedules)
+		// waiters - сколько ожиданий заведено на часах.
+		waiters := func() int {
+			clock.mu.Lock()
+			defer clock.mu.Unlock()
+			return len(clock.waiters)
+		}
+		// горутина воспроизведения отменяет своё ожидание сама
+		for deadline := time.Now().Add(time.Second); waiters() > 0 && time.Now().Before(deadline); {
+			time.Sleep(time.Millisecond)
+		}
+		td.Cmp(t, waiters(), 0, "Close отменяет таймеры расписаний")
+	})
+
+	t.Run("close cancels schedules", func(t *testing.T) {
+		pl, _ := NewPlayer(song)
+		_, _ = pl.SchedulePlay(ctx, time.Now().Add(10*time.Millisecond))
+		_ = pl.Close(ctx)
+
+		td.CmpEmpty(t, pl.schedules)
+		_, err := pl.SchedulePlay(ctx, time.Now())
+		td.Cmp(t, err, ErrClosed)
+	})
+}
@@ -0,0 +1,179 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	// scrobbleMinDuration - более короткие песни не скробблятся
+	scrobbleMinDuration = 30 * time.Second
+	// scrobbleMaxWait - после стольких минут песня скробблится, даже если не доиграла до середины
+	scrobbleMaxWait = 4 * time.Minute
+)
+
+// Scrobbler - сервис учёта прослушиваний, например Last.fm или ListenBrainz.
+// Методы вызываются по порядку в отдельной горутине, ошибки логируются.
+type Scrobbler interface {
+	// NowPlaying - песня начала играть
+	NowPlaying(ctx context.Context, song Song) error
+	// Scrobble - песня прослушана: сыграла половину или 4 минуты
+	Scrobble(ctx context.Context, song Song, playedFor time.Duration) error
+}
+
+// WithScrobbler - сообщает scrobbler о начале песен и их прослушивании.
+// Песни короче 30 секунд не скробблятся.
+func WithScrobbler(s Scrobbler) Option {
+	return func(p *playerImpl) error {
+		if s == nil {
+			return errors.New("scrobbler is nil")
+		}
+
+		p.scrobbler = s
+		return nil
+	}
+}
+
+// nowPlayingLocked - сообщает скробблеру о начале текущей песни.
+// Вызывается под блокировкой.
+func (p *playerImpl) nowPlayingLocked(ctx context.Context) {
+	if p.scrobbler == nil {
+		return
+	}
+
+	s, song := p.scrobbler, *p.current.song
+	ctx = context.WithoutCancel(ctx)
+	p.hookQueue.push(func() {
+		if err := s.NowPlaying(ctx, song); err != nil {
+			p.logger.ErrorContext(ctx, "now playing failed", songAttr(song), slog.Any("error", err))
+		}
+	})
+}
+
+// scrobbleDueLocked - сообщает, что текущую песню ещё предстоит заскробблить.
+// Вызывается под блокировкой.
+func (p *playerImpl) scrobbleDueLocked() bool {
+	if p.scrobbler == nil || p.inGap || p.scrobbled == p.current {
+		return false
+	}
+
+	song := p.current.song
+	return song.IsStream() || song.Duration >= scrobbleMinDuration
+}
+
+// untilScrobbleLocked - возвращает время до прослушивания текущей песни.
+// Вызывается под блокировкой.
+func (p *playerImpl) untilScrobbleLocked() time.Duration {
+	at := scrobbleMaxWait
+	if song := p.current.song; !song.IsStream() {
+		at = min(at, song.Duration/2)
+	}
+
+	return at - p.elapsedLocked()
+}
+
+// scrobbleLocked - отправляет прослушивание текущей песни.
+// Вызывается под блокировкой.
+func (p *playerImpl) scrobbleLocked(ctx context.Context) {
+	p.scrobbled = p.current
+
+	s, song, played := p.scrobbler, *p.current.song, p.elapsedLocked()
+	ctx = context.WithoutCancel(ctx)
+	p.hookQueue.push(func() {
+		if err := s.Scrobble(ctx, song, played); err != nil {
+			p.logger.ErrorContext(ctx, "scrobble failed", songAttr(song), slog.Any("error", err))
+		}
+	})
+}
+
+// ListenBrainz - Scrobbler, отправляющий прослушивания в ListenBrainz.
+type ListenBrainz struct {
+	// Token - токен пользователя
+	Token string
+	// URL - адрес API, по умолчанию https://api.listenbrainz.org
+	URL string
+	// Client - HTTP-клиент, по умолчанию http.DefaultClient
+	Client *http.Client
+}
+
+// NewListenBrainz - конструктор для ListenBrainz.
+func NewListenBrainz(token string) *ListenBrainz {
+	return &ListenBrainz{Token: token, URL: "https://api.listenbrainz.org"}
+}
+
+// listenBrainzListen - прослушивание в формате API ListenBrainz.
+type listenBrainzListen struct {
+	ListenedAt int64 `json:"listened_at,omitempty"`
+	Track      struct {
+		Artist  string `json:"artist_name"`
+		Track   string `json:"track_name"`
+		Release string `json:"release_name,omitempty"`
+		Info    struct {
+			DurationMs int64 `json:"duration_ms,omitempty"`
+		} `json:"additional_info"`
+	} `json:"track_metadata"`
+}
+
+func (lb *ListenBrainz) NowPlaying(ctx context.Context, song Song) error {
+	return lb.submit(ctx, "playing_now", lb.listen(song, time.Time{}))
+}
+
+func (lb *ListenBrainz) Scrobble(ctx context.Context, song Song, playedFor time.Duration) error {
+	return lb.submit(ctx, "single", lb.listen(song, time.Now().Add(-playedFor)))
+}
+
+func (lb *ListenBrainz) listen(song Song, at time.Time) listenBrainzListen {
+	var l listenBrainzListen
+	if !at.IsZero() {
+		l.ListenedAt = at.Unix()
+	}
+	l.Track.Artist = song.Artist
+	l.Track.Track = song.Name
+	l.Track.Release = song.Album
+	l.Track.Info.DurationMs = song.Duration.Milliseconds()
+
+	return l
+}
+
+// submit - отправляет прослушивание типа listenType.
+func (lb *ListenBrainz) submit(ctx context.Context, listenType string, l listenBrainzListen) error {
+	body, err := json.Marshal(map[string]any{
+		"listen_type": listenType,
+		"payload":     []listenBrainzListen{l},
+	})
+	if err != nil {
+		return fmt.Errorf("marshal listen: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, lb.URL+"/1/submit-listens", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Token "+lb.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := lb.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("submit listen: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("submit listen: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package player
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// recordingScrobbler - скробблер, запоминающий вызовы.
+type recordingScrobbler struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (s *recordingScrobbler) NowPlaying(_ context.Context, song Song) error {
+	s.record("now " + song.Name)
+	return nil
+}
+
+func (s *recordingScrobbler) Scrobble(_ context.Context, song Song, playedFor time.Duration) error {
+	s.record(fmt.Sprintf("scrobble %s %v", song.Name, playedFor.Truncate(time.Second)))
+	return nil
+}
+
+func (s *recordingScrobbler) record(call string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = append(s.calls, call)
+}
+
+func (s *recordingScrobbler) Calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.calls...)
+}
+
+func TestWithScrobbler(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := New(WithScrobbler(nil))
+	td.CmpError(t, err)
+
+	sc := &recordingScrobbler{}
+	pl, _ := New(WithScrobbler(sc), WithSongs(
+		Song{Name: "a", Duration: time.Minute},
+		Song{Name: "jingle", Duration: 10 * time.Second},
+		Song{Name: "long", Duration: time.Hour},
+	))
+
+	// половина песни
+	td.CmpNoError(t, pl.PlayFrom(ctx, 0, 30*time.Second-20*time.Millisecond))
+	time.Sleep(40 * time.Millisecond)
+
+	// короткая песня не скробблится
+	td.CmpNoError(t, pl.PlayFrom(ctx, 1, 5*time.Second))
+	time.Sleep(20 * time.Millisecond)
+
+	// длинная песня скробблится после 4 минут
+	td.CmpNoError(t, pl.PlayFrom(ctx, 2, 4*time.Minute-20*time.Millisecond))
+	time.Sleep(40 * time.Millisecond)
+	td.CmpNoError(t, pl.Close(ctx))
+
+	td.Cmp(t, sc.Calls(), []string{
+		"now a",
+		"scrobble a 30s",
+		"now jingle",
+		"now long",
+		"scrobble long 4m0s",
+	})
+}
+
+func TestListenBrainz(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		mu       sync.Mutex
+		requests []map[string]any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1/submit-listens" || r.Header.Get("Authorization") != "Token secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		mu.Lock()
+		requests = append(requests, body)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	lb := NewListenBrainz("secret")
+	lb.URL = srv.URL
+	song := Song{Name: "30 лет", Artist: "Сектор Газа", Duration: 3 * time.Minute}
+
+	td.CmpNoError(t, lb.NowPlaying(ctx, song))
+	td.CmpNoError(t, lb.Scrobble(ctx, song, 2*time.Minute))
+
+	td.Cmp(t, requests, td.Len(2))
+	td.Cmp(t, requests[0]["listen_type"], "playing_now")
+	td.Cmp(t, requests[1], td.SuperMapOf(map[string]any{
+		"listen_type": "single",
+		"payload": td.ArrayEach(td.SuperMapOf(map[string]any{
+			"listened_at": td.Between(float64(time.Now().Add(-2*time.Minute-time.Second).Unix()), float64(time.Now().Unix())),
+			"track_metadata": td.SuperMapOf(map[string]any{
+				"artist_name": "Сектор Газа",
+				"track_name":  "30 лет",
+			}, nil),
+		}, nil)),
+	}, nil))
+
+	lb.Token = "wrong"
+	td.CmpError(t, lb.NowPlaying(ctx, song), "ошибка сервера возвращается")
+}
@@ -0,0 +1,254 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+)
+
+// SearchResult - найденная песня.
+type SearchResult struct {
+	// ID - ID песни в активном плейлисте, 0 для трека, которого в нём нет
+	ID SongID
+	// Index - индекс песни в активном плейлисте для PlayAt, RemoveAt и MoveSong,
+	// -1 для трека, которого в нём нет
+	Index int
+	// TrackID - трек библиотеки
+	TrackID TrackID
+	// Song - песня
+	Song Song
+	// Score - 1 для точного вхождения запроса, меньше - для нечёткого совпадения
+	Score float64
+}
+
+// searchOptions - параметры Search.
+type searchOptions struct {
+	// fuzzy - минимальная доля общих триграмм для нечёткого совпадения, 0 - только точное
+	fuzzy float64
+	limit int
+	// library - искать и среди треков библиотеки, которых нет в активном плейлисте
+	library bool
+}
+
+// SearchOption - параметр поиска.
+type SearchOption func(o *searchOptions)
+
+// SearchFuzzy - находит также песни с опечатками: совпадением считается, если
+// в поле встречается не меньше доли minScore трёхбуквенных сочетаний запроса.
+// Разумные значения - от 0.5 до 0.8.
+func SearchFuzzy(minScore float64) SearchOption {
+	return func(o *searchOptions) {
+		o.fuzzy = minScore
+	}
+}
+
+// SearchLimit - возвращает не больше n лучших результатов.
+func SearchLimit(n int) SearchOption {
+	return func(o *searchOptions) {
+		o.limit = n
+	}
+}
+
+// SearchLibrary - ищет также среди треков библиотеки, которых нет в активном плейлисте.
+func SearchLibrary() SearchOption {
+	return func(o *searchOptions) {
+		o.library = true
+	}
+}
+
+// Search - ищет песни активного плейлиста, в названии, исполнителе или альбоме
+// которых встречается query без учёта регистра. Результаты отсортированы
+// по убыванию Score, а при равном Score - по порядку плейлиста.
+// Поиск идёт по индексу библиотеки, поэтому не сравнивает строки всех песен.
+func (p *playerImpl) Search(ctx context.Context, query string, opts ...SearchOption) ([]SearchResult, error) {
+	var o searchOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.fuzzy < 0 || o.fuzzy > 1 {
+		return nil, errors.New("fuzzy score must be in [0, 1]")
+	}
+	if o.limit < 0 {
+		return nil, errors.New("search limit is negative")
+	}
+
+	matches := p.library.match(query, o.fuzzy)
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	if err := p.rlock(ctx); err != nil {
+		return nil, err
+	}
+	var results []SearchResult
+	inPlaylist := make(map[TrackID]bool)
+	i := 0
+	for n := p.first(); n != nil; n = n.next() {
+		if m, ok := matches[n.track]; ok {
+			results = append(results, SearchResult{ID: n.id, Index: i, TrackID: n.track, Song: *n.song, Score: m.score})
+			inPlaylist[n.track] = true
+		}
+		i++
+	}
+	p.mu.RUnlock()
+
+	if o.library {
+		for id, m := range matches {
+			if !inPlaylist[id] {
+				results = append(results, SearchResult{Index: -1, TrackID: id, Song: m.song, Score: m.score})
+			}
+		}
+	}
+
+	slices.SortStableFunc(results, func(a, b SearchResult) int {
+		switch {
+		case a.Score != b.Score:
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		case a.Index != b.Index:
+			// треки только из библиотеки - после песен плейлиста
+			if a.Index < 0 || b.Index < 0 {
+				return b.Index - a.Index
+			}
+			return a.Index - b.Index
+		default:
+			return int(a.TrackID) - int(b.TrackID)
+		}
+	})
+
+	if o.limit > 0 && len(results) > o.limit {
+		results = results[:o.limit]
+	}
+
+	return results, nil
+}
+
+// libraryMatch - трек, подходящий под запрос.
+type libraryMatch struct {
+	song  Song
+	score float64
+}
+
+// searchIndex - триграммный индекс библиотеки по названию, исполнителю и альбому.
+type searchIndex struct {
+	// fields - поля трека в нижнем регистре
+	fields map[TrackID][3]string
+	// grams - треки по трёхбуквенным сочетаниям их полей
+	grams map[string]map[TrackID]struct{}
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		fields: make(map[TrackID][3]string),
+		grams:  make(map[string]map[TrackID]struct{}),
+	}
+}
+
+func (si *searchIndex) add(id TrackID, song Song) {
+	fields := [3]string{strings.ToLower(song.Name), strings.ToLower(song.Artist), strings.ToLower(song.Album)}
+	si.fields[id] = fields
+
+	for _, f := range fields {
+		for _, g := range trigrams(f) {
+			ids := si.grams[g]
+			if ids == nil {
+				ids = make(map[TrackID]struct{})
+				si.grams[g] = ids
+			}
+			ids[id] = struct{}{}
+		}
+	}
+}
+
+func (si *searchIndex) remove(id TrackID) {
+	for _, f := range si.fields[id] {
+		for _, g := range trigrams(f) {
+			delete(si.grams[g], id)
+			if len(si.grams[g]) == 0 {
+				delete(si.grams, g)
+			}
+		}
+	}
+	delete(si.fields, id)
+}
+
+// match - возвращает треки, в полях которых встречается query, с оценкой 1,
+// и, если fuzzy больше нуля, треки с долей общих триграмм не меньше fuzzy.
+func (si *searchIndex) match(query string, fuzzy float64) map[TrackID]float64 {
+	query = strings.ToLower(query)
+	contains := func(id TrackID) bool {
+		f := si.fields[id]
+		return strings.Contains(f[0], query) || strings.Contains(f[1], query) || strings.Contains(f[2], query)
+	}
+
+	res := make(map[TrackID]float64)
+	grams := trigrams(query)
+	// короткий запрос не разбивается на триграммы
+	if len(grams) == 0 {
+		for id := range si.fields {
+			if contains(id) {
+				res[id] = 1
+			}
+		}
+		return res
+	}
+
+	// точное вхождение содержит все триграммы запроса,
+	// поэтому достаточно проверить самый короткий их список
+	if fuzzy == 0 {
+		rarest := si.grams[grams[0]]
+		for _, g := range grams[1:] {
+			if len(si.grams[g]) < len(rarest) {
+				rarest = si.grams[g]
+			}
+		}
+		for id := range rarest {
+			if contains(id) {
+				res[id] = 1
+			}
+		}
+		return res
+	}
+
+	shared := make(map[TrackID]int)
+	for _, g := range grams {
+		for id := range si.grams[g] {
+			shared[id]++
+		}
+	}
+
+	for id, n := range shared {
+		score := float64(n) / float64(len(grams))
+		switch {
+		case n == len(grams) && contains(id):
+			res[id] = 1
+		case score >= fuzzy:
+			res[id] = min(score, 0.99)
+		}
+	}
+
+	return res
+}
+
+// trigrams - возвращает различные сочетания из трёх подряд идущих символов s.
+func trigrams(s string) []string {
+	runes := []rune(s)
+	if len(runes) < 3 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(runes)-2)
+	grams := make([]string, 0, len(runes)-2)
+	for i := 0; i+3 <= len(runes); i++ {
+		g := string(runes[i : i+3])
+		if !seen[g] {
+			seen[g] = true
+			grams = append(grams, g)
+		}
+	}
+
+	return grams
+}
@@ -0,0 +1,109 @@
+package player
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Search(t *testing.T) {
+	ctx := context.Background()
+	songs := []Song{
+		{Name: "Karma Police", Artist: "Radiohead", Album: "OK Computer", Duration: time.Minute},
+		{Name: "Creep", Artist: "Radiohead", Album: "Pablo Honey", Duration: time.Minute},
+		{Name: "Yellow", Artist: "Coldplay", Album: "Parachutes", Duration: time.Minute},
+		{Name: "Кино - Группа крови", Artist: "Кино", Duration: time.Minute},
+	}
+	pl, _ := New(WithSongs(songs...))
+	indices := func(res []SearchResult) []int {
+		var idx []int
+		for _, r := range res {
+			idx = append(idx, r.Index)
+		}
+		return idx
+	}
+
+	t.Run("substring", func(t *testing.T) {
+		res, err := pl.Search(ctx, "radioHEAD")
+		td.CmpNoError(t, err)
+		td.Cmp(t, indices(res), []int{0, 1})
+		td.Cmp(t, res[1], td.SStruct(SearchResult{Index: 1, Song: songs[1], Score: 1}, td.StructFields{
+			"ID":      td.NotZero(),
+			"TrackID": td.NotZero(),
+		}))
+
+		res, _ = pl.Search(ctx, "computer")
+		td.Cmp(t, indices(res), []int{0}, "по альбому")
+
+		res, _ = pl.Search(ctx, "кров")
+		td.Cmp(t, indices(res), []int{3}, "без учёта регистра в юникоде")
+
+		res, _ = pl.Search(ctx, "ye")
+		td.Cmp(t, indices(res), []int{2}, "короткий запрос")
+
+		res, _ = pl.Search(ctx, "")
+		td.CmpNil(t, res)
+	})
+
+	t.Run("ids feed playlist methods", func(t *testing.T) {
+		res, _ := pl.Search(ctx, "yellow")
+		td.CmpNoError(t, pl.PlayAt(ctx, res[0].Index))
+		td.Cmp(t, pl.Status(ctx).SongID, res[0].ID)
+		_ = pl.Pause(ctx)
+	})
+
+	t.Run("fuzzy", func(t *testing.T) {
+		res, _ := pl.Search(ctx, "radiohaed")
+		td.CmpEmpty(t, res, "опечатка без нечёткого поиска")
+
+		res, _ = pl.Search(ctx, "radiohaed", SearchFuzzy(0.5))
+		td.Cmp(t, indices(res), []int{0, 1})
+		td.Cmp(t, res[0].Score, td.Between(0.5, 1.0, td.BoundsInOut))
+
+		res, _ = pl.Search(ctx, "creep", SearchFuzzy(0.3))
+		td.Cmp(t, res[0].Index, 1, "точное совпадение первым")
+		td.Cmp(t, res[0].Score, 1.0)
+	})
+
+	t.Run("limit and library", func(t *testing.T) {
+		res, _ := pl.Search(ctx, "radiohead", SearchLimit(1))
+		td.Cmp(t, indices(res), []int{0})
+
+		id, _ := pl.Library().Add(Song{Name: "No Surprises", Artist: "Radiohead", Duration: time.Minute})
+		res, _ = pl.Search(ctx, "radiohead", SearchLibrary())
+		td.Cmp(t, indices(res), []int{0, 1, -1})
+		td.Cmp(t, res[2].TrackID, id)
+		td.Cmp(t, res[2].ID, SongID(0))
+
+		td.CmpNoError(t, pl.Library().Remove(id))
+		res, _ = pl.Search(ctx, "surprises", SearchLibrary())
+		td.CmpEmpty(t, res, "удалённый трек не находится")
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := pl.Search(ctx, "a", SearchFuzzy(2))
+		td.CmpError(t, err)
+		_, err = pl.Search(ctx, "a", SearchLimit(-1))
+		td.CmpError(t, err)
+	})
+}
+
+func BenchmarkPlayerImpl_Search(b *testing.B) {
+	ctx := context.Background()
+	pl, _ := NewPlayer()
+
+	songs := make([]Song, 100_000)
+	for i := range songs {
+		songs[i] = Song{Name: "song " + strconv.Itoa(i), Artist: "artist " + strconv.Itoa(i%1000), Duration: time.Second}
+	}
+	_ = pl.AddSongs(ctx, songs...)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = pl.Search(ctx, "artist 42")
+	}
+}
@@ -0,0 +1,252 @@
+package player
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// Sequencer - выбирает, какая из оставшихся песен будет играть после текущей.
+// Вызывается под блокировкой плеера, когда песня начинает играть,
+// поэтому должен отвечать быстро и не обращаться к плееру.
+type Sequencer interface {
+	// Next - возвращает индекс в remaining песни, которая будет играть после current.
+	// remaining - не больше sequenceWindow ещё не сыгранных в этом проходе песен
+	// в порядке плейлиста, начиная после текущей, всегда непустой.
+	// Индекс вне remaining оставляет порядок плейлиста.
+	Next(current Song, remaining []Song) int
+}
+
+// SequencerFunc - функция, удовлетворяющая интерфейсу Sequencer.
+type SequencerFunc func(current Song, remaining []Song) int
+
+func (f SequencerFunc) Next(current Song, remaining []Song) int {
+	return f(current, remaining)
+}
+
+// Sequential - песни играют в порядке плейлиста, поведение по умолчанию.
+func Sequential() Sequencer {
+	return SequencerFunc(func(Song, []Song) int { return 0 })
+}
+
+// shuffle - Sequencer Shuffle.
+type shuffle struct{}
+
+// Shuffle - следующая песня выбирается случайно из оставшихся.
+// В плеере выбор делается генератором WithRandSource.
+func Shuffle() Sequencer {
+	return shuffle{}
+}
+
+func (shuffle) Next(_ Song, remaining []Song) int {
+	return rand.Intn(len(remaining))
+}
+
+// BestMatch - следующей играет песня с наибольшей оценкой score, например
+// по близости темпа и тональности к текущей. При равных оценках - ближайшая.
+func BestMatch(score func(current, candidate Song) float64) Sequencer {
+	return SequencerFunc(func(current Song, remaining []Song) int {
+		best, bestScore := 0, score(current, remaining[0])
+		for i, s := range remaining[1:] {
+			if v := score(current, s); v > bestScore {
+				best, bestScore = i+1, v
+			}
+		}
+		return best
+	})
+}
+
+// WithSequencer - задаёт выбор следующей песни. Порядок плейлиста не меняется:
+// выбранная песня запоминается и играет сразу после текущей.
+func WithSequencer(s Sequencer) Option {
+	return func(p *playerImpl) error {
+		if s == nil {
+			return errors.New("sequencer is nil")
+		}
+
+		// WeightedRandom отсчитывает время по часам плеера
+		if w, ok := s.(*weightedRandom); ok {
+			s = &weightedRandom{weight: w.weight, now: p.now}
+		}
+
+		p.sequencer = s
+		return nil
+	}
+}
+
+// sequenceWindow - сколько оставшихся песен получает Sequencer.Next.
+const sequenceWindow = 100
+
+// SequenceState - сериализуемый выбор секвенсора в активном плейлисте.
+type SequenceState struct {
+	// Next - индекс песни, которая играет после текущей, -1 - после текущей проход кончается
+	Next int `json:"next"`
+	// Played - индексы песен, сыгранных в текущем проходе
+	Played []int `json:"played,omitempty"`
+}
+
+// sequenceLocked - выбирает песню, которая играет после текущей.
+// Для каждой песни выбор делается один раз.
+// Вызывается под блокировкой.
+func (p *playerImpl) sequenceLocked() {
+	cur := p.current
+	if p.sequencer == nil || cur == nil || p.sequenced == cur || !p.contains(cur) {
+		return
+	}
+	p.sequenced = cur
+
+	// WeightedRandom выбирает из всего плейлиста, в том числе уже сыгранные песни
+	if weighted, ok := p.sequencer.(*weightedRandom); ok {
+		p.chosen = p.weightedLocked(weighted)
+	} else {
+		if p.played == nil {
+			p.played = make(map[*playerNode]struct{})
+		}
+		p.played[cur] = struct{}{}
+
+		p.chosen = p.sequencedLocked(cur, p.played)
+		// проход кончился, следующий начнётся со всеми песнями
+		if p.chosen == nil {
+			p.played = nil
+		}
+	}
+
+	// следующая песня изменилась
+	if p.chosen != cur.next() {
+		p.prepared = nil
+	}
+}
+
+// sequencedLocked - песня после cur, которую выбрал секвенсор, если в проходе
+// сыграны песни played, nil - все песни сыграны.
+// Вызывается под блокировкой.
+func (p *playerImpl) sequencedLocked(cur *playerNode, played map[*playerNode]struct{}) *playerNode {
+	// Shuffle выбирает из всех оставшихся, не копируя их
+	if _, ok := p.sequencer.(shuffle); ok {
+		var count int
+		p.eachUnplayedLocked(cur, played, func(*playerNode) bool {
+			count++
+			return true
+		})
+		if count == 0 {
+			return nil
+		}
+
+		var node *playerNode
+		i := p.rand.Intn(count)
+		p.eachUnplayedLocked(cur, played, func(n *playerNode) bool {
+			node = n
+			i--
+			return i >= 0
+		})
+		return node
+	}
+
+	var nodes []*playerNode
+	var remaining []Song
+	p.eachUnplayedLocked(cur, played, func(n *playerNode) bool {
+		nodes = append(nodes, n)
+		remaining = append(remaining, *n.song)
+		return len(nodes) < sequenceWindow
+	})
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	i := p.sequencer.Next(*cur.song, remaining)
+	if i < 0 || i >= len(nodes) {
+		i = 0
+	}
+
+	return nodes[i]
+}
+
+// eachUnplayedLocked - вызывает fn для песен не из played в порядке плейлиста
+// начиная после cur, пока fn возвращает true.
+// Вызывается под блокировкой.
+func (p *playerImpl) eachUnplayedLocked(cur *playerNode, played map[*playerNode]struct{}, fn func(n *playerNode) bool) {
+	for n := cur.next(); n != nil; n = n.next() {
+		if _, ok := played[n]; !ok && !fn(n) {
+			return
+		}
+	}
+	for n := p.first(); n != nil && n != cur; n = n.next() {
+		if _, ok := played[n]; !ok && !fn(n) {
+			return
+		}
+	}
+}
+
+// afterLocked - песня, которая играет после node: выбранная секвенсором
+// или следующая в плейлисте, nil если после node воспроизведение кончается.
+// При EdgeWrap после последней песни прохода снова играет первая.
+// Вызывается под блокировкой.
+func (p *playerImpl) afterLocked(node *playerNode) *playerNode {
+	var next *playerNode
+	// выбранную песню могли удалить из плейлиста
+	if p.sequencer != nil && node == p.sequenced && (p.chosen == nil || p.contains(p.chosen)) {
+		next = p.chosen
+	} else {
+		next = node.next()
+	}
+
+	if next == nil && p.edge == EdgeWrap && p.contains(node) {
+		return p.first()
+	}
+
+	return next
+}
+
+// resetSequenceLocked - забывает выбор секвенсора и сыгранные в проходе песни.
+// Вызывается под блокировкой.
+func (p *playerImpl) resetSequenceLocked() {
+	p.sequenced, p.chosen, p.played = nil, nil, nil
+}
+
+// sequenceStateLocked - выбор секвенсора для текущей песни плейлиста pl,
+// nil если выбор для неё не сделан.
+// Вызывается под блокировкой.
+func (p *playerImpl) sequenceStateLocked(pl *playlist) *SequenceState {
+	if p.sequencer == nil || p.sequenced == nil || p.sequenced != pl.current {
+		return nil
+	}
+
+	st := &SequenceState{Next: -1}
+	for i, n := 0, pl.first(); n != nil; i, n = i+1, n.next() {
+		if n == p.chosen {
+			st.Next = i
+		}
+		if _, ok := p.played[n]; ok {
+			st.Played = append(st.Played, i)
+		}
+	}
+
+	return st
+}
+
+// restoreSequenceLocked - восстанавливает выбор секвенсора для текущей песни
+// активного плейлиста. Индексы вне плейлиста пропускаются.
+// Вызывается под блокировкой.
+func (p *playerImpl) restoreSequenceLocked(st *SequenceState) {
+	p.resetSequenceLocked()
+	if st == nil || p.current == nil {
+		return
+	}
+
+	played := make(map[int]bool, len(st.Played))
+	for _, i := range st.Played {
+		played[i] = true
+	}
+
+	p.sequenced = p.current
+	for i, n := 0, p.first(); n != nil; i, n = i+1, n.next() {
+		if i == st.Next {
+			p.chosen = n
+		}
+		if played[i] {
+			if p.played == nil {
+				p.played = make(map[*playerNode]struct{})
+			}
+			p.played[n] = struct{}{}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Sequencer(t *testing.T) {
+	ctx := context.Background()
+	songs := []Song{
+		{Name: "a", Duration: time.Minute},
+		{Name: "b", Duration: time.Minute},
+		{Name: "c", Duration: time.Minute},
+		{Name: "d", Duration: time.Minute},
+	}
+	played := func(pl Player) []string {
+		var res []string
+		for range songs {
+			res = append(res, pl.Status(ctx).Song.Name)
+			_ = pl.Next(ctx)
+		}
+		_ = pl.Pause(ctx)
+		return res
+	}
+
+	t.Run("sequential", func(t *testing.T) {
+		pl, _ := New(WithSequencer(Sequential()), WithSongs(songs...))
+		_ = pl.Play(ctx)
+
+		td.Cmp(t, played(pl), []string{"a", "b", "c", "d"})
+	})
+
+	t.Run("best match", func(t *testing.T) {
+		// следующей играет песня с ближайшим темпом
+		bpm := map[string]float64{"a": 120, "b": 90, "c": 128, "d": 124}
+		seq := BestMatch(func(cur, cand Song) float64 { return -math.Abs(bpm[cur.Name] - bpm[cand.Name]) })
+		pl, _ := New(WithSequencer(seq), WithSongs(songs...))
+		_ = pl.Play(ctx)
+		td.Cmp(t, pl.PeekNext(ctx, 1), []Song{songs[3]}, "следующая выбрана при запуске")
+
+		td.Cmp(t, played(pl), []string{"a", "d", "c", "b"})
+		td.Cmp(t, names(pl), []string{"a", "b", "c", "d"}, "порядок плейлиста не меняется")
+	})
+
+	t.Run("shuffle", func(t *testing.T) {
+		pl, _ := New(WithSequencer(Shuffle()), WithSongs(songs...))
+		_ = pl.Play(ctx)
+
+		res := played(pl)
+		td.Cmp(t, res[0], "a", "первая песня не меняется")
+		sort.Strings(res)
+		td.Cmp(t, res, []string{"a", "b", "c", "d"}, "каждая песня сыграна один раз")
+		td.Cmp(t, names(pl), []string{"a", "b", "c", "d"}, "порядок плейлиста не меняется")
+	})
+
+	t.Run("played songs are skipped", func(t *testing.T) {
+		// каждый раз выбирается последняя из оставшихся
+		seq := SequencerFunc(func(_ Song, remaining []Song) int { return len(remaining) - 1 })
+		pl, _ := New(WithSequencer(seq), WithSongs(songs...))
+		_ = pl.PlayAt(ctx, 1)
+
+		td.Cmp(t, played(pl)[:3], []string{"b", "a", "d"}, "после b идут песни перед ней")
+	})
+
+	t.Run("window", func(t *testing.T) {
+		many := make([]Song, sequenceWindow+50)
+		for i := range many {
+			many[i] = minuteSong(fmt.Sprintf("s%d", i))
+		}
+		var got int
+		seq := SequencerFunc(func(_ Song, remaining []Song) int {
+			got = len(remaining)
+			return 0
+		})
+		pl, _ := New(WithSequencer(seq), WithSongs(many...))
+		td.CmpNoError(t, pl.Play(ctx))
+		defer pl.Pause(ctx)
+
+		td.Cmp(t, got, sequenceWindow, "секвенсор получает не весь плейлист")
+	})
+
+	t.Run("out of range keeps order", func(t *testing.T) {
+		seq := SequencerFunc(func(_ Song, remaining []Song) int { return len(remaining) })
+		pl, _ := New(WithSequencer(seq), WithSongs(songs...))
+		_ = pl.Play(ctx)
+
+		td.Cmp(t, played(pl), []string{"a", "b", "c", "d"})
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		_, err := New(WithSequencer(nil))
+		td.CmpString(t, err, "sequencer is nil")
+	})
+}
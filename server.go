@@ -0,0 +1,634 @@
+package player
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnauthenticated - запрос к HTTP API без действительных учётных данных.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Role - роль пользователя HTTP API.
+type Role int
+
+const (
+	// RoleListener - слушатель: смотрит состояние, добавляет песни и голосует за пропуск
+	RoleListener Role = iota
+	// RoleAdmin - администратор: вдобавок управляет воспроизведением, удаляет и переставляет песни
+	RoleAdmin
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleListener:
+		return "listener"
+	case RoleAdmin:
+		return "admin"
+	default:
+		return "Role(" + strconv.Itoa(int(r)) + ")"
+	}
+}
+
+// Principal - пользователь, от имени которого выполняется запрос.
+type Principal struct {
+	// UserID - ID пользователя, используется в AddSongAs и VoteSkip
+	UserID string
+	// Role - роль пользователя
+	Role Role
+}
+
+type principalKey struct{}
+
+// PrincipalFromContext - возвращает пользователя, определённого Authenticator для запроса.
+// Доступно в обработчиках и middleware, подключённых через WithMiddleware.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	pr, ok := ctx.Value(principalKey{}).(Principal)
+	return pr, ok
+}
+
+// Authenticator - определяет пользователя по HTTP-запросу.
+// Для запроса без учётных данных или с неверными должен вернуть ErrUnauthenticated.
+type Authenticator interface {
+	Authenticate(r *http.Request) (Principal, error)
+}
+
+// AuthenticatorFunc - функция, реализующая Authenticator.
+type AuthenticatorFunc func(r *http.Request) (Principal, error)
+
+func (f AuthenticatorFunc) Authenticate(r *http.Request) (Principal, error) {
+	return f(r)
+}
+
+// TokenAuth - Authenticator по заголовку "Authorization: Bearer <token>".
+// tokens сопоставляет токен пользователю и копируется.
+func TokenAuth(tokens map[string]Principal) Authenticator {
+	known := make(map[string]Principal, len(tokens))
+	for token, pr := range tokens {
+		known[token] = pr
+	}
+
+	return AuthenticatorFunc(func(r *http.Request) (Principal, error) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			return Principal{}, ErrUnauthenticated
+		}
+
+		pr, ok := known[strings.TrimSpace(token)]
+		if !ok {
+			return Principal{}, ErrUnauthenticated
+		}
+
+		return pr, nil
+	})
+}
+
+// CORSConfig - настройки CORS для HTTP API.
+type CORSConfig struct {
+	// AllowedOrigins - разрешённые источники, "*" разрешает любой
+	AllowedOrigins []string
+	// AllowedHeaders - разрешённые заголовки запроса, по умолчанию Authorization и Content-Type
+	AllowedHeaders []string
+	// MaxAge - сколько браузер может кешировать ответ на preflight, 0 - не указывать
+	MaxAge time.Duration
+}
+
+// allows - разрешён ли источник origin.
+func (c *CORSConfig) allows(origin string) bool {
+	for _, o := range c.AllowedOrigins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HTTPOption - настройка HTTP API.
+type HTTPOption func(s *httpServer) error
+
+// WithAuth - проверяет каждый запрос через auth.
+// Без WithAuth любой запрос выполняется с правами администратора,
+// поэтому открывать такой обработчик можно только в доверенной сети.
+func WithAuth(auth Authenticator) HTTPOption {
+	return func(s *httpServer) error {
+		if auth == nil {
+			return errors.New("authenticator is nil")
+		}
+
+		s.auth = auth
+		return nil
+	}
+}
+
+// WithMiddleware - оборачивает API в middleware, первое из них выполняется раньше остальных.
+// Middleware выполняются после аутентификации, пользователь доступен через PrincipalFromContext.
+func WithMiddleware(mw ...func(http.Handler) http.Handler) HTTPOption {
+	return func(s *httpServer) error {
+		for _, m := range mw {
+			if m == nil {
+				return errors.New("middleware is nil")
+			}
+		}
+
+		s.middleware = append(s.middleware, mw...)
+		return nil
+	}
+}
+
+// WithCORS - разрешает браузерам обращаться к API с других источников.
+func WithCORS(cfg CORSConfig) HTTPOption {
+	return func(s *httpServer) error {
+		if len(cfg.AllowedOrigins) == 0 {
+			return errors.New("no allowed origins")
+		}
+
+		if cfg.MaxAge < 0 {
+			return errors.New("cors max age is negative")
+		}
+
+		if len(cfg.AllowedHeaders) == 0 {
+			cfg.AllowedHeaders = []string{"Authorization", "Content-Type"}
+		}
+
+		s.cors = &cfg
+		return nil
+	}
+}
+
+// anonymous - пользователь запросов, когда WithAuth не задан.
+var anonymous = Principal{UserID: "anonymous", Role: RoleAdmin}
+
+// httpServer - HTTP API плеера.
+type httpServer struct {
+	p          *playerImpl
+	auth       Authenticator
+	middleware []func(http.Handler) http.Handler
+	cors       *CORSConfig
+}
+
+// HTTPHandler - возвращает REST+JSON API плеера.
+//
+// Слушателю доступны:
+//
+//	GET    /status             - состояние воспроизведения
+//	GET    /queue              - страница очереди активного плейлиста, QueuePage:
+//	                             ?offset=n&limit=n или ?cursor=id&limit=n
+//	POST   /queue              - добавить песню от своего имени
+//	POST   /listeners          - зарегистрироваться слушателем
+//	POST   /vote               - проголосовать за пропуск
+//	GET    /eq                 - эквалайзер и его предустановки, EQResponse
+//
+// Администратору вдобавок:
+//
+//	POST   /play, /pause, /next, /prev
+//	PUT    /volume             - {"volume": 0..100}
+//	PUT    /eq                 - {"bands": [...]} или {"preset": "rock"}
+//	DELETE /queue/{id}         - удалить песню
+//	POST   /queue/{id}/move    - {"index": n}, переставить песню
+//	POST   /queue/{id}/play    - играть песню
+//
+// Ошибки возвращаются как ErrorResponse с подходящим HTTP-статусом.
+// Описание API в формате OpenAPI 3 доступно всем по GET /openapi.json, см. OpenAPI.
+// Проверки GET /healthz и GET /readyz тоже доступны всем и возвращают
+// HealthReport от Healthz и Readyz со статусом 200 или 503, если проверка не пройдена.
+func (p *playerImpl) HTTPHandler(opts ...HTTPOption) (http.Handler, error) {
+	s := &httpServer{p: p}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+
+	var h http.Handler = http.HandlerFunc(s.route)
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		h = s.middleware[i](h)
+	}
+
+	h = s.withHealth(s.withSpec(s.authenticate(h)))
+	if s.cors != nil {
+		h = s.withCORS(h)
+	}
+
+	return h, nil
+}
+
+// authenticate - определяет пользователя и кладёт его в контекст запроса.
+func (s *httpServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pr := anonymous
+		if s.auth != nil {
+			var err error
+			if pr, err = s.auth.Authenticate(r); err != nil {
+				writeHTTPError(w, err)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(ContextWithPrincipal(r.Context(), pr)))
+	})
+}
+
+// withSpec - отдаёт описание API по GET /openapi.json без аутентификации,
+// чтобы клиенты можно было сгенерировать до получения токена.
+func (s *httpServer) withSpec(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openapi.json" || r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(openAPI)
+	})
+}
+
+// withHealth - отвечает на GET /healthz и /readyz без аутентификации,
+// чтобы их могли опрашивать балансировщики и оркестраторы.
+func (s *httpServer) withHealth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var check func(ctx context.Context) HealthReport
+		switch r.URL.Path {
+		case "/healthz":
+			check = s.p.Healthz
+		case "/readyz":
+			check = s.p.Readyz
+		}
+		if check == nil || r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		report := check(r.Context())
+		code := http.StatusOK
+		if !report.OK {
+			code = http.StatusServiceUnavailable
+		}
+		writeJSON(w, code, report)
+	})
+}
+
+// withCORS - добавляет заголовки CORS и отвечает на preflight-запросы до аутентификации.
+func (s *httpServer) withCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		if !s.cors.allows(origin) {
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE")
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(s.cors.AllowedHeaders, ", "))
+		if s.cors.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(s.cors.MaxAge/time.Second)))
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// route - разбирает путь запроса и вызывает обработчик.
+func (s *httpServer) route(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case len(parts) == 1 && parts[0] == "status":
+		s.handle(w, r, http.MethodGet, RoleListener, s.status)
+	case len(parts) == 1 && parts[0] == "queue":
+		switch r.Method {
+		case http.MethodGet:
+			s.handle(w, r, http.MethodGet, RoleListener, s.queue)
+		default:
+			s.handle(w, r, http.MethodPost, RoleListener, s.enqueue)
+		}
+	case len(parts) == 1 && parts[0] == "listeners":
+		s.handle(w, r, http.MethodPost, RoleListener, s.listen)
+	case len(parts) == 1 && parts[0] == "vote":
+		s.handle(w, r, http.MethodPost, RoleListener, s.vote)
+	case len(parts) == 1 && parts[0] == "volume":
+		s.handle(w, r, http.MethodPut, RoleAdmin, s.volume)
+	case len(parts) == 1 && parts[0] == "eq":
+		switch r.Method {
+		case http.MethodGet:
+			s.handle(w, r, http.MethodGet, RoleListener, s.eq)
+		default:
+			s.handle(w, r, http.MethodPut, RoleAdmin, s.setEQ)
+		}
+	case len(parts) == 1 && parts[0] == "play":
+		s.handle(w, r, http.MethodPost, RoleAdmin, s.control(s.p.Play))
+	case len(parts) == 1 && parts[0] == "pause":
+		s.handle(w, r, http.MethodPost, RoleAdmin, s.control(s.p.Pause))
+	case len(parts) == 1 && parts[0] == "next":
+		s.handle(w, r, http.MethodPost, RoleAdmin, s.control(s.p.Next))
+	case len(parts) == 1 && parts[0] == "prev":
+		s.handle(w, r, http.MethodPost, RoleAdmin, s.control(s.p.Prev))
+	case len(parts) == 2 && parts[0] == "queue":
+		s.handleSong(w, r, parts[1], http.MethodDelete, s.remove)
+	case len(parts) == 3 && parts[0] == "queue" && parts[2] == "move":
+		s.handleSong(w, r, parts[1], http.MethodPost, s.move)
+	case len(parts) == 3 && parts[0] == "queue" && parts[2] == "play":
+		s.handleSong(w, r, parts[1], http.MethodPost, s.playSong)
+	default:
+		writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "not found"})
+	}
+}
+
+// handle - проверяет метод и роль пользователя и вызывает обработчик.
+func (s *httpServer) handle(w http.ResponseWriter, r *http.Request, method string, role Role, h func(w http.ResponseWriter, r *http.Request, pr Principal)) {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	pr, _ := PrincipalFromContext(r.Context())
+	if pr.Role < role {
+		writeJSON(w, http.StatusForbidden, ErrorResponse{Error: "forbidden"})
+		return
+	}
+
+	h(w, r, pr)
+}
+
+// handleSong - как handle для администраторских операций над песней очереди.
+func (s *httpServer) handleSong(w http.ResponseWriter, r *http.Request, rawID string, method string, h func(w http.ResponseWriter, r *http.Request, id SongID)) {
+	id, err := strconv.ParseUint(rawID, 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "not found"})
+		return
+	}
+
+	s.handle(w, r, method, RoleAdmin, func(w http.ResponseWriter, r *http.Request, _ Principal) {
+		h(w, r, SongID(id))
+	})
+}
+
+func (s *httpServer) status(w http.ResponseWriter, r *http.Request, _ Principal) {
+	writeJSON(w, http.StatusOK, StatusResponse{Status: s.p.Status(r.Context()), State: s.p.State(r.Context())})
+}
+
+// Размер страницы GET /queue.
+const (
+	defaultQueuePage = 100
+	maxQueuePage     = 1000
+)
+
+func (s *httpServer) queue(w http.ResponseWriter, r *http.Request, _ Principal) {
+	q := r.URL.Query()
+	offset, limit, cursor := 0, defaultQueuePage, uint64(0)
+
+	var err error
+	if v := q.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "offset must be a non-negative integer"})
+			return
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 || limit > maxQueuePage {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "limit must be in range [1, " + strconv.Itoa(maxQueuePage) + "]"})
+			return
+		}
+	}
+	if v := q.Get("cursor"); v != "" {
+		if cursor, err = strconv.ParseUint(v, 10, 64); err != nil || cursor == 0 {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "cursor must be a song id"})
+			return
+		}
+	}
+
+	var page QueuePage
+	if cursor != 0 {
+		page, err = s.p.QueueAfter(r.Context(), SongID(cursor), limit)
+	} else {
+		page, err = s.p.QueuePage(r.Context(), offset, limit)
+	}
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *httpServer) enqueue(w http.ResponseWriter, r *http.Request, pr Principal) {
+	var song Song
+	if !readJSON(w, r, &song) {
+		return
+	}
+
+	id, err := s.p.AddSongAs(r.Context(), pr.UserID, song)
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, AddSongResponse{ID: id})
+}
+
+func (s *httpServer) listen(w http.ResponseWriter, r *http.Request, pr Principal) {
+	if err := s.p.AddListener(r.Context(), pr.UserID); err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *httpServer) vote(w http.ResponseWriter, r *http.Request, pr Principal) {
+	skipped, err := s.p.VoteSkip(playbackContext(r), pr.UserID)
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, VoteResponse{Skipped: skipped})
+}
+
+func (s *httpServer) volume(w http.ResponseWriter, r *http.Request, _ Principal) {
+	var body VolumeRequest
+	if !readJSON(w, r, &body) {
+		return
+	}
+
+	if body.Volume == nil || *body.Volume < 0 || *body.Volume > MaxVolume {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "volume must be in range [0, " + strconv.Itoa(MaxVolume) + "]"})
+		return
+	}
+
+	if err := s.p.SetVolume(r.Context(), *body.Volume); err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *httpServer) eq(w http.ResponseWriter, r *http.Request, _ Principal) {
+	bands := s.p.EQ(r.Context())
+	if bands == nil {
+		bands = []float64{}
+	}
+
+	writeJSON(w, http.StatusOK, EQResponse{Bands: bands, Presets: EQPresets()})
+}
+
+func (s *httpServer) setEQ(w http.ResponseWriter, r *http.Request, _ Principal) {
+	var body EQRequest
+	if !readJSON(w, r, &body) {
+		return
+	}
+
+	var err error
+	switch {
+	case (body.Bands == nil) == (body.Preset == ""):
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "exactly one of bands and preset is required"})
+		return
+	case body.Preset != "":
+		err = s.p.SetEQPreset(r.Context(), body.Preset)
+	default:
+		if err := validateEQ(body.Bands); err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		err = s.p.SetEQ(r.Context(), body.Bands)
+	}
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// playbackContext - контекст для команд, которые могут запустить воспроизведение.
+// Горутина воспроизведения живёт, пока не завершится её контекст, поэтому он
+// не должен отменяться вместе с запросом, но сохраняет его значения.
+func playbackContext(r *http.Request) context.Context {
+	return context.WithoutCancel(r.Context())
+}
+
+// control - обработчик команды управления воспроизведением без аргументов.
+func (s *httpServer) control(cmd func(ctx context.Context) error) func(w http.ResponseWriter, r *http.Request, _ Principal) {
+	return func(w http.ResponseWriter, r *http.Request, _ Principal) {
+		if err := cmd(playbackContext(r)); err != nil {
+			writeHTTPError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *httpServer) remove(w http.ResponseWriter, r *http.Request, id SongID) {
+	if err := s.p.RemoveSong(playbackContext(r), id); err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *httpServer) move(w http.ResponseWriter, r *http.Request, id SongID) {
+	var body MoveRequest
+	if !readJSON(w, r, &body) {
+		return
+	}
+
+	if body.Index == nil || *body.Index < 0 {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "index must be non-negative"})
+		return
+	}
+
+	if err := s.p.MoveSong(r.Context(), id, *body.Index); err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *httpServer) playSong(w http.ResponseWriter, r *http.Request, id SongID) {
+	if err := s.p.PlayByID(playbackContext(r), id); err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// maxRequestBody - наибольший размер тела запроса к HTTP API.
+const maxRequestBody = 1 << 20
+
+// readJSON - разбирает тело запроса в v, при ошибке отвечает 400.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid request body: " + err.Error()})
+		return false
+	}
+
+	return true
+}
+
+// httpStatus - HTTP-статус для ошибки плеера.
+func httpStatus(err error) int {
+	var stateErr StateError
+	switch {
+	case errors.Is(err, ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrUnknownListener):
+		return http.StatusForbidden
+	case errors.Is(err, ErrSongNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrInvalidSong), errors.Is(err, ErrUnknownPreset):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrQueueLimit), errors.Is(err, ErrPlaylistFull), errors.Is(err, ErrRecentlyPlayed), errors.Is(err, ErrVotingDisabled), errors.As(err, &stateErr):
+		return http.StatusConflict
+	case errors.Is(err, ErrQuotaExceeded):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrClosed):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeHTTPError - отвечает ошибкой err.
+func writeHTTPError(w http.ResponseWriter, err error) {
+	status := httpStatus(err)
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	}
+
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
+
+// writeJSON - отвечает статусом status и телом v в JSON.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,338 @@
+package player
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// serve - выполняет запрос к обработчику и возвращает ответ.
+func serve(h http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// serveDetached - как serve, но отменяет контекст запроса после ответа,
+// как это делает http.Server.
+func serveDetached(h http.Handler, method, path, token string) *httptest.ResponseRecorder {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := httptest.NewRequest(method, path, nil).WithContext(ctx)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// decode - разбирает JSON-тело ответа.
+func decode(t *testing.T, w *httptest.ResponseRecorder) any {
+	t.Helper()
+
+	td.Cmp(t, w.Header().Get("Content-Type"), "application/json")
+	var v any
+	td.CmpNoError(t, json.Unmarshal(w.Body.Bytes(), &v))
+	return v
+}
+
+func TestPlayerImpl_HTTPHandler(t *testing.T) {
+	ctx := context.Background()
+	song := func(name string) Song { return Song{Name: name, Duration: 30 * time.Second} }
+	auth := WithAuth(TokenAuth(map[string]Principal{
+		"l": {UserID: "вася", Role: RoleListener},
+		"a": {UserID: "root", Role: RoleAdmin},
+	}))
+
+	t.Run("options", func(t *testing.T) {
+		pl, _ := NewPlayer()
+
+		_, err := pl.HTTPHandler(WithAuth(nil))
+		td.CmpString(t, err, "authenticator is nil")
+		_, err = pl.HTTPHandler(WithMiddleware(nil))
+		td.CmpString(t, err, "middleware is nil")
+		_, err = pl.HTTPHandler(WithCORS(CORSConfig{}))
+		td.CmpString(t, err, "no allowed origins")
+	})
+
+	t.Run("authentication", func(t *testing.T) {
+		pl, _ := NewPlayer(song("a"))
+		h, err := pl.HTTPHandler(auth)
+		td.CmpNoError(t, err)
+
+		w := serve(h, http.MethodGet, "/status", "", "")
+		td.Cmp(t, w.Code, http.StatusUnauthorized, "без токена")
+		td.Cmp(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+		td.CmpJSON(t, decode(t, w), `{"error": "unauthenticated"}`, nil)
+
+		w = serve(h, http.MethodGet, "/status", "чужой", "")
+		td.Cmp(t, w.Code, http.StatusUnauthorized, "неизвестный токен")
+
+		w = serve(h, http.MethodGet, "/status", "l", "")
+		td.Cmp(t, w.Code, http.StatusOK)
+		td.CmpJSON(t, decode(t, w), `{
+			"playlist": "default",
+			"song": {"name": "a", "duration": 30000000000},
+			"song_id": $1,
+			"position": 0,
+			"playing": false,
+			"volume": 100,
+			"muted": false,
+			"state": "stopped"
+		}`, []any{float64(pl.Queue(ctx)[0].ID)})
+	})
+
+	t.Run("roles", func(t *testing.T) {
+		out := &recordingOutput{}
+		pl, _ := NewPlayer(song("a"), song("b"))
+		td.CmpNoError(t, WithOutput(out)(pl))
+		h, _ := pl.HTTPHandler(auth)
+		queue := pl.Queue(ctx)
+		a, b := strconv.FormatUint(uint64(queue[0].ID), 10), strconv.FormatUint(uint64(queue[1].ID), 10)
+
+		for _, req := range [][2]string{
+			{http.MethodPost, "/play"},
+			{http.MethodPost, "/pause"},
+			{http.MethodPost, "/next"},
+			{http.MethodPost, "/prev"},
+			{http.MethodPut, "/volume"},
+			{http.MethodPut, "/eq"},
+			{http.MethodDelete, "/queue/" + a},
+			{http.MethodPost, "/queue/" + a + "/move"},
+			{http.MethodPost, "/queue/" + a + "/play"},
+		} {
+			td.Cmp(t, serve(h, req[0], req[1], "l", "{}").Code, http.StatusForbidden, "слушателю нельзя %s %s", req[0], req[1])
+		}
+		td.CmpEmpty(t, out.Calls(), "ничего не выполнено")
+
+		td.Cmp(t, serve(h, http.MethodPost, "/play", "a", "").Code, http.StatusNoContent)
+		td.CmpTrue(t, pl.Status(ctx).Playing)
+		td.Cmp(t, serve(h, http.MethodPost, "/next", "a", "").Code, http.StatusNoContent)
+		td.Cmp(t, pl.Status(ctx).Song.Name, "b")
+		td.Cmp(t, serve(h, http.MethodPost, "/pause", "a", "").Code, http.StatusNoContent)
+		td.Cmp(t, pl.State(ctx), StatePaused)
+
+		td.Cmp(t, serve(h, http.MethodPut, "/volume", "a", `{"volume": 40}`).Code, http.StatusNoContent)
+		td.Cmp(t, pl.Status(ctx).Volume, 40)
+		td.Cmp(t, serve(h, http.MethodPut, "/volume", "a", `{"volume": 400}`).Code, http.StatusBadRequest)
+		td.Cmp(t, serve(h, http.MethodPut, "/volume", "a", `{}`).Code, http.StatusBadRequest)
+
+		td.Cmp(t, serve(h, http.MethodPost, "/queue/"+b+"/move", "a", `{"index": 0}`).Code, http.StatusNoContent)
+		td.Cmp(t, names(pl), []string{"b", "a"})
+		td.Cmp(t, serve(h, http.MethodPost, "/queue/"+a+"/play", "a", "").Code, http.StatusNoContent)
+		td.Cmp(t, pl.Status(ctx).Song.Name, "a")
+
+		td.Cmp(t, serve(h, http.MethodDelete, "/queue/"+b, "a", "").Code, http.StatusNoContent)
+		td.Cmp(t, names(pl), []string{"a"})
+
+		w := serve(h, http.MethodDelete, "/queue/"+b, "a", "")
+		td.Cmp(t, w.Code, http.StatusNotFound, "песни уже нет")
+		td.CmpJSON(t, decode(t, w), `{"error": "song not found"}`, nil)
+	})
+
+	t.Run("queue", func(t *testing.T) {
+		pl, _ := NewPlayer(song("a"))
+		td.CmpNoError(t, WithUserQueueLimit(1)(pl))
+		h, _ := pl.HTTPHandler(auth)
+
+		w := serve(h, http.MethodPost, "/queue", "l", `{"name": "b", "duration": 30000000000}`)
+		td.Cmp(t, w.Code, http.StatusCreated)
+		var added float64
+		td.CmpJSON(t, decode(t, w), `{"id": $1}`, []any{td.Catch(&added, td.NotZero())})
+
+		w = serve(h, http.MethodPost, "/queue", "l", `{"name": "c", "duration": 30000000000}`)
+		td.Cmp(t, w.Code, http.StatusConflict, "лимит очереди")
+
+		td.Cmp(t, serve(h, http.MethodPost, "/queue", "a", `{"name": "", "duration": 1}`).Code, http.StatusBadRequest, "невалидная песня")
+		td.Cmp(t, serve(h, http.MethodPost, "/queue", "a", `{"name": "c", "bitrate": 1}`).Code, http.StatusBadRequest, "неизвестное поле")
+		td.Cmp(t, serve(h, http.MethodPost, "/queue", "a", `[`).Code, http.StatusBadRequest, "битый JSON")
+
+		w = serve(h, http.MethodGet, "/queue", "l", "")
+		td.Cmp(t, w.Code, http.StatusOK)
+		first := float64(pl.Queue(ctx)[0].ID)
+		td.CmpJSON(t, decode(t, w), `{
+			"songs": [
+				{"id": $1, "song": {"name": "a", "duration": 30000000000}},
+				{"id": $2, "song": {"name": "b", "duration": 30000000000}, "added_by": "вася"}
+			],
+			"total": 2
+		}`, []any{first, added})
+
+		w = serve(h, http.MethodGet, "/queue?limit=1", "l", "")
+		td.Cmp(t, w.Code, http.StatusOK)
+		td.CmpJSON(t, decode(t, w), `{"songs": [{"id": $1, "song": {"name": "a", "duration": 30000000000}}], "total": 2, "next": $1}`, []any{first})
+		w = serve(h, http.MethodGet, "/queue?limit=1&cursor="+strconv.FormatFloat(first, 'f', 0, 64), "l", "")
+		td.CmpJSON(t, decode(t, w), `{"songs": [{"id": $1, "song": {"name": "b", "duration": 30000000000}, "added_by": "вася"}], "total": 2}`, []any{added})
+		w = serve(h, http.MethodGet, "/queue?offset=1&limit=5", "l", "")
+		td.CmpJSON(t, decode(t, w), `{"songs": [{"id": $1, "song": {"name": "b", "duration": 30000000000}, "added_by": "вася"}], "total": 2}`, []any{added})
+		w = serve(h, http.MethodGet, "/queue?offset=5", "l", "")
+		td.CmpJSON(t, decode(t, w), `{"songs": [], "total": 2}`, nil)
+
+		for _, query := range []string{"offset=-1", "offset=x", "limit=0", "limit=1001", "cursor=0", "cursor=x"} {
+			td.Cmp(t, serve(h, http.MethodGet, "/queue?"+query, "l", "").Code, http.StatusBadRequest, query)
+		}
+		td.Cmp(t, serve(h, http.MethodGet, "/queue?cursor=999999", "l", "").Code, http.StatusNotFound, "песни курсора нет")
+
+		w = serve(h, http.MethodPatch, "/queue", "l", "")
+		td.Cmp(t, w.Code, http.StatusMethodNotAllowed)
+		td.Cmp(t, w.Header().Get("Allow"), http.MethodPost)
+		td.Cmp(t, serve(h, http.MethodGet, "/songs", "l", "").Code, http.StatusNotFound)
+		td.Cmp(t, serve(h, http.MethodDelete, "/queue/x", "a", "").Code, http.StatusNotFound)
+	})
+
+	t.Run("eq", func(t *testing.T) {
+		pl, _ := NewPlayer(song("a"))
+		h, _ := pl.HTTPHandler(auth)
+
+		w := serve(h, http.MethodGet, "/eq", "l", "")
+		td.Cmp(t, w.Code, http.StatusOK)
+		td.CmpJSON(t, decode(t, w), `{"bands": [], "presets": ["bass", "flat", "pop", "rock", "speech"]}`, nil)
+
+		td.Cmp(t, serve(h, http.MethodPut, "/eq", "a", `{"preset": "rock"}`).Code, http.StatusNoContent)
+		td.Cmp(t, pl.EQ(ctx), eqPresets["rock"])
+		td.Cmp(t, serve(h, http.MethodPut, "/eq", "a", `{"bands": [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]}`).Code, http.StatusNoContent)
+		w = serve(h, http.MethodGet, "/eq", "l", "")
+		td.CmpJSON(t, decode(t, w), `{"bands": [1, 2, 3, 4, 5, 6, 7, 8, 9, 10], "presets": Len(5)}`, nil)
+
+		for _, body := range []string{
+			`{}`,
+			`{"bands": [1], "preset": "rock"}`,
+			`{"bands": [1, 2, 3]}`,
+			`{"bands": [0, 0, 0, 0, 0, 0, 0, 0, 0, 13]}`,
+			`{"preset": "jazz"}`,
+		} {
+			td.Cmp(t, serve(h, http.MethodPut, "/eq", "a", body).Code, http.StatusBadRequest, body)
+		}
+		td.Cmp(t, pl.EQ(ctx), []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, "эквалайзер не изменился")
+	})
+
+	t.Run("vote", func(t *testing.T) {
+		pl, _ := NewPlayer(song("a"), song("b"))
+		h, _ := pl.HTTPHandler(auth)
+
+		td.Cmp(t, serve(h, http.MethodPost, "/vote", "l", "").Code, http.StatusConflict, "голосование выключено")
+
+		td.CmpNoError(t, WithSkipFraction(1)(pl))
+		td.Cmp(t, serve(h, http.MethodPost, "/vote", "l", "").Code, http.StatusForbidden, "не слушатель")
+		td.Cmp(t, serve(h, http.MethodPost, "/listeners", "l", "").Code, http.StatusNoContent)
+
+		w := serve(h, http.MethodPost, "/vote", "l", "")
+		td.Cmp(t, w.Code, http.StatusOK)
+		td.CmpJSON(t, decode(t, w), `{"skipped": true}`, nil)
+		td.Cmp(t, pl.Status(ctx).Song.Name, "b")
+	})
+
+	t.Run("playback outlives request", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(song("a"), song("b"), song("c")), WithSkipFraction(1))
+		h, _ := pl.HTTPHandler(auth)
+		td.CmpNoError(t, pl.AddListener(ctx, "вася"))
+		c := strconv.FormatUint(uint64(pl.Queue(ctx)[2].ID), 10)
+
+		for _, req := range []struct{ method, path, token, song string }{
+			{http.MethodPost, "/play", "a", "a"},
+			{http.MethodPost, "/next", "a", "b"},
+			{http.MethodPost, "/prev", "a", "a"},
+			{http.MethodPost, "/queue/" + c + "/play", "a", "c"},
+			{http.MethodPost, "/vote", "l", "c"},
+		} {
+			w := serveDetached(h, req.method, req.path, req.token)
+			td.Cmp(t, w.Code, td.Between(http.StatusOK, http.StatusNoContent), "%s %s", req.method, req.path)
+
+			// горутина воспроизведения успела бы увидеть отмену запроса
+			time.Sleep(10 * time.Millisecond)
+			_, err := pl.SimulatePlayback(ctx, 10*time.Second)
+			td.CmpNoError(t, err)
+			st := pl.Status(ctx)
+			td.CmpTrue(t, st.Playing, "%s %s: играет после ответа", req.method, req.path)
+			td.Cmp(t, st.Song.Name, req.song)
+			td.Cmp(t, st.Position, 10*time.Second)
+		}
+	})
+
+	t.Run("middleware", func(t *testing.T) {
+		pl, _ := NewPlayer(song("a"))
+
+		var seen []string
+		mw := func(tag string) func(http.Handler) http.Handler {
+			return func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					pr, _ := PrincipalFromContext(r.Context())
+					seen = append(seen, tag+":"+pr.UserID)
+					next.ServeHTTP(w, r)
+				})
+			}
+		}
+
+		h, _ := pl.HTTPHandler(auth, WithMiddleware(mw("1"), mw("2")))
+		td.Cmp(t, serve(h, http.MethodGet, "/status", "a", "").Code, http.StatusOK)
+		td.Cmp(t, serve(h, http.MethodGet, "/status", "", "").Code, http.StatusUnauthorized)
+		td.Cmp(t, seen, []string{"1:root", "2:root"}, "после аутентификации, по порядку")
+	})
+
+	t.Run("custom authenticator", func(t *testing.T) {
+		pl, _ := NewPlayer(song("a"))
+		h, _ := pl.HTTPHandler(WithAuth(AuthenticatorFunc(func(r *http.Request) (Principal, error) {
+			return Principal{}, errors.New("backend is down")
+		})))
+
+		w := serve(h, http.MethodGet, "/status", "", "")
+		td.Cmp(t, w.Code, http.StatusInternalServerError)
+		td.CmpJSON(t, decode(t, w), `{"error": "backend is down"}`, nil)
+	})
+
+	t.Run("no auth", func(t *testing.T) {
+		pl, _ := NewPlayer(song("a"))
+		h, _ := pl.HTTPHandler()
+
+		td.Cmp(t, serve(h, http.MethodPost, "/play", "", "").Code, http.StatusNoContent, "без WithAuth все администраторы")
+		td.CmpNoError(t, pl.Close(ctx))
+		td.Cmp(t, serve(h, http.MethodPost, "/pause", "", "").Code, http.StatusServiceUnavailable)
+	})
+
+	t.Run("cors", func(t *testing.T) {
+		pl, _ := NewPlayer(song("a"))
+		h, _ := pl.HTTPHandler(auth, WithCORS(CORSConfig{AllowedOrigins: []string{"http://lan.local"}, MaxAge: time.Hour}))
+
+		preflight := func(origin string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest(http.MethodOptions, "/queue", nil)
+			r.Header.Set("Origin", origin)
+			r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			return w
+		}
+
+		w := preflight("http://lan.local")
+		td.Cmp(t, w.Code, http.StatusNoContent, "preflight без токена")
+		td.Cmp(t, w.Header().Get("Access-Control-Allow-Origin"), "http://lan.local")
+		td.Cmp(t, w.Header().Get("Access-Control-Allow-Methods"), "GET, POST, PUT, DELETE")
+		td.Cmp(t, w.Header().Get("Access-Control-Allow-Headers"), "Authorization, Content-Type")
+		td.Cmp(t, w.Header().Get("Access-Control-Max-Age"), "3600")
+
+		w = preflight("http://evil.example")
+		td.Cmp(t, w.Code, http.StatusForbidden)
+		td.CmpEmpty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+		r := httptest.NewRequest(http.MethodGet, "/status", nil)
+		r.Header.Set("Origin", "http://lan.local")
+		r.Header.Set("Authorization", "Bearer l")
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		td.Cmp(t, w.Code, http.StatusOK)
+		td.Cmp(t, w.Header().Get("Access-Control-Allow-Origin"), "http://lan.local")
+	})
+}
@@ -0,0 +1,135 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// sleepTimer - таймер сна, останавливающий воспроизведение.
+type sleepTimer struct {
+	// cancel - отменяет таймер по времени, nil для таймера по количеству песен
+	cancel func()
+	// at - когда срабатывает таймер по времени, по часам плеера
+	at time.Time
+	// finishSong - по истечении таймера дождаться окончания текущей песни
+	finishSong bool
+	// songsLeft - сколько песен осталось доиграть до остановки, 0 - без ограничения
+	songsLeft int
+}
+
+// SetSleepTimer - приостанавливает воспроизведение через d по часам плеера.
+// Если finishSong, текущая на момент срабатывания песня доигрывается до конца.
+// Заменяет ранее установленный таймер сна.
+func (p *playerImpl) SetSleepTimer(ctx context.Context, d time.Duration, finishSong bool) error {
+	if d <= 0 {
+		return errors.New("sleep duration must be positive")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	p.cancelSleepLocked()
+
+	st := &sleepTimer{finishSong: finishSong, at: p.now().Add(d)}
+	st.cancel = p.afterFunc(d, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		// таймер отменили или заменили
+		if p.sleep != st {
+			return
+		}
+
+		if st.finishSong && p.isPlaying {
+			st.songsLeft = 1
+			return
+		}
+
+		p.pauseLocked(context.Background())
+		p.sleep = nil
+		p.logger.Info("sleep timer paused playback")
+	})
+	p.sleep = st
+
+	return nil
+}
+
+// SetSleepAfterSongs - останавливает воспроизведение, когда доиграют n песен,
+// включая текущую. Заменяет ранее установленный таймер сна.
+func (p *playerImpl) SetSleepAfterSongs(ctx context.Context, n int) error {
+	if n <= 0 {
+		return errors.New("songs count must be positive")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	p.cancelSleepLocked()
+	p.sleep = &sleepTimer{songsLeft: n}
+
+	return nil
+}
+
+// CancelSleepTimer - отменяет таймер сна.
+func (p *playerImpl) CancelSleepTimer(ctx context.Context) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	p.cancelSleepLocked()
+	return nil
+}
+
+// cancelSleepLocked - отменяет таймер сна.
+// Вызывается под блокировкой.
+func (p *playerImpl) cancelSleepLocked() {
+	if p.sleep == nil {
+		return
+	}
+
+	if p.sleep.cancel != nil {
+		p.sleep.cancel()
+	}
+	p.sleep = nil
+}
+
+// sleepOnSongEndLocked - учитывает доигравшую песню в таймере сна
+// и сообщает, нужно ли остановить воспроизведение.
+// Вызывается под блокировкой.
+func (p *playerImpl) sleepOnSongEndLocked() bool {
+	if p.sleep == nil {
+		return false
+	}
+
+	// таймер истёк, пока песня доигрывала, но ещё не успел получить блокировку
+	if p.sleep.finishSong && p.sleep.cancel != nil && !p.now().Before(p.sleep.at) {
+		p.cancelSleepLocked()
+		return true
+	}
+
+	if p.sleep.songsLeft == 0 {
+		return false
+	}
+
+	p.sleep.songsLeft--
+	if p.sleep.songsLeft > 0 {
+		return false
+	}
+
+	p.cancelSleepLocked()
+	return true
+}
@@ -0,0 +1,76 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_SleepTimer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pause after duration", func(t *testing.T) {
+		clock := NewFakeClock(testStart)
+		pl, _ := New(WithClock(clock), WithSongs(minuteSong("a")))
+		td.CmpError(t, pl.SetSleepTimer(ctx, 0, false), "нулевая длительность")
+		td.CmpNoError(t, pl.SetSleepTimer(ctx, 20*time.Second, false))
+
+		td.CmpNoError(t, pl.Play(ctx))
+		clock.Advance(19 * time.Second)
+		td.CmpTrue(t, pl.Status(ctx).Playing, "таймер ещё не сработал")
+
+		clock.Advance(time.Second)
+		waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		td.CmpNoError(t, pl.WaitFor(waitCtx, func(st Status) bool { return !st.Playing }))
+		td.Cmp(t, pl.Status(ctx).Position, 20*time.Second, "приостановлено по часам плеера")
+		td.Cmp(t, pl.State(ctx), StatePaused)
+	})
+
+	t.Run("finish current song", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSongs("a", "b")...))
+		td.CmpNoError(t, pl.SetSleepTimer(ctx, 20*time.Second, true))
+
+		td.CmpNoError(t, pl.Play(ctx))
+		_, err := pl.SimulatePlayback(ctx, 30*time.Second)
+		td.CmpNoError(t, err)
+		td.CmpTrue(t, pl.Status(ctx).Playing, "песня ещё доигрывает")
+
+		_, err = pl.SimulatePlayback(ctx, 30*time.Second)
+		td.CmpNoError(t, err)
+		st := pl.Status(ctx)
+		td.CmpFalse(t, st.Playing, "воспроизведение остановлено после песни")
+		td.Cmp(t, st.Song.Name, "b", "курсор на следующей песне")
+		td.Cmp(t, st.Position, time.Duration(0))
+	})
+
+	t.Run("after songs", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSongs("a", "b", "c")...))
+		td.CmpError(t, pl.SetSleepAfterSongs(ctx, 0), "нулевое количество")
+		td.CmpNoError(t, pl.SetSleepAfterSongs(ctx, 2))
+
+		td.CmpNoError(t, pl.Play(ctx))
+		played, err := pl.SimulatePlayback(ctx, 3*time.Minute)
+		td.CmpNoError(t, err)
+		td.Cmp(t, titles(played), []string{"a", "b"})
+
+		st := pl.Status(ctx)
+		td.CmpFalse(t, st.Playing, "воспроизведение остановлено после двух песен")
+		td.Cmp(t, st.Song.Name, "c")
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		clock := NewFakeClock(testStart)
+		pl, _ := New(WithClock(clock), WithSongs(minuteSong("a")))
+		td.CmpNoError(t, pl.SetSleepTimer(ctx, 20*time.Second, false))
+		td.CmpNoError(t, pl.CancelSleepTimer(ctx))
+
+		td.CmpNoError(t, pl.Play(ctx))
+		clock.Advance(30 * time.Second)
+		td.CmpTrue(t, pl.Status(ctx).Playing, "таймер отменён")
+		td.Cmp(t, pl.Status(ctx).Position, 30*time.Second)
+		td.CmpNoError(t, pl.Pause(ctx))
+	})
+}
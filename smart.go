@@ -0,0 +1,208 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// SongInfo - песня вместе с метаданными плеера, по которым отбираются песни.
+type SongInfo struct {
+	// Song - песня
+	Song Song
+	// Stats - статистика воспроизведения
+	Stats SongStats
+	// AddedAt - когда песня добавлена в библиотеку
+	AddedAt time.Time
+	// Now - момент отбора по часам плеера
+	Now time.Time
+}
+
+// Rule - условие отбора песен в умный плейлист.
+type Rule func(info SongInfo) bool
+
+// DurationUnder - песни короче d.
+func DurationUnder(d time.Duration) Rule {
+	return func(info SongInfo) bool { return info.Song.Duration < d }
+}
+
+// DurationOver - песни длиннее d.
+func DurationOver(d time.Duration) Rule {
+	return func(info SongInfo) bool { return info.Song.Duration > d }
+}
+
+// ArtistIs - песни исполнителя, без учёта регистра.
+func ArtistIs(artist string) Rule {
+	return func(info SongInfo) bool { return strings.EqualFold(info.Song.Artist, artist) }
+}
+
+// PlayCountOver - песни, доигравшие до конца больше n раз.
+func PlayCountOver(n int) Rule {
+	return func(info SongInfo) bool { return info.Stats.PlayCount > n }
+}
+
+// AddedWithin - песни, добавленные не раньше d назад.
+func AddedWithin(d time.Duration) Rule {
+	return func(info SongInfo) bool { return info.Now.Sub(info.AddedAt) <= d }
+}
+
+// AllOf - песни, подходящие под все правила.
+func AllOf(rules ...Rule) Rule {
+	return func(info SongInfo) bool {
+		for _, r := range rules {
+			if !r(info) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AnyOf - песни, подходящие хотя бы под одно правило.
+func AnyOf(rules ...Rule) Rule {
+	return func(info SongInfo) bool {
+		for _, r := range rules {
+			if r(info) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not - песни, не подходящие под правило.
+func Not(rule Rule) Rule {
+	return func(info SongInfo) bool { return !rule(info) }
+}
+
+// CreateSmartPlaylist - создаёт плейлист, который заполняется песнями
+// из библиотеки, подходящими под правило.
+func (p *playerImpl) CreateSmartPlaylist(ctx context.Context, name string, rule Rule) error {
+	if name == "" {
+		return errors.New("playlist name is empty")
+	}
+	if rule == nil {
+		return errors.New("rule is nil")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if p.hasPlaylistLocked(name) {
+		return ErrPlaylistExists
+	}
+
+	p.playlists[name] = &playlist{}
+	p.smart[name] = rule
+	if err := p.refreshSmartLocked(ctx, name); err != nil {
+		delete(p.playlists, name)
+		delete(p.smart, name)
+		return err
+	}
+	p.logger.InfoContext(ctx, "smart playlist created", slog.String("playlist", name))
+
+	return nil
+}
+
+// RefreshSmartPlaylist - заново отбирает песни в умный плейлист.
+// Если плейлист активен и текущая песня по-прежнему подходит,
+// воспроизведение продолжается без перерыва.
+func (p *playerImpl) RefreshSmartPlaylist(ctx context.Context, name string) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if _, ok := p.smart[name]; !ok {
+		return ErrPlaylistNotFound
+	}
+
+	if err := p.refreshSmartLocked(ctx, name); err != nil {
+		return err
+	}
+	p.logger.InfoContext(ctx, "smart playlist refreshed", slog.String("playlist", name))
+
+	return nil
+}
+
+// refreshSmartLocked - заполняет умный плейлист. Если из активного плейлиста
+// пропала текущая песня, воспроизведение останавливается. Если подходящие
+// песни не помещаются в ограничения плейлистов, плейлист не меняется
+// и возвращается ErrPlaylistFull.
+// Вызывается под блокировкой.
+func (p *playerImpl) refreshSmartLocked(ctx context.Context, name string) error {
+	pl := p.playlists[name]
+	if name == p.active {
+		pl = &p.playlist
+	}
+
+	rule := p.smart[name]
+	var (
+		matched []*track
+		songs   []Song
+	)
+	for _, t := range p.library.all() {
+		if rule(p.songInfoLocked(t)) {
+			matched = append(matched, t)
+			songs = append(songs, *t.song)
+		}
+	}
+
+	if !p.limits.fitSongs(songs) {
+		return ErrPlaylistFull
+	}
+
+	keep := pl.current
+	fresh := playlist{}
+	for _, t := range matched {
+		// текущую песню переиспользуем, чтобы не прерывать воспроизведение
+		if keep != nil && keep.track == t.id {
+			fresh.appendNode(keep)
+			fresh.current = keep
+			keep = nil
+			continue
+		}
+
+		fresh.appendTrack(t, nil)
+	}
+
+	if fresh.current == nil {
+		fresh.current = fresh.first()
+	} else {
+		fresh.playedTime = pl.playedTime
+	}
+
+	if name == p.active {
+		p.resetEditsLocked()
+		if keep != nil {
+			p.haltLocked(ctx)
+			p.section = nil
+		}
+	}
+
+	*pl = fresh
+	return nil
+}
+
+// songInfoLocked - возвращает трек вместе со статистикой.
+// Вызывается под блокировкой.
+func (p *playerImpl) songInfoLocked(t *track) SongInfo {
+	info := SongInfo{Song: *t.song, AddedAt: t.addedAt, Now: p.now()}
+	if s, ok := p.stats[songKey(*t.song)]; ok {
+		info.Stats = *s
+	}
+
+	return info
+}
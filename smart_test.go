@@ -0,0 +1,87 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestRules(t *testing.T) {
+	info := SongInfo{
+		Song:    Song{Name: "30 лет", Artist: "Сектор Газа", Duration: 3 * time.Minute},
+		Stats:   SongStats{PlayCount: 5},
+		AddedAt: testStart.Add(-48 * time.Hour),
+		Now:     testStart,
+	}
+
+	td.CmpTrue(t, DurationUnder(4*time.Minute)(info))
+	td.CmpFalse(t, DurationOver(4*time.Minute)(info))
+	td.CmpTrue(t, ArtistIs("сектор газа")(info), "без учёта регистра")
+	td.CmpTrue(t, PlayCountOver(4)(info))
+	td.CmpFalse(t, PlayCountOver(5)(info))
+	td.CmpTrue(t, AddedWithin(7*24*time.Hour)(info))
+	td.CmpFalse(t, AddedWithin(24*time.Hour)(info))
+
+	td.CmpTrue(t, AllOf(ArtistIs("Сектор Газа"), DurationUnder(4*time.Minute))(info))
+	td.CmpFalse(t, AllOf(ArtistIs("Сектор Газа"), DurationOver(4*time.Minute))(info))
+	td.CmpTrue(t, AnyOf(ArtistIs("Пушной"), PlayCountOver(1))(info))
+	td.CmpTrue(t, Not(ArtistIs("Пушной"))(info))
+}
+
+func TestPlayerImpl_SmartPlaylist(t *testing.T) {
+	ctx := context.Background()
+	sg := Song{Name: "30 лет", Artist: "Сектор Газа", Duration: 30 * time.Second}
+	sg2 := Song{Name: "Туман", Artist: "Сектор Газа", Duration: 30 * time.Second}
+	ap := Song{Name: "Почему я идиот?", Artist: "Александр Пушной", Duration: 30 * time.Second}
+
+	pl, _ := NewPlayer(sg, ap)
+	_ = pl.CreatePlaylist(ctx, "more")
+	_, _ = pl.AddSongTo(ctx, "more", sg2)
+	_, _ = pl.AddSongTo(ctx, "more", sg)
+
+	td.CmpError(t, pl.CreateSmartPlaylist(ctx, "gaza", nil))
+	td.Cmp(t, pl.CreateSmartPlaylist(ctx, "more", ArtistIs("x")), ErrPlaylistExists)
+	td.Cmp(t, pl.RefreshSmartPlaylist(ctx, "more"), ErrPlaylistNotFound, "обычный плейлист не обновляется")
+
+	td.CmpNoError(t, pl.CreateSmartPlaylist(ctx, "gaza", ArtistIs("Сектор Газа")))
+	td.Cmp(t, pl.playlists["gaza"].state("gaza").Songs, []Song{sg, sg2}, "песни без повторов")
+
+	_ = pl.SwitchPlaylist(ctx, "gaza")
+	pl.current = pl.last()
+	_ = pl.Play(ctx)
+
+	_, _ = pl.AddSongTo(ctx, "more", Song{Name: "Лирика", Artist: "Сектор Газа", Duration: time.Minute})
+	td.CmpNoError(t, pl.RefreshSmartPlaylist(ctx, "gaza"))
+	td.CmpTrue(t, pl.isPlaying, "текущая песня осталась, воспроизведение продолжается")
+	td.Cmp(t, *pl.current.song, sg2)
+	td.Cmp(t, pl.state("gaza").Songs, td.Len(3))
+
+	pl.smart["gaza"] = ArtistIs("Александр Пушной")
+	td.CmpNoError(t, pl.RefreshSmartPlaylist(ctx, "gaza"))
+	td.CmpFalse(t, pl.isPlaying, "текущая песня пропала, воспроизведение остановлено")
+	td.Cmp(t, *pl.current.song, ap)
+
+	_ = pl.SwitchPlaylist(ctx, DefaultPlaylist)
+	td.CmpNoError(t, pl.DeletePlaylist(ctx, "gaza"))
+	td.Cmp(t, pl.smart, td.Not(td.ContainsKey("gaza")))
+}
+
+func TestPlayerImpl_SmartPlaylistClock(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock(testStart)
+	pl, err := New(WithClock(clock), WithSongs(minuteSong("old")))
+	td.CmpNoError(t, err)
+
+	clock.Advance(48 * time.Hour)
+	_, err = pl.AddSong(ctx, minuteSong("new"))
+	td.CmpNoError(t, err)
+
+	entries := pl.Library().Search("new")
+	td.Cmp(t, entries, td.Len(1))
+	td.Cmp(t, entries[0].AddedAt, testStart.Add(48*time.Hour), "время добавления по часам плеера")
+
+	td.CmpNoError(t, pl.CreateSmartPlaylist(ctx, "fresh", AddedWithin(24*time.Hour)))
+	td.Cmp(t, pl.playlists["fresh"].state("fresh").Songs, []Song{minuteSong("new")}, "давность по часам плеера")
+}
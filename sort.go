@@ -0,0 +1,74 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// ByName - сравнивает песни по названию без учёта регистра.
+func ByName(a, b Song) bool {
+	return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+}
+
+// ByDuration - сравнивает песни по длительности.
+func ByDuration(a, b Song) bool {
+	return a.Duration < b.Duration
+}
+
+// ByArtist - сравнивает песни по исполнителю, затем по альбому и названию.
+func ByArtist(a, b Song) bool {
+	if x, y := strings.ToLower(a.Artist), strings.ToLower(b.Artist); x != y {
+		return x < y
+	}
+
+	if x, y := strings.ToLower(a.Album), strings.ToLower(b.Album); x != y {
+		return x < y
+	}
+
+	return ByName(a, b)
+}
+
+// SortPlaylist - упорядочивает активный плейлист по less.
+// Сортировка устойчивая: равные песни сохраняют взаимный порядок.
+// Текущая песня остаётся текущей и продолжает играть.
+func (p *playerImpl) SortPlaylist(ctx context.Context, less func(a, b Song) bool) error {
+	if less == nil {
+		return errors.New("less is nil")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	before := p.nodes()
+	p.sort(less)
+	p.recordEditLocked(p.reorderEdit("sort", before, p.nodes(), p.current))
+	p.auditLocked(ctx, AuditEntry{Op: AuditSort, Song: p.currentSongLocked(), Detail: p.active})
+
+	// следующая песня могла измениться
+	p.prepared = nil
+	p.rescheduleLocked()
+	p.logger.DebugContext(ctx, "playlist sorted", slog.String("playlist", p.active))
+	return nil
+}
+
+// sort - переставляет узлы списка по less, не меняя курсор.
+func (pl *playlist) sort(less func(a, b Song) bool) {
+	nodes := pl.nodes()
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return less(*nodes[i].song, *nodes[j].song)
+	})
+
+	for _, n := range nodes {
+		pl.songs.MoveToBack(n.elem)
+	}
+}
@@ -0,0 +1,42 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_SortPlaylist(t *testing.T) {
+	ctx := context.Background()
+
+	pl, _ := NewPlayer(
+		Song{Name: "b", Artist: "Сектор Газа", Duration: 30 * time.Second},
+		Song{Name: "C", Artist: "Пушной", Duration: 10 * time.Second},
+		Song{Name: "a", Artist: "Сектор Газа", Duration: 20 * time.Second},
+		Song{Name: "d", Artist: "Пушной", Duration: 10 * time.Second},
+	)
+	td.CmpError(t, pl.SortPlaylist(ctx, nil))
+
+	_ = pl.Play(ctx)
+	playing := pl.current
+
+	td.CmpNoError(t, pl.SortPlaylist(ctx, ByName))
+	td.Cmp(t, names(pl), []string{"a", "b", "C", "d"}, "без учёта регистра")
+	td.Cmp(t, pl.current, playing, "курсор остался на играющей песне")
+	td.CmpTrue(t, pl.isPlaying, "воспроизведение не прервано")
+
+	td.CmpNoError(t, pl.SortPlaylist(ctx, ByDuration))
+	td.Cmp(t, names(pl), []string{"C", "d", "a", "b"}, "равные сохраняют порядок")
+
+	td.CmpNoError(t, pl.SortPlaylist(ctx, ByArtist))
+	td.Cmp(t, names(pl), []string{"C", "d", "a", "b"})
+
+	td.CmpNoError(t, pl.SortPlaylist(ctx, func(a, b Song) bool { return ByName(b, a) }))
+	td.Cmp(t, names(pl), []string{"d", "C", "b", "a"})
+	td.CmpNil(t, pl.first().prev())
+	td.CmpNil(t, pl.last().next())
+	td.Cmp(t, pl.last().prev().prev().prev(), pl.first(), "обратные ссылки согласованы")
+	_ = pl.Pause(ctx)
+}
@@ -0,0 +1,263 @@
+package player
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnknownDuration - источник не может определить длительность песни.
+var ErrUnknownDuration = errors.New("unknown duration")
+
+// resolveTimeout - сколько плеер ждёт длительность песни от источника.
+const resolveTimeout = 10 * time.Second
+
+// Source - источник звука песни, например файл или адрес HTTP.
+// Если у песни есть Source и не задана Duration, плеер узнаёт длительность
+// у источника в отдельной горутине, когда песня начинает играть, а для следующей
+// песни - пока играет текущая. До этого песня считается потоком.
+// Если длительность узнать не удалось, песня обрабатывается по WithErrorPolicy.
+type Source interface {
+	// Open - открывает звук песни для чтения
+	Open(ctx context.Context) (io.ReadCloser, error)
+	// Duration - определяет длительность песни, 0 для потока
+	Duration(ctx context.Context) (time.Duration, error)
+}
+
+// FileSource - Source для локального файла.
+type FileSource struct {
+	// Path - путь к файлу
+	Path string
+	// Probe - определяет длительность по содержимому файла,
+	// по умолчанию ProbeWAV для файлов .wav
+	Probe func(r io.Reader) (time.Duration, error)
+}
+
+func (s FileSource) Open(_ context.Context) (io.ReadCloser, error) {
+	return os.Open(s.Path)
+}
+
+func (s FileSource) Duration(ctx context.Context) (time.Duration, error) {
+	probe := s.Probe
+	if probe == nil {
+		if !strings.EqualFold(filepath.Ext(s.Path), ".wav") {
+			return 0, fmt.Errorf("%w: %s", ErrUnknownDuration, s.Path)
+		}
+		probe = ProbeWAV
+	}
+
+	f, err := s.Open(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return probe(f)
+}
+
+// ProbeWAV - определяет длительность файла WAV по заголовку RIFF.
+func ProbeWAV(r io.Reader) (time.Duration, error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return 0, fmt.Errorf("read riff header: %v", err)
+	}
+	if string(riff[:4]) != "RIFF" || string(riff[8:]) != "WAVE" {
+		return 0, errors.New("not a wav file")
+	}
+
+	var byteRate uint32
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return 0, fmt.Errorf("read chunk header: %v", err)
+		}
+		id, size := string(chunk[:4]), binary.LittleEndian.Uint32(chunk[4:])
+
+		switch id {
+		case "fmt ":
+			var format [16]byte
+			if size < 16 {
+				return 0, errors.New("fmt chunk is too short")
+			}
+			if _, err := io.ReadFull(r, format[:]); err != nil {
+				return 0, fmt.Errorf("read fmt chunk: %v", err)
+			}
+			byteRate = binary.LittleEndian.Uint32(format[8:12])
+			size -= 16
+		case "data":
+			if byteRate == 0 {
+				return 0, errors.New("data chunk before fmt chunk")
+			}
+			return time.Duration(float64(size) / float64(byteRate) * float64(time.Second)), nil
+		}
+
+		// чанки выравниваются по двум байтам
+		if _, err := io.CopyN(io.Discard, r, int64(size+size%2)); err != nil {
+			return 0, fmt.Errorf("skip %q chunk: %v", id, err)
+		}
+	}
+}
+
+// HTTPSource - Source для файла по адресу HTTP.
+// Длительность берётся из заголовка X-Content-Duration или Content-Duration
+// ответа на запрос HEAD.
+type HTTPSource struct {
+	// URL - адрес файла
+	URL string
+	// Client - HTTP-клиент, по умолчанию http.DefaultClient
+	Client *http.Client
+}
+
+func (s HTTPSource) Open(ctx context.Context) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+func (s HTTPSource) Duration(ctx context.Context) (time.Duration, error) {
+	resp, err := s.do(ctx, http.MethodHead)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	for _, h := range []string{"X-Content-Duration", "Content-Duration"} {
+		if v := resp.Header.Get(h); v != "" {
+			sec, err := strconv.ParseFloat(v, 64)
+			if err != nil || sec < 0 {
+				return 0, fmt.Errorf("invalid %s %q", h, v)
+			}
+			return time.Duration(sec * float64(time.Second)), nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s", ErrUnknownDuration, s.URL)
+}
+
+// do - выполняет запрос method и проверяет статус ответа.
+func (s HTTPSource) do(ctx context.Context, method string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %v", err)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %v", method, s.URL, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s: %s", method, s.URL, resp.Status)
+	}
+
+	return resp, nil
+}
+
+// unresolved - сообщает, что длительность песни ещё нужно узнать у источника.
+func (n *playerNode) unresolved() bool {
+	return n.song.Source != nil && n.song.Duration == 0 && !n.resolved && n.resolveErr == nil
+}
+
+// resolveTargetLocked - песня, длительность которой нужно узнать:
+// текущая, а если она известна - следующая. nil если узнавать нечего.
+// Вызывается под блокировкой.
+func (p *playerImpl) resolveTargetLocked() *playerNode {
+	if p.current.unresolved() {
+		return p.current
+	}
+
+	if next := p.afterLocked(p.current); next != nil && next.unresolved() {
+		return next
+	}
+
+	return nil
+}
+
+// resolveDueLocked - сообщает, что нужно узнать длительность текущей или следующей песни.
+// Вызывается под блокировкой.
+func (p *playerImpl) resolveDueLocked() bool {
+	node := p.resolveTargetLocked()
+	return node != nil && p.resolving != node
+}
+
+// resolveAheadLocked - узнаёт длительность песни resolveTargetLocked в отдельной
+// горутине не дольше resolveTimeout, чтобы источник не держал блокировку плеера.
+// Вызывается под блокировкой.
+func (p *playerImpl) resolveAheadLocked(ctx context.Context) {
+	node := p.resolveTargetLocked()
+	p.resolving = node
+	src := node.song.Source
+
+	go func() {
+		rctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+		d, err := src.Duration(rctx)
+		cancel()
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		if p.resolving == node {
+			p.resolving = nil
+		}
+		if !node.unresolved() || p.closed {
+			return
+		}
+
+		if err := p.resolvedLocked(ctx, node, d, err); err != nil {
+			// песня уже играет как поток
+			if node == p.current && p.isPlaying {
+				p.haltLocked(ctx)
+				p.failurePolicyLocked(ctx, *node.song)
+			}
+			return
+		}
+		p.rescheduleLocked()
+	}()
+}
+
+// resolvedLocked - сохраняет длительность песни, полученную от источника,
+// или запоминает ошибку в resolveErr узла.
+// Вызывается под блокировкой.
+func (p *playerImpl) resolvedLocked(ctx context.Context, node *playerNode, d time.Duration, err error) error {
+	if err == nil {
+		song := *node.song
+		song.Duration = d
+		err = p.validator.Validate(song)
+	}
+
+	if err != nil {
+		err = fmt.Errorf("resolve duration: %w", err)
+		node.resolveErr = err
+		p.playbackErrorLocked(ctx, *node.song, StageResolve, err)
+		return err
+	}
+
+	// песню трека разделяют плееры библиотеки, поэтому она заменяется, а не меняется
+	t := p.library.resolve(node.track, *node.song, d)
+	node.song, node.track = t.song, t.id
+	node.resolved = true
+	if p.contains(node) {
+		p.total += d - node.counted
+		node.counted = d
+	}
+	p.logger.DebugContext(ctx, "duration resolved", songAttr(*node.song), slog.Duration("duration", d))
+	return nil
+}
@@ -0,0 +1,236 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// wavFile - файл WAV с тишиной длительностью d: 8 кГц, 8 бит, моно.
+func wavFile(d time.Duration) []byte {
+	const byteRate = 8000
+	size := uint32(d.Seconds() * byteRate)
+
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(4+8+16+8+3+1+8)+size)
+	b.WriteString("WAVE")
+
+	b.WriteString("fmt ")
+	_ = binary.Write(&b, binary.LittleEndian, []uint32{16})
+	_ = binary.Write(&b, binary.LittleEndian, []uint16{1, 1})
+	_ = binary.Write(&b, binary.LittleEndian, []uint32{8000, byteRate})
+	_ = binary.Write(&b, binary.LittleEndian, []uint16{1, 8})
+
+	// чанк нечётной длины с выравниванием
+	b.WriteString("LIST")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(3))
+	b.WriteString("abc\x00")
+
+	b.WriteString("data")
+	_ = binary.Write(&b, binary.LittleEndian, size)
+	b.Write(make([]byte, size))
+
+	return b.Bytes()
+}
+
+// lazySource - источник, который отдаёт длительность не сразу.
+type lazySource struct {
+	d     time.Duration
+	err   error
+	calls atomic.Int32
+	// block - если задан, Duration ждёт его закрытия
+	block chan struct{}
+	// bounded - у контекста Duration был срок
+	bounded atomic.Bool
+}
+
+func (s *lazySource) Open(context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(nil)), nil
+}
+
+func (s *lazySource) Duration(ctx context.Context) (time.Duration, error) {
+	s.calls.Add(1)
+	_, ok := ctx.Deadline()
+	s.bounded.Store(ok)
+	if s.block != nil {
+		<-s.block
+	}
+	return s.d, s.err
+}
+
+func TestProbeWAV(t *testing.T) {
+	d, err := ProbeWAV(bytes.NewReader(wavFile(2 * time.Second)))
+	td.CmpNoError(t, err)
+	td.Cmp(t, d, 2*time.Second)
+
+	_, err = ProbeWAV(bytes.NewReader([]byte("ID3 not a wav file")))
+	td.CmpString(t, err, "not a wav file")
+}
+
+func TestFileSource(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "тишина.WAV")
+	td.CmpNoError(t, os.WriteFile(path, wavFile(1500*time.Millisecond), 0o600))
+
+	d, err := FileSource{Path: path}.Duration(ctx)
+	td.CmpNoError(t, err)
+	td.Cmp(t, d, 1500*time.Millisecond)
+
+	_, err = FileSource{Path: filepath.Join(dir, "песня.mp3")}.Duration(ctx)
+	td.CmpTrue(t, errors.Is(err, ErrUnknownDuration), "mp3 без Probe")
+
+	d, err = FileSource{Path: path, Probe: func(io.Reader) (time.Duration, error) { return time.Minute, nil }}.Duration(ctx)
+	td.CmpNoError(t, err)
+	td.Cmp(t, d, time.Minute, "своя проверка")
+}
+
+func TestHTTPSource(t *testing.T) {
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/song.ogg":
+			w.Header().Set("X-Content-Duration", "12.5")
+			_, _ = w.Write([]byte("ogg"))
+		case "/radio":
+			_, _ = w.Write([]byte("mp3"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	d, err := HTTPSource{URL: srv.URL + "/song.ogg"}.Duration(ctx)
+	td.CmpNoError(t, err)
+	td.Cmp(t, d, 12500*time.Millisecond)
+
+	rc, err := HTTPSource{URL: srv.URL + "/song.ogg"}.Open(ctx)
+	td.CmpNoError(t, err)
+	body, _ := io.ReadAll(rc)
+	rc.Close()
+	td.Cmp(t, string(body), "ogg")
+
+	_, err = HTTPSource{URL: srv.URL + "/radio"}.Duration(ctx)
+	td.CmpTrue(t, errors.Is(err, ErrUnknownDuration))
+
+	_, err = HTTPSource{URL: srv.URL + "/missing"}.Open(ctx)
+	td.CmpError(t, err)
+}
+
+func TestPlayerImpl_LazyDuration(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("длительность узнаётся перед воспроизведением", func(t *testing.T) {
+		first, second := &lazySource{d: 30 * time.Millisecond}, &lazySource{d: time.Minute}
+		pl, err := New(WithValidator(ValidationPolicy{}), WithSongs(Song{Name: "a", Source: first}, Song{Name: "b", Source: second}))
+		td.CmpNoError(t, err, "длительность не нужна при добавлении")
+		defer pl.Close(ctx)
+
+		td.Cmp(t, first.calls.Load(), int32(0), "до воспроизведения источник не трогается")
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpTrue(t, eventually(func() bool { return pl.Status(ctx).Song.Duration == 30*time.Millisecond }))
+		td.CmpTrue(t, first.bounded.Load(), "ожидание ограничено")
+		td.CmpTrue(t, eventually(func() bool { return second.calls.Load() == 1 }), "следующая песня узнаётся заранее")
+
+		waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		td.CmpNoError(t, pl.WaitFor(waitCtx, func(st Status) bool { return st.Song.Name == "b" }), "переход по узнанной длительности")
+		td.Cmp(t, pl.Status(ctx).Song.Duration, time.Minute)
+		td.Cmp(t, second.calls.Load(), int32(1), "повторно не узнаётся")
+	})
+
+	t.Run("ошибка источника", func(t *testing.T) {
+		boom := errors.New("file is gone")
+		pl, _ := NewPlayer(Song{Name: "a", Source: &lazySource{err: boom}})
+		defer pl.Close(ctx)
+
+		td.CmpNoError(t, pl.Play(ctx), "длительность узнаётся во время воспроизведения")
+
+		perr := nextPlaybackError(t, pl)
+		td.Cmp(t, perr.Stage, StageResolve)
+		td.CmpTrue(t, errors.Is(perr, boom))
+		td.CmpTrue(t, eventually(func() bool { return !pl.Status(ctx).Playing }), "песня остановлена по политике")
+	})
+
+	t.Run("ошибка следующей песни", func(t *testing.T) {
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithErrorPolicy(ErrorSkip), WithSongs(
+			Song{Name: "a", Source: &lazySource{d: 30 * time.Millisecond}},
+			Song{Name: "b", Source: &lazySource{err: errors.New("file is gone")}},
+			Song{Name: "c", Duration: time.Minute},
+		))
+		defer pl.Close(ctx)
+
+		td.CmpNoError(t, pl.Play(ctx))
+		waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		td.CmpNoError(t, pl.WaitFor(waitCtx, func(st Status) bool { return st.Song.Name == "c" && st.Playing }),
+			"песня без длительности пропущена")
+		td.Cmp(t, nextPlaybackError(t, pl).Song.Name, "b")
+	})
+
+	t.Run("длительность проверяется", func(t *testing.T) {
+		pl, _ := NewPlayer(Song{Name: "a", Source: &lazySource{d: time.Millisecond}})
+		defer pl.Close(ctx)
+
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpTrue(t, errors.Is(nextPlaybackError(t, pl), ErrInvalidSong), "короче секунды")
+	})
+
+	t.Run("источник не держит блокировку", func(t *testing.T) {
+		src := &lazySource{d: time.Minute, block: make(chan struct{})}
+		pl, _ := NewPlayer(Song{Name: "a", Source: src})
+		defer pl.Close(ctx)
+
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpTrue(t, eventually(func() bool { return src.calls.Load() == 1 }))
+
+		callCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		st := pl.Status(callCtx)
+		td.CmpTrue(t, st.Playing, "песня играет как поток")
+		td.Cmp(t, st.Song.Duration, time.Duration(0))
+		td.CmpNoError(t, pl.SetVolume(callCtx, 40), "плеер отвечает, пока источник думает")
+
+		close(src.block)
+		td.CmpTrue(t, eventually(func() bool { return pl.Status(ctx).Song.Duration == time.Minute }))
+	})
+
+	t.Run("общая библиотека", func(t *testing.T) {
+		lib := NewLibrary()
+		song := Song{Name: "a", Source: &lazySource{d: time.Minute}}
+		a, _ := New(WithLibrary(lib), WithSongs(song))
+		b, _ := New(WithLibrary(lib), WithSongs(song))
+		defer a.Close(ctx)
+		defer b.Close(ctx)
+
+		td.CmpNoError(t, a.Play(ctx))
+		td.CmpNoError(t, b.Play(ctx))
+		td.CmpTrue(t, eventually(func() bool {
+			return a.Status(ctx).Song.Duration == time.Minute && b.Status(ctx).Song.Duration == time.Minute
+		}))
+
+		entries := lib.Entries()
+		td.Cmp(t, entries, td.Len(1), "трек один")
+		td.Cmp(t, entries[0].Song.Duration, time.Minute)
+		resolved := song
+		resolved.Duration = time.Minute
+		id, err := lib.Add(resolved)
+		td.CmpNoError(t, err)
+		td.Cmp(t, id, entries[0].ID, "трек найден по новой длительности")
+		td.Cmp(t, lib.Search("a"), td.Len(1))
+	})
+}
@@ -0,0 +1,161 @@
+package player
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// stagingShards - количество очередей, по которым распределяются добавления.
+const stagingShards = 8
+
+// Состояния запроса на добавление.
+const (
+	// addPending - запрос ждёт в очереди
+	addPending int32 = iota
+	// addTaken - запрос забрал обработчик, результат будет в id и err
+	addTaken
+	// addCanceled - добавивший ушёл по ctx, запрос пропускается
+	addCanceled
+)
+
+// addRequest - песня, ожидающая добавления в конец активного плейлиста.
+type addRequest struct {
+	ctx  context.Context
+	song Song
+	// state - addPending, addTaken или addCanceled
+	state atomic.Int32
+	// id и err заполняет обработчик до закрытия done
+	id  SongID
+	err error
+	// playlist - плейлист, в который добавлена песня
+	playlist string
+	done     chan struct{}
+}
+
+// stagingShard - одна очередь запросов со своей блокировкой.
+type stagingShard struct {
+	mu   sync.Mutex
+	reqs []*addRequest
+	// выравнивание до линии кэша, чтобы соседние очереди не мешали друг другу
+	_ [40]byte
+}
+
+// staging - распределённая по очередям промежуточная очередь добавлений.
+// Добавляющие складывают запросы в очереди без блокировки плеера, а тот,
+// кто получил право обработчика, забирает их все за один захват блокировки,
+// поэтому при одновременных добавлениях блокировка плеера захватывается
+// один раз на пачку песен, а не на каждую.
+type staging struct {
+	shards [stagingShards]stagingShard
+	next   atomic.Uint32
+	// turn - право обработчика: в канале лежит один токен
+	turn chan struct{}
+	// batch - запросы текущей пачки, принадлежит обработчику
+	batch []*addRequest
+}
+
+func newStaging() *staging {
+	s := &staging{turn: make(chan struct{}, 1)}
+	s.turn <- struct{}{}
+	return s
+}
+
+// push - ставит запрос в очередь.
+func (s *staging) push(req *addRequest) {
+	shard := &s.shards[s.next.Add(1)%stagingShards]
+	shard.mu.Lock()
+	shard.reqs = append(shard.reqs, req)
+	shard.mu.Unlock()
+}
+
+// drain - забирает все запросы из очередей.
+func (s *staging) drain(reqs []*addRequest) []*addRequest {
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mu.Lock()
+		reqs = append(reqs, shard.reqs...)
+		clear(shard.reqs)
+		shard.reqs = shard.reqs[:0]
+		shard.mu.Unlock()
+	}
+
+	return reqs
+}
+
+// stageAdd - добавляет песню через промежуточную очередь и ждёт результата.
+// Запрос обрабатывает либо сам добавляющий, получив право обработчика,
+// либо другой обработчик, захвативший блокировку раньше.
+func (p *playerImpl) stageAdd(ctx context.Context, song Song) (*addRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	req := &addRequest{ctx: ctx, song: song, done: make(chan struct{})}
+	p.staging.push(req)
+
+	select {
+	case <-req.done:
+	case <-p.staging.turn:
+		err := p.lock(ctx)
+		if err == nil {
+			p.drainStagedLocked()
+			p.mu.Unlock()
+		}
+		p.staging.turn <- struct{}{}
+
+		if err != nil && req.state.CompareAndSwap(addPending, addCanceled) {
+			return nil, err
+		}
+		<-req.done
+	case <-ctx.Done():
+		if req.state.CompareAndSwap(addPending, addCanceled) {
+			return nil, ctx.Err()
+		}
+		// запрос уже забрал обработчик, дожидаемся его
+		<-req.done
+	}
+
+	return req, req.err
+}
+
+// drainStagedLocked - добавляет все ожидающие в промежуточной очереди песни.
+// Вызывается под блокировкой.
+func (p *playerImpl) drainStagedLocked() {
+	batch := p.staging.drain(p.staging.batch[:0])
+	for _, req := range batch {
+		if !req.state.CompareAndSwap(addPending, addTaken) {
+			continue
+		}
+
+		req.id, req.err = p.addStagedLocked(req.ctx, req.song)
+		req.playlist = p.active
+		close(req.done)
+	}
+
+	clear(batch)
+	p.staging.batch = batch[:0]
+}
+
+// addStagedLocked - добавляет песню в конец активного плейлиста, как AddSong.
+// Вызывается под блокировкой.
+func (p *playerImpl) addStagedLocked(ctx context.Context, song Song) (SongID, error) {
+	if p.closed {
+		return 0, ErrClosed
+	}
+	if err := p.checkCooldownLocked(song); err != nil {
+		return 0, err
+	}
+	if err := p.admitLocked(ctx, p.active, song); err != nil {
+		return 0, err
+	}
+
+	// в библиотеку попадают только принятые песни
+	node := p.newNode(song, nil)
+	p.appendNode(node)
+	p.recordEditLocked(p.addEdit(node))
+	p.auditLocked(ctx, AuditEntry{Op: AuditAdd, Song: &song, Detail: p.active})
+	// у текущей песни могла появиться следующая
+	p.rescheduleLocked()
+	return node.id, nil
+}
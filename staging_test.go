@@ -0,0 +1,78 @@
+package player
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_StagedAdd(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("concurrent", func(t *testing.T) {
+		pl, err := New()
+		td.CmpNoError(t, err)
+
+		const workers, each = 8, 500
+		ids := make([][]SongID, workers)
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < each; i++ {
+					id, err := pl.AddSong(ctx, Song{Name: strconv.Itoa(w*each + i), Duration: time.Second})
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					ids[w] = append(ids[w], id)
+				}
+			}(w)
+		}
+		wg.Wait()
+
+		seen := map[SongID]bool{}
+		for _, worker := range ids {
+			for i, id := range worker {
+				td.CmpFalse(t, seen[id], "ID выдаётся один раз")
+				seen[id] = true
+				if i > 0 {
+					td.CmpGt(t, id, worker[i-1], "песни одного вызывающего идут по порядку")
+				}
+			}
+		}
+		td.Cmp(t, len(seen), workers*each)
+		td.Cmp(t, pl.songs.Len(), workers*each)
+		td.Cmp(t, pl.Library().Len(), workers*each)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		pl, err := New()
+		td.CmpNoError(t, err)
+
+		pl.mu.Lock()
+		tctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		_, err = pl.AddSong(tctx, minuteSong("a"))
+		cancel()
+		pl.mu.Unlock()
+		td.Cmp(t, err, context.DeadlineExceeded)
+
+		_, err = pl.AddSong(ctx, minuteSong("b"))
+		td.CmpNoError(t, err)
+		td.Cmp(t, names(pl), []string{"b"}, "отменённая песня не добавлена")
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		pl, err := New()
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, pl.Close(ctx))
+
+		_, err = pl.AddSong(ctx, minuteSong("a"))
+		td.Cmp(t, err, ErrClosed)
+	})
+}
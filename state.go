@@ -0,0 +1,218 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// PlayerState - сериализуемое состояние плеера для восстановления после сбоя.
+// Обработчики песен, правила умных плейлистов, история и статистика в состояние не входят.
+type PlayerState struct {
+	// Active - название активного плейлиста
+	Active string `json:"active"`
+	// Playlists - все плейлисты плеера
+	Playlists []PlaylistState `json:"playlists"`
+	// IsPlaying - шло ли воспроизведение
+	IsPlaying bool `json:"is_playing"`
+	// EQ - усиление полос эквалайзера, пусто если не задавался
+	EQ []float64 `json:"eq,omitempty"`
+	// Quota - время прослушивания за день, пусто если сегодня не слушали
+	Quota *QuotaUsage `json:"quota,omitempty"`
+	// Queue - очередь Enqueue, начиная с играющей песни очереди
+	Queue []Song `json:"queue,omitempty"`
+	// Rand - состояние генератора Shuffle и WeightedRandom, пусто если источник не ReplayableRand
+	Rand *RandState `json:"rand,omitempty"`
+	// Sequence - выбор секвенсора для текущей песни, пусто если выбор не сделан
+	Sequence *SequenceState `json:"sequence,omitempty"`
+}
+
+// PlaylistState - сериализуемое состояние плейлиста.
+type PlaylistState struct {
+	// Name - название плейлиста
+	Name string `json:"name"`
+	// Songs - песни по порядку
+	Songs []Song `json:"songs"`
+	// Cursor - индекс текущей песни, -1 для пустого плейлиста
+	Cursor int `json:"cursor"`
+	// PlayedTime - позиция воспроизведения текущей песни
+	PlayedTime time.Duration `json:"played_time"`
+}
+
+// Snapshot - возвращает текущее состояние плеера.
+func (p *playerImpl) Snapshot(ctx context.Context) (PlayerState, error) {
+	if err := p.rlock(ctx); err != nil {
+		return PlayerState{}, err
+	}
+	defer p.mu.RUnlock()
+
+	return p.snapshotLocked(), nil
+}
+
+// snapshotLocked - сериализуемое состояние плеера, как Snapshot.
+// Вызывается под блокировкой.
+func (p *playerImpl) snapshotLocked() PlayerState {
+	active := p.playlist
+	active.playedTime = p.elapsedLocked()
+	// вставка не входит в плейлист, сохраняем прерванную песню
+	if in := p.interruption; in != nil {
+		active.current, active.playedTime = in.node, in.position
+	}
+
+	state := PlayerState{
+		Active:    p.active,
+		Playlists: []PlaylistState{active.state(p.active)},
+		IsPlaying: p.isPlaying,
+		EQ:        append([]float64(nil), p.eq...),
+		Rand:      p.randStateLocked(),
+		Sequence:  p.sequenceStateLocked(&active),
+	}
+	if usage := p.usageLocked(p.now()); usage.Total > 0 {
+		state.Quota = &usage
+	}
+	// играющая песня очереди начнётся заново
+	if in := p.interruption; in != nil && in.queued {
+		state.Queue = append(state.Queue, *in.jingle.song)
+	}
+	state.Queue = append(state.Queue, p.upNext.Values()...)
+	if len(state.Queue) == 0 {
+		state.Queue = nil
+	}
+
+	names := make([]string, 0, len(p.playlists))
+	for name := range p.playlists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		state.Playlists = append(state.Playlists, p.playlists[name].state(name))
+	}
+
+	return state
+}
+
+// RestoreState - заменяет состояние плеера сохранённым.
+// Если в сохранённом состоянии шло воспроизведение, оно продолжается с сохранённой позиции.
+func (p *playerImpl) RestoreState(ctx context.Context, state PlayerState) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	playlists := make(map[string]*playlist, len(state.Playlists))
+	for i, ps := range state.Playlists {
+		if ps.Name == "" {
+			return fmt.Errorf("playlists[%d]: playlist name is empty", i)
+		}
+		if _, ok := playlists[ps.Name]; ok {
+			return fmt.Errorf("playlists[%d]: %w", i, ErrPlaylistExists)
+		}
+
+		pl, err := p.restorePlaylistLocked(ps)
+		if err != nil {
+			return fmt.Errorf("playlists[%d]: %w", i, err)
+		}
+		playlists[ps.Name] = pl
+	}
+
+	active, ok := playlists[state.Active]
+	if !ok {
+		return fmt.Errorf("active playlist %q: %w", state.Active, ErrPlaylistNotFound)
+	}
+
+	if state.EQ != nil {
+		if err := validateEQ(state.EQ); err != nil {
+			return err
+		}
+	}
+	delete(playlists, state.Active)
+
+	p.haltLocked(ctx)
+	p.playlist = *active
+	p.setActiveLocked(state.Active)
+	p.resetEditsLocked()
+	p.playlists = playlists
+	p.section = nil
+
+	// правила сохраняются для умных плейлистов с теми же названиями
+	for name := range p.smart {
+		if !p.hasPlaylistLocked(name) {
+			delete(p.smart, name)
+		}
+	}
+	p.interruption = nil
+	p.afterCurrent = nil
+	p.restoreQueueLocked(state.Queue)
+	p.restoreRandLocked(state.Rand)
+	p.restoreSequenceLocked(state.Sequence)
+	p.logger.InfoContext(ctx, "state restored", slog.String("playlist", state.Active))
+
+	if state.EQ != nil {
+		p.eq = append([]float64(nil), state.EQ...)
+		if err := p.applyEQLocked(ctx); err != nil {
+			return err
+		}
+	}
+
+	p.restoreQuotaLocked(state.Quota)
+	if state.IsPlaying {
+		err := p.playLocked(ctx)
+		if errors.Is(err, ErrQuotaExceeded) {
+			p.logger.InfoContext(ctx, "playback not resumed", slog.Any("reason", err))
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
+
+// state - возвращает сериализуемое состояние плейлиста.
+func (pl *playlist) state(name string) PlaylistState {
+	ps := PlaylistState{Name: name, Cursor: -1, PlayedTime: pl.playedTime}
+
+	for i, node := 0, pl.first(); node != nil; i, node = i+1, node.next() {
+		ps.Songs = append(ps.Songs, *node.song)
+		if node == pl.current {
+			ps.Cursor = i
+		}
+	}
+
+	return ps
+}
+
+// restorePlaylistLocked - восстанавливает плейлист из сохранённого состояния,
+// добавляя песни в библиотеку.
+// Вызывается под блокировкой.
+func (p *playerImpl) restorePlaylistLocked(ps PlaylistState) (*playlist, error) {
+	if len(ps.Songs) == 0 && ps.Cursor != -1 || len(ps.Songs) > 0 && (ps.Cursor < 0 || ps.Cursor >= len(ps.Songs)) {
+		return nil, fmt.Errorf("cursor %d out of range", ps.Cursor)
+	}
+	if ps.PlayedTime < 0 {
+		return nil, errors.New("played time is negative")
+	}
+	if !p.limits.fitSongs(ps.Songs) {
+		return nil, ErrPlaylistFull
+	}
+
+	pl := &playlist{}
+	for _, s := range ps.Songs {
+		pl.appendTrack(p.library.add(s, p.now()), nil)
+	}
+
+	pl.current = pl.first()
+	for i := 0; i < ps.Cursor; i++ {
+		pl.current = pl.current.next()
+	}
+	pl.playedTime = ps.PlayedTime
+
+	return pl, nil
+}
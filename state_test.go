@@ -0,0 +1,74 @@
+package player
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Snapshot(t *testing.T) {
+	ctx := context.Background()
+	sg := Song{Name: "Сектор Газа - 30 лет", Duration: 30 * time.Second}
+	ap := Song{Name: "Александр Пушной - Почему я идиот?", Duration: 30 * time.Second}
+	shuff := Song{Name: "Михаил Шуфутинский - 3 сентября", Duration: 30 * time.Second}
+
+	pl, _ := NewPlayer(sg, ap)
+	_ = pl.CreatePlaylist(ctx, "chanson")
+	_, _ = pl.AddSongTo(ctx, "chanson", shuff)
+	_ = pl.CreatePlaylist(ctx, "empty")
+
+	pl.current = pl.last()
+	_ = pl.Play(ctx)
+	time.Sleep(20 * time.Millisecond)
+
+	state, err := pl.Snapshot(ctx)
+	td.CmpNoError(t, err)
+	_ = pl.Pause(ctx)
+
+	td.Cmp(t, state, td.Struct(PlayerState{
+		Active:    DefaultPlaylist,
+		IsPlaying: true,
+	}, td.StructFields{
+		"Playlists": td.Slice([]PlaylistState{}, td.ArrayEntries{
+			0: td.Struct(PlaylistState{Name: DefaultPlaylist, Songs: []Song{sg, ap}, Cursor: 1}, td.StructFields{
+				"PlayedTime": td.Gte(20 * time.Millisecond),
+			}),
+			1: PlaylistState{Name: "chanson", Songs: []Song{shuff}, Cursor: 0},
+			2: PlaylistState{Name: "empty", Cursor: -1},
+		}),
+	}))
+
+	// состояние переживает сериализацию
+	b, err := json.Marshal(state)
+	td.CmpNoError(t, err)
+	var decoded PlayerState
+	td.CmpNoError(t, json.Unmarshal(b, &decoded))
+
+	restored, _ := NewPlayer()
+	td.CmpNoError(t, restored.RestoreState(ctx, decoded))
+	td.CmpTrue(t, restored.isPlaying, "воспроизведение продолжено")
+	_ = restored.Pause(ctx)
+
+	td.Cmp(t, *restored.current.song, ap)
+	td.Cmp(t, restored.playedTime, td.Gte(state.Playlists[0].PlayedTime))
+	td.Cmp(t, *restored.playlists["chanson"].current.song, shuff)
+
+	t.Run("invalid state", func(t *testing.T) {
+		pl, _ := NewPlayer()
+		td.CmpError(t, pl.RestoreState(ctx, PlayerState{Active: "unknown"}))
+		td.CmpError(t, pl.RestoreState(ctx, PlayerState{
+			Active:    DefaultPlaylist,
+			Playlists: []PlaylistState{{Name: DefaultPlaylist, Songs: []Song{sg}, Cursor: 1}},
+		}))
+		td.CmpError(t, pl.RestoreState(ctx, PlayerState{
+			Active: DefaultPlaylist,
+			Playlists: []PlaylistState{
+				{Name: DefaultPlaylist, Cursor: -1},
+				{Name: DefaultPlaylist, Cursor: -1},
+			},
+		}))
+	})
+}
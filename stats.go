@@ -0,0 +1,71 @@
+package player
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// SongStats - статистика воспроизведения песни.
+type SongStats struct {
+	// Song - песня
+	Song Song
+	// PlayCount - сколько раз песня доиграла до конца
+	PlayCount int
+	// SkipCount - сколько раз песню пропустили
+	SkipCount int
+	// Listened - сколько всего песню слушали
+	Listened time.Duration
+	// LastPlayed - когда песню слушали последний раз
+	LastPlayed time.Time
+	// Rating - оценка от 1 до 5 звёзд, 0 - без оценки
+	Rating int
+	// Favorite - песня в избранном
+	Favorite bool
+}
+
+// Stats - возвращает статистику по всем песням, которые хотя бы раз играли или были оценены.
+// Самые прослушиваемые песни идут первыми.
+func (p *playerImpl) Stats(_ context.Context) []SongStats {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	stats := make([]SongStats, 0, len(p.stats))
+	for _, s := range p.stats {
+		stats = append(stats, *s)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].PlayCount != stats[j].PlayCount {
+			return stats[i].PlayCount > stats[j].PlayCount
+		}
+		return stats[i].Song.Name < stats[j].Song.Name
+	})
+
+	return stats
+}
+
+// recordStatsLocked - обновляет статистику песни.
+// Вызывается под блокировкой.
+func (p *playerImpl) recordStatsLocked(song Song, played time.Duration, completed bool, at time.Time) {
+	s := p.songStatsLocked(song)
+	if completed {
+		s.PlayCount++
+	} else {
+		s.SkipCount++
+	}
+	s.Listened += played
+	s.LastPlayed = at
+}
+
+// songStatsLocked - возвращает статистику песни, создавая её при необходимости.
+// Вызывается под блокировкой.
+func (p *playerImpl) songStatsLocked(song Song) *SongStats {
+	s, ok := p.stats[songKey(song)]
+	if !ok {
+		s = &SongStats{Song: song}
+		p.stats[songKey(song)] = s
+	}
+
+	return s
+}
@@ -0,0 +1,34 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Stats(t *testing.T) {
+	ctx := context.Background()
+	sg := Song{Name: "Сектор Газа - 30 лет", Duration: 30 * time.Millisecond}
+	ap := Song{Name: "Александр Пушной - Почему я идиот?", Duration: 30 * time.Second}
+
+	pl, _ := New(WithValidator(ValidationPolicy{}), WithSongs(sg, ap))
+	td.CmpEmpty(t, pl.Stats(ctx), "ничего не играло")
+
+	_ = pl.Play(ctx)
+	time.Sleep(50 * time.Millisecond)
+	_ = pl.Prev(ctx)
+	time.Sleep(50 * time.Millisecond)
+	_ = pl.Pause(ctx)
+
+	td.Cmp(t, pl.Stats(ctx), td.Slice([]SongStats{}, td.ArrayEntries{
+		0: td.Struct(SongStats{Song: sg, PlayCount: 2, Listened: 2 * sg.Duration}, td.StructFields{
+			"LastPlayed": td.NotZero(),
+		}),
+		1: td.Struct(SongStats{Song: ap, SkipCount: 1}, td.StructFields{
+			"Listened":   td.Gte(10 * time.Millisecond),
+			"LastPlayed": td.NotZero(),
+		}),
+	}))
+}
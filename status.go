@@ -0,0 +1,68 @@
+package player
+
+import (
+	"context"
+	"time"
+)
+
+// Status - состояние воспроизведения.
+type Status struct {
+	// Playlist - название активного плейлиста
+	Playlist string `json:"playlist"`
+	// Song - текущая песня, nil для пустого плейлиста
+	Song *Song `json:"song"`
+	// SongID - ID текущей песни, 0 для пустого плейлиста и вставки
+	SongID SongID `json:"song_id"`
+	// Position - позиция воспроизведения текущей песни
+	Position time.Duration `json:"position"`
+	// Playing - идёт ли воспроизведение
+	Playing bool `json:"playing"`
+	// Volume - громкость от 0 до 100
+	Volume int `json:"volume"`
+	// Muted - звук выключен
+	Muted bool `json:"muted"`
+	// Chapter - текущая глава песни, nil если глав нет
+	Chapter *Chapter `json:"chapter,omitempty"`
+	// Cue - текущая дорожка CUE, nil если дорожек нет
+	Cue *CueTrack `json:"cue,omitempty"`
+	// CuePosition - позиция воспроизведения от начала дорожки Cue
+	CuePosition time.Duration `json:"cue_position,omitempty"`
+}
+
+func (p *playerImpl) Status(_ context.Context) Status {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.statusLocked()
+}
+
+// statusLocked - возвращает состояние воспроизведения.
+// Вызывается под блокировкой.
+func (p *playerImpl) statusLocked() Status {
+	st := Status{
+		Playlist: p.active,
+		Playing:  p.isPlaying,
+		Volume:   p.volume,
+		Muted:    p.muted,
+	}
+
+	if p.current != nil {
+		song := *p.current.song
+		st.Song = &song
+		st.SongID = p.current.id
+		st.Position = p.elapsedLocked()
+
+		if i := chapterAt(song, st.Position); i >= 0 {
+			chapter := song.Chapters[i]
+			st.Chapter = &chapter
+		}
+
+		if i := cueAt(song, st.Position); i >= 0 {
+			cue := song.Cues[i]
+			st.Cue = &cue
+			st.CuePosition = st.Position - cue.Start
+		}
+	}
+
+	return st
+}
@@ -0,0 +1,260 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// ErrStateNotFound - в хранилище нет сохранённого состояния.
+var ErrStateNotFound = errors.New("state not found")
+
+// Storage - хранилище, в котором плеер сохраняет плейлисты, историю и состояние
+// между перезапусками.
+type Storage interface {
+	// SavePlaylist - сохраняет песни плейлиста, заменяя ранее сохранённые
+	SavePlaylist(ctx context.Context, name string, songs []Song) error
+	// LoadPlaylist - возвращает песни плейлиста или ErrPlaylistNotFound
+	LoadPlaylist(ctx context.Context, name string) ([]Song, error)
+	// AppendHistory - дописывает запись истории воспроизведения
+	AppendHistory(ctx context.Context, entry HistoryEntry) error
+	// LoadHistory - возвращает всю историю от старых записей к новым
+	LoadHistory(ctx context.Context) ([]HistoryEntry, error)
+	// SaveState - сохраняет состояние плеера
+	SaveState(ctx context.Context, state PlayerState) error
+	// LoadState - возвращает сохранённое состояние или ErrStateNotFound
+	LoadState(ctx context.Context) (PlayerState, error)
+}
+
+// WithStorage - задаёт хранилище. При создании плеер восстанавливает из него
+// историю, статистику и состояние, которое заменяет песни из WithSongs.
+// История дописывается по мере воспроизведения, состояние сохраняется
+// методом Persist, при закрытии плеера и, пока действуют лимиты
+// прослушивания, раз в минуту во время воспроизведения.
+// По умолчанию используется хранилище в памяти. На диске состояние хранят
+// FileStorage и boltstore.Store из отдельного модуля player/boltstore.
+func WithStorage(s Storage) Option {
+	return func(p *playerImpl) error {
+		if s == nil {
+			return errors.New("storage is nil")
+		}
+
+		p.storage = s
+		return nil
+	}
+}
+
+// Persist - сохраняет состояние плеера в хранилище.
+func (p *playerImpl) Persist(ctx context.Context) error {
+	state, err := p.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := p.storage.SaveState(ctx, state); err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+
+	return nil
+}
+
+// SavePlaylist - сохраняет песни плейлиста name в хранилище.
+func (p *playerImpl) SavePlaylist(ctx context.Context, name string) error {
+	if err := p.rlock(ctx); err != nil {
+		return err
+	}
+	pl, ok := p.playlistLocked(name)
+	var songs []Song
+	if ok {
+		for n := pl.first(); n != nil; n = n.next() {
+			songs = append(songs, *n.song)
+		}
+	}
+	p.mu.RUnlock()
+
+	if !ok {
+		return ErrPlaylistNotFound
+	}
+
+	if err := p.storage.SavePlaylist(ctx, name, songs); err != nil {
+		return fmt.Errorf("save playlist: %v", err)
+	}
+
+	return nil
+}
+
+// LoadPlaylist - создаёт плейлист name из песен, сохранённых в хранилище.
+// Если песни не помещаются в ограничения плейлистов, возвращает ErrPlaylistFull.
+func (p *playerImpl) LoadPlaylist(ctx context.Context, name string) error {
+	songs, err := p.storage.LoadPlaylist(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if p.hasPlaylistLocked(name) {
+		return ErrPlaylistExists
+	}
+
+	if !p.limits.fitSongs(songs) {
+		return ErrPlaylistFull
+	}
+
+	pl := &playlist{}
+	for _, song := range songs {
+		pl.appendNode(p.newNode(song, nil))
+	}
+	p.playlists[name] = pl
+
+	p.logger.InfoContext(ctx, "playlist loaded", slog.String("playlist", name), slog.Int("songs", len(songs)))
+	return nil
+}
+
+// loadStorage - восстанавливает историю, статистику и состояние из хранилища.
+func (p *playerImpl) loadStorage(ctx context.Context) error {
+	history, err := p.storage.LoadHistory(ctx)
+	if err != nil {
+		return fmt.Errorf("load history: %v", err)
+	}
+
+	p.mu.Lock()
+	for _, e := range history {
+		p.recordHistoryLocked(e)
+		p.recordStatsLocked(e.Song, e.Played, e.Completed, e.FinishedAt)
+		p.counters.record(e.Played, e.Completed)
+	}
+	p.mu.Unlock()
+
+	state, err := p.storage.LoadState(ctx)
+	if errors.Is(err, ErrStateNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("load state: %v", err)
+	}
+
+	return p.RestoreState(ctx, state)
+}
+
+// persistHistoryLocked - дописывает запись истории в хранилище в отдельной горутине.
+// Вызывается под блокировкой.
+func (p *playerImpl) persistHistoryLocked(entry HistoryEntry) {
+	s := p.storage
+	p.hookQueue.push(func() {
+		if err := s.AppendHistory(context.Background(), entry); err != nil {
+			p.logger.Error("append history failed", songAttr(entry.Song), slog.Any("error", err))
+		}
+	})
+}
+
+// playlistLocked - возвращает плейлист по названию, включая активный.
+// Вызывается под блокировкой.
+func (p *playerImpl) playlistLocked(name string) (*playlist, bool) {
+	if name == p.active {
+		return &p.playlist, true
+	}
+
+	pl, ok := p.playlists[name]
+	return pl, ok
+}
+
+// MemoryStorage - хранилище в памяти, не переживает перезапуск процесса.
+type MemoryStorage struct {
+	mu        sync.Mutex
+	playlists map[string][]Song
+	history   []HistoryEntry
+	state     *PlayerState
+	zones     map[string]PlayerState
+}
+
+// NewMemoryStorage - конструктор для MemoryStorage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{playlists: make(map[string][]Song)}
+}
+
+func (s *MemoryStorage) SavePlaylist(_ context.Context, name string, songs []Song) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.playlists[name] = append([]Song(nil), songs...)
+	return nil
+}
+
+func (s *MemoryStorage) LoadPlaylist(_ context.Context, name string) ([]Song, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	songs, ok := s.playlists[name]
+	if !ok {
+		return nil, ErrPlaylistNotFound
+	}
+
+	return append([]Song(nil), songs...), nil
+}
+
+func (s *MemoryStorage) AppendHistory(_ context.Context, entry HistoryEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.history = append(s.history, entry)
+	return nil
+}
+
+func (s *MemoryStorage) LoadHistory(_ context.Context) ([]HistoryEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]HistoryEntry(nil), s.history...), nil
+}
+
+func (s *MemoryStorage) SaveState(_ context.Context, state PlayerState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state = &state
+	return nil
+}
+
+func (s *MemoryStorage) LoadState(_ context.Context) (PlayerState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == nil {
+		return PlayerState{}, ErrStateNotFound
+	}
+
+	return *s.state, nil
+}
+
+func (s *MemoryStorage) SaveZoneState(_ context.Context, zone string, state PlayerState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.zones == nil {
+		s.zones = make(map[string]PlayerState)
+	}
+	s.zones[zone] = state
+	return nil
+}
+
+func (s *MemoryStorage) LoadZoneState(_ context.Context, zone string) (PlayerState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.zones[zone]
+	if !ok {
+		return PlayerState{}, ErrStateNotFound
+	}
+
+	return state, nil
+}
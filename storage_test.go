@@ -0,0 +1,93 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Storage(t *testing.T) {
+	ctx := context.Background()
+
+	short := Song{Name: "Сектор Газа - 30 лет", Duration: 50 * time.Millisecond}
+	long := Song{Name: "Александр Пушной - Почему я идиот?", Duration: 30 * time.Second}
+
+	t.Run("history, stats and state survive restart", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := NewFileStorage(dir)
+		td.CmpNoError(t, err)
+
+		pl, err := New(WithValidator(ValidationPolicy{}), WithStorage(s), WithSongs(short, long))
+		td.CmpNoError(t, err)
+		_ = pl.Play(ctx)
+		time.Sleep(70 * time.Millisecond)
+		td.CmpNoError(t, pl.Close(ctx))
+
+		s, err = NewFileStorage(dir)
+		td.CmpNoError(t, err)
+		pl, err = New(WithStorage(s))
+		td.CmpNoError(t, err)
+
+		td.Cmp(t, history(t, pl, 0), td.Len(1), "история восстановлена")
+		td.Cmp(t, history(t, pl, 0)[0].Song, short)
+		td.Cmp(t, pl.Stats(ctx), td.Len(1), "статистика восстановлена")
+		td.Cmp(t, names(pl), []string{short.Name, long.Name}, "плейлист восстановлен")
+		td.Cmp(t, pl.current.song.Name, long.Name, "текущая песня восстановлена")
+		td.CmpFalse(t, pl.Status(ctx).Playing)
+	})
+
+	t.Run("playlists", func(t *testing.T) {
+		s := NewMemoryStorage()
+		pl, err := New(WithValidator(ValidationPolicy{}), WithStorage(s), WithSongs(short, long))
+		td.CmpNoError(t, err)
+
+		td.CmpNoError(t, pl.SavePlaylist(ctx, DefaultPlaylist))
+		td.Cmp(t, pl.SavePlaylist(ctx, "нет такого"), ErrPlaylistNotFound)
+		td.Cmp(t, pl.LoadPlaylist(ctx, DefaultPlaylist), ErrPlaylistExists)
+		td.Cmp(t, pl.LoadPlaylist(ctx, "нет такого"), ErrPlaylistNotFound)
+
+		_ = s.SavePlaylist(ctx, "копия", []Song{long})
+		td.CmpNoError(t, pl.LoadPlaylist(ctx, "копия"))
+		td.CmpNoError(t, pl.SwitchPlaylist(ctx, "копия"))
+		td.Cmp(t, names(pl), []string{long.Name}, "плейлист загружен из хранилища")
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		s := NewMemoryStorage()
+		_ = s.SavePlaylist(ctx, "копия", []Song{short})
+		pl, _ := New(WithStorage(s))
+		_ = pl.Close(ctx)
+
+		td.Cmp(t, pl.LoadPlaylist(ctx, "копия"), ErrClosed)
+		_, err := s.LoadState(ctx)
+		td.CmpNoError(t, err, "состояние сохранено при закрытии")
+	})
+
+	t.Run("failing storage", func(t *testing.T) {
+		pl, _ := New(WithStorage(failingStorage{}))
+		td.CmpTrue(t, errors.Is(pl.Close(ctx), errStorage))
+	})
+
+	t.Run("nil storage", func(t *testing.T) {
+		_, err := New(WithStorage(nil))
+		td.CmpString(t, err, "storage is nil")
+	})
+}
+
+var errStorage = errors.New("storage failure")
+
+// failingStorage - хранилище, которое ничего не хранит и не даёт сохранить состояние.
+type failingStorage struct {
+	*MemoryStorage
+}
+
+func (failingStorage) LoadHistory(context.Context) ([]HistoryEntry, error) { return nil, nil }
+
+func (failingStorage) LoadState(context.Context) (PlayerState, error) {
+	return PlayerState{}, ErrStateNotFound
+}
+
+func (failingStorage) SaveState(context.Context, PlayerState) error { return errStorage }
@@ -0,0 +1,50 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestNewStream(t *testing.T) {
+	radio, err := NewStream("Радио Шансон", "http://chanson.example/stream")
+	td.CmpNoError(t, err)
+	td.CmpTrue(t, radio.IsStream())
+
+	_, err = NewStream("Радио Шансон", "")
+	td.CmpError(t, err, "поток без адреса")
+
+	_, err = NewSong("jingle", 0)
+	td.CmpError(t, err, "NewSong по-прежнему требует длительность")
+}
+
+func TestStreamPlayback(t *testing.T) {
+	ctx := context.Background()
+	radio, _ := NewStream("Радио Шансон", "http://chanson.example/stream")
+	song := Song{Name: "a", Duration: 30 * time.Second}
+
+	out := &recordingOutput{}
+	pl, _ := New(WithOutput(out), WithCrossfade(10*time.Millisecond), WithSongs(radio, song))
+
+	_ = pl.Play(ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	st := pl.Status(ctx)
+	td.Cmp(t, st.Song.Name, radio.Name, "поток не переключается по таймеру")
+	td.CmpTrue(t, st.Playing)
+	td.Cmp(t, st.Position, td.Gte(50*time.Millisecond), "позиция растёт")
+	td.Cmp(t, pl.Elapsed(ctx), td.Gte(50*time.Millisecond))
+	td.Cmp(t, pl.Remaining(ctx), time.Duration(0))
+
+	td.Cmp(t, pl.PlayFrom(ctx, 0, time.Second), td.NotNil(), "поток нельзя начать с середины")
+
+	td.CmpNoError(t, pl.Next(ctx))
+	td.Cmp(t, pl.Status(ctx).Song.Name, "a", "Next переключает поток")
+	_ = pl.Pause(ctx)
+
+	td.Cmp(t, out.Calls(), []string{"start " + radio.Name, "stop " + radio.Name, "start a", "stop a"})
+	td.Cmp(t, history(t, pl, 1), td.Len(1))
+	td.Cmp(t, history(t, pl, 1)[0].Played, td.Gte(50*time.Millisecond), "пропуск потока записан с временем прослушивания")
+}
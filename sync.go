@@ -0,0 +1,199 @@
+package player
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// syncTolerance - расхождение позиции с ведущим, при котором ведомый не перематывает песню.
+const syncTolerance = 250 * time.Millisecond
+
+// SyncState - состояние воспроизведения ведущего плеера в момент At по его часам.
+type SyncState struct {
+	// Song - текущая песня ведущего, nil для пустого плейлиста
+	Song *Song `json:"song,omitempty"`
+	// Position - позиция песни в момент At
+	Position time.Duration `json:"position"`
+	// Playing - идёт ли воспроизведение
+	Playing bool `json:"playing"`
+	// At - момент снятия состояния
+	At time.Time `json:"at"`
+}
+
+// SyncSink - транспорт, по которому ведущий рассылает своё состояние.
+type SyncSink interface {
+	Send(ctx context.Context, state SyncState) error
+}
+
+// SyncSource - транспорт, из которого ведомый получает состояние ведущего.
+type SyncSource interface {
+	// Recv - блокируется до получения следующего состояния
+	Recv(ctx context.Context) (SyncState, error)
+}
+
+// SyncState - возвращает текущее состояние для рассылки ведомым.
+func (p *playerImpl) SyncState(_ context.Context) SyncState {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	st := SyncState{Playing: p.isPlaying, At: p.now()}
+	if p.current != nil {
+		song := *p.current.song
+		st.Song = &song
+		st.Position = p.elapsedLocked()
+	}
+
+	return st
+}
+
+// PublishSync - рассылает состояние плеера в sink каждые interval по часам плеера,
+// пока не завершится ctx.
+func (p *playerImpl) PublishSync(ctx context.Context, sink SyncSink, interval time.Duration) error {
+	if interval <= 0 {
+		return errors.New("sync interval must be positive")
+	}
+
+	for {
+		if err := sink.Send(ctx, p.SyncState(ctx)); err != nil {
+			return fmt.Errorf("send sync state: %w", err)
+		}
+
+		due, cancel := p.after(interval)
+		select {
+		case <-ctx.Done():
+			cancel()
+			return ctx.Err()
+		case <-due:
+		}
+	}
+}
+
+// SyncTo - делает плеер ведомым: повторяет песню, позицию и воспроизведение
+// ведущего, пока не завершится ctx или src не вернёт ошибку.
+// Песня ведущего, которой нет в активном плейлисте, добавляется в его конец.
+func (p *playerImpl) SyncTo(ctx context.Context, src SyncSource) error {
+	for {
+		st, err := src.Recv(ctx)
+		if err != nil {
+			return fmt.Errorf("receive sync state: %w", err)
+		}
+
+		if err := p.ApplySync(ctx, st); err != nil {
+			return err
+		}
+	}
+}
+
+// ApplySync - приводит плеер к состоянию ведущего. Задержка доставки
+// считается по часам плеера, поэтому они должны идти вместе с часами ведущего.
+func (p *playerImpl) ApplySync(ctx context.Context, st SyncState) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if st.Song == nil {
+		p.pauseLocked(ctx)
+		return nil
+	}
+
+	if p.current == nil || libraryKey(*p.current.song) != libraryKey(*st.Song) {
+		node := p.findSongLocked(*st.Song)
+		if node == nil {
+			if err := p.admitLocked(ctx, p.active, *st.Song); err != nil {
+				return err
+			}
+			node = p.newNode(*st.Song, nil)
+			p.appendNode(node)
+			p.recordEditLocked(p.addEdit(node))
+			song := *st.Song
+			p.auditLocked(ctx, AuditEntry{Op: AuditAdd, Song: &song, Detail: p.active})
+		}
+
+		if p.current != nil {
+			p.skipLocked()
+		}
+		p.haltLocked(ctx)
+		p.section = nil
+		p.cancelInterruptionLocked()
+		p.moveToLocked(node)
+		p.logger.InfoContext(ctx, "synced to leader song", songAttr(*st.Song))
+	}
+
+	pos := st.Position
+	if st.Playing {
+		pos += p.since(st.At)
+	}
+
+	if !st.Playing {
+		p.pauseLocked(ctx)
+		p.playedTime = pos
+		return nil
+	}
+
+	if d := p.elapsedLocked() - pos; !st.Song.IsStream() && (d > syncTolerance || d < -syncTolerance) {
+		if p.isPlaying {
+			p.seekLocked(ctx, pos)
+			p.rescheduleLocked()
+		} else {
+			p.playedTime = pos
+		}
+		p.logger.DebugContext(ctx, "synced to leader position", slog.Duration("position", pos))
+	}
+
+	return p.playLocked(ctx)
+}
+
+// findSongLocked - ищет первую песню активного плейлиста, совпадающую с song.
+// Вызывается под блокировкой.
+func (p *playerImpl) findSongLocked(song Song) *playerNode {
+	key := libraryKey(song)
+	for n := p.first(); n != nil; n = n.next() {
+		if libraryKey(*n.song) == key {
+			return n
+		}
+	}
+
+	return nil
+}
+
+// jsonSync - транспорт синхронизации в виде JSON по строкам,
+// например поверх TCP-соединения.
+type jsonSync struct {
+	enc *json.Encoder
+	dec *json.Decoder
+}
+
+// NewJSONSyncSink - транспорт ведущего, пишущий состояния в w по одному JSON на строку.
+func NewJSONSyncSink(w io.Writer) SyncSink {
+	return &jsonSync{enc: json.NewEncoder(w)}
+}
+
+// NewJSONSyncSource - транспорт ведомого, читающий состояния из r.
+// Отмена ctx не прерывает чтение, для этого нужно закрыть r.
+func NewJSONSyncSource(r io.Reader) SyncSource {
+	return &jsonSync{dec: json.NewDecoder(bufio.NewReader(r))}
+}
+
+func (s *jsonSync) Send(_ context.Context, st SyncState) error {
+	return s.enc.Encode(st)
+}
+
+func (s *jsonSync) Recv(ctx context.Context) (SyncState, error) {
+	var st SyncState
+	if err := s.dec.Decode(&st); err != nil {
+		return SyncState{}, err
+	}
+
+	return st, ctx.Err()
+}
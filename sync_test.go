@@ -0,0 +1,99 @@
+package player
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_SyncTo(t *testing.T) {
+	ctx := context.Background()
+	a := Song{Name: "a", Duration: 30 * time.Second}
+	b := Song{Name: "b", Duration: 30 * time.Second}
+
+	leader, _ := NewPlayer(a, b)
+	follower, _ := NewPlayer(a)
+
+	r, w := io.Pipe()
+	pctx, cancel := context.WithCancel(ctx)
+	published := make(chan error, 1)
+	go func() { published <- leader.PublishSync(pctx, NewJSONSyncSink(w), 10*time.Millisecond) }()
+
+	synced := make(chan error, 1)
+	go func() { synced <- follower.SyncTo(ctx, NewJSONSyncSource(r)) }()
+
+	td.CmpNoError(t, leader.PlayFrom(ctx, 1, 10*time.Second))
+	time.Sleep(50 * time.Millisecond)
+
+	st := follower.Status(ctx)
+	td.Cmp(t, st.Song.Name, "b", "песня ведущего добавлена и играет")
+	td.CmpTrue(t, st.Playing)
+	td.Cmp(t, st.Position, td.Between(10*time.Second, 10*time.Second+syncTolerance+50*time.Millisecond))
+	td.Cmp(t, follower.Len(ctx), 2)
+
+	_ = leader.Pause(ctx)
+	time.Sleep(30 * time.Millisecond)
+	td.CmpFalse(t, follower.Status(ctx).Playing, "пауза ведущего")
+	td.Cmp(t, follower.Status(ctx).Position, leader.Status(ctx).Position)
+
+	cancel()
+	td.Cmp(t, <-published, context.Canceled)
+	_ = w.Close()
+	td.CmpError(t, <-synced, "транспорт закрыт")
+}
+
+func TestPlayerImpl_ApplySync(t *testing.T) {
+	ctx := context.Background()
+	a := Song{Name: "a", Duration: 30 * time.Second}
+	pl, _ := NewPlayer(a)
+
+	td.CmpNoError(t, pl.ApplySync(ctx, SyncState{Song: &a, Position: 5 * time.Second, Playing: true, At: time.Now().Add(-time.Second)}))
+	td.Cmp(t, pl.Elapsed(ctx), td.Between(6*time.Second, 6*time.Second+50*time.Millisecond), "учтена задержка доставки")
+
+	td.CmpNoError(t, pl.ApplySync(ctx, SyncState{Song: &a, Position: 6*time.Second + 100*time.Millisecond, Playing: true, At: time.Now()}))
+	td.Cmp(t, pl.Elapsed(ctx), td.Lt(6*time.Second+100*time.Millisecond), "малое расхождение не перематывается")
+
+	td.CmpNoError(t, pl.ApplySync(ctx, SyncState{At: time.Now()}))
+	td.CmpFalse(t, pl.Status(ctx).Playing, "у ведущего пустой плейлист")
+	td.Cmp(t, pl.Close(ctx), nil)
+	td.Cmp(t, pl.ApplySync(ctx, SyncState{}), ErrClosed)
+}
+
+func TestPlayerImpl_SyncClock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("state", func(t *testing.T) {
+		clock := NewFakeClock(testStart)
+		pl, _ := New(WithClock(clock), WithSongs(minuteSong("a")))
+		td.CmpNoError(t, pl.PlayFrom(ctx, 0, 5*time.Second))
+		defer pl.Pause(ctx)
+
+		clock.Advance(time.Second)
+		td.Cmp(t, pl.SyncState(ctx), SyncState{Song: &Song{Name: "a", Duration: time.Minute}, Position: 6 * time.Second, Playing: true, At: testStart.Add(time.Second)})
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		clock := NewFakeClock(testStart.Add(time.Second))
+		pl, _ := New(WithClock(clock), WithSongs(minuteSong("a")))
+		b := minuteSong("b")
+
+		td.CmpNoError(t, pl.ApplySync(ctx, SyncState{Song: &b, Position: 5 * time.Second, Playing: true, At: testStart}))
+		defer pl.Pause(ctx)
+		td.Cmp(t, pl.Elapsed(ctx), 6*time.Second, "задержка доставки по часам плеера")
+
+		td.Cmp(t, pl.AuditLog(ctx, time.Time{}), []AuditEntry{{At: testStart.Add(time.Second), Op: AuditAdd, Song: &b, Detail: DefaultPlaylist}})
+		td.CmpNoError(t, pl.Undo(ctx))
+		td.Cmp(t, names(pl), []string{"a"}, "добавление отменяется")
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		pl := newFakePlayer(t, WithMaxPlaylistSize(1), WithSongs(minuteSong("a")))
+		b := minuteSong("b")
+
+		td.Cmp(t, pl.ApplySync(ctx, SyncState{Song: &b, Playing: true, At: testStart}), ErrPlaylistFull)
+		td.Cmp(t, names(pl), []string{"a"})
+	})
+}
@@ -0,0 +1,86 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// TrackTransition - смена текущей песни.
+type TrackTransition struct {
+	// From - песня, которая была текущей
+	From Song
+	// FromID - ID прежней песни, 0 для вставки
+	FromID SongID
+	// To - новая текущая песня
+	To Song
+	// ToID - ID новой песни, 0 для вставки
+	ToID SongID
+	// At - момент смены с монотонными часами: для автоматического перехода -
+	// расчётный момент окончания песни или начала наложения, а не срабатывания таймера
+	At time.Time
+	// Automatic - песня доиграла, а не была сменена через Next, Prev, PlayAt и другие методы
+	Automatic bool
+	// State - состояние плеера в момент смены
+	State State
+}
+
+// TransitionHook - обработчик смены текущей песни.
+type TransitionHook func(event TrackTransition)
+
+// OnTrackTransition - регистрирует обработчик, который вызывается при каждой смене
+// текущей песни, в том числе на паузе и при переходе к первой песне в конце плейлиста.
+// Обработчики вызываются по порядку в отдельной горутине.
+func (p *playerImpl) OnTrackTransition(ctx context.Context, hook TransitionHook) error {
+	if hook == nil {
+		return errors.New("hook is nil")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	p.transitionHooks = append(p.transitionHooks, hook)
+	return nil
+}
+
+// transitionedLocked - сообщает подписчикам и обработчикам о смене песни from на to.
+// Вызывается под блокировкой.
+func (p *playerImpl) transitionedLocked(from, to *playerNode) {
+	at, auto := p.autoTransitionAt, !p.autoTransitionAt.IsZero()
+	p.autoTransitionAt = time.Time{}
+
+	if from == nil || to == nil || from == to {
+		return
+	}
+
+	if !auto {
+		at = p.now()
+	}
+	event := TrackTransition{
+		From:      *from.song,
+		FromID:    from.id,
+		To:        *to.song,
+		ToID:      to.id,
+		At:        at,
+		Automatic: auto,
+		State:     p.stateLocked(),
+	}
+
+	p.publishLocked(EventTransition, event)
+	if len(p.transitionHooks) == 0 {
+		return
+	}
+
+	hooks := append([]TransitionHook(nil), p.transitionHooks...)
+	p.hookQueue.push(func() {
+		for _, h := range hooks {
+			h(event)
+		}
+	})
+}
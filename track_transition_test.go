@@ -0,0 +1,53 @@
+package player
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_OnTrackTransition(t *testing.T) {
+	ctx := context.Background()
+	a := Song{Name: "a", Duration: 30 * time.Millisecond}
+	b := Song{Name: "b", Duration: time.Minute}
+	c := Song{Name: "c", Duration: time.Minute}
+	pl, _ := New(WithValidator(ValidationPolicy{}), WithSongs(a, b, c))
+
+	var mu sync.Mutex
+	var events []TrackTransition
+	td.CmpNoError(t, pl.OnTrackTransition(ctx, func(e TrackTransition) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	}))
+	td.CmpString(t, pl.OnTrackTransition(ctx, nil), "hook is nil")
+
+	start := time.Now()
+	_ = pl.Play(ctx)
+	waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	td.CmpNoError(t, pl.WaitFor(waitCtx, func(st Status) bool { return st.Song.Name == "b" }))
+
+	_ = pl.Next(ctx)
+	_ = pl.Pause(ctx)
+	td.CmpNoError(t, pl.hookQueue.wait(ctx))
+
+	mu.Lock()
+	defer mu.Unlock()
+	td.Cmp(t, events, td.Slice([]TrackTransition{}, td.ArrayEntries{
+		0: td.SStruct(TrackTransition{From: a, To: b, Automatic: true, State: StatePlaying}, td.StructFields{
+			"FromID": td.NotZero(),
+			"ToID":   td.NotZero(),
+			// расчётный момент, а не срабатывание таймера
+			"At": td.Between(start.Add(a.Duration), start.Add(a.Duration+5*time.Millisecond)),
+		}),
+		1: td.SStruct(TrackTransition{From: b, To: c, State: StateStopped}, td.StructFields{
+			"FromID": td.NotZero(),
+			"ToID":   td.NotZero(),
+			"At":     td.Gt(start.Add(a.Duration)),
+		}),
+	}))
+}
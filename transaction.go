@@ -0,0 +1,199 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// ErrTxDone - транзакция уже завершилась, её методы больше нельзя вызывать.
+var ErrTxDone = errors.New("transaction is done")
+
+// PlaylistTx - изменения активного плейлиста внутри WithTransaction.
+// Изменения видны только через PlaylistTx, пока транзакция не завершится.
+type PlaylistTx interface {
+	// Songs - песни плейлиста с учётом изменений транзакции
+	Songs() []QueuedSong
+	// Add - добавляет песню в конец плейлиста и возвращает её ID
+	Add(song Song) (SongID, error)
+	// Insert - вставляет песню на позицию index, считая с нуля, и возвращает её ID.
+	// Если index за пределами плейлиста, песня добавляется в конец.
+	// В заполненный плейлист песня не вставляется, ErrPlaylistFull, песни не вытесняются.
+	Insert(index int, song Song) (SongID, error)
+	// Remove - удаляет песню
+	Remove(id SongID) error
+	// Move - переставляет песню на позицию index, как MoveSong
+	Move(id SongID, index int) error
+}
+
+// playlistTx - PlaylistTx над копией списка узлов активного плейлиста.
+type playlistTx struct {
+	p     *playerImpl
+	nodes []*playerNode
+	// cursor - песня, которая станет текущей, если текущую удалят
+	cursor *playerNode
+	// total - суммарная длительность песен nodes для ограничений плейлиста
+	total time.Duration
+	// added - вставленные узлы, в библиотеку они попадают только при фиксации
+	added []*playerNode
+	// changed - транзакция что-то изменила
+	changed bool
+	done    bool
+}
+
+// WithTransaction - выполняет fn и применяет все сделанные через tx изменения
+// активного плейлиста разом: воспроизведение не видит промежуточных состояний,
+// обработчики узнают только об итоговом плейлисте, а Undo отменяет транзакцию целиком.
+// Если fn вернула ошибку или ctx отменён, изменения отбрасываются.
+// fn выполняется под блокировкой плеера и не должна вызывать его методы.
+func (p *playerImpl) WithTransaction(ctx context.Context, fn func(tx PlaylistTx) error) error {
+	if fn == nil {
+		return errors.New("transaction func is nil")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	before := p.nodes()
+	tx := &playlistTx{p: p, nodes: slices.Clone(before), cursor: p.current, total: p.total}
+	defer func() { tx.done = true }()
+
+	if err := fn(tx); err != nil {
+		p.logger.DebugContext(ctx, "playlist transaction rolled back", slog.String("playlist", p.active), slog.Any("error", err))
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if !tx.changed {
+		return nil
+	}
+
+	tx.addToLibrary()
+	p.recordEditLocked(p.reorderEdit("transaction", before, tx.nodes, p.current))
+	p.auditLocked(ctx, AuditEntry{Op: AuditTransaction, Song: p.currentSongLocked(), Detail: p.active})
+	p.logger.DebugContext(ctx, "playlist transaction committed", slog.String("playlist", p.active), slog.Int("songs", len(tx.nodes)))
+	return p.relinkLocked(ctx, tx.nodes, tx.cursor)
+}
+
+func (tx *playlistTx) Songs() []QueuedSong {
+	if tx.done {
+		return nil
+	}
+
+	songs := make([]QueuedSong, 0, len(tx.nodes))
+	for _, n := range tx.nodes {
+		songs = append(songs, queuedSong(n))
+	}
+
+	return songs
+}
+
+func (tx *playlistTx) Add(song Song) (SongID, error) {
+	return tx.Insert(len(tx.nodes), song)
+}
+
+func (tx *playlistTx) Insert(index int, song Song) (SongID, error) {
+	if tx.done {
+		return 0, ErrTxDone
+	}
+
+	if index < 0 {
+		return 0, errors.New("index is negative")
+	}
+
+	if err := tx.p.validator.Validate(song); err != nil {
+		return 0, err
+	}
+
+	if err := tx.p.checkCooldownLocked(song); err != nil {
+		return 0, err
+	}
+
+	if !tx.p.limits.fit(len(tx.nodes)+1, tx.total+song.Duration) {
+		return 0, ErrPlaylistFull
+	}
+
+	// в библиотеку песня попадёт при фиксации, откат её не оставит
+	node := &playerNode{id: newSongID(), song: &song}
+	tx.nodes = slices.Insert(tx.nodes, min(index, len(tx.nodes)), node)
+	tx.added = append(tx.added, node)
+	tx.total += song.Duration
+	tx.changed = true
+	return node.id, nil
+}
+
+func (tx *playlistTx) Remove(id SongID) error {
+	if tx.done {
+		return ErrTxDone
+	}
+
+	i := tx.find(id)
+	if i < 0 {
+		return ErrSongNotFound
+	}
+
+	// как RemoveSong: вместо удалённой текущей песни играет следующая
+	if tx.nodes[i] == tx.cursor {
+		tx.cursor = nil
+		if i+1 < len(tx.nodes) {
+			tx.cursor = tx.nodes[i+1]
+		} else if i > 0 {
+			tx.cursor = tx.nodes[i-1]
+		}
+	}
+
+	tx.total -= tx.nodes[i].counted
+	tx.nodes = slices.Delete(tx.nodes, i, i+1)
+	tx.changed = true
+	return nil
+}
+
+func (tx *playlistTx) Move(id SongID, index int) error {
+	if tx.done {
+		return ErrTxDone
+	}
+
+	if index < 0 {
+		return errors.New("index is negative")
+	}
+
+	i := tx.find(id)
+	if i < 0 {
+		return ErrSongNotFound
+	}
+
+	node := tx.nodes[i]
+	tx.nodes = slices.Delete(tx.nodes, i, i+1)
+	tx.nodes = slices.Insert(tx.nodes, min(index, len(tx.nodes)), node)
+	tx.changed = true
+	return nil
+}
+
+// addToLibrary - добавляет в библиотеку песни, вставленные транзакцией
+// и оставшиеся в плейлисте.
+func (tx *playlistTx) addToLibrary() {
+	for _, node := range tx.added {
+		if tx.find(node.id) < 0 {
+			continue
+		}
+
+		t := tx.p.library.add(*node.song, tx.p.now())
+		node.song, node.track = t.song, t.id
+	}
+}
+
+// find - индекс песни в транзакции, -1 если её нет.
+func (tx *playlistTx) find(id SongID) int {
+	return slices.IndexFunc(tx.nodes, func(n *playerNode) bool { return n.id == id })
+}
@@ -0,0 +1,139 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_WithTransaction(t *testing.T) {
+	ctx := context.Background()
+	newPlayer := func(out Output) *playerImpl {
+		pl, _ := New(WithOutput(out), WithSongs(minuteSong("a"), minuteSong("b"), minuteSong("c"), minuteSong("d")))
+		return pl
+	}
+
+	t.Run("commit", func(t *testing.T) {
+		out := &recordingOutput{}
+		pl := newPlayer(out)
+		ids := pl.Queue(ctx)
+		td.CmpNoError(t, pl.Play(ctx))
+
+		var added SongID
+		err := pl.WithTransaction(ctx, func(tx PlaylistTx) error {
+			td.CmpNoError(t, tx.Remove(ids[1].ID))
+			td.CmpNoError(t, tx.Remove(ids[3].ID))
+
+			var err error
+			added, err = tx.Insert(0, minuteSong("x"))
+			td.CmpNoError(t, err)
+			_, err = tx.Add(minuteSong("y"))
+			td.CmpNoError(t, err)
+			td.CmpNoError(t, tx.Move(ids[2].ID, 1))
+
+			var staged []string
+			for _, s := range tx.Songs() {
+				staged = append(staged, s.Song.Name)
+			}
+			td.Cmp(t, staged, []string{"x", "c", "a", "y"}, "изменения видны в транзакции")
+			td.Cmp(t, names(pl), []string{"a", "b", "c", "d"}, "но не в плейлисте")
+			return nil
+		})
+		td.CmpNoError(t, err)
+
+		td.Cmp(t, names(pl), []string{"x", "c", "a", "y"})
+		td.Cmp(t, pl.Queue(ctx)[0].ID, added)
+		td.Cmp(t, pl.Status(ctx).Song.Name, "a", "текущая песня продолжает играть")
+		td.Cmp(t, out.Calls(), []string{"start a"})
+
+		td.CmpNoError(t, pl.Undo(ctx))
+		td.Cmp(t, names(pl), []string{"a", "b", "c", "d"}, "отменяется целиком")
+		td.CmpNoError(t, pl.Redo(ctx))
+		td.Cmp(t, names(pl), []string{"x", "c", "a", "y"})
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		pl := newPlayer(&recordingOutput{})
+		ids := pl.Queue(ctx)
+
+		var leaked PlaylistTx
+		err := pl.WithTransaction(ctx, func(tx PlaylistTx) error {
+			leaked = tx
+			td.CmpNoError(t, tx.Remove(ids[0].ID))
+			return tx.Move(ids[0].ID, 0)
+		})
+		td.CmpTrue(t, errors.Is(err, ErrSongNotFound))
+		td.Cmp(t, names(pl), []string{"a", "b", "c", "d"})
+		td.CmpTrue(t, errors.Is(pl.Undo(ctx), ErrNothingToUndo), "откатившаяся транзакция не попадает в Undo")
+
+		_, err = leaked.Add(minuteSong("z"))
+		td.Cmp(t, err, ErrTxDone)
+		td.Cmp(t, leaked.Remove(ids[1].ID), ErrTxDone)
+		td.Cmp(t, leaked.Move(ids[1].ID, 0), ErrTxDone)
+		td.CmpNil(t, leaked.Songs())
+
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+		err = pl.WithTransaction(cctx, func(tx PlaylistTx) error { return tx.Remove(ids[0].ID) })
+		td.Cmp(t, err, context.Canceled)
+		td.Cmp(t, names(pl), []string{"a", "b", "c", "d"})
+	})
+
+	t.Run("library", func(t *testing.T) {
+		pl := newPlayer(&recordingOutput{})
+		lib := pl.Library().Len()
+
+		err := pl.WithTransaction(ctx, func(tx PlaylistTx) error {
+			_, err := tx.Add(minuteSong("ghost"))
+			td.CmpNoError(t, err)
+			return errors.New("rollback")
+		})
+		td.CmpString(t, err, "rollback")
+		td.Cmp(t, pl.Library().Len(), lib, "откатившаяся вставка не попадает в библиотеку")
+		td.CmpEmpty(t, pl.Library().Search("ghost"))
+
+		td.CmpNoError(t, pl.WithTransaction(ctx, func(tx PlaylistTx) error {
+			id, err := tx.Add(minuteSong("ghost"))
+			td.CmpNoError(t, err)
+			_, err = tx.Add(minuteSong("x"))
+			td.CmpNoError(t, err)
+			return tx.Remove(id)
+		}))
+		td.Cmp(t, pl.Library().Len(), lib+1)
+		td.CmpEmpty(t, pl.Library().Search("ghost"), "удалённая в транзакции песня тоже")
+		td.Cmp(t, pl.Library().Search("x"), td.Len(1))
+	})
+
+	t.Run("current removed", func(t *testing.T) {
+		out := &recordingOutput{}
+		pl := newPlayer(out)
+		ids := pl.Queue(ctx)
+		td.CmpNoError(t, pl.Play(ctx))
+
+		td.CmpNoError(t, pl.WithTransaction(ctx, func(tx PlaylistTx) error {
+			td.CmpNoError(t, tx.Remove(ids[0].ID))
+			return tx.Remove(ids[1].ID)
+		}))
+
+		td.Cmp(t, pl.Status(ctx).Song.Name, "c", "как RemoveSong, но без промежуточной b")
+		td.Cmp(t, out.Calls(), []string{"start a", "stop a", "start c"})
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		pl := newPlayer(&recordingOutput{})
+		td.CmpString(t, pl.WithTransaction(ctx, nil), "transaction func is nil")
+
+		td.CmpNoError(t, pl.WithTransaction(ctx, func(tx PlaylistTx) error {
+			_, err := tx.Insert(-1, minuteSong("x"))
+			td.CmpString(t, err, "index is negative")
+			td.CmpString(t, tx.Move(1, -1), "index is negative")
+			return nil
+		}))
+		td.CmpTrue(t, errors.Is(pl.Undo(ctx), ErrNothingToUndo), "пустая транзакция ничего не меняет")
+
+		td.CmpNoError(t, pl.Close(ctx))
+		td.Cmp(t, pl.WithTransaction(ctx, func(PlaylistTx) error { return nil }), ErrClosed)
+	})
+}
@@ -0,0 +1,335 @@
+package player
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"time"
+)
+
+// unbounded - время ожидания шага, который никогда не наступит.
+const unbounded = time.Duration(math.MaxInt64)
+
+// fadeOut - затухающая при наложении песня.
+type fadeOut struct {
+	song Song
+	// at - когда песня остановится по часам плеера
+	at time.Time
+	// cancel - отменяет остановку песни
+	cancel func()
+}
+
+// untilStepLocked - возвращает время до следующего шага воспроизведения:
+// подготовки следующей песни или перехода.
+// Вызывается под блокировкой.
+func (p *playerImpl) untilStepLocked() time.Duration {
+	until := p.untilTransitionLocked()
+	if p.prepareDueLocked() {
+		until -= p.prepareLead
+	}
+
+	if p.loopActiveLocked() {
+		if d := p.section.end - p.elapsedLocked(); d < until {
+			until = d
+		}
+	}
+
+	if p.endFadeDueLocked() {
+		until = min(until, p.untilEndFadeLocked())
+	}
+
+	if p.leadDueLocked() {
+		until = min(until, p.untilLeadLocked())
+	}
+
+	if p.chapterDueLocked() {
+		until = min(until, p.untilChapterLocked())
+	}
+
+	if p.scrobbleDueLocked() {
+		until = min(until, p.untilScrobbleLocked())
+	}
+
+	if p.milestoneDueLocked() {
+		until = min(until, p.untilMilestoneLocked())
+	}
+
+	if p.quotaDueLocked() {
+		until = min(until, p.untilQuotaLocked())
+	}
+
+	if p.quotaSaveDueLocked() {
+		until = min(until, p.untilQuotaSaveLocked())
+	}
+
+	if p.resolveDueLocked() || p.prefetchDueLocked() {
+		until = 0
+	}
+
+	return until
+}
+
+// stepLocked - выполняет наступивший шаг воспроизведения
+// и сообщает, продолжается ли воспроизведение.
+// Вызывается под блокировкой.
+func (p *playerImpl) stepLocked(ctx context.Context) bool {
+	if p.quotaDueLocked() && p.untilQuotaLocked() <= 0 {
+		p.quotaExceededLocked(ctx)
+		return false
+	}
+
+	if p.quotaSaveDueLocked() && p.untilQuotaSaveLocked() <= 0 {
+		p.saveQuotaLocked()
+		return true
+	}
+
+	if p.loopActiveLocked() && p.elapsedLocked() >= p.section.end {
+		p.seekLocked(ctx, p.section.start)
+		return true
+	}
+
+	if p.scrobbleDueLocked() && p.untilScrobbleLocked() <= 0 {
+		p.scrobbleLocked(ctx)
+		return true
+	}
+
+	if p.milestoneDueLocked() && p.untilMilestoneLocked() <= 0 {
+		p.milestoneLocked(ctx)
+		return true
+	}
+
+	if p.leadDueLocked() && p.untilLeadLocked() <= 0 {
+		p.leadLocked(ctx)
+		return true
+	}
+
+	if p.endFadeDueLocked() && p.untilEndFadeLocked() <= 0 {
+		p.endFadeLocked(ctx)
+		return true
+	}
+
+	if p.chapterDueLocked() && p.untilChapterLocked() <= 0 {
+		p.chapterChangedLocked(ctx)
+		return true
+	}
+
+	if p.resolveDueLocked() {
+		p.resolveAheadLocked(ctx)
+		return true
+	}
+
+	if p.prefetchDueLocked() {
+		p.prefetchLocked(ctx)
+		return true
+	}
+
+	if p.prepareDueLocked() && p.untilTransitionLocked() > 0 {
+		p.prepareNextLocked(ctx)
+		return true
+	}
+
+	return p.transitionLocked(ctx)
+}
+
+// untilTransitionLocked - возвращает время до следующего перехода:
+// начала наложения, конца песни или конца паузы между песнями.
+// Вызывается под блокировкой.
+func (p *playerImpl) untilTransitionLocked() time.Duration {
+	if p.inGap {
+		return -p.since(p.startedAt)
+	}
+
+	// поток не переключается по таймеру
+	if p.current.song.IsStream() {
+		return unbounded
+	}
+
+	return p.current.song.End() - p.crossfadeLocked() - p.elapsedLocked()
+}
+
+// crossfadeLocked - возвращает длительность наложения текущей песни на следующую.
+// Вызывается под блокировкой.
+func (p *playerImpl) crossfadeLocked() time.Duration {
+	// песня перед остановкой доигрывает до конца
+	if p.afterCurrent != nil {
+		return 0
+	}
+
+	if p.transition != nil {
+		_, overlap := p.transitionPlanLocked()
+		return overlap
+	}
+
+	next := p.afterLocked(p.current)
+	if p.crossfade == 0 || next == nil {
+		return 0
+	}
+
+	fade := p.crossfade
+	if d := p.current.song.playLength(); fade > d {
+		fade = d
+	}
+	if d := next.song.playLength(); fade > d {
+		fade = d
+	}
+
+	return fade
+}
+
+// transitionLocked - выполняет переход и сообщает, продолжается ли воспроизведение.
+// Вызывается под блокировкой.
+func (p *playerImpl) transitionLocked(ctx context.Context) bool {
+	// пауза между песнями закончилась
+	if p.inGap {
+		p.inGap = false
+		p.startOutputLocked(ctx, *p.current.song, p.playedTime)
+		p.fadeInLocked(ctx, *p.current.song)
+		p.nowPlayingLocked(ctx)
+		return true
+	}
+
+	prev := p.current
+	fade := p.crossfadeLocked()
+	p.section = nil
+	// elapsedLocked считается от startedAt, когда позиция была playedTime
+	p.autoTransitionAt = p.startedAt.Add(prev.song.End() - fade - p.playedTime)
+
+	p.playedTime = prev.song.End()
+	p.finishLocked(true)
+	p.playedTime = 0
+	p.startedAt = p.now()
+
+	// недавно сыгранные песни пропускаются
+	upcoming := p.cooledDownLocked(ctx, p.afterLocked(prev))
+
+	if p.afterCurrent != nil {
+		p.stopAfterCurrentLocked(ctx, prev, upcoming)
+		return false
+	}
+
+	// когда достигли конца списка
+	// делаем текущую песню первой
+	// и останавливаем воспроизведение
+	if upcoming == nil && p.upNext.Len() == 0 {
+		p.haltLocked(ctx)
+		p.moveToLocked(p.first())
+		p.logger.InfoContext(ctx, "playlist ended", slog.String("playlist", p.active))
+		return false
+	}
+
+	if p.sleepOnSongEndLocked() {
+		if upcoming == nil {
+			upcoming = p.first()
+		}
+		p.haltLocked(ctx)
+		p.moveToLocked(upcoming)
+		p.logger.InfoContext(ctx, "sleep timer stopped playback")
+		return false
+	}
+
+	next := p.dequeueLocked(upcoming)
+	if next == nil {
+		next = p.interstitialLocked(ctx, prev, upcoming)
+	}
+	// длительность следующей песни не удалось узнать заранее
+	if next.resolveErr != nil {
+		p.haltLocked(ctx)
+		p.moveToLocked(next)
+		p.failurePolicyLocked(ctx, *next.song)
+		return false
+	}
+
+	if fade > next.song.playLength() && !next.song.IsStream() {
+		fade = next.song.playLength()
+	}
+
+	p.moveToLocked(next)
+	p.sequenceLocked()
+	switch {
+	case p.transition != nil:
+		p.transition.Switch(ctx, deck{p: p}, *prev.song, *p.current.song)
+		p.nowPlayingLocked(ctx)
+	case fade > 0:
+		p.startOutputLocked(ctx, *p.current.song, p.playedTime)
+		p.rampLocked(ctx, *p.current.song, 0, 1, fade)
+		p.rampLocked(ctx, *prev.song, 1, 0, fade)
+		p.fadeOutLocked(ctx, *prev.song, fade)
+		p.nowPlayingLocked(ctx)
+	case p.gap > 0:
+		p.stopOutputLocked(ctx, *prev.song)
+		p.inGap = true
+		p.startedAt = p.startedAt.Add(p.gap)
+	default:
+		p.stopOutputLocked(ctx, *prev.song)
+		p.startOutputLocked(ctx, *p.current.song, p.playedTime)
+		p.fadeInLocked(ctx, *p.current.song)
+		p.nowPlayingLocked(ctx)
+	}
+
+	return true
+}
+
+// seekLocked - переносит позицию воспроизведения текущей песни.
+// Вызывается под блокировкой.
+func (p *playerImpl) seekLocked(ctx context.Context, pos time.Duration) {
+	p.playedTime = pos
+	p.startedAt = p.now()
+	p.rampedOut = nil
+	p.begun = nil
+	p.milestoneSeekLocked(pos)
+
+	if p.isPlaying && !p.inGap {
+		p.stopOutputLocked(ctx, *p.current.song)
+		p.startOutputLocked(ctx, *p.current.song, pos)
+	}
+}
+
+// fadeOutLocked - останавливает песню после наложения длительностью d.
+// Вызывается под блокировкой.
+func (p *playerImpl) fadeOutLocked(ctx context.Context, song Song, d time.Duration) {
+	p.stopFadeLocked(ctx)
+
+	due, cancel := p.after(d)
+	stop := make(chan struct{})
+	f := &fadeOut{song: song, at: p.now().Add(d), cancel: func() { cancel(); close(stop) }}
+	go func() {
+		select {
+		case <-due:
+		case <-stop:
+			return
+		}
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		if p.fading != f {
+			return
+		}
+
+		p.fading = nil
+		p.stopOutputLocked(ctx, song)
+	}()
+	p.fading = f
+}
+
+// untilFadeLocked - время до остановки затухающей песни, unbounded если её нет.
+// Вызывается под блокировкой.
+func (p *playerImpl) untilFadeLocked() time.Duration {
+	if p.fading == nil {
+		return unbounded
+	}
+
+	return p.fading.at.Sub(p.now())
+}
+
+// stopFadeLocked - немедленно останавливает затухающую песню.
+// Вызывается под блокировкой.
+func (p *playerImpl) stopFadeLocked(ctx context.Context) {
+	if p.fading == nil {
+		return
+	}
+
+	p.fading.cancel()
+	p.stopOutputLocked(ctx, p.fading.song)
+	p.fading = nil
+}
@@ -0,0 +1,212 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// Curve - форма изменения усиления: для доли прошедшего времени x от 0 до 1
+// возвращает долю пройденного изменения от 0 до 1.
+type Curve func(x float64) float64
+
+var (
+	// Linear - равномерное изменение усиления.
+	Linear Curve = func(x float64) float64 { return x }
+	// EqualPower - изменение по четверти синусоиды: при наложении двух песен
+	// суммарная мощность остаётся постоянной, без провала громкости посередине.
+	EqualPower Curve = func(x float64) float64 { return math.Sin(x * math.Pi / 2) }
+	// SCurve - медленное начало и конец изменения, быстрая середина.
+	SCurve Curve = func(x float64) float64 { return x * x * (3 - 2*x) }
+)
+
+// CurveOutput - бэкенд, поддерживающий изменение усиления по кривой.
+// Бэкенды, реализующие только FadeOutput, меняют усиление линейно.
+type CurveOutput interface {
+	// RampCurve - выставляет усиление песни в from и меняет его до to за время d по кривой curve.
+	RampCurve(ctx context.Context, song Song, from, to float64, d time.Duration, curve Curve) error
+}
+
+// Deck - управление бэкендом во время перехода между песнями.
+// Ошибки бэкенда уходят в Errors, как и при обычном воспроизведении.
+type Deck interface {
+	// Start - начинает входящую песню с позиции, с которой её ждёт плеер
+	Start(ctx context.Context, song Song)
+	// Stop - сразу останавливает песню
+	Stop(ctx context.Context, song Song)
+	// StopAfter - останавливает песню через d, например после затухания.
+	// Следующий переход или остановка плеера останавливают её сразу.
+	StopAfter(ctx context.Context, song Song, d time.Duration)
+	// Ramp - меняет усиление песни от from до to за время d по кривой curve,
+	// nil - Linear. Без FadeOutput ничего не делает.
+	Ramp(ctx context.Context, song Song, from, to float64, d time.Duration, curve Curve)
+}
+
+// Transition - переход между песнями, который плеер выполняет на их границе
+// вместо наложения WithCrossfade. Методы вызываются под блокировкой плеера
+// и не должны обращаться к нему.
+type Transition interface {
+	// Plan - тайминги перехода с outgoing на incoming: за lead до конца outgoing
+	// вызывается Begin, за overlap до конца outgoing - Switch, после которого
+	// текущей становится incoming. overlap не больше lead; оба ограничиваются
+	// длительностью песен.
+	Plan(outgoing, incoming Song) (lead, overlap time.Duration)
+	// Begin - подготовка перехода, например затухание outgoing
+	Begin(ctx context.Context, deck Deck, outgoing, incoming Song)
+	// Switch - запускает incoming и останавливает outgoing, сразу или через Deck.StopAfter
+	Switch(ctx context.Context, deck Deck, outgoing, incoming Song)
+}
+
+// WithTransition - выполняет переходы между песнями через t, например
+// HardCut, CrossfadeWith, FadeOutIn или собственную реализацию.
+// Несовместимо с WithCrossfade и WithGap; затухание WithFade в конце песни
+// заменяется переходом.
+func WithTransition(t Transition) Option {
+	return func(p *playerImpl) error {
+		if t == nil {
+			return errors.New("transition is nil")
+		}
+
+		p.transition = t
+		return nil
+	}
+}
+
+// hardCut - песни встык.
+type hardCut struct{}
+
+// HardCut - переход встык: outgoing останавливается, incoming сразу начинается.
+func HardCut() Transition {
+	return hardCut{}
+}
+
+func (hardCut) Plan(Song, Song) (time.Duration, time.Duration) { return 0, 0 }
+
+func (hardCut) Begin(context.Context, Deck, Song, Song) {}
+
+func (hardCut) Switch(ctx context.Context, deck Deck, outgoing, incoming Song) {
+	deck.Stop(ctx, outgoing)
+	deck.Start(ctx, incoming)
+}
+
+// crossfade - наложение песен.
+type crossfade struct {
+	d     time.Duration
+	curve Curve
+}
+
+// CrossfadeWith - наложение конца outgoing на начало incoming длительностью d:
+// incoming нарастает, outgoing затухает по кривой curve, nil - Linear.
+func CrossfadeWith(d time.Duration, curve Curve) Transition {
+	return crossfade{d: d, curve: curve}
+}
+
+func (c crossfade) Plan(Song, Song) (time.Duration, time.Duration) { return c.d, c.d }
+
+func (crossfade) Begin(context.Context, Deck, Song, Song) {}
+
+func (c crossfade) Switch(ctx context.Context, deck Deck, outgoing, incoming Song) {
+	deck.Start(ctx, incoming)
+	deck.Ramp(ctx, incoming, 0, 1, c.d, c.curve)
+	deck.Ramp(ctx, outgoing, 1, 0, c.d, c.curve)
+	deck.StopAfter(ctx, outgoing, c.d)
+}
+
+// fadeOutIn - затухание outgoing, затем нарастание incoming.
+type fadeOutIn struct {
+	out   time.Duration
+	in    time.Duration
+	curve Curve
+}
+
+// FadeOutIn - outgoing затухает за последние out своей длительности,
+// затем incoming начинается и нарастает за in, по кривой curve, nil - Linear.
+func FadeOutIn(out, in time.Duration, curve Curve) Transition {
+	return fadeOutIn{out: out, in: in, curve: curve}
+}
+
+func (f fadeOutIn) Plan(Song, Song) (time.Duration, time.Duration) { return f.out, 0 }
+
+func (f fadeOutIn) Begin(ctx context.Context, deck Deck, outgoing, _ Song) {
+	deck.Ramp(ctx, outgoing, 1, 0, f.out, f.curve)
+}
+
+func (f fadeOutIn) Switch(ctx context.Context, deck Deck, outgoing, incoming Song) {
+	deck.Stop(ctx, outgoing)
+	deck.Start(ctx, incoming)
+	deck.Ramp(ctx, incoming, 0, 1, f.in, f.curve)
+}
+
+// deck - Deck над бэкендом плеера.
+type deck struct {
+	p *playerImpl
+}
+
+func (d deck) Start(ctx context.Context, song Song) {
+	d.p.startOutputLocked(ctx, song, d.p.playedTime)
+}
+
+func (d deck) Stop(ctx context.Context, song Song) {
+	d.p.stopOutputLocked(ctx, song)
+}
+
+func (d deck) StopAfter(ctx context.Context, song Song, after time.Duration) {
+	if after <= 0 {
+		d.p.stopOutputLocked(ctx, song)
+		return
+	}
+
+	d.p.fadeOutLocked(ctx, song, after)
+}
+
+func (d deck) Ramp(ctx context.Context, song Song, from, to float64, dur time.Duration, curve Curve) {
+	co, ok := d.p.output.(CurveOutput)
+	if !ok || dur <= 0 {
+		d.p.rampLocked(ctx, song, from, to, dur)
+		return
+	}
+
+	if curve == nil {
+		curve = Linear
+	}
+	if err := co.RampCurve(ctx, song, from, to, dur, curve); err != nil {
+		d.p.playbackErrorLocked(ctx, song, StageRamp, err)
+	}
+}
+
+// transitionPlanLocked - тайминги перехода с текущей песни на следующую,
+// ограниченные длительностью песен.
+// Вызывается под блокировкой.
+func (p *playerImpl) transitionPlanLocked() (lead, overlap time.Duration) {
+	next := p.afterLocked(p.current)
+	if next == nil {
+		return 0, 0
+	}
+
+	lead, overlap = p.transition.Plan(*p.current.song, *next.song)
+	lead = min(max(lead, 0), p.current.song.playLength())
+	overlap = min(max(overlap, 0), lead, next.song.playLength())
+	return lead, overlap
+}
+
+// leadDueLocked - сообщает, что для текущей песни ещё предстоит Begin перехода.
+// Вызывается под блокировкой.
+func (p *playerImpl) leadDueLocked() bool {
+	return p.transition != nil && p.afterCurrent == nil && !p.inGap && !p.current.song.IsStream() &&
+		p.begun != p.current && p.afterLocked(p.current) != nil
+}
+
+// untilLeadLocked - возвращает время до Begin перехода.
+// Вызывается под блокировкой.
+func (p *playerImpl) untilLeadLocked() time.Duration {
+	lead, _ := p.transitionPlanLocked()
+	return p.current.song.End() - lead - p.elapsedLocked()
+}
+
+// leadLocked - начинает переход с текущей песни на следующую.
+// Вызывается под блокировкой.
+func (p *playerImpl) leadLocked(ctx context.Context) {
+	p.begun = p.current
+	p.transition.Begin(ctx, deck{p: p}, *p.current.song, *p.afterLocked(p.current).song)
+}
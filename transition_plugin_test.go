@@ -0,0 +1,123 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// curveOutput - бэкенд, меняющий усиление по кривой.
+type curveOutput struct {
+	fadeOutput
+}
+
+func (o *curveOutput) RampCurve(_ context.Context, song Song, from, to float64, d time.Duration, curve Curve) error {
+	o.record(fmt.Sprintf("curve %s %g->%g %v mid=%.2f", song.Name, from, to, d, curve(0.5)))
+	return nil
+}
+
+// beatMatch - собственный переход: начинает b с сильной доли через lead и тут же глушит a.
+type beatMatch struct{ lead time.Duration }
+
+func (b beatMatch) Plan(outgoing, _ Song) (time.Duration, time.Duration) {
+	return b.lead, 0
+}
+
+func (beatMatch) Begin(ctx context.Context, deck Deck, outgoing, _ Song) {
+	deck.Ramp(ctx, outgoing, 1, 0.5, time.Second, nil)
+}
+
+func (beatMatch) Switch(ctx context.Context, deck Deck, outgoing, incoming Song) {
+	deck.Start(ctx, incoming)
+	deck.Stop(ctx, outgoing)
+}
+
+func TestWithTransition(t *testing.T) {
+	ctx := context.Background()
+	songs := []Song{
+		{Name: "a", Duration: time.Minute},
+		{Name: "b", Duration: time.Minute},
+	}
+
+	// simulate - проигрывает d на поддельных часах и возвращает вызовы бэкенда.
+	simulate := func(t *testing.T, out interface {
+		Output
+		Calls() []string
+	}, tr Transition, d time.Duration) (*playerImpl, []Song) {
+		pl := newFakePlayer(t, WithOutput(out), WithTransition(tr), WithSongs(songs...))
+		td.CmpNoError(t, pl.Play(ctx))
+
+		played, err := pl.SimulatePlayback(ctx, d)
+		td.CmpNoError(t, err)
+		return pl, played
+	}
+
+	t.Run("curves", func(t *testing.T) {
+		td.Cmp(t, Linear(0.25), 0.25)
+		td.Cmp(t, EqualPower(0.5), td.Between(math.Sqrt2/2-1e-9, math.Sqrt2/2+1e-9))
+		td.Cmp(t, SCurve(0.5), 0.5)
+		for _, c := range []Curve{Linear, EqualPower, SCurve} {
+			td.Cmp(t, c(0), td.Between(-1e-9, 1e-9))
+			td.Cmp(t, c(1), td.Between(1-1e-9, 1+1e-9))
+		}
+	})
+
+	t.Run("hard cut", func(t *testing.T) {
+		out := &fadeOutput{}
+		pl, played := simulate(t, out, HardCut(), time.Minute+time.Second)
+		td.Cmp(t, titles(played), []string{"a", "b"})
+		td.Cmp(t, pl.Status(ctx).Position, time.Second)
+		td.Cmp(t, out.Calls(), []string{"start a", "stop a", "start b"})
+	})
+
+	t.Run("crossfade", func(t *testing.T) {
+		out := &curveOutput{}
+		pl, played := simulate(t, out, CrossfadeWith(10*time.Second, EqualPower), time.Minute)
+		td.Cmp(t, titles(played), []string{"a", "b"}, "b начинается за 10с до конца a")
+		td.Cmp(t, pl.Status(ctx).Position, 10*time.Second)
+
+		td.CmpTrue(t, eventually(func() bool { return len(out.Calls()) == 5 }), "a останавливается после затухания")
+		td.Cmp(t, out.Calls(), []string{
+			"start a",
+			"start b",
+			"curve b 0->1 10s mid=0.71",
+			"curve a 1->0 10s mid=0.71",
+			"stop a",
+		})
+	})
+
+	t.Run("fade out in", func(t *testing.T) {
+		out := &fadeOutput{}
+		_, played := simulate(t, out, FadeOutIn(5*time.Second, 2*time.Second, nil), 57*time.Second)
+		td.Cmp(t, titles(played), []string{"a"})
+		td.Cmp(t, out.Calls(), []string{"start a", "ramp a 1->0 5s"}, "a затухает до конца")
+	})
+
+	t.Run("fade out in switch", func(t *testing.T) {
+		out := &fadeOutput{}
+		_, played := simulate(t, out, FadeOutIn(5*time.Second, 2*time.Second, nil), time.Minute)
+		td.Cmp(t, titles(played), []string{"a", "b"})
+		td.Cmp(t, out.Calls(), []string{"start a", "ramp a 1->0 5s", "stop a", "start b", "ramp b 0->1 2s"})
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		out := &fadeOutput{}
+		pl, played := simulate(t, out, beatMatch{lead: 3 * time.Second}, time.Minute+time.Second)
+		td.Cmp(t, titles(played), []string{"a", "b"})
+		td.Cmp(t, out.Calls(), []string{"start a", "ramp a 1->0.5 1s", "start b", "stop a"})
+		td.CmpNoError(t, pl.Pause(ctx))
+	})
+
+	t.Run("options", func(t *testing.T) {
+		_, err := New(WithTransition(nil))
+		td.CmpString(t, err, "transition is nil")
+		_, err = New(WithTransition(HardCut()), WithCrossfade(time.Second))
+		td.CmpString(t, err, "transition is incompatible with crossfade and gap")
+		_, err = New(WithTransition(HardCut()), WithGap(time.Second))
+		td.CmpString(t, err, "transition is incompatible with crossfade and gap")
+	})
+}
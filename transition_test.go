@@ -0,0 +1,132 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// recordingOutput - бэкенд, запоминающий вызовы.
+type recordingOutput struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (o *recordingOutput) Start(_ context.Context, song Song, offset time.Duration) error {
+	o.record(fmt.Sprintf("start %s", song.Name))
+	return nil
+}
+
+func (o *recordingOutput) Stop(_ context.Context, song Song) error {
+	o.record(fmt.Sprintf("stop %s", song.Name))
+	return nil
+}
+
+func (o *recordingOutput) record(call string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.calls = append(o.calls, call)
+}
+
+func (o *recordingOutput) Calls() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return append([]string(nil), o.calls...)
+}
+
+func TestTransitions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("options", func(t *testing.T) {
+		_, err := New(WithCrossfade(time.Second), WithGap(time.Second))
+		td.CmpError(t, err, "crossfade и gap взаимоисключающие")
+
+		_, err = New(WithCrossfade(-time.Second))
+		td.CmpError(t, err)
+
+		_, err = New(WithOutput(nil))
+		td.CmpError(t, err)
+	})
+
+	t.Run("hard cut", func(t *testing.T) {
+		out := &recordingOutput{}
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithOutput(out), WithSongs(
+			Song{Name: "a", Duration: 30 * time.Millisecond},
+			Song{Name: "b", Duration: 30 * time.Second},
+		))
+
+		_ = pl.Play(ctx)
+		time.Sleep(50 * time.Millisecond)
+		_ = pl.Pause(ctx)
+
+		td.Cmp(t, out.Calls(), []string{"start a", "stop a", "start b", "stop b"})
+	})
+
+	t.Run("crossfade", func(t *testing.T) {
+		out := &recordingOutput{}
+		pl := newFakePlayer(t, WithOutput(out), WithCrossfade(20*time.Second), WithSongs(
+			Song{Name: "a", Duration: time.Minute},
+			Song{Name: "b", Duration: time.Minute},
+			Song{Name: "c", Duration: 30 * time.Minute},
+		))
+
+		td.CmpNoError(t, pl.Play(ctx))
+		_, err := pl.SimulatePlayback(ctx, 50*time.Second)
+		td.CmpNoError(t, err)
+		td.Cmp(t, out.Calls(), []string{"start a", "start b"}, "b начинает играть поверх a")
+		td.Cmp(t, pl.Status(ctx).Song.Name, "b")
+
+		_, err = pl.SimulatePlayback(ctx, time.Minute)
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, pl.Pause(ctx))
+
+		td.Cmp(t, out.Calls(), []string{"start a", "start b", "stop a", "start c", "stop b", "stop c"})
+	})
+
+	t.Run("crossfade after live add", func(t *testing.T) {
+		out := &recordingOutput{}
+		clock := NewFakeClock(testStart)
+		pl, _ := New(WithClock(clock), WithOutput(out), WithCrossfade(4*time.Second), WithSongs(minuteSong("a")))
+		defer pl.Pause(ctx)
+
+		td.CmpNoError(t, pl.Play(ctx))
+		// горутина воспроизведения уснула до конца a
+		time.Sleep(10 * time.Millisecond)
+
+		_, err := pl.AddSong(ctx, minuteSong("b"))
+		td.CmpNoError(t, err)
+		clock.Advance(56 * time.Second)
+		td.CmpTrue(t, eventually(func() bool { return len(out.Calls()) == 2 }), "наложение начинается за 4 секунды до конца a")
+		td.Cmp(t, out.Calls(), []string{"start a", "start b"})
+		td.Cmp(t, pl.Status(ctx).Song.Name, "b")
+	})
+
+	t.Run("gap", func(t *testing.T) {
+		out := &recordingOutput{}
+		pl := newFakePlayer(t, WithOutput(out), WithGap(30*time.Second), WithSongs(
+			Song{Name: "a", Duration: 30 * time.Second},
+			Song{Name: "b", Duration: 30 * time.Minute},
+		))
+
+		td.CmpNoError(t, pl.Play(ctx))
+		_, err := pl.SimulatePlayback(ctx, 45*time.Second)
+		td.CmpNoError(t, err)
+		td.Cmp(t, out.Calls(), []string{"start a", "stop a"}, "пауза между песнями")
+		st := pl.Status(ctx)
+		td.CmpTrue(t, st.Playing)
+		td.Cmp(t, st.Song.Name, "b")
+
+		_, err = pl.SimulatePlayback(ctx, 30*time.Second)
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, pl.Pause(ctx))
+
+		td.Cmp(t, out.Calls(), []string{"start a", "stop a", "start b", "stop b"})
+		td.Cmp(t, pl.Elapsed(ctx), 15*time.Second, "пауза не входит в позицию песни")
+	})
+}
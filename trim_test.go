@@ -0,0 +1,74 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// offsetOutput - бэкенд, запоминающий позиции запуска песен.
+type offsetOutput struct {
+	nopOutput
+	mu     sync.Mutex
+	starts map[string]time.Duration
+}
+
+func (o *offsetOutput) Start(_ context.Context, song Song, offset time.Duration) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.starts[song.Name] = offset
+	return nil
+}
+
+func TestPlayerImpl_Trim(t *testing.T) {
+	ctx := context.Background()
+	// от live-записи остаётся 30 мс
+	live := Song{Name: "live", Duration: time.Minute, LeadIn: 20 * time.Second, LeadOut: 40*time.Second - 30*time.Millisecond}
+	next := Song{Name: "next", Duration: time.Minute, LeadIn: 5 * time.Second}
+
+	t.Run("playback", func(t *testing.T) {
+		out := &offsetOutput{starts: make(map[string]time.Duration)}
+		pl, _ := New(WithOutput(out), WithSongs(live, next))
+		_ = pl.Play(ctx)
+
+		waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		td.CmpNoError(t, pl.WaitFor(waitCtx, func(st Status) bool { return st.Song.Name == "next" }))
+		_ = pl.Pause(ctx)
+
+		out.mu.Lock()
+		td.Cmp(t, out.starts, map[string]time.Duration{"live": 20 * time.Second, "next": 5 * time.Second})
+		out.mu.Unlock()
+		td.Cmp(t, pl.Remaining(ctx), next.End()-pl.Elapsed(ctx))
+	})
+
+	t.Run("prev restarts after lead-in", func(t *testing.T) {
+		pl, _ := New(WithSongs(next), WithPrevRestart(time.Second))
+		td.CmpNoError(t, pl.PlayFrom(ctx, 0, 30*time.Second))
+		td.CmpNoError(t, pl.Prev(ctx))
+		_ = pl.Pause(ctx)
+		td.Cmp(t, pl.Elapsed(ctx), td.Between(5*time.Second, 5*time.Second+50*time.Millisecond))
+	})
+
+	t.Run("total duration", func(t *testing.T) {
+		pl, _ := New(WithSongs(live, next))
+		total, _ := pl.TotalDuration(ctx)
+		td.Cmp(t, total, 30*time.Millisecond+55*time.Second)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		bad := Song{Name: "a", Duration: time.Minute, LeadIn: 30 * time.Second, LeadOut: 30 * time.Second}
+		td.CmpTrue(t, errors.Is(DefaultValidationPolicy.Validate(bad), ErrInvalidSong), "обрезана вся песня")
+
+		bad = Song{Name: "a", Duration: time.Minute, LeadIn: -time.Second}
+		td.CmpTrue(t, errors.Is(DefaultValidationPolicy.Validate(bad), ErrInvalidSong))
+
+		bad = Song{Name: "radio", URL: "http://radio", LeadOut: time.Second}
+		td.CmpTrue(t, errors.Is(DefaultValidationPolicy.Validate(bad), ErrInvalidSong), "у потока нет конца")
+	})
+}
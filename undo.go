@@ -0,0 +1,279 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// undoLimit - сколько последних изменений плейлиста можно отменить.
+const undoLimit = 100
+
+var (
+	// ErrNothingToUndo - нет изменений, которые можно отменить.
+	ErrNothingToUndo = errors.New("nothing to undo")
+	// ErrNothingToRedo - нет отменённых изменений, которые можно повторить.
+	ErrNothingToRedo = errors.New("nothing to redo")
+)
+
+// edit - изменение активного плейлиста, которое можно отменить и повторить.
+// Функции вызываются под блокировкой.
+type edit struct {
+	// op - название изменения для лога
+	op   string
+	undo func(ctx context.Context) error
+	redo func(ctx context.Context) error
+}
+
+// Undo - отменяет последнее изменение активного плейлиста: добавление, удаление,
+// перестановку, очистку, сортировку, удаление повторов или слияние.
+// Удалённые песни возвращаются на прежние места с прежними ID.
+// История изменений сбрасывается при смене активного плейлиста.
+// Если возвращаемые песни не помещаются в ограничения плейлистов,
+// возвращает ErrPlaylistFull, а изменение остаётся неотменённым.
+func (p *playerImpl) Undo(ctx context.Context) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if len(p.edits) == 0 {
+		return ErrNothingToUndo
+	}
+
+	e := p.edits[len(p.edits)-1]
+	p.auditLocked(ctx, AuditEntry{Op: AuditUndo, Song: p.currentSongLocked(), Detail: e.op})
+	err := e.undo(ctx)
+	// не поместившееся в плейлист изменение остаётся неотменённым
+	if errors.Is(err, ErrPlaylistFull) {
+		return err
+	}
+
+	p.edits = p.edits[:len(p.edits)-1]
+	p.undone = append(p.undone, e)
+	p.logger.DebugContext(ctx, "playlist edit undone", slog.String("op", e.op), slog.String("playlist", p.active))
+	return err
+}
+
+// Redo - повторяет последнее отменённое изменение активного плейлиста.
+// Новое изменение плейлиста сбрасывает отменённые.
+// Как и Undo, не превышает ограничения плейлистов и возвращает ErrPlaylistFull.
+func (p *playerImpl) Redo(ctx context.Context) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if len(p.undone) == 0 {
+		return ErrNothingToRedo
+	}
+
+	e := p.undone[len(p.undone)-1]
+	p.auditLocked(ctx, AuditEntry{Op: AuditRedo, Song: p.currentSongLocked(), Detail: e.op})
+	err := e.redo(ctx)
+	// не поместившееся в плейлист изменение остаётся неповторённым
+	if errors.Is(err, ErrPlaylistFull) {
+		return err
+	}
+
+	p.undone = p.undone[:len(p.undone)-1]
+	p.edits = append(p.edits, e)
+	p.logger.DebugContext(ctx, "playlist edit redone", slog.String("op", e.op), slog.String("playlist", p.active))
+	return err
+}
+
+// recordEditLocked - запоминает изменение активного плейлиста и сбрасывает отменённые.
+// Вызывается под блокировкой.
+func (p *playerImpl) recordEditLocked(e edit) {
+	if len(p.edits) == undoLimit {
+		copy(p.edits, p.edits[1:])
+		p.edits = p.edits[:undoLimit-1]
+	}
+
+	p.edits = append(p.edits, e)
+	p.undone = nil
+}
+
+// resetEditsLocked - забывает изменения после замены активного плейлиста.
+// Вызывается под блокировкой.
+func (p *playerImpl) resetEditsLocked() {
+	p.edits, p.undone = nil, nil
+}
+
+// addEdit - добавление узлов в конец активного плейлиста.
+func (p *playerImpl) addEdit(nodes ...*playerNode) edit {
+	return edit{
+		op: "add",
+		undo: func(ctx context.Context) error {
+			var errs []error
+			for i := len(nodes) - 1; i >= 0; i-- {
+				if p.contains(nodes[i]) {
+					errs = append(errs, p.removeLocked(ctx, nodes[i]))
+				}
+			}
+			return errors.Join(errs...)
+		},
+		redo: func(context.Context) error {
+			var missing []*playerNode
+			for _, n := range nodes {
+				if !p.contains(n) {
+					missing = append(missing, n)
+				}
+			}
+			if !p.limits.fitNodes(&p.playlist, missing...) {
+				return ErrPlaylistFull
+			}
+
+			for _, n := range missing {
+				p.appendNode(n)
+			}
+			p.rescheduleLocked()
+			return nil
+		},
+	}
+}
+
+// removeEdit - удаление узла из активного плейлиста.
+// Вызывается под блокировкой до удаления.
+func (p *playerImpl) removeEdit(node *playerNode) edit {
+	index := p.position(node)
+	return edit{
+		op: "remove",
+		undo: func(context.Context) error {
+			if !p.contains(node) {
+				if !p.limits.fitNodes(&p.playlist, node) {
+					return ErrPlaylistFull
+				}
+				p.insertAt(node, index)
+				p.prepared = nil
+				p.rescheduleLocked()
+			}
+			return nil
+		},
+		redo: func(ctx context.Context) error {
+			if !p.contains(node) {
+				return nil
+			}
+			return p.removeLocked(ctx, node)
+		},
+	}
+}
+
+// moveEdit - перестановка узла активного плейлиста на позицию index.
+// Вызывается под блокировкой до перестановки.
+func (p *playerImpl) moveEdit(node *playerNode, index int) edit {
+	from := p.position(node)
+	return edit{
+		op: "move",
+		undo: func(context.Context) error {
+			p.moveNodeLocked(node, from)
+			return nil
+		},
+		redo: func(context.Context) error {
+			p.moveNodeLocked(node, index)
+			return nil
+		},
+	}
+}
+
+// reorderEdit - замена списка узлов активного плейлиста: очистка, сортировка,
+// удаление повторов. before и after - узлы до и после изменения,
+// cursor - текущая песня до изменения.
+func (p *playerImpl) reorderEdit(op string, before, after []*playerNode, cursor *playerNode) edit {
+	return edit{
+		op: op,
+		undo: func(ctx context.Context) error {
+			return p.relinkLocked(ctx, before, cursor)
+		},
+		redo: func(ctx context.Context) error {
+			return p.relinkLocked(ctx, after, nil)
+		},
+	}
+}
+
+// nodes - возвращает узлы списка по порядку.
+func (pl *playlist) nodes() []*playerNode {
+	return pl.songs.Values()
+}
+
+// moveNodeLocked - переставляет узел активного плейлиста на позицию index.
+// Вызывается под блокировкой.
+func (p *playerImpl) moveNodeLocked(node *playerNode, index int) {
+	if !p.contains(node) {
+		return
+	}
+
+	p.unlink(node)
+	p.insertAt(node, index)
+
+	// следующая песня могла измениться
+	p.prepared = nil
+	p.rescheduleLocked()
+}
+
+// relinkLocked - составляет активный плейлист из nodes.
+// Если текущей песни нет среди nodes, воспроизведение переходит на cursor,
+// а если его тоже нет - на первую песню.
+// Если nodes не помещаются в ограничения плейлистов, плейлист не меняется
+// и возвращается ErrPlaylistFull.
+// Вызывается под блокировкой.
+func (p *playerImpl) relinkLocked(ctx context.Context, nodes []*playerNode, cursor *playerNode) error {
+	if !p.limits.fitNodes(&playlist{}, nodes...) {
+		return ErrPlaylistFull
+	}
+
+	keep := make(map[*playerNode]bool, len(nodes))
+	for _, n := range nodes {
+		keep[n] = true
+	}
+	if !keep[cursor] && len(nodes) > 0 {
+		cursor = nodes[0]
+	}
+
+	// во время вставки текущая песня не из списка, её место занимает прерванная
+	playing := p.isPlaying
+	replaced := p.current == nil || p.contains(p.current) && !keep[p.current]
+	if replaced && p.current != nil {
+		p.skipLocked()
+		p.haltLocked(ctx)
+	}
+	if in := p.interruption; in != nil && !keep[in.node] {
+		p.retargetInterruptionLocked(cursor)
+	}
+	if p.section != nil && !keep[p.section.node] {
+		p.section = nil
+	}
+	p.prepared = nil
+
+	current := p.current
+	for _, n := range p.nodes() {
+		n.elem = nil
+	}
+	p.songs.Clear()
+	p.byID = nil
+	p.total = 0
+	for _, n := range nodes {
+		p.appendNode(n)
+	}
+	p.playlist.current = current
+
+	if !replaced {
+		p.rescheduleLocked()
+		return nil
+	}
+
+	p.moveToLocked(cursor)
+	if playing && cursor != nil {
+		return p.playLocked(ctx)
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Undo(t *testing.T) {
+	ctx := context.Background()
+
+	songs := []Song{
+		{Name: "Сектор Газа - 30 лет", Duration: 30 * time.Second},
+		{Name: "Александр Пушной - Почему я идиот?", Duration: 20 * time.Second},
+		{Name: "Михаил Шуфутинский - 3 сентября", Duration: 10 * time.Second},
+	}
+
+	t.Run("nothing to undo", func(t *testing.T) {
+		pl, _ := NewPlayer(songs...)
+
+		td.Cmp(t, pl.Undo(ctx), ErrNothingToUndo, "песни из WithSongs не отменяются")
+		td.Cmp(t, pl.Redo(ctx), ErrNothingToRedo)
+	})
+
+	t.Run("add", func(t *testing.T) {
+		pl, _ := NewPlayer(songs[0])
+		id, _ := pl.AddSong(ctx, songs[1])
+		_ = pl.AddSongs(ctx, songs[2], songs[2])
+
+		td.CmpNoError(t, pl.Undo(ctx))
+		td.Cmp(t, names(pl), []string{songs[0].Name, songs[1].Name}, "пакет отменяется целиком")
+		td.CmpNoError(t, pl.Undo(ctx))
+		td.Cmp(t, names(pl), []string{songs[0].Name})
+
+		td.CmpNoError(t, pl.Redo(ctx))
+		td.Cmp(t, names(pl), []string{songs[0].Name, songs[1].Name})
+		td.Cmp(t, pl.IndexOf(ctx, id), 1, "песня вернулась под прежним ID")
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		pl, _ := New(WithSongs(songs...))
+		_, id, _ := pl.SongAt(ctx, 1)
+		td.CmpNoError(t, pl.RemoveSong(ctx, id))
+		td.CmpNoError(t, pl.RemoveAt(ctx, 0))
+		td.Cmp(t, names(pl), []string{songs[2].Name})
+
+		td.CmpNoError(t, pl.Undo(ctx))
+		td.CmpNoError(t, pl.Undo(ctx))
+		td.Cmp(t, names(pl), []string{songs[0].Name, songs[1].Name, songs[2].Name})
+		td.Cmp(t, pl.IndexOf(ctx, id), 1)
+
+		td.CmpNoError(t, pl.Redo(ctx))
+		td.Cmp(t, names(pl), []string{songs[0].Name, songs[2].Name})
+	})
+
+	t.Run("move and sort", func(t *testing.T) {
+		pl, _ := NewPlayer(songs...)
+		_, id, _ := pl.SongAt(ctx, 0)
+		_ = pl.MoveSong(ctx, id, 1)
+		_ = pl.SortPlaylist(ctx, ByDuration)
+		td.Cmp(t, names(pl), []string{songs[2].Name, songs[1].Name, songs[0].Name})
+
+		td.CmpNoError(t, pl.Undo(ctx))
+		td.Cmp(t, names(pl), []string{songs[1].Name, songs[0].Name, songs[2].Name})
+		td.CmpNoError(t, pl.Undo(ctx))
+		td.Cmp(t, names(pl), []string{songs[0].Name, songs[1].Name, songs[2].Name})
+		td.Cmp(t, pl.Status(ctx).Song.Name, songs[0].Name, "текущая песня не меняется")
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		pl, _ := NewPlayer(songs...)
+		_ = pl.Next(ctx)
+		_ = pl.Pause(ctx)
+		td.CmpNoError(t, pl.ClearPlaylist(ctx))
+		td.Cmp(t, pl.Len(ctx), 0)
+
+		td.CmpNoError(t, pl.Undo(ctx))
+		td.Cmp(t, names(pl), []string{songs[0].Name, songs[1].Name, songs[2].Name})
+		td.Cmp(t, pl.Status(ctx).Song.Name, songs[1].Name, "курсор восстановлен")
+
+		td.CmpNoError(t, pl.Redo(ctx))
+		td.Cmp(t, pl.Len(ctx), 0)
+	})
+
+	t.Run("redo of current song removal", func(t *testing.T) {
+		pl, _ := NewPlayer(songs...)
+		_ = pl.Play(ctx)
+		td.CmpNoError(t, pl.ClearPlaylist(ctx))
+		_ = pl.Undo(ctx)
+		_ = pl.Play(ctx)
+
+		td.CmpNoError(t, pl.RemoveAt(ctx, 0))
+		_ = pl.Undo(ctx)
+		td.CmpNoError(t, pl.Redo(ctx))
+		td.Cmp(t, pl.Status(ctx).Song.Name, songs[1].Name, "играет следующая песня")
+		td.CmpTrue(t, pl.Status(ctx).Playing)
+		_ = pl.Pause(ctx)
+	})
+
+	t.Run("new edit drops undone", func(t *testing.T) {
+		pl, _ := NewPlayer(songs[0])
+		_, _ = pl.AddSong(ctx, songs[1])
+		_ = pl.Undo(ctx)
+		_, _ = pl.AddSong(ctx, songs[2])
+
+		td.Cmp(t, pl.Redo(ctx), ErrNothingToRedo)
+	})
+
+	t.Run("switch playlist resets edits", func(t *testing.T) {
+		pl, _ := NewPlayer(songs[0])
+		_, _ = pl.AddSong(ctx, songs[1])
+		_ = pl.CreatePlaylist(ctx, "второй")
+		_ = pl.SwitchPlaylist(ctx, "второй")
+
+		td.Cmp(t, pl.Undo(ctx), ErrNothingToUndo)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		pl, _ := NewPlayer()
+		for i := 0; i < undoLimit+5; i++ {
+			_, _ = pl.AddSong(ctx, songs[0])
+		}
+
+		for i := 0; i < undoLimit; i++ {
+			td.CmpNoError(t, pl.Undo(ctx))
+		}
+		td.Cmp(t, pl.Undo(ctx), ErrNothingToUndo)
+		td.Cmp(t, pl.Len(ctx), 5, "самые старые изменения забыты")
+	})
+}
@@ -0,0 +1,103 @@
+package player
+
+import (
+	"context"
+	"log/slog"
+
+	"player/list"
+)
+
+// Enqueue - ставит песню в очередь приоритетного воспроизведения: песни очереди
+// играют по порядку добавления сразу после текущей, а затем воспроизведение
+// возвращается к плейлисту с той песни, которая шла бы без очереди.
+// Песни очереди не добавляются в плейлист и переживают смену плейлиста.
+// Next переходит к следующей песне очереди, Prev - обратно к плейлисту.
+func (p *playerImpl) Enqueue(ctx context.Context, song Song) error {
+	if err := p.validator.Validate(song); err != nil {
+		return err
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if err := p.checkCooldownLocked(song); err != nil {
+		return err
+	}
+
+	p.auditLocked(ctx, AuditEntry{Op: AuditEnqueue, Song: &song})
+	p.upNext.PushBack(song)
+	p.rescheduleLocked()
+	p.logger.DebugContext(ctx, "song enqueued", songAttr(song), slog.Int("queued", p.upNext.Len()))
+	return nil
+}
+
+// QueueRemaining - песни очереди Enqueue, которые ещё не начали играть.
+func (p *playerImpl) QueueRemaining(_ context.Context) []Song {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.upNext.Values()
+}
+
+// ClearQueue - очищает очередь Enqueue. Играющая песня очереди доигрывает,
+// после неё воспроизведение возвращается к плейлисту.
+func (p *playerImpl) ClearQueue(ctx context.Context) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	p.auditLocked(ctx, AuditEntry{Op: AuditClearQueue, Song: p.currentSongLocked()})
+	p.upNext.Clear()
+	p.logger.DebugContext(ctx, "queue cleared")
+	return nil
+}
+
+// dequeueLocked - забирает первую песню очереди и возвращает её узел,
+// после которого воспроизведение продолжится с upcoming, или nil, если очередь пуста.
+// Если upcoming nil, после очереди воспроизведение останавливается на первой песне.
+// Вызывается под блокировкой.
+func (p *playerImpl) dequeueLocked(upcoming *playerNode) *playerNode {
+	e := p.upNext.Front()
+	if e == nil {
+		return nil
+	}
+	song := p.upNext.Remove(e)
+
+	// подряд идущие песни очереди возвращают к одной и той же песне плейлиста
+	in := p.interruption
+	if in == nil || p.current != in.jingle {
+		in = &interruption{node: upcoming}
+		if upcoming != nil {
+			in.position = p.resumePositionLocked(*upcoming.song)
+		} else {
+			in.node = p.first()
+		}
+	} else {
+		upcoming = in.jingle.around
+	}
+
+	in.jingle = &playerNode{song: &song, around: upcoming}
+	in.queued = true
+	p.interruption = in
+	return in.jingle
+}
+
+// restoreQueueLocked - заменяет очередь Enqueue сохранённой.
+// Вызывается под блокировкой.
+func (p *playerImpl) restoreQueueLocked(songs []Song) {
+	p.upNext = list.List[Song]{}
+	for _, s := range songs {
+		p.upNext.PushBack(s)
+	}
+}
@@ -0,0 +1,103 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_Enqueue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("plays next", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSongs("a", "b", "c")...))
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpNoError(t, pl.Enqueue(ctx, minuteSong("x")))
+		td.CmpNoError(t, pl.Enqueue(ctx, minuteSong("y")))
+
+		td.Cmp(t, titles(pl.QueueRemaining(ctx)), []string{"x", "y"})
+		td.Cmp(t, titles(pl.PeekNext(ctx, 3)), []string{"x", "y", "b"})
+
+		played, err := pl.SimulatePlayback(ctx, 4*time.Minute)
+		td.CmpNoError(t, err)
+		td.Cmp(t, titles(played), []string{"a", "x", "y", "b", "c"}, "очередь, затем плейлист по порядку")
+		td.Cmp(t, names(pl), []string{"a", "b", "c"}, "плейлист не меняется")
+		td.CmpEmpty(t, pl.QueueRemaining(ctx))
+	})
+
+	t.Run("end of playlist", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSongs("a", "b", "c")...))
+		td.CmpNoError(t, pl.PlayAt(ctx, 2))
+		td.CmpNoError(t, pl.Enqueue(ctx, minuteSong("x")))
+
+		played, err := pl.SimulatePlayback(ctx, time.Hour)
+		td.CmpNoError(t, err)
+		td.Cmp(t, titles(played), []string{"c", "x"}, "очередь играет и после последней песни")
+		td.CmpFalse(t, pl.Status(ctx).Playing)
+		td.Cmp(t, pl.Status(ctx).Song.Name, "a")
+	})
+
+	t.Run("next and prev", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSongs("a", "b", "c")...))
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpNoError(t, pl.Enqueue(ctx, minuteSong("x")))
+		td.CmpNoError(t, pl.Enqueue(ctx, minuteSong("y")))
+
+		td.CmpNoError(t, pl.Next(ctx))
+		td.Cmp(t, pl.Status(ctx).Song.Name, "x")
+		td.CmpNoError(t, pl.Next(ctx))
+		td.Cmp(t, pl.Status(ctx).Song.Name, "y")
+		td.CmpNoError(t, pl.Prev(ctx))
+		td.Cmp(t, pl.Status(ctx).Song.Name, "b", "Prev возвращает к плейлисту")
+		td.CmpEmpty(t, pl.QueueRemaining(ctx))
+		td.CmpNoError(t, pl.Pause(ctx))
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSongs("a", "b", "c")...))
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpNoError(t, pl.Enqueue(ctx, minuteSong("x")))
+		td.CmpNoError(t, pl.Enqueue(ctx, minuteSong("y")))
+
+		played, err := pl.SimulatePlayback(ctx, time.Minute)
+		td.CmpNoError(t, err)
+		td.Cmp(t, titles(played), []string{"a", "x"})
+
+		td.CmpNoError(t, pl.ClearQueue(ctx))
+		played, err = pl.SimulatePlayback(ctx, time.Minute)
+		td.CmpNoError(t, err)
+		td.Cmp(t, titles(played), []string{"x", "b"}, "играющая песня очереди доигрывает")
+	})
+
+	t.Run("state", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSongs("a", "b", "c")...))
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpNoError(t, pl.Enqueue(ctx, minuteSong("x")))
+		td.CmpNoError(t, pl.Enqueue(ctx, minuteSong("y")))
+		td.CmpNoError(t, pl.Next(ctx))
+
+		state, err := pl.Snapshot(ctx)
+		td.CmpNoError(t, err)
+		td.Cmp(t, titles(state.Queue), []string{"x", "y"}, "играющая песня очереди сохраняется")
+
+		restored := newFakePlayer(t, WithSongs(minuteSongs("a", "b", "c")...))
+		td.CmpNoError(t, restored.RestoreState(ctx, state))
+		td.Cmp(t, titles(restored.QueueRemaining(ctx)), []string{"x", "y"})
+		td.Cmp(t, restored.Status(ctx).Song.Name, "b", "плейлист продолжится с песни после очереди")
+		td.CmpNoError(t, pl.Pause(ctx))
+		td.CmpNoError(t, restored.Pause(ctx))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		pl := newFakePlayer(t, WithSongs(minuteSongs("a", "b", "c")...))
+		err := pl.Enqueue(ctx, Song{Name: "short", Duration: time.Millisecond})
+		td.CmpTrue(t, errors.Is(err, ErrInvalidSong))
+
+		td.CmpNoError(t, pl.Close(ctx))
+		td.Cmp(t, pl.Enqueue(ctx, minuteSong("x")), ErrClosed)
+		td.Cmp(t, pl.ClearQueue(ctx), ErrClosed)
+	})
+}
@@ -0,0 +1,140 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrInvalidSong - песня не прошла проверку.
+var ErrInvalidSong = errors.New("invalid song")
+
+// SongValidator - проверка песен перед добавлением.
+// Ошибка должна оборачивать ErrInvalidSong.
+type SongValidator interface {
+	Validate(song Song) error
+}
+
+// ValidationPolicy - настраиваемые правила проверки песен.
+// Название песни не может быть пустым, а у потока должен быть адрес
+// независимо от политики. Нулевые значения полей снимают ограничения.
+type ValidationPolicy struct {
+	// MinDuration - минимальная длительность песни, на потоки не распространяется
+	MinDuration time.Duration
+	// MaxDuration - максимальная длительность песни
+	MaxDuration time.Duration
+	// MaxNameLength - максимальная длина названия в символах
+	MaxNameLength int
+	// AllowedChars - допустимые символы названия, nil - любые
+	AllowedChars func(r rune) bool
+}
+
+// DefaultValidationPolicy - правила по умолчанию: песня не короче секунды.
+var DefaultValidationPolicy = ValidationPolicy{MinDuration: time.Second}
+
+// Validate - проверяет песню по правилам политики.
+func (vp ValidationPolicy) Validate(song Song) error {
+	if song.Name == "" {
+		return fmt.Errorf("%w: song name is empty", ErrInvalidSong)
+	}
+
+	if vp.MaxNameLength > 0 && utf8.RuneCountInString(song.Name) > vp.MaxNameLength {
+		return fmt.Errorf("%w: song name is longer than %d characters", ErrInvalidSong, vp.MaxNameLength)
+	}
+
+	if vp.AllowedChars != nil {
+		for _, r := range song.Name {
+			if !vp.AllowedChars(r) {
+				return fmt.Errorf("%w: song name contains %q", ErrInvalidSong, r)
+			}
+		}
+	}
+
+	if err := validateChapters(song); err != nil {
+		return err
+	}
+
+	if err := validateCues(song); err != nil {
+		return err
+	}
+
+	if song.LeadIn < 0 || song.LeadOut < 0 {
+		return fmt.Errorf("%w: song lead-in or lead-out is negative", ErrInvalidSong)
+	}
+
+	if song.IsStream() {
+		// длительность станет известна перед воспроизведением
+		if song.Source != nil {
+			return nil
+		}
+		if song.URL == "" {
+			return fmt.Errorf("%w: stream url is empty", ErrInvalidSong)
+		}
+		if song.LeadOut > 0 {
+			return fmt.Errorf("%w: stream has lead-out", ErrInvalidSong)
+		}
+		return nil
+	}
+
+	if song.Duration < 0 {
+		return fmt.Errorf("%w: song duration is negative", ErrInvalidSong)
+	}
+
+	if song.LeadIn+song.LeadOut >= song.Duration {
+		return fmt.Errorf("%w: song lead-in and lead-out cover the whole song", ErrInvalidSong)
+	}
+
+	if song.Duration < vp.MinDuration {
+		return fmt.Errorf("%w: song duration is less than %v", ErrInvalidSong, vp.MinDuration)
+	}
+
+	if vp.MaxDuration > 0 && song.Duration > vp.MaxDuration {
+		return fmt.Errorf("%w: song duration is more than %v", ErrInvalidSong, vp.MaxDuration)
+	}
+
+	return nil
+}
+
+// WithValidator - задаёт проверку песен, которую проходит каждая песня,
+// добавляемая в плейлист или играющая вставкой. По умолчанию используется
+// DefaultValidationPolicy. Песни WithSongs проверяются валидатором,
+// заданным перед ними.
+func WithValidator(v SongValidator) Option {
+	return func(p *playerImpl) error {
+		if v == nil {
+			return errors.New("validator is nil")
+		}
+
+		p.validator = v
+		return nil
+	}
+}
+
+// admitLocked - проверяет песню валидатором и освобождает для неё место
+// в плейлисте name. Через неё проходят все способы добавления песен.
+// Вызывается под блокировкой.
+func (p *playerImpl) admitLocked(ctx context.Context, name string, song Song) error {
+	if err := p.validator.Validate(song); err != nil {
+		return err
+	}
+
+	return p.makeRoomLocked(ctx, name, song)
+}
+
+// NewSongWith - конструктор для Song с проверкой по v,
+// например для джинглов короче секунды.
+func NewSongWith(v SongValidator, name string, d time.Duration) (Song, error) {
+	song := Song{Name: name, Duration: d}
+	if err := v.Validate(song); err != nil {
+		return Song{}, err
+	}
+
+	return song, nil
+}
+
+// validateSong - проверяет песню по правилам по умолчанию.
+func validateSong(song Song) error {
+	return DefaultValidationPolicy.Validate(song)
+}
@@ -0,0 +1,101 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"unicode"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestValidationPolicy(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		vp := DefaultValidationPolicy
+
+		td.CmpNoError(t, vp.Validate(Song{Name: "30 лет", Duration: time.Minute}))
+		td.CmpNoError(t, vp.Validate(Song{Name: "Радио", URL: "http://radio"}))
+		td.CmpError(t, vp.Validate(Song{Name: "Радио"}), "поток без адреса")
+		td.CmpError(t, vp.Validate(Song{Duration: time.Minute}), "пустое название")
+		td.CmpError(t, vp.Validate(Song{Name: "Джингл", Duration: 500 * time.Millisecond}))
+		td.CmpError(t, vp.Validate(Song{Name: "Джингл", Duration: -time.Second}))
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		vp := ValidationPolicy{
+			MaxDuration:   10 * time.Minute,
+			MaxNameLength: 6,
+			AllowedChars: func(r rune) bool {
+				return unicode.IsLetter(r) || unicode.IsSpace(r)
+			},
+		}
+
+		td.CmpNoError(t, vp.Validate(Song{Name: "Джингл", Duration: 500 * time.Millisecond}),
+			"короткие песни разрешены")
+		td.CmpError(t, vp.Validate(Song{Name: "Джингл", Duration: time.Hour}))
+		td.CmpError(t, vp.Validate(Song{Name: "Джингл 2", Duration: time.Minute}), "длинное название")
+
+		err := vp.Validate(Song{Name: "Гол!", Duration: time.Minute})
+		td.CmpTrue(t, errors.Is(err, ErrInvalidSong))
+		td.CmpContains(t, err, `'!'`)
+	})
+
+	t.Run("NewSongWith", func(t *testing.T) {
+		song, err := NewSongWith(ValidationPolicy{}, "Джингл", 300*time.Millisecond)
+		td.CmpNoError(t, err)
+		td.Cmp(t, song, Song{Name: "Джингл", Duration: 300 * time.Millisecond})
+
+		_, err = NewSong("Джингл", 300*time.Millisecond)
+		td.CmpError(t, err, "NewSong проверяет по правилам по умолчанию")
+	})
+}
+
+func TestWithValidator(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := New(WithValidator(nil))
+	td.CmpString(t, err, "validator is nil")
+
+	pl, err := New(WithValidator(ValidationPolicy{MaxDuration: time.Hour}))
+	td.CmpNoError(t, err)
+
+	errs := pl.AddSongs(ctx,
+		Song{Name: "Джингл", Duration: 300 * time.Millisecond},
+		Song{Name: "Подкаст", Duration: 2 * time.Hour},
+	)
+	td.Cmp(t, errs, td.Len(2))
+	td.CmpNoError(t, errs[0])
+	td.CmpTrue(t, errors.Is(errs[1], ErrInvalidSong))
+	td.Cmp(t, pl.Len(ctx), 1)
+}
+
+func TestPlayerImpl_insertValidated(t *testing.T) {
+	ctx := context.Background()
+	bad := Song{Name: "Минус секунда", Duration: -time.Second}
+
+	pl, _ := NewPlayer(minuteSong("a"))
+	td.CmpNoError(t, pl.CreatePlaylist(ctx, "other"))
+	td.CmpNoError(t, WithUserQueueLimit(5)(pl))
+
+	_, err := pl.AddSong(ctx, bad)
+	td.CmpTrue(t, errors.Is(err, ErrInvalidSong), "AddSong")
+	_, err = pl.AddSongTo(ctx, "other", bad)
+	td.CmpTrue(t, errors.Is(err, ErrInvalidSong), "AddSongTo")
+	_, err = pl.AddSongAs(ctx, "вася", bad)
+	td.CmpTrue(t, errors.Is(err, ErrInvalidSong), "AddSongAs")
+	_, err = pl.AddSongWithHook(ctx, bad, func(Song, bool) {})
+	td.CmpTrue(t, errors.Is(err, ErrInvalidSong), "AddSongWithHook")
+	td.CmpTrue(t, errors.Is(pl.InterruptWith(ctx, bad), ErrInvalidSong), "InterruptWith")
+	td.CmpTrue(t, errors.Is(pl.ApplySync(ctx, SyncState{Song: &bad}), ErrInvalidSong), "ApplySync")
+	err = pl.WithTransaction(ctx, func(tx PlaylistTx) error {
+		_, err := tx.Insert(0, bad)
+		return err
+	})
+	td.CmpTrue(t, errors.Is(err, ErrInvalidSong), "PlaylistTx.Insert")
+
+	td.Cmp(t, names(pl), []string{"a"}, "ничего не добавлено")
+	td.CmpNoError(t, pl.Play(ctx))
+	td.Cmp(t, pl.Status(ctx).Song.Name, "a")
+	td.CmpNoError(t, pl.Close(ctx))
+}
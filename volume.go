@@ -0,0 +1,128 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+)
+
+// MaxVolume - максимальная громкость.
+const MaxVolume = 100
+
+// VolumeOutput - бэкенд, поддерживающий управление громкостью.
+type VolumeOutput interface {
+	// SetVolume - устанавливает громкость от 0 до 100
+	SetVolume(ctx context.Context, volume int) error
+}
+
+func (p *playerImpl) SetVolume(ctx context.Context, volume int) error {
+	if volume < 0 || volume > MaxVolume {
+		return fmt.Errorf("volume %d out of range [0, %d]", volume, MaxVolume)
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if err := p.setVolumeLocked(ctx, volume, p.muted); err != nil {
+		return err
+	}
+
+	p.auditLocked(ctx, AuditEntry{Op: AuditVolume, Detail: strconv.Itoa(volume)})
+	return nil
+}
+
+func (p *playerImpl) Volume(_ context.Context) int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.volume
+}
+
+func (p *playerImpl) Mute(ctx context.Context) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if err := p.setVolumeLocked(ctx, p.volume, true); err != nil {
+		return err
+	}
+
+	p.auditLocked(ctx, AuditEntry{Op: AuditVolume, Detail: "muted"})
+	return nil
+}
+
+func (p *playerImpl) Unmute(ctx context.Context) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if err := p.setVolumeLocked(ctx, p.volume, false); err != nil {
+		return err
+	}
+
+	p.auditLocked(ctx, AuditEntry{Op: AuditVolume, Detail: "unmuted"})
+	return nil
+}
+
+// effectiveVolumeLocked - возвращает громкость с учётом выключения звука.
+// Вызывается под блокировкой.
+func (p *playerImpl) effectiveVolumeLocked() int {
+	if p.muted {
+		return 0
+	}
+
+	if p.ducked {
+		return int(math.Round(float64(p.volume) * p.duckLevel))
+	}
+
+	return p.volume
+}
+
+// setVolumeLocked - передаёт бэкенду громкость volume и выключение звука muted.
+// Если бэкенд их не принял, громкость плеера не меняется.
+// Вызывается под блокировкой.
+func (p *playerImpl) setVolumeLocked(ctx context.Context, volume int, muted bool) error {
+	prevVolume, prevMuted := p.volume, p.muted
+	p.volume, p.muted = volume, muted
+	if err := p.applyVolumeLocked(ctx); err != nil {
+		p.volume, p.muted = prevVolume, prevMuted
+		return err
+	}
+
+	return nil
+}
+
+// applyVolumeLocked - передаёт громкость бэкенду, если он её поддерживает.
+// Вызывается под блокировкой.
+func (p *playerImpl) applyVolumeLocked(ctx context.Context) error {
+	p.logger.InfoContext(ctx, "volume changed", slog.Int("volume", p.volume), slog.Bool("muted", p.muted))
+
+	vo, ok := p.output.(VolumeOutput)
+	if !ok {
+		return nil
+	}
+
+	if err := vo.SetVolume(ctx, p.effectiveVolumeLocked()); err != nil {
+		return fmt.Errorf("set output volume: %v", err)
+	}
+
+	return nil
+}
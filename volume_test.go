@@ -0,0 +1,82 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// volumeOutput - бэкенд, запоминающий громкость.
+type volumeOutput struct {
+	nopOutput
+	volumes []int
+	// err - ошибка, которую возвращает SetVolume
+	err error
+}
+
+func (o *volumeOutput) SetVolume(_ context.Context, volume int) error {
+	if o.err != nil {
+		return o.err
+	}
+
+	o.volumes = append(o.volumes, volume)
+	return nil
+}
+
+func TestPlayerImpl_Volume(t *testing.T) {
+	ctx := context.Background()
+	out := &volumeOutput{}
+	pl, _ := New(WithOutput(out), WithSongs(Song{Name: "a", Duration: 30 * time.Second}))
+
+	td.Cmp(t, pl.Volume(ctx), MaxVolume, "по умолчанию максимальная громкость")
+	td.CmpError(t, pl.SetVolume(ctx, -1))
+	td.CmpError(t, pl.SetVolume(ctx, MaxVolume+1))
+
+	td.CmpNoError(t, pl.SetVolume(ctx, 40))
+	td.CmpNoError(t, pl.Mute(ctx))
+	td.Cmp(t, pl.Volume(ctx), 40, "громкость сохраняется при выключении звука")
+	td.Cmp(t, pl.Status(ctx), td.SStruct(Status{Playlist: DefaultPlaylist, Volume: 40, Muted: true}, td.StructFields{
+		"Song":   td.Ignore(),
+		"SongID": td.Ignore(),
+	}))
+
+	td.CmpNoError(t, pl.Unmute(ctx))
+	td.Cmp(t, out.volumes, []int{40, 0, 40}, "бэкенд получает итоговую громкость")
+
+	out.err = errors.New("device unplugged")
+	td.CmpString(t, pl.SetVolume(ctx, 70), "set output volume: device unplugged")
+	td.CmpError(t, pl.Mute(ctx))
+	td.CmpError(t, pl.Apply(ctx, Config{Volume: 10}))
+	td.CmpError(t, pl.Duck(ctx, 0.5))
+	st := pl.Status(ctx)
+	td.Cmp(t, st.Volume, 40, "громкость, которую бэкенд не принял, не запоминается")
+	td.CmpFalse(t, st.Muted)
+	td.Cmp(t, pl.CurrentConfig(ctx).Volume, 40)
+	td.CmpFalse(t, pl.ducked)
+	td.Cmp(t, pl.AuditLog(ctx, time.Time{}), td.Len(3), "неудачные вызовы не записываются")
+}
+
+func TestPlayerImpl_Status(t *testing.T) {
+	ctx := context.Background()
+
+	empty, _ := NewPlayer()
+	td.Cmp(t, empty.Status(ctx), Status{Playlist: DefaultPlaylist, Volume: MaxVolume})
+
+	a := Song{Name: "a", Duration: 30 * time.Second}
+	pl, _ := NewPlayer(a)
+	_ = pl.Play(ctx)
+	time.Sleep(10 * time.Millisecond)
+
+	td.Cmp(t, pl.Status(ctx), td.Struct(Status{
+		Playlist: DefaultPlaylist,
+		Song:     &a,
+		Playing:  true,
+		Volume:   MaxVolume,
+	}, td.StructFields{
+		"Position": td.Gte(10 * time.Millisecond),
+	}))
+	_ = pl.Pause(ctx)
+}
@@ -0,0 +1,207 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+)
+
+var (
+	// ErrVotingDisabled - голосование за пропуск не настроено.
+	ErrVotingDisabled = errors.New("skip voting is disabled")
+	// ErrUnknownListener - пользователь не зарегистрирован как слушатель.
+	ErrUnknownListener = errors.New("unknown listener")
+)
+
+// VoteEvent - голос за пропуск текущей песни.
+type VoteEvent struct {
+	// Song - песня, за пропуск которой проголосовали
+	Song Song
+	// UserID - проголосовавший пользователь
+	UserID string
+	// Votes - сколько голосов набрано
+	Votes int
+	// Needed - сколько голосов нужно для пропуска
+	Needed int
+	// Skipped - голосов хватило, и песня пропущена
+	Skipped bool
+	// State - состояние плеера в момент голоса
+	State State
+}
+
+// VoteHook - обработчик голосов за пропуск.
+type VoteHook func(event VoteEvent)
+
+// WithSkipVotes - песня пропускается, когда за это проголосуют n пользователей.
+func WithSkipVotes(n int) Option {
+	return func(p *playerImpl) error {
+		if n <= 0 {
+			return errors.New("skip votes must be positive")
+		}
+
+		p.skipVotes, p.skipFraction = n, 0
+		return nil
+	}
+}
+
+// WithSkipFraction - песня пропускается, когда за это проголосует доля f
+// зарегистрированных слушателей, но не меньше одного.
+// Голосовать могут только слушатели, зарегистрированные через AddListener.
+func WithSkipFraction(f float64) Option {
+	return func(p *playerImpl) error {
+		if f <= 0 || f > 1 {
+			return errors.New("skip fraction must be in (0, 1]")
+		}
+
+		p.skipFraction, p.skipVotes = f, 0
+		return nil
+	}
+}
+
+// AddListener - регистрирует слушателя для голосования долей слушателей.
+func (p *playerImpl) AddListener(ctx context.Context, userID string) error {
+	if userID == "" {
+		return errors.New("user id is empty")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	if p.listeners == nil {
+		p.listeners = make(map[string]bool)
+	}
+	p.listeners[userID] = true
+	return nil
+}
+
+// RemoveListener - снимает регистрацию слушателя, его голос за текущую песню отзывается.
+func (p *playerImpl) RemoveListener(ctx context.Context, userID string) error {
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	delete(p.listeners, userID)
+	delete(p.votes, userID)
+	return nil
+}
+
+// VoteSkip - голосует за пропуск текущей песни. Повторный голос пользователя
+// за ту же песню не учитывается. Когда голосов набирается достаточно,
+// плеер переходит к следующей песне, как по Next. Возвращает, была ли песня пропущена.
+func (p *playerImpl) VoteSkip(ctx context.Context, userID string) (bool, error) {
+	if userID == "" {
+		return false, errors.New("user id is empty")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return false, err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return false, ErrClosed
+	}
+
+	if p.skipVotes == 0 && p.skipFraction == 0 {
+		return false, ErrVotingDisabled
+	}
+
+	if p.skipFraction > 0 && !p.listeners[userID] {
+		return false, ErrUnknownListener
+	}
+
+	if p.current == nil {
+		return false, nil
+	}
+
+	// голоса относятся к текущей песне
+	if p.votesFor != p.current {
+		p.votes, p.votesFor = make(map[string]bool), p.current
+	}
+	p.votes[userID] = true
+
+	event := VoteEvent{
+		Song:   *p.current.song,
+		UserID: userID,
+		Votes:  len(p.votes),
+		Needed: p.neededVotesLocked(),
+		State:  p.stateLocked(),
+	}
+	event.Skipped = event.Votes >= event.Needed
+
+	entry := AuditEntry{UserID: userID, Op: AuditVoteSkip, Song: &event.Song}
+	if event.Skipped {
+		entry.Detail = "skipped"
+	}
+	p.auditLocked(ctx, entry)
+
+	p.voteCastLocked(event)
+	p.logger.DebugContext(ctx, "skip vote cast", songAttr(event.Song), slog.String("user", userID),
+		slog.Int("votes", event.Votes), slog.Int("needed", event.Needed))
+
+	if !event.Skipped {
+		return false, nil
+	}
+
+	p.votes, p.votesFor = nil, nil
+	p.logger.InfoContext(ctx, "song skipped by vote", songAttr(event.Song))
+	return true, p.nextLocked(ctx)
+}
+
+// OnVote - регистрирует обработчик, который вызывается при каждом голосе за пропуск,
+// в том числе при голосе, после которого песня пропущена.
+// Обработчики вызываются по порядку в отдельной горутине.
+func (p *playerImpl) OnVote(ctx context.Context, hook VoteHook) error {
+	if hook == nil {
+		return errors.New("hook is nil")
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	p.voteHooks = append(p.voteHooks, hook)
+	return nil
+}
+
+// neededVotesLocked - возвращает количество голосов, нужное для пропуска.
+// Вызывается под блокировкой.
+func (p *playerImpl) neededVotesLocked() int {
+	if p.skipFraction > 0 {
+		return max(1, int(math.Ceil(p.skipFraction*float64(len(p.listeners)))))
+	}
+
+	return p.skipVotes
+}
+
+// voteCastLocked - ставит в очередь обработчики голоса.
+// Вызывается под блокировкой.
+func (p *playerImpl) voteCastLocked(event VoteEvent) {
+	if len(p.voteHooks) == 0 {
+		return
+	}
+
+	hooks := append([]VoteHook(nil), p.voteHooks...)
+	p.hookQueue.push(func() {
+		for _, h := range hooks {
+			h(event)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package player
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_VoteSkip(t *testing.T) {
+	ctx := context.Background()
+	songs := []Song{
+		{Name: "a", Duration: 30 * time.Second},
+		{Name: "b", Duration: 30 * time.Second},
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		pl, _ := NewPlayer(songs...)
+		_, err := pl.VoteSkip(ctx, "вася")
+		td.Cmp(t, err, ErrVotingDisabled)
+	})
+
+	t.Run("count", func(t *testing.T) {
+		var (
+			mu     sync.Mutex
+			events []VoteEvent
+		)
+
+		pl, _ := New(WithSkipVotes(2), WithSongs(songs...))
+		td.CmpNoError(t, pl.OnVote(ctx, func(e VoteEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, e)
+		}))
+		_ = pl.Play(ctx)
+		defer pl.Pause(ctx)
+
+		skipped, err := pl.VoteSkip(ctx, "вася")
+		td.CmpNoError(t, err)
+		td.CmpFalse(t, skipped)
+
+		skipped, _ = pl.VoteSkip(ctx, "вася")
+		td.CmpFalse(t, skipped, "повторный голос не считается")
+
+		skipped, _ = pl.VoteSkip(ctx, "петя")
+		td.CmpTrue(t, skipped)
+		td.Cmp(t, pl.Status(ctx).Song.Name, "b")
+		td.CmpTrue(t, pl.Status(ctx).Playing)
+
+		skipped, _ = pl.VoteSkip(ctx, "вася")
+		td.CmpFalse(t, skipped, "голоса за новую песню считаются заново")
+
+		td.CmpNoError(t, pl.hookQueue.wait(ctx))
+		mu.Lock()
+		defer mu.Unlock()
+		td.Cmp(t, events, []VoteEvent{
+			{Song: songs[0], UserID: "вася", Votes: 1, Needed: 2, State: StatePlaying},
+			{Song: songs[0], UserID: "вася", Votes: 1, Needed: 2, State: StatePlaying},
+			{Song: songs[0], UserID: "петя", Votes: 2, Needed: 2, Skipped: true, State: StatePlaying},
+			{Song: songs[1], UserID: "вася", Votes: 1, Needed: 2, State: StatePlaying},
+		})
+	})
+
+	t.Run("fraction", func(t *testing.T) {
+		pl, _ := New(WithSkipFraction(0.5), WithSongs(songs...))
+		for _, u := range []string{"вася", "петя", "коля"} {
+			td.CmpNoError(t, pl.AddListener(ctx, u))
+		}
+
+		_, err := pl.VoteSkip(ctx, "гость")
+		td.Cmp(t, err, ErrUnknownListener)
+
+		skipped, _ := pl.VoteSkip(ctx, "вася")
+		td.CmpFalse(t, skipped, "нужно 2 голоса из 3")
+
+		_ = pl.RemoveListener(ctx, "коля")
+		_ = pl.RemoveListener(ctx, "вася")
+		skipped, _ = pl.VoteSkip(ctx, "петя")
+		td.CmpTrue(t, skipped, "голос ушедшего слушателя отозван, нужен 1 голос из 1")
+		td.Cmp(t, pl.Status(ctx).Song.Name, "b")
+	})
+
+	t.Run("options", func(t *testing.T) {
+		_, err := New(WithSkipVotes(0))
+		td.CmpError(t, err)
+		_, err = New(WithSkipFraction(1.5))
+		td.CmpError(t, err)
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		pl, _ := New(WithSkipVotes(1))
+		_ = pl.Close(ctx)
+
+		_, err := pl.VoteSkip(ctx, "вася")
+		td.Cmp(t, err, ErrClosed)
+		td.Cmp(t, pl.AddListener(ctx, "вася"), ErrClosed)
+		td.Cmp(t, pl.OnVote(ctx, func(VoteEvent) {}), ErrClosed)
+	})
+}
@@ -0,0 +1,65 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// watchPoll - как часто WaitFor перепроверяет условие, чтобы заметить изменение позиции.
+const watchPoll = 10 * time.Millisecond
+
+// WaitFor - блокируется, пока состояние плеера не будет удовлетворять pred,
+// например пока не начнёт играть нужная песня или позиция не превысит минуту.
+// Условие проверяется сразу, при смене песни, начале и остановке воспроизведения,
+// а также каждые 10 мс. Возвращает ошибку ctx, если он завершится раньше,
+// и ErrClosed, если плеер закрыт.
+func (p *playerImpl) WaitFor(ctx context.Context, pred func(Status) bool) error {
+	if pred == nil {
+		return errors.New("predicate is nil")
+	}
+
+	ticker := time.NewTicker(watchPoll)
+	defer ticker.Stop()
+
+	for {
+		if err := p.lock(ctx); err != nil {
+			return err
+		}
+		changed, st, closed := p.changedLocked(), p.statusLocked(), p.closed
+		p.mu.Unlock()
+
+		if pred(st) {
+			return nil
+		}
+		if closed {
+			return ErrClosed
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		case <-ticker.C:
+		}
+	}
+}
+
+// changedLocked - возвращает канал, который закроется при следующем изменении состояния.
+// Вызывается под блокировкой.
+func (p *playerImpl) changedLocked() <-chan struct{} {
+	if p.changed == nil {
+		p.changed = make(chan struct{})
+	}
+
+	return p.changed
+}
+
+// notifyLocked - будит ожидающих изменения состояния.
+// Вызывается под блокировкой.
+func (p *playerImpl) notifyLocked() {
+	if p.changed != nil {
+		close(p.changed)
+		p.changed = nil
+	}
+}
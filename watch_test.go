@@ -0,0 +1,79 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestPlayerImpl_WaitFor(t *testing.T) {
+	ctx := context.Background()
+	first := Song{Name: "Сектор Газа - 30 лет", Duration: 30 * time.Millisecond}
+	second := Song{Name: "Александр Пушной - Почему я идиот?", Duration: 30 * time.Second}
+
+	songIs := func(name string) func(Status) bool {
+		return func(st Status) bool { return st.Song != nil && st.Song.Name == name }
+	}
+
+	t.Run("song started", func(t *testing.T) {
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithSongs(first, second))
+		_ = pl.Play(ctx)
+		defer pl.Pause(ctx)
+
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+
+		start := time.Now()
+		td.CmpNoError(t, pl.WaitFor(ctx, songIs(second.Name)))
+		td.Cmp(t, time.Since(start), td.Between(20*time.Millisecond, 60*time.Millisecond))
+	})
+
+	t.Run("position", func(t *testing.T) {
+		pl, _ := NewPlayer(second)
+		_ = pl.Play(ctx)
+		defer pl.Pause(ctx)
+
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+
+		td.CmpNoError(t, pl.WaitFor(ctx, func(st Status) bool { return st.Position > 25*time.Millisecond }))
+		td.Cmp(t, pl.Elapsed(ctx), td.Gt(25*time.Millisecond))
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		pl, _ := NewPlayer(second)
+		_ = pl.Play(ctx)
+
+		go func() {
+			time.Sleep(5 * time.Millisecond)
+			_ = pl.Pause(ctx)
+		}()
+
+		td.CmpNoError(t, pl.WaitFor(ctx, func(st Status) bool { return !st.Playing }))
+	})
+
+	t.Run("context expires", func(t *testing.T) {
+		pl, _ := NewPlayer(second)
+		ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		td.Cmp(t, pl.WaitFor(ctx, func(st Status) bool { return st.Playing }), context.DeadlineExceeded)
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		pl, _ := NewPlayer(second)
+		go func() {
+			time.Sleep(5 * time.Millisecond)
+			_ = pl.Close(ctx)
+		}()
+
+		td.Cmp(t, pl.WaitFor(ctx, func(st Status) bool { return st.Playing }), ErrClosed)
+	})
+
+	t.Run("nil predicate", func(t *testing.T) {
+		pl, _ := NewPlayer()
+		td.CmpString(t, pl.WaitFor(ctx, nil), "predicate is nil")
+	})
+}
@@ -0,0 +1,140 @@
+package player
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// WeightFunc - вес песни для WeightedRandom: чем больше, тем чаще песня выбирается.
+// stats - статистика песни, для ещё не игравших песен заполнено только Song.
+// Неположительный вес исключает песню из выбора.
+type WeightFunc func(stats SongStats, now time.Time) float64
+
+// recencyWindow - сколько DefaultWeight снижает вес недавно игравших песен.
+const recencyWindow = 4 * time.Hour
+
+// DefaultWeight - вес по умолчанию для WeightedRandom:
+//   - оценка в звёздах делённая на 3, песни без оценки считаются на 3 звезды;
+//   - избранное в полтора раза чаще;
+//   - каждый пропуск и каждые 10 прослушиваний снижают вес;
+//   - песня, которую слушали меньше 4 часов назад, выбирается реже, чем свежее она в памяти.
+func DefaultWeight(stats SongStats, now time.Time) float64 {
+	w := 1.0
+	if stats.Rating > 0 {
+		w = float64(stats.Rating) / 3
+	}
+
+	if stats.Favorite {
+		w *= 1.5
+	}
+
+	w /= 1 + float64(stats.SkipCount) + float64(stats.PlayCount)/10
+
+	if !stats.LastPlayed.IsZero() {
+		since := now.Sub(stats.LastPlayed)
+		w *= math.Max(math.Min(float64(since)/float64(recencyWindow), 1), 0.05)
+	}
+
+	return w
+}
+
+// weightedRandom - Sequencer WeightedRandom.
+type weightedRandom struct {
+	weight WeightFunc
+	// now - часы плеера, которому передан секвенсор, nil - системные
+	now func() time.Time
+}
+
+// WeightedRandom - следующая песня выбирается случайно с весами weight,
+// nil - DefaultWeight. В плеере выбор делается из всех песен плейлиста,
+// кроме текущей, с их статистикой, поэтому воспроизведение не кончается,
+// пока в плейлисте больше одной песни. Через Sequencer.Next
+// выбор делается из remaining без статистики.
+func WeightedRandom(weight WeightFunc) Sequencer {
+	if weight == nil {
+		weight = DefaultWeight
+	}
+
+	return &weightedRandom{weight: weight}
+}
+
+func (w *weightedRandom) Next(_ Song, remaining []Song) int {
+	stats := make([]SongStats, len(remaining))
+	for i, s := range remaining {
+		stats[i].Song = s
+	}
+
+	now := time.Now
+	if w.now != nil {
+		now = w.now
+	}
+
+	return w.pick(stats, now(), nil)
+}
+
+// pick - индекс случайной песни с учётом весов, выбранной генератором r,
+// nil - общим генератором. Если ни у одной песни нет положительного веса,
+// выбор равновероятный.
+func (w *weightedRandom) pick(stats []SongStats, now time.Time, r *rand.Rand) int {
+	weights := make([]float64, len(stats))
+	var total float64
+	for i, s := range stats {
+		// NaN и бесконечность тоже исключают песню
+		if v := w.weight(s, now); v > 0 && !math.IsInf(v, 1) {
+			weights[i] = v
+			total += v
+		}
+	}
+
+	intn, float64n := rand.Intn, rand.Float64
+	if r != nil {
+		intn, float64n = r.Intn, r.Float64
+	}
+
+	if total == 0 {
+		return intn(len(stats))
+	}
+
+	x := float64n() * total
+	for i, v := range weights {
+		if x < v {
+			return i
+		}
+		x -= v
+	}
+
+	// погрешность округления
+	for i := len(weights) - 1; i >= 0; i-- {
+		if weights[i] > 0 {
+			return i
+		}
+	}
+	return 0
+}
+
+// weightedLocked - выбирает следующую песню среди всех песен плейлиста, кроме текущей.
+// Вызывается под блокировкой.
+func (p *playerImpl) weightedLocked(w *weightedRandom) *playerNode {
+	cur := p.current
+	var nodes []*playerNode
+	var stats []SongStats
+	for n := p.first(); n != nil; n = n.next() {
+		if n == cur {
+			continue
+		}
+
+		st := SongStats{Song: *n.song}
+		if s, ok := p.stats[songKey(*n.song)]; ok {
+			st = *s
+		}
+		nodes = append(nodes, n)
+		stats = append(stats, st)
+	}
+
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	return nodes[w.pick(stats, p.now(), p.rand)]
+}
@@ -0,0 +1,127 @@
+package player
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestDefaultWeight(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for name, tc := range map[string]struct {
+		stats SongStats
+		want  float64
+	}{
+		"new":       {SongStats{}, 1},
+		"5 stars":   {SongStats{Rating: 5}, 5.0 / 3},
+		"favorite":  {SongStats{Rating: 3, Favorite: true}, 1.5},
+		"skipped":   {SongStats{SkipCount: 3}, 0.25},
+		"played":    {SongStats{PlayCount: 10, LastPlayed: now.Add(-24 * time.Hour)}, 0.5},
+		"recent":    {SongStats{LastPlayed: now.Add(-time.Hour)}, 0.25},
+		"just now":  {SongStats{LastPlayed: now}, 0.05},
+		"all flags": {SongStats{Rating: 1, SkipCount: 1, LastPlayed: now.Add(-2 * time.Hour)}, 1.0 / 3 / 2 / 2},
+	} {
+		td.Cmp(t, DefaultWeight(tc.stats, now), td.Between(tc.want-1e-9, tc.want+1e-9), name)
+	}
+}
+
+func TestWeightedRandom(t *testing.T) {
+	ctx := context.Background()
+	songs := []Song{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+
+	t.Run("weights", func(t *testing.T) {
+		only := func(name string) WeightFunc {
+			return func(s SongStats, _ time.Time) float64 {
+				if s.Song.Name == name {
+					return 1
+				}
+				return 0
+			}
+		}
+
+		for i := 0; i < 20; i++ {
+			td.Cmp(t, WeightedRandom(only("c")).Next(Song{}, songs), 2, "вес только у c")
+		}
+
+		nan := WeightedRandom(func(SongStats, time.Time) float64 { return math.NaN() })
+		td.Cmp(t, nan.Next(Song{}, songs), td.Between(0, 2), "без весов - равновероятно")
+
+		counts := make([]int, len(songs))
+		seq := WeightedRandom(func(s SongStats, _ time.Time) float64 { return map[string]float64{"a": 1, "b": 3}[s.Song.Name] })
+		for i := 0; i < 4000; i++ {
+			counts[seq.Next(Song{}, songs)]++
+		}
+		td.Cmp(t, counts[2], 0)
+		td.Cmp(t, float64(counts[1])/float64(counts[0]), td.Between(2.5, 3.5), "b в три раза чаще a")
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		// выбираются только невыслушанные песни
+		fresh := WeightedRandom(func(s SongStats, _ time.Time) float64 {
+			if s.PlayCount > 0 {
+				return 0
+			}
+			return 1
+		})
+		pl, _ := New(WithSequencer(fresh), WithSongs(
+			Song{Name: "a", Duration: time.Minute},
+			Song{Name: "b", Duration: time.Minute},
+			Song{Name: "c", Duration: time.Minute},
+		))
+
+		pl.mu.Lock()
+		pl.songStatsLocked(Song{Name: "b", Duration: time.Minute}).PlayCount = 5
+		pl.mu.Unlock()
+
+		td.CmpNoError(t, pl.Play(ctx))
+		td.Cmp(t, titles(pl.PeekNext(ctx, 1)), []string{"c"})
+		td.Cmp(t, names(pl), []string{"a", "b", "c"}, "порядок плейлиста не меняется")
+		td.CmpNoError(t, pl.Pause(ctx))
+	})
+
+	t.Run("whole playlist", func(t *testing.T) {
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithSequencer(WeightedRandom(nil)), WithSongs(
+			Song{Name: "a", Duration: 20 * time.Millisecond},
+			Song{Name: "b", Duration: 20 * time.Millisecond},
+		))
+
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpTrue(t, eventually(func() bool { return len(history(t, pl, 0)) >= 4 }), "плейлист не кончается")
+		td.CmpTrue(t, pl.Status(ctx).Playing)
+		td.CmpNoError(t, pl.Pause(ctx))
+
+		var played []string
+		for _, e := range history(t, pl, 0) {
+			played = append(played, e.Song.Name)
+		}
+		for i := 1; i < len(played); i++ {
+			td.Cmp(t, played[i], td.Not(played[i-1]), "текущая песня не выбирается повторно")
+		}
+	})
+
+	t.Run("player clock", func(t *testing.T) {
+		var seen []time.Time
+		seq := WeightedRandom(func(_ SongStats, now time.Time) float64 {
+			seen = append(seen, now)
+			return 1
+		})
+		pl, err := New(WithSequencer(seq), WithClock(NewFakeClock(testStart)), WithSongs(minuteSongs("a", "b")...))
+		td.CmpNoError(t, err)
+
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpNoError(t, pl.Pause(ctx))
+		pl.sequencer.Next(Song{}, songs)
+		td.Cmp(t, seen, td.All(td.NotEmpty(), td.ArrayEach(testStart)), "время по часам плеера")
+	})
+
+	t.Run("single song", func(t *testing.T) {
+		pl, _ := New(WithValidator(ValidationPolicy{}), WithSequencer(WeightedRandom(nil)), WithSongs(Song{Name: "a", Duration: 20 * time.Millisecond}))
+
+		td.CmpNoError(t, pl.Play(ctx))
+		td.CmpTrue(t, eventually(func() bool { return !pl.Status(ctx).Playing }), "выбирать не из чего")
+	})
+}
@@ -0,0 +1,266 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrZoneNotFound - зоны с таким названием нет.
+	ErrZoneNotFound = errors.New("zone not found")
+	// ErrZoneExists - зона с таким названием уже есть.
+	ErrZoneExists = errors.New("zone already exists")
+)
+
+// ZoneStateStorage - хранилище, которое хранит состояние каждой зоны отдельно.
+// Его реализуют MemoryStorage и FileStorage.
+type ZoneStateStorage interface {
+	// SaveZoneState - сохраняет состояние плеера зоны
+	SaveZoneState(ctx context.Context, zone string, state PlayerState) error
+	// LoadZoneState - возвращает сохранённое состояние зоны или ErrStateNotFound
+	LoadZoneState(ctx context.Context, zone string) (PlayerState, error)
+}
+
+// zoneStorage - хранилище плеера зоны: плейлисты и история общие,
+// состояние своё у каждой зоны.
+type zoneStorage struct {
+	Storage
+	zone string
+}
+
+func (s zoneStorage) SaveState(ctx context.Context, state PlayerState) error {
+	return s.Storage.(ZoneStateStorage).SaveZoneState(ctx, s.zone, state)
+}
+
+func (s zoneStorage) LoadState(ctx context.Context) (PlayerState, error) {
+	return s.Storage.(ZoneStateStorage).LoadZoneState(ctx, s.zone)
+}
+
+// Zones - несколько независимых плееров, например по комнатам дома,
+// над общими библиотекой и хранилищем. Каждая зона играет свой плейлист
+// и сохраняет своё состояние, а история и сохранённые плейлисты общие.
+type Zones struct {
+	mu      sync.RWMutex
+	library *Library
+	storage Storage
+	opts    []Option
+	zones   map[string]*playerImpl
+}
+
+// ZoneStatus - состояние воспроизведения зоны.
+type ZoneStatus struct {
+	// Zone - название зоны
+	Zone string `json:"zone"`
+	// Status - состояние воспроизведения плеера зоны
+	Status Status `json:"status"`
+	// State - состояние плеера зоны
+	State State `json:"state"`
+}
+
+// NewZones - создаёт менеджер зон над библиотекой library и хранилищем storage,
+// nil - новые пустые. Хранилище должно реализовывать ZoneStateStorage.
+// opts применяются к плееру каждой зоны перед опциями самой зоны.
+func NewZones(library *Library, storage Storage, opts ...Option) (*Zones, error) {
+	if library == nil {
+		library = NewLibrary()
+	}
+	if storage == nil {
+		storage = NewMemoryStorage()
+	}
+
+	if _, ok := storage.(ZoneStateStorage); !ok {
+		return nil, errors.New("storage does not support zone state")
+	}
+
+	return &Zones{
+		library: library,
+		storage: storage,
+		opts:    opts,
+		zones:   make(map[string]*playerImpl),
+	}, nil
+}
+
+// Add - создаёт зону name. Её плеер восстанавливает сохранённое состояние зоны,
+// если оно есть. Библиотеку и хранилище зоны задаёт менеджер.
+func (z *Zones) Add(_ context.Context, name string, opts ...Option) (*playerImpl, error) {
+	if name == "" {
+		return nil, errors.New("zone name is empty")
+	}
+
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	if _, ok := z.zones[name]; ok {
+		return nil, ErrZoneExists
+	}
+
+	all := make([]Option, 0, len(z.opts)+len(opts)+2)
+	all = append(all, z.opts...)
+	all = append(all, opts...)
+	all = append(all, WithLibrary(z.library), WithStorage(zoneStorage{Storage: z.storage, zone: name}))
+
+	p, err := New(all...)
+	if err != nil {
+		return nil, fmt.Errorf("zone %q: %w", name, err)
+	}
+
+	z.zones[name] = p
+	return p, nil
+}
+
+// Zone - плеер зоны name.
+func (z *Zones) Zone(name string) (*playerImpl, error) {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+
+	p, ok := z.zones[name]
+	if !ok {
+		return nil, ErrZoneNotFound
+	}
+
+	return p, nil
+}
+
+// Names - названия зон по алфавиту.
+func (z *Zones) Names() []string {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+
+	names := make([]string, 0, len(z.zones))
+	for name := range z.zones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Remove - закрывает плеер зоны name, сохраняя её состояние, и удаляет зону.
+func (z *Zones) Remove(ctx context.Context, name string) error {
+	z.mu.Lock()
+	p, ok := z.zones[name]
+	delete(z.zones, name)
+	z.mu.Unlock()
+
+	if !ok {
+		return ErrZoneNotFound
+	}
+
+	return p.Close(ctx)
+}
+
+// Close - закрывает плееры всех зон.
+func (z *Zones) Close(ctx context.Context) error {
+	z.mu.Lock()
+	zones := z.zones
+	z.zones = make(map[string]*playerImpl)
+	z.mu.Unlock()
+
+	var errs []error
+	for name, p := range zones {
+		if err := p.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("zone %q: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Transfer - переносит воспроизведение из зоны from в зону to: песни активного
+// плейлиста from с текущей позицией и очередь Enqueue заменяют активный
+// плейлист и очередь to. Если from играла, to продолжает с той же позиции,
+// а from ставится на паузу и её очередь очищается.
+// Остальные плейлисты to не меняются.
+func (z *Zones) Transfer(ctx context.Context, from, to string) error {
+	if from == to {
+		return errors.New("cannot transfer playback to the same zone")
+	}
+
+	src, err := z.Zone(from)
+	if err != nil {
+		return fmt.Errorf("zone %q: %w", from, err)
+	}
+	dst, err := z.Zone(to)
+	if err != nil {
+		return fmt.Errorf("zone %q: %w", to, err)
+	}
+
+	state, err := dst.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	moved, err := src.handOff(ctx)
+	if err != nil {
+		return fmt.Errorf("zone %q: %w", from, err)
+	}
+
+	// активный плейлист всегда первый в снимке
+	active := moved.Playlists[0]
+	active.Name = state.Playlists[0].Name
+	state.Playlists[0] = active
+	state.Queue = moved.Queue
+	state.IsPlaying = moved.IsPlaying
+
+	// воспроизведение to переживает вызов Transfer, поэтому отмена ctx
+	// не должна останавливать его горутину
+	if err := dst.RestoreState(context.WithoutCancel(ctx), state); err != nil {
+		// from возвращается в состояние до переноса
+		_ = src.RestoreState(context.WithoutCancel(ctx), moved)
+		return fmt.Errorf("zone %q: %w", to, err)
+	}
+
+	return nil
+}
+
+// handOff - снимает состояние плеера для Transfer, ставит его на паузу
+// и очищает очередь Enqueue. Всё делается под одной блокировкой, поэтому
+// изменения очереди не теряются, а песня не играет в двух зонах сразу.
+func (p *playerImpl) handOff(ctx context.Context) (PlayerState, error) {
+	if err := p.lock(ctx); err != nil {
+		return PlayerState{}, err
+	}
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return PlayerState{}, ErrClosed
+	}
+
+	state := p.snapshotLocked()
+	if p.isPlaying {
+		p.auditLocked(ctx, AuditEntry{Op: AuditPause, Song: p.currentSongLocked()})
+		p.pauseLocked(ctx)
+	}
+	p.auditLocked(ctx, AuditEntry{Op: AuditClearQueue, Song: p.currentSongLocked()})
+	p.upNext.Clear()
+
+	return state, nil
+}
+
+// Status - состояние воспроизведения всех зон по алфавиту.
+// Если ctx завершился раньше, чем удалось прочитать все зоны, возвращаются прочитанные.
+func (z *Zones) Status(ctx context.Context) []ZoneStatus {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+
+	names := make([]string, 0, len(z.zones))
+	for name := range z.zones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	statuses := make([]ZoneStatus, 0, len(names))
+	for _, name := range names {
+		p := z.zones[name]
+		if err := p.rlock(ctx); err != nil {
+			break
+		}
+		statuses = append(statuses, ZoneStatus{Zone: name, Status: p.statusLocked(), State: p.stateLocked()})
+		p.mu.RUnlock()
+	}
+
+	return statuses
+}
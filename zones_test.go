@@ -0,0 +1,183 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestZones(t *testing.T) {
+	ctx := context.Background()
+
+	newZones := func(t *testing.T, storage Storage) *Zones {
+		z, err := NewZones(nil, storage, WithClock(NewFakeClock(testStart)))
+		td.CmpNoError(t, err)
+		return z
+	}
+
+	t.Run("add and remove", func(t *testing.T) {
+		z := newZones(t, nil)
+
+		kitchen, err := z.Add(ctx, "kitchen", WithSongs(minuteSong("a")))
+		td.CmpNoError(t, err)
+		hall, err := z.Add(ctx, "hall")
+		td.CmpNoError(t, err)
+		td.Cmp(t, kitchen.Library(), hall.Library(), "библиотека общая")
+
+		_, err = z.Add(ctx, "kitchen")
+		td.CmpTrue(t, errors.Is(err, ErrZoneExists))
+		_, err = z.Add(ctx, "")
+		td.CmpString(t, err, "zone name is empty")
+
+		td.Cmp(t, z.Names(), []string{"hall", "kitchen"})
+
+		got, err := z.Zone("kitchen")
+		td.CmpNoError(t, err)
+		td.Cmp(t, got, td.Shallow(kitchen))
+
+		td.CmpNoError(t, z.Remove(ctx, "kitchen"))
+		td.CmpTrue(t, errors.Is(kitchen.Play(ctx), ErrClosed), "плеер зоны закрыт")
+		_, err = z.Zone("kitchen")
+		td.CmpTrue(t, errors.Is(err, ErrZoneNotFound))
+		td.CmpTrue(t, errors.Is(z.Remove(ctx, "kitchen"), ErrZoneNotFound))
+
+		td.CmpNoError(t, z.Close(ctx))
+		td.CmpEmpty(t, z.Names())
+	})
+
+	t.Run("transfer", func(t *testing.T) {
+		z := newZones(t, nil)
+		defer z.Close(ctx)
+
+		living, err := z.Add(ctx, "living", WithSongs(minuteSongs("a", "b", "c")...))
+		td.CmpNoError(t, err)
+		kitchen, err := z.Add(ctx, "kitchen", WithSongs(minuteSong("x")))
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, kitchen.CreatePlaylist(ctx, "morning"))
+
+		td.CmpNoError(t, living.PlayAt(ctx, 1))
+		_, err = living.SimulatePlayback(ctx, 20*time.Second)
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, living.Enqueue(ctx, minuteSong("q")))
+
+		td.CmpNoError(t, z.Transfer(ctx, "living", "kitchen"))
+
+		st := kitchen.Status(ctx)
+		td.CmpTrue(t, st.Playing, "кухня продолжает воспроизведение")
+		td.Cmp(t, st.Song.Name, "b")
+		td.Cmp(t, st.Position, 20*time.Second)
+		td.Cmp(t, names(kitchen), []string{"a", "b", "c"})
+		td.Cmp(t, titles(kitchen.QueueRemaining(ctx)), []string{"q"})
+		td.CmpNoError(t, kitchen.SwitchPlaylist(ctx, "morning"), "остальные плейлисты кухни на месте")
+
+		td.CmpFalse(t, living.Status(ctx).Playing, "гостиная на паузе")
+		td.CmpEmpty(t, living.QueueRemaining(ctx))
+
+		td.CmpString(t, z.Transfer(ctx, "kitchen", "kitchen"), "cannot transfer playback to the same zone")
+		td.CmpTrue(t, errors.Is(z.Transfer(ctx, "kitchen", "garage"), ErrZoneNotFound))
+	})
+
+	t.Run("transfer failed", func(t *testing.T) {
+		z := newZones(t, nil)
+		defer z.Close(ctx)
+
+		living, err := z.Add(ctx, "living", WithSongs(minuteSongs("a", "b")...))
+		td.CmpNoError(t, err)
+		kitchen, err := z.Add(ctx, "kitchen")
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, living.PlayAt(ctx, 1))
+		td.CmpNoError(t, living.Enqueue(ctx, minuteSong("q")))
+		td.CmpNoError(t, kitchen.Close(ctx))
+
+		td.CmpTrue(t, errors.Is(z.Transfer(ctx, "living", "kitchen"), ErrClosed))
+		st := living.Status(ctx)
+		td.CmpTrue(t, st.Playing, "гостиная продолжает играть")
+		td.Cmp(t, st.Song.Name, "b")
+		td.Cmp(t, titles(living.QueueRemaining(ctx)), []string{"q"}, "очередь на месте")
+	})
+
+	t.Run("transfer outlives ctx", func(t *testing.T) {
+		z := newZones(t, nil)
+		defer z.Close(ctx)
+
+		living, err := z.Add(ctx, "living", WithSongs(minuteSongs("a", "b")...))
+		td.CmpNoError(t, err)
+		kitchen, err := z.Add(ctx, "kitchen")
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, living.Play(ctx))
+
+		callCtx, cancel := context.WithCancel(ctx)
+		td.CmpNoError(t, z.Transfer(callCtx, "living", "kitchen"))
+		cancel()
+
+		// горутина воспроизведения успела бы увидеть отмену
+		time.Sleep(10 * time.Millisecond)
+		_, err = kitchen.SimulatePlayback(ctx, 10*time.Second)
+		td.CmpNoError(t, err)
+		st := kitchen.Status(ctx)
+		td.CmpTrue(t, st.Playing, "кухня играет после отмены ctx")
+		td.Cmp(t, st.Song.Name, "a")
+		td.Cmp(t, st.Position, 10*time.Second)
+	})
+
+	t.Run("status", func(t *testing.T) {
+		z := newZones(t, nil)
+		defer z.Close(ctx)
+
+		living, err := z.Add(ctx, "living", WithSongs(minuteSong("a")))
+		td.CmpNoError(t, err)
+		_, err = z.Add(ctx, "bedroom")
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, living.Play(ctx))
+
+		td.Cmp(t, z.Status(ctx), []ZoneStatus{
+			{Zone: "bedroom", Status: Status{Playlist: DefaultPlaylist, Volume: MaxVolume}, State: StateStopped},
+			{Zone: "living", Status: living.Status(ctx), State: StatePlaying},
+		}, "зоны по алфавиту")
+
+		// гостиная занята, её не дождались
+		living.mu.Lock()
+		waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		td.Cmp(t, z.Status(waitCtx), []ZoneStatus{
+			{Zone: "bedroom", Status: Status{Playlist: DefaultPlaylist, Volume: MaxVolume}, State: StateStopped},
+		})
+		living.mu.Unlock()
+	})
+
+	t.Run("state per zone", func(t *testing.T) {
+		storage, err := NewFileStorage(t.TempDir())
+		td.CmpNoError(t, err)
+
+		z := newZones(t, storage)
+		living, err := z.Add(ctx, "living", WithSongs(minuteSongs("a", "b")...))
+		td.CmpNoError(t, err)
+		kitchen, err := z.Add(ctx, "kitchen", WithSongs(minuteSong("x")))
+		td.CmpNoError(t, err)
+		td.CmpNoError(t, living.CreatePlaylist(ctx, "saved"))
+		td.CmpNoError(t, living.SavePlaylist(ctx, "saved"))
+		td.CmpNoError(t, z.Close(ctx))
+
+		_, err = storage.LoadState(ctx)
+		td.CmpTrue(t, errors.Is(err, ErrStateNotFound), "общее состояние не пишется")
+
+		z = newZones(t, storage)
+		defer z.Close(ctx)
+		living, err = z.Add(ctx, "living")
+		td.CmpNoError(t, err)
+		kitchen, err = z.Add(ctx, "kitchen")
+		td.CmpNoError(t, err)
+		td.Cmp(t, names(living), []string{"a", "b"})
+		td.Cmp(t, names(kitchen), []string{"x"})
+
+		td.CmpNoError(t, kitchen.LoadPlaylist(ctx, "saved"), "плейлисты общие")
+	})
+
+	t.Run("storage without zones", func(t *testing.T) {
+		_, err := NewZones(nil, struct{ Storage }{NewMemoryStorage()})
+		td.CmpString(t, err, "storage does not support zone state")
+	})
+}